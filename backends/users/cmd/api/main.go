@@ -1,19 +1,39 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/config"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/email"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/jwtlib"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/logger"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/oidc"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/sms"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/breached"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/memory"
 	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/psql"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/redis"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/transport/grpcserver"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/transport/httpserver"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/webauthn"
+	"go.uber.org/zap"
+)
+
+const (
+	cacheMaxItems        = 100_000
+	cacheCleanupInterval = time.Minute
+	grantPurgeInterval   = time.Hour
 )
 
 func main() {
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("This is a users service"))
-	})
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal(err)
@@ -26,5 +46,150 @@ func main() {
 		log.Fatal(err)
 	}
 	defer repo.Close()
-	log.Fatal(http.ListenAndServe(":8080", nil))
+
+	lgr, err := logger.NewLogger(zap.DebugLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer lgr.Sync()
+
+	// with several replicas codes have to be shared through redis
+	var (
+		cache          domain.Cache
+		rateLimitStore domain.RateLimitStore
+	)
+	if cfg.Redis.Addr != "" {
+		redisCache, err := redis.NewCache(redis.Config{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer redisCache.Close()
+		cache = redisCache
+		rateLimitStore = domain.NewCacheRateLimitStore(redisCache)
+	} else {
+		memoryCache := memory.NewCache(cacheMaxItems, cacheCleanupInterval)
+		defer memoryCache.Close()
+		cache = memoryCache
+		// separate store so counters never push codes out of the cache
+		memoryStore := memory.NewRateLimitStore(cacheMaxItems, cacheCleanupInterval)
+		defer memoryStore.Close()
+		rateLimitStore = memoryStore
+	}
+
+	keyring, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{
+		Algorithm: jwtlib.Algorithm(cfg.JWT.Algorithm),
+		Dir:       cfg.JWT.KeysDir,
+		// old keys have to verify refresh tokens till they expire
+		RetiredKeyTTL: domain.AuthRefreshExp,
+		// new keys sign only when cached JWKS of other services has them,
+		// plus a minute for other replicas to reload the key
+		PublishAhead: jwtlib.JWKSMaxAge + time.Minute,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	stopRotation := keyring.StartRotation(cfg.JWT.RotationInterval, func(err error) {
+		lgr.Errorf("could not rotate jwt keys: %s", err.Error())
+	})
+	defer stopRotation()
+
+	opts := []domain.Option{
+		domain.WithRateLimiter(domain.NewRateLimiter(rateLimitStore, domain.DefaultRateLimitConfig)),
+		domain.WithLockout(domain.LockoutConfig{
+			AccountThreshold: cfg.Lockout.AccountThreshold,
+			IPThreshold:      cfg.Lockout.IPThreshold,
+			Window:           cfg.Lockout.Window,
+			Duration:         cfg.Lockout.Duration,
+		}),
+	}
+	if cfg.TwoFactor.RequiredForAdmins {
+		opts = append(opts, domain.WithRequiredSecondFactor(domain.RoleOwner, domain.RoleAdmin))
+	}
+	if cfg.WebAuthn.RPID != "" {
+		rp, err := webauthn.NewRelyingParty(webauthn.Config{
+			RPID:    cfg.WebAuthn.RPID,
+			RPName:  cfg.WebAuthn.RPName,
+			Origins: cfg.WebAuthn.Origins,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, domain.WithWebAuthn(rp))
+	}
+	if len(cfg.OIDC.Providers) != 0 {
+		providers := make([]domain.OIDCProvider, len(cfg.OIDC.Providers))
+		for i, v := range cfg.OIDC.Providers {
+			p, err := oidc.NewProvider(context.Background(), oidc.Config{
+				Name:         v.Name,
+				Issuer:       v.Issuer,
+				ClientID:     v.ClientID,
+				ClientSecret: v.ClientSecret,
+				RedirectURL:  cfg.OIDC.RedirectURL,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+			providers[i] = p
+		}
+		opts = append(opts, domain.WithOIDCProviders(providers...))
+	}
+	if cfg.Passwords.BreachedDir != "" {
+		breachedPasswords, err := breached.NewDir(cfg.Passwords.BreachedDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, domain.WithPasswordPolicy(domain.NewPasswordPolicy(
+			append(domain.DefaultPasswordRules(), domain.BreachedRule(breachedPasswords))...,
+		)))
+	}
+
+	service, err := domain.NewService(
+		repo,
+		&sms.SMSsender{},
+		&email.Emailer{},
+		cache,
+		lgr,
+		jwtlib.NewJwtManager(
+			keyring,
+			jwtlib.WithIssuer(cfg.JWT.Issuer),
+			jwtlib.WithAudience(cfg.JWT.Issuer),
+			jwtlib.WithAudiences(append([]string{cfg.JWT.Issuer}, cfg.JWT.Audiences...)...),
+			jwtlib.WithClockSkew(cfg.JWT.ClockSkew),
+			// client assertions name either us or our token endpoint
+			jwtlib.WithAssertionAudiences(cfg.JWT.Issuer, strings.TrimSuffix(cfg.JWT.Issuer, "/")+httpserver.TokenPath),
+		),
+		opts...,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// expired grants are already ignored, this only keeps the table small
+	stopGrantPurge := domain.StartGrantPurge(service, grantPurgeInterval, func(err error) {
+		lgr.Errorf("could not purge expired role grants: %s", err.Error())
+	})
+	defer stopGrantPurge()
+	// every request is checked against the caller transports put into context
+	service = domain.NewAuthorizer(service)
+
+	lis, err := net.Listen("tcp", cfg.Server.GRPCPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	grpcServer := grpcserver.NewServer(service)
+	go func() {
+		log.Fatal(grpcServer.Serve(lis))
+	}()
+
+	httpOpts := []httpserver.Option{httpserver.WithJWKS(jwtlib.JWKSPath, keyring)}
+	if cfg.OAuth.LoginURL != "" {
+		httpOpts = append(httpOpts, httpserver.WithAuthorizationServer(httpserver.AuthorizationServer{
+			Issuer:     cfg.JWT.Issuer,
+			LoginURL:   cfg.OAuth.LoginURL,
+			SigningAlg: cfg.JWT.Algorithm,
+		}))
+	}
+	log.Fatal(http.ListenAndServe(cfg.Server.HTTPPort, httpserver.NewHandler(service, httpOpts...)))
 }
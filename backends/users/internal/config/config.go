@@ -2,7 +2,11 @@ package config
 
 import (
 	"errors"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,8 +17,82 @@ type (
 		Password string
 		DBname   string
 	}
+	Server struct {
+		HTTPPort string
+		GRPCPort string
+	}
+	// JWT keys are rotated every RotationInterval. Without KeysDir they
+	// are kept in memory and every restart signs users out.
+	// Audiences are microservices our access tokens are meant for.
+	JWT struct {
+		Algorithm        string
+		KeysDir          string
+		RotationInterval time.Duration
+
+		Issuer    string
+		Audiences []string
+		ClockSkew time.Duration
+	}
+	// BreachedDir is an optional local copy of Pwned Passwords
+	// range files, without it breached passwords are not checked.
+	Passwords struct {
+		BreachedDir string
+	}
+	// After AccountThreshold wrong passwords within Window the account
+	// is locked for Duration, IPThreshold does the same for client ips.
+	// Zero threshold turns the lock off.
+	Lockout struct {
+		AccountThreshold int64
+		IPThreshold      int64
+		Window           time.Duration
+		Duration         time.Duration
+	}
+	// With RequiredForAdmins owners and admins can not
+	// sign in until they set up TOTP
+	TwoFactor struct {
+		RequiredForAdmins bool
+	}
+	// Passkeys are turned on by RPID, the domain they are bound to.
+	// Origins are where browsers run ceremonies, like https://micro-pizzas.com
+	WebAuthn struct {
+		RPID    string
+		RPName  string
+		Origins []string
+	}
+	// OIDC lists providers users can sign in with, RedirectURL
+	// is where providers send users back to our frontend.
+	OIDC struct {
+		RedirectURL string
+		Providers   []OIDCProvider
+	}
+	OIDCProvider struct {
+		Name         string
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+	}
+	// With LoginURL we are an OpenID Connect provider for other apps,
+	// JWT.Issuer has to be the url we are reachable at then.
+	// LoginURL is where our frontend signs users in and asks for consent.
+	OAuth struct {
+		LoginURL string
+	}
+	// Redis is optional, without it codes are kept in memory
+	Redis struct {
+		Addr     string
+		Password string
+	}
 	Config struct {
-		Database Database
+		Database  Database
+		Server    Server
+		JWT       JWT
+		Passwords Passwords
+		Lockout   Lockout
+		TwoFactor TwoFactor
+		WebAuthn  WebAuthn
+		OIDC      OIDC
+		OAuth     OAuth
+		Redis     Redis
 	}
 )
 
@@ -22,10 +100,63 @@ const (
 	databaseUsername = "POSTGRES_USER"
 	databasePassword = "POSTGRES_PASSWORD"
 	databaseDBname   = "POSTGRES_DB"
+
+	serverHTTPPort = "HTTP_PORT"
+	serverGRPCPort = "GRPC_PORT"
+
+	jwtAlgorithm        = "JWT_ALGORITHM"
+	jwtKeysDir          = "JWT_KEYS_DIR"
+	jwtRotationInterval = "JWT_ROTATION_INTERVAL"
+	jwtIssuer           = "JWT_ISSUER"
+	jwtAudiences        = "JWT_AUDIENCES"
+	jwtClockSkew        = "JWT_CLOCK_SKEW"
+
+	passwordsBreachedDir = "BREACHED_PASSWORDS_DIR"
+
+	lockoutAccountThreshold = "LOCKOUT_ACCOUNT_THRESHOLD"
+	lockoutIPThreshold      = "LOCKOUT_IP_THRESHOLD"
+	lockoutWindow           = "LOCKOUT_WINDOW"
+	lockoutDuration         = "LOCKOUT_DURATION"
+
+	twoFactorRequiredForAdmins = "TWO_FACTOR_REQUIRED_FOR_ADMINS"
+
+	webauthnRPID    = "WEBAUTHN_RP_ID"
+	webauthnRPName  = "WEBAUTHN_RP_NAME"
+	webauthnOrigins = "WEBAUTHN_ORIGINS"
+
+	// every provider from OIDC_PROVIDERS=google,apple is configured
+	// with OIDC_GOOGLE_ISSUER, OIDC_GOOGLE_CLIENT_ID and OIDC_GOOGLE_CLIENT_SECRET
+	oidcProviders   = "OIDC_PROVIDERS"
+	oidcRedirectURL = "OIDC_REDIRECT_URL"
+	oidcPrefix      = "OIDC_"
+
+	oauthLoginURL = "OAUTH_LOGIN_URL"
+
+	redisAddr     = "REDIS_ADDR"
+	redisPassword = "REDIS_PASSWORD"
+
+	defaultHTTPPort = ":8080"
+	defaultGRPCPort = ":9090"
+
+	defaultJWTAlgorithm        = "EdDSA"
+	defaultJWTRotationInterval = time.Hour * 24 * 7
+	defaultJWTIssuer           = "users"
+	defaultJWTClockSkew        = time.Second * 30
+
+	defaultLockoutAccountThreshold = 5
+	defaultLockoutIPThreshold      = 50
+	defaultLockoutWindow           = time.Minute * 15
+	defaultLockoutDuration         = time.Minute * 15
 )
 
 var (
-	ErrDBnotFound = errors.New("config: did not find configs for database")
+	ErrDBnotFound       = errors.New("config: did not find configs for database")
+	ErrJWTinvalid       = errors.New("config: invalid jwt rotation interval or clock skew")
+	ErrLockoutInvalid   = errors.New("config: invalid lockout threshold, window or duration")
+	ErrTwoFactorInvalid = errors.New("config: invalid two factor settings")
+	ErrWebAuthnInvalid  = errors.New("config: webauthn needs at least one origin")
+	ErrOIDCInvalid      = errors.New("config: oidc providers need redirect url, issuer and client id")
+	ErrOAuthInvalid     = errors.New("config: oauth needs login url and jwt issuer that are https urls")
 )
 
 func Load(files ...string) (Config, error) {
@@ -38,10 +169,139 @@ func Load(files ...string) (Config, error) {
 			Password: os.Getenv(databasePassword),
 			DBname:   os.Getenv(databaseDBname),
 		},
+		Server: Server{
+			HTTPPort: os.Getenv(serverHTTPPort),
+			GRPCPort: os.Getenv(serverGRPCPort),
+		},
+		JWT: JWT{
+			Algorithm:        os.Getenv(jwtAlgorithm),
+			KeysDir:          os.Getenv(jwtKeysDir),
+			RotationInterval: defaultJWTRotationInterval,
+			Issuer:           os.Getenv(jwtIssuer),
+			ClockSkew:        defaultJWTClockSkew,
+		},
+		Passwords: Passwords{
+			BreachedDir: os.Getenv(passwordsBreachedDir),
+		},
+		Lockout: Lockout{
+			AccountThreshold: defaultLockoutAccountThreshold,
+			IPThreshold:      defaultLockoutIPThreshold,
+			Window:           defaultLockoutWindow,
+			Duration:         defaultLockoutDuration,
+		},
+		WebAuthn: WebAuthn{
+			RPID:   os.Getenv(webauthnRPID),
+			RPName: os.Getenv(webauthnRPName),
+		},
+		OAuth: OAuth{
+			LoginURL: os.Getenv(oauthLoginURL),
+		},
+		Redis: Redis{
+			Addr:     os.Getenv(redisAddr),
+			Password: os.Getenv(redisPassword),
+		},
 	}
 	if cfg.Database.Username == "" || cfg.Database.Password == "" ||
 		cfg.Database.DBname == "" {
 		return cfg, ErrDBnotFound
 	}
+	if cfg.JWT.Algorithm == "" {
+		cfg.JWT.Algorithm = defaultJWTAlgorithm
+	}
+	if v := os.Getenv(jwtRotationInterval); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			return cfg, ErrJWTinvalid
+		}
+		cfg.JWT.RotationInterval = interval
+	}
+	if cfg.JWT.Issuer == "" {
+		cfg.JWT.Issuer = defaultJWTIssuer
+	}
+	// comma separated, like users,orders,products
+	for _, v := range strings.Split(os.Getenv(jwtAudiences), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			cfg.JWT.Audiences = append(cfg.JWT.Audiences, v)
+		}
+	}
+	if v := os.Getenv(jwtClockSkew); v != "" {
+		skew, err := time.ParseDuration(v)
+		if err != nil || skew < 0 {
+			return cfg, ErrJWTinvalid
+		}
+		cfg.JWT.ClockSkew = skew
+	}
+	for env, threshold := range map[string]*int64{
+		lockoutAccountThreshold: &cfg.Lockout.AccountThreshold,
+		lockoutIPThreshold:      &cfg.Lockout.IPThreshold,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return cfg, ErrLockoutInvalid
+			}
+			*threshold = n
+		}
+	}
+	for env, duration := range map[string]*time.Duration{
+		lockoutWindow:   &cfg.Lockout.Window,
+		lockoutDuration: &cfg.Lockout.Duration,
+	} {
+		if v := os.Getenv(env); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return cfg, ErrLockoutInvalid
+			}
+			*duration = d
+		}
+	}
+	if v := os.Getenv(twoFactorRequiredForAdmins); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, ErrTwoFactorInvalid
+		}
+		cfg.TwoFactor.RequiredForAdmins = required
+	}
+	for _, v := range strings.Split(os.Getenv(webauthnOrigins), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			cfg.WebAuthn.Origins = append(cfg.WebAuthn.Origins, v)
+		}
+	}
+	if cfg.WebAuthn.RPID != "" && len(cfg.WebAuthn.Origins) == 0 {
+		return cfg, ErrWebAuthnInvalid
+	}
+	for _, name := range strings.Split(os.Getenv(oidcProviders), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		prefix := oidcPrefix + strings.ToUpper(name) + "_"
+		provider := OIDCProvider{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return cfg, ErrOIDCInvalid
+		}
+		cfg.OIDC.Providers = append(cfg.OIDC.Providers, provider)
+	}
+	cfg.OIDC.RedirectURL = os.Getenv(oidcRedirectURL)
+	if len(cfg.OIDC.Providers) != 0 && cfg.OIDC.RedirectURL == "" {
+		return cfg, ErrOIDCInvalid
+	}
+	if cfg.OAuth.LoginURL != "" {
+		for _, v := range []string{cfg.OAuth.LoginURL, cfg.JWT.Issuer} {
+			if u, err := url.Parse(v); err != nil || u.Scheme != "https" || u.Host == "" {
+				return cfg, ErrOAuthInvalid
+			}
+		}
+	}
+	if cfg.Server.HTTPPort == "" {
+		cfg.Server.HTTPPort = defaultHTTPPort
+	}
+	if cfg.Server.GRPCPort == "" {
+		cfg.Server.GRPCPort = defaultGRPCPort
+	}
 	return cfg, nil
 }
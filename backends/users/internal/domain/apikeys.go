@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// API keys look like mpk_<id>.<secret>, id finds the key and secret is
+// checked against its hash. Keys are random, so like client secrets they
+// are hashed with plain sha256. The prefix lets us tell them from JWTs
+// and lets secret scanners find leaked ones.
+
+const (
+	apiKeyPrefix       = "mpk_"
+	apiKeySecretLength = 32
+)
+
+// apiKeyScopes are everything a key may be allowed to do
+var apiKeyScopes = map[string]bool{
+	ScopeUsersRead:  true,
+	ScopeUsersWrite: true,
+}
+
+func (s *service) CreateAPIKey(ctx context.Context, inp CreateAPIKeyInput) (APIKeyCreation, error) {
+	now := time.Now().UTC()
+	if err := validateAPIKey(inp, now); err != nil {
+		return APIKeyCreation{}, fmt.Errorf("createAPIKey(): %w", err)
+	}
+	if inp.Owner.UserID != 0 {
+		if _, err := s.repo.Read(ctx, inp.Owner.UserID); err != nil {
+			return APIKeyCreation{}, fmt.Errorf("createAPIKey(): %w", err)
+		}
+	}
+
+	id, err := newTokenID()
+	if err != nil {
+		return APIKeyCreation{}, fmt.Errorf("createAPIKey(): could not generate id: %w", err)
+	}
+	b := make([]byte, apiKeySecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return APIKeyCreation{}, fmt.Errorf("createAPIKey(): could not generate secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	key := APIKey{
+		ID:        id,
+		Name:      strings.TrimSpace(inp.Name),
+		Owner:     APIKeyOwner{UserID: inp.Owner.UserID, Organization: strings.TrimSpace(inp.Owner.Organization)},
+		Hash:      hashClientSecret(secret),
+		Scopes:    inp.Scopes,
+		CreatedAt: now,
+	}
+	if inp.ExpiresAt != nil {
+		expiresAt := inp.ExpiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return APIKeyCreation{}, fmt.Errorf("createAPIKey(): %w", err)
+	}
+	return APIKeyCreation{APIKey: key, Key: apiKeyPrefix + id + "." + secret}, nil
+}
+
+func validateAPIKey(inp CreateAPIKeyInput, now time.Time) error {
+	hasUser, hasOrganization := inp.Owner.UserID != 0, strings.TrimSpace(inp.Owner.Organization) != ""
+	switch {
+	case strings.TrimSpace(inp.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidAPIKeyInput)
+	case hasUser == hasOrganization:
+		return fmt.Errorf("%w: owner has to be either a user or an organization", ErrInvalidAPIKeyInput)
+	case len(inp.Scopes) == 0:
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyInput)
+	case inp.ExpiresAt != nil && !inp.ExpiresAt.After(now):
+		return fmt.Errorf("%w: key would be expired already", ErrInvalidAPIKeyInput)
+	}
+	for _, v := range inp.Scopes {
+		if !apiKeyScopes[v] {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyInput, v)
+		}
+	}
+	return nil
+}
+
+// authenticateAPIKey returns ErrInvalidToken for every kind of bad key,
+// so callers can not tell revoked keys from ones that never existed.
+func (s *service) authenticateAPIKey(ctx context.Context, apiKey string) (AccessClaims, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(apiKey, apiKeyPrefix), ".")
+	if !ok || id == "" || secret == "" {
+		return AccessClaims{}, fmt.Errorf("%w: malformed api key", ErrInvalidToken)
+	}
+	key, err := s.repo.ReadAPIKey(ctx, id)
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return AccessClaims{}, fmt.Errorf("%w: unknown api key", ErrInvalidToken)
+	}
+	if err != nil {
+		return AccessClaims{}, err
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case subtle.ConstantTimeCompare([]byte(hashClientSecret(secret)), []byte(key.Hash)) != 1:
+		return AccessClaims{}, fmt.Errorf("%w: unknown api key", ErrInvalidToken)
+	case key.RevokedAt != nil:
+		return AccessClaims{}, fmt.Errorf("%w: api key was revoked", ErrInvalidToken)
+	case key.ExpiresAt != nil && !now.Before(*key.ExpiresAt):
+		return AccessClaims{}, fmt.Errorf("%w: api key is expired", ErrInvalidToken)
+	}
+
+	// partners call us a lot, there is no point to write every use
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= APIKeyLastUsedPrecision {
+		if err := s.repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			s.logger.Errorf("could not update last use of api key %s: %s", key.ID, err.Error())
+		}
+	}
+	return AccessClaims{APIKeyID: key.ID, Scopes: key.Scopes}, nil
+}
+
+func (s *service) APIKeys(ctx context.Context, owner APIKeyOwner) ([]APIKey, error) {
+	keys, err := s.repo.ReadAPIKeys(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("apiKeys(): %w", err)
+	}
+	return keys, nil
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, id string) error {
+	if err := s.repo.RevokeAPIKey(ctx, id, time.Now().UTC()); err != nil {
+		return fmt.Errorf("revokeAPIKey(): %w", err)
+	}
+	return nil
+}
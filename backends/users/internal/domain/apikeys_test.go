@@ -0,0 +1,180 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name string
+		inp  domain.CreateAPIKeyInput
+		err  error
+	}{
+		{
+			name: "key of organization",
+			inp: domain.CreateAPIKeyInput{
+				Name:   "Glovo",
+				Owner:  domain.APIKeyOwner{Organization: "glovo"},
+				Scopes: []string{domain.ScopeUsersRead},
+			},
+		},
+		{
+			name: "fail with two owners",
+			inp: domain.CreateAPIKeyInput{
+				Name:   "Glovo",
+				Owner:  domain.APIKeyOwner{UserID: 1, Organization: "glovo"},
+				Scopes: []string{domain.ScopeUsersRead},
+			},
+			err: domain.ErrInvalidAPIKeyInput,
+		},
+		{
+			name: "fail with scope keys can not have",
+			inp: domain.CreateAPIKeyInput{
+				Name:   "Glovo",
+				Owner:  domain.APIKeyOwner{Organization: "glovo"},
+				Scopes: []string{domain.ScopeRoles},
+			},
+			err: domain.ErrInvalidAPIKeyInput,
+		},
+		{
+			name: "fail when expired already",
+			inp: domain.CreateAPIKeyInput{
+				Name:      "Glovo",
+				Owner:     domain.APIKeyOwner{Organization: "glovo"},
+				Scopes:    []string{domain.ScopeUsersRead},
+				ExpiresAt: &past,
+			},
+			err: domain.ErrInvalidAPIKeyInput,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			var stored domain.APIKey
+			if tc.err == nil {
+				deps.repo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, k domain.APIKey) error {
+						stored = k
+						return nil
+					})
+			}
+
+			created, err := s.CreateAPIKey(context.Background(), tc.inp)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(created.Key, "mpk_"+stored.ID+".") || strings.Contains(created.Key, stored.Hash) {
+				t.Errorf("unexpected key %q for %+v", created.Key, stored)
+			}
+
+			// the key has to work right after it was made
+			deps.repo.EXPECT().ReadAPIKey(gomock.Any(), stored.ID).Return(stored, nil)
+			deps.repo.EXPECT().TouchAPIKey(gomock.Any(), stored.ID, gomock.Any()).Return(nil)
+			claims, err := s.Authenticate(context.Background(), created.Key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.APIKeyID != stored.ID || !claims.Scoped() || !claims.HasScope(domain.ScopeUsersRead) {
+				t.Errorf("got %+v", claims)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	// hash of "secret"
+	const hash = "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+	past, recently, future := time.Now().Add(-time.Hour), time.Now().Add(-time.Second), time.Now().Add(time.Hour)
+	key := domain.APIKey{ID: "key", Hash: hash, Scopes: []string{domain.ScopeUsersRead}}
+
+	testCases := []struct {
+		name    string
+		apiKey  string
+		stored  func(k domain.APIKey) domain.APIKey
+		touched bool
+		err     error
+	}{
+		{
+			name:    "write first use",
+			apiKey:  "mpk_key.secret",
+			stored:  func(k domain.APIKey) domain.APIKey { k.ExpiresAt = &future; return k },
+			touched: true,
+		},
+		{
+			name:   "do not write every use",
+			apiKey: "mpk_key.secret",
+			stored: func(k domain.APIKey) domain.APIKey { k.LastUsedAt = &recently; return k },
+		},
+		{
+			name:   "fail with wrong secret",
+			apiKey: "mpk_key.wrong",
+			stored: func(k domain.APIKey) domain.APIKey { return k },
+			err:    domain.ErrInvalidToken,
+		},
+		{
+			name:   "fail when revoked",
+			apiKey: "mpk_key.secret",
+			stored: func(k domain.APIKey) domain.APIKey { k.RevokedAt = &past; return k },
+			err:    domain.ErrInvalidToken,
+		},
+		{
+			name:   "fail when expired",
+			apiKey: "mpk_key.secret",
+			stored: func(k domain.APIKey) domain.APIKey { k.ExpiresAt = &past; return k },
+			err:    domain.ErrInvalidToken,
+		},
+		{
+			name:   "fail with unknown key",
+			apiKey: "mpk_unknown.secret",
+			err:    domain.ErrInvalidToken,
+		},
+		{
+			name:   "fail with malformed key",
+			apiKey: "mpk_key",
+			err:    domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.repo.EXPECT().ReadAPIKey(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, id string) (domain.APIKey, error) {
+					if id != key.ID || tc.stored == nil {
+						return domain.APIKey{}, domain.ErrAPIKeyNotFound
+					}
+					return tc.stored(key), nil
+				}).AnyTimes()
+			if tc.touched {
+				deps.repo.EXPECT().TouchAPIKey(gomock.Any(), key.ID, gomock.Any()).Return(nil)
+			}
+
+			if _, err := s.Authenticate(context.Background(), tc.apiKey); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCanNotSetPassword(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := domain.WithCaller(context.Background(), domain.AccessClaims{APIKeyID: "key", Scopes: []string{domain.ScopeUsersWrite}})
+
+	err := s.Update(ctx, domain.UpdateInput{ID: 1, FullName: "Pizza Lover", Password: "correct horse battery staple"})
+	if !errors.Is(err, domain.ErrNotAllowed) {
+		t.Errorf("expected %v, got %v", domain.ErrNotAllowed, err)
+	}
+}
@@ -0,0 +1,685 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+)
+
+// Transports put the caller into context and authorizer checks it
+// before the service does anything. Users are allowed by built in
+// roles or by permissions of any role, so custom roles work too.
+// Clients and api keys have no roles, only their scopes count.
+// Tokens clients got on behalf of users do not let them act as
+// those users either.
+
+type policy struct {
+	// public methods check passwords, codes or tokens they get on their own
+	public bool
+	// roles that may do it to anybody
+	roles []Role
+	// permission lets users with any role do it to anybody
+	permission string
+	// self lets users do it to their own account
+	self bool
+	// scope lets clients and api keys do it
+	scope string
+	// owners may be changed only by owners
+	touchesOwner bool
+}
+
+var (
+	admins = []Role{RoleOwner, RoleAdmin}
+	staff  = []Role{RoleOwner, RoleAdmin, RoleModerator}
+)
+
+// policies of every method of Service, methods that are not here
+// are not allowed to anybody.
+var policies = map[string]policy{
+	"RequestSignUp":        {public: true},
+	"SignUp":               {public: true},
+	"RequestSignIn":        {public: true},
+	"SignIn":               {public: true},
+	"SignInEmailPassword":  {public: true},
+	"Refresh":              {public: true},
+	"RequestPasswordReset": {public: true},
+	"ResetPassword":        {public: true},
+	"SignOut":              {public: true},
+	"RevokeOtherSessions":  {public: true},
+	"VerifySecondFactor":   {public: true},
+	"BeginPasskeySignIn":   {public: true},
+	"FinishPasskeySignIn":  {public: true},
+	"BeginOIDCSignIn":      {public: true},
+	"FinishOIDCSignIn":     {public: true},
+	"Token":                {public: true},
+	"UserInfo":             {public: true},
+	"Authenticate":         {public: true},
+
+	"Read":              {self: true, roles: staff, permission: PermissionUsersRead, scope: ScopeUsersRead},
+	"ReadByEmail":       {roles: staff, permission: PermissionUsersRead, scope: ScopeUsersRead},
+	"ReadByPhoneNumber": {roles: staff, permission: PermissionUsersRead, scope: ScopeUsersRead},
+	"ReadAll":           {roles: admins, permission: PermissionUsersRead},
+	"Update":            {self: true, roles: admins, permission: PermissionUsersWrite, scope: ScopeUsersWrite, touchesOwner: true},
+	"Delete":            {self: true, roles: admins, permission: PermissionUsersWrite, touchesOwner: true},
+	"AddRole":           {roles: admins, permission: PermissionRolesAssign, touchesOwner: true},
+	"RemoveRole":        {roles: admins, permission: PermissionRolesAssign, touchesOwner: true},
+	"UnlockAccount":     {roles: admins, permission: PermissionUsersWrite, touchesOwner: true},
+
+	// the background job calls the service itself, not through authorizer
+	"PurgeExpiredGrants": {roles: admins},
+
+	"Roles":              {roles: admins, permission: PermissionRolesAssign},
+	"CreateRole":         {roles: admins, permission: PermissionRolesManage},
+	"SetRolePermissions": {roles: admins, permission: PermissionRolesManage},
+	"DeleteRole":         {roles: admins, permission: PermissionRolesManage},
+
+	"RequestRole":       {self: true},
+	"RoleRequest":       {self: true, roles: admins, permission: PermissionRolesAssign},
+	"RoleRequests":      {self: true, roles: admins, permission: PermissionRolesAssign},
+	"ReviewRoleRequest": {roles: admins, permission: PermissionRolesAssign, touchesOwner: true},
+
+	"Sessions":      {self: true, roles: admins},
+	"RevokeSession": {self: true, roles: admins, touchesOwner: true},
+
+	"EnrollTOTP":              {self: true},
+	"ConfirmTOTP":             {self: true},
+	"DisableTOTP":             {self: true},
+	"RegenerateRecoveryCodes": {self: true},
+
+	"BeginPasskeyRegistration":  {self: true},
+	"FinishPasskeyRegistration": {self: true},
+	"Passkeys":                  {self: true, roles: admins},
+	"DeletePasskey":             {self: true, roles: admins, touchesOwner: true},
+
+	"BeginOIDCLink":  {self: true},
+	"FinishOIDCLink": {self: true},
+	"Identities":     {self: true, roles: admins},
+	"UnlinkIdentity": {self: true, roles: admins, touchesOwner: true},
+
+	"Authorize":     {self: true},
+	"GrantConsent":  {self: true},
+	"Consents":      {self: true},
+	"RevokeConsent": {self: true},
+
+	"CreateAPIKey": {roles: admins},
+	"APIKeys":      {roles: admins},
+	"RevokeAPIKey": {roles: admins},
+
+	"RegisterOAuthClient": {roles: admins},
+	"OAuthClient":         {roles: admins},
+	"OAuthClients":        {roles: admins},
+	"DeleteOAuthClient":   {roles: admins},
+}
+
+// authorizer does not embed Service,
+// so a new method can not skip the checks by accident
+type authorizer struct {
+	service Service
+}
+
+// NewAuthorizer wraps s so guarded methods are called only if caller
+// from context is allowed to, everybody else gets ErrNotAllowed.
+func NewAuthorizer(s Service) Service {
+	return &authorizer{service: s}
+}
+
+// authorize checks if caller may call method for the target user,
+// target is 0 for methods that are not about a particular user.
+func (a *authorizer) authorize(ctx context.Context, method string, target ID) error {
+	p, ok := policies[method]
+	if !ok {
+		return fmt.Errorf("%s: no policy: %w", method, ErrNotAllowed)
+	}
+	if p.public {
+		return nil
+	}
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%s: anonymous caller: %w", method, ErrNotAllowed)
+	}
+	if caller.Scoped() {
+		if p.scope == "" || !caller.HasScope(p.scope) {
+			return fmt.Errorf("%s: needs scope %q: %w", method, p.scope, ErrNotAllowed)
+		}
+		return a.checkOwner(ctx, p, caller, target)
+	}
+
+	allowed := p.self && target != 0 && caller.UserID == target
+	allowed = allowed || (p.permission != "" && caller.HasPermission(p.permission))
+	for _, v := range p.roles {
+		allowed = allowed || hasRole(caller.Roles, v)
+	}
+	if !allowed {
+		return fmt.Errorf("%s: %w", method, ErrNotAllowed)
+	}
+	return a.checkOwner(ctx, p, caller, target)
+}
+
+func (a *authorizer) checkOwner(ctx context.Context, p policy, caller AccessClaims, target ID) error {
+	if !p.touchesOwner || target == 0 || hasRole(caller.Roles, RoleOwner) {
+		return nil
+	}
+	u, err := a.service.Read(ctx, target)
+	if err != nil {
+		return err
+	}
+	if hasRole(u.Roles, RoleOwner) {
+		return fmt.Errorf("only owners can change owners: %w", ErrNotAllowed)
+	}
+	return nil
+}
+
+// checkPermissions does not let callers give permissions they do not
+// have themselves, including to their own roles.
+func checkPermissions(caller AccessClaims, permissions []string) error {
+	for _, v := range permissions {
+		if !caller.HasPermission(v) {
+			return fmt.Errorf("caller does not have %q: %w", v, ErrNotAllowed)
+		}
+	}
+	return nil
+}
+
+// checkGrantable is checkPermissions for all permissions of the role
+func (a *authorizer) checkGrantable(ctx context.Context, role Role) error {
+	caller, _ := CallerFromContext(ctx)
+	if hasRole(caller.Roles, RoleOwner) {
+		return nil
+	}
+	roles, err := a.service.Roles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range roles {
+		if v.ID == role {
+			return checkPermissions(caller, v.Permissions)
+		}
+	}
+	return ErrRoleNotFound
+}
+
+func hasRole(roles []Role, role Role) bool {
+	for _, v := range roles {
+		if v == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *authorizer) Read(ctx context.Context, id ID) (User, error) {
+	if err := a.authorize(ctx, "Read", id); err != nil {
+		return User{}, fmt.Errorf("read(): %w", err)
+	}
+	return a.service.Read(ctx, id)
+}
+
+func (a *authorizer) ReadByEmail(ctx context.Context, email string) (User, error) {
+	if err := a.authorize(ctx, "ReadByEmail", 0); err != nil {
+		return User{}, fmt.Errorf("readByEmail(): %w", err)
+	}
+	return a.service.ReadByEmail(ctx, email)
+}
+
+func (a *authorizer) ReadByPhoneNumber(ctx context.Context, phoneNumber string) (User, error) {
+	if err := a.authorize(ctx, "ReadByPhoneNumber", 0); err != nil {
+		return User{}, fmt.Errorf("readByPhoneNumber(): %w", err)
+	}
+	return a.service.ReadByPhoneNumber(ctx, phoneNumber)
+}
+
+func (a *authorizer) ReadAll(ctx context.Context, cfg ReadAllInput) ([]User, error) {
+	if err := a.authorize(ctx, "ReadAll", 0); err != nil {
+		return nil, fmt.Errorf("readAll(): %w", err)
+	}
+	return a.service.ReadAll(ctx, cfg)
+}
+
+func (a *authorizer) Update(ctx context.Context, changeset UpdateInput) error {
+	if err := a.authorize(ctx, "Update", changeset.ID); err != nil {
+		return fmt.Errorf("update(): %w", err)
+	}
+	return a.service.Update(ctx, changeset)
+}
+
+func (a *authorizer) Delete(ctx context.Context, userID ID) error {
+	if err := a.authorize(ctx, "Delete", userID); err != nil {
+		return fmt.Errorf("delete(): %w", err)
+	}
+	return a.service.Delete(ctx, userID)
+}
+
+func (a *authorizer) AddRole(ctx context.Context, inp AddRoleInput) error {
+	if err := a.authorize(ctx, "AddRole", inp.UserID); err != nil {
+		return fmt.Errorf("addRole(): %w", err)
+	}
+	if err := a.checkGrantable(ctx, inp.Role); err != nil {
+		return fmt.Errorf("addRole(): %w", err)
+	}
+	return a.service.AddRole(ctx, inp)
+}
+
+func (a *authorizer) RemoveRole(ctx context.Context, userID ID, role Role, scope string) error {
+	if err := a.authorize(ctx, "RemoveRole", userID); err != nil {
+		return fmt.Errorf("removeRole(): %w", err)
+	}
+	if err := a.checkGrantable(ctx, role); err != nil {
+		return fmt.Errorf("removeRole(): %w", err)
+	}
+	return a.service.RemoveRole(ctx, userID, role, scope)
+}
+
+func (a *authorizer) PurgeExpiredGrants(ctx context.Context) (int64, error) {
+	if err := a.authorize(ctx, "PurgeExpiredGrants", 0); err != nil {
+		return 0, fmt.Errorf("purgeExpiredGrants(): %w", err)
+	}
+	return a.service.PurgeExpiredGrants(ctx)
+}
+
+func (a *authorizer) RequestRole(ctx context.Context, inp RequestRoleInput) (RoleRequest, error) {
+	if err := a.authorize(ctx, "RequestRole", inp.UserID); err != nil {
+		return RoleRequest{}, fmt.Errorf("requestRole(): %w", err)
+	}
+	return a.service.RequestRole(ctx, inp)
+}
+
+func (a *authorizer) RoleRequest(ctx context.Context, id string) (RoleRequest, error) {
+	req, err := a.roleRequest(ctx, "RoleRequest", id)
+	if err != nil {
+		return RoleRequest{}, fmt.Errorf("roleRequest(): %w", err)
+	}
+	return req, nil
+}
+
+// roleRequest reads the request and checks method against its author.
+// Only callers allowed to see every request learn that an id does not exist.
+func (a *authorizer) roleRequest(ctx context.Context, method, id string) (RoleRequest, error) {
+	req, err := a.service.RoleRequest(ctx, id)
+	if err != nil {
+		if authErr := a.authorize(ctx, method, 0); authErr != nil {
+			return RoleRequest{}, authErr
+		}
+		return RoleRequest{}, err
+	}
+	if err := a.authorize(ctx, method, req.UserID); err != nil {
+		return RoleRequest{}, err
+	}
+	return req, nil
+}
+
+// RoleRequests of everybody are the queue of admins,
+// users may read only their own ones.
+func (a *authorizer) RoleRequests(ctx context.Context, inp ReadRoleRequestsInput) ([]RoleRequest, error) {
+	if err := a.authorize(ctx, "RoleRequests", inp.UserID); err != nil {
+		return nil, fmt.Errorf("roleRequests(): %w", err)
+	}
+	return a.service.RoleRequests(ctx, inp)
+}
+
+// ReviewRoleRequest is checked like AddRole of the requested role,
+// nobody reviews their own requests.
+func (a *authorizer) ReviewRoleRequest(ctx context.Context, inp ReviewRoleRequestInput) (RoleRequest, error) {
+	req, err := a.roleRequest(ctx, "ReviewRoleRequest", inp.ID)
+	if err != nil {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", err)
+	}
+	if caller, _ := CallerFromContext(ctx); caller.UserID == req.UserID {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): own request: %w", ErrNotAllowed)
+	}
+	if err := a.checkGrantable(ctx, req.Role); err != nil {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", err)
+	}
+	return a.service.ReviewRoleRequest(ctx, inp)
+}
+
+func (a *authorizer) Roles(ctx context.Context) ([]RoleDefinition, error) {
+	if err := a.authorize(ctx, "Roles", 0); err != nil {
+		return nil, fmt.Errorf("roles(): %w", err)
+	}
+	return a.service.Roles(ctx)
+}
+
+func (a *authorizer) CreateRole(ctx context.Context, inp CreateRoleInput) (RoleDefinition, error) {
+	if err := a.authorize(ctx, "CreateRole", 0); err != nil {
+		return RoleDefinition{}, fmt.Errorf("createRole(): %w", err)
+	}
+	caller, _ := CallerFromContext(ctx)
+	if err := checkPermissions(caller, inp.Permissions); err != nil {
+		return RoleDefinition{}, fmt.Errorf("createRole(): %w", err)
+	}
+	return a.service.CreateRole(ctx, inp)
+}
+
+func (a *authorizer) SetRolePermissions(ctx context.Context, role Role, permissions []string) error {
+	if err := a.authorize(ctx, "SetRolePermissions", 0); err != nil {
+		return fmt.Errorf("setRolePermissions(): %w", err)
+	}
+	caller, _ := CallerFromContext(ctx)
+	if err := checkPermissions(caller, permissions); err != nil {
+		return fmt.Errorf("setRolePermissions(): %w", err)
+	}
+	// taking away permissions is giving them back later
+	if err := a.checkGrantable(ctx, role); err != nil {
+		return fmt.Errorf("setRolePermissions(): %w", err)
+	}
+	return a.service.SetRolePermissions(ctx, role, permissions)
+}
+
+func (a *authorizer) DeleteRole(ctx context.Context, role Role) error {
+	if err := a.authorize(ctx, "DeleteRole", 0); err != nil {
+		return fmt.Errorf("deleteRole(): %w", err)
+	}
+	if err := a.checkGrantable(ctx, role); err != nil {
+		return fmt.Errorf("deleteRole(): %w", err)
+	}
+	return a.service.DeleteRole(ctx, role)
+}
+
+func (a *authorizer) UnlockAccount(ctx context.Context, userID ID) error {
+	if err := a.authorize(ctx, "UnlockAccount", userID); err != nil {
+		return fmt.Errorf("unlockAccount(): %w", err)
+	}
+	return a.service.UnlockAccount(ctx, userID)
+}
+
+func (a *authorizer) Sessions(ctx context.Context, userID ID) ([]Session, error) {
+	if err := a.authorize(ctx, "Sessions", userID); err != nil {
+		return nil, fmt.Errorf("sessions(): %w", err)
+	}
+	return a.service.Sessions(ctx, userID)
+}
+
+func (a *authorizer) RevokeSession(ctx context.Context, userID ID, sessionID string) error {
+	if err := a.authorize(ctx, "RevokeSession", userID); err != nil {
+		return fmt.Errorf("revokeSession(): %w", err)
+	}
+	return a.service.RevokeSession(ctx, userID, sessionID)
+}
+
+func (a *authorizer) EnrollTOTP(ctx context.Context, userID ID) (TOTPEnrollment, error) {
+	if err := a.authorize(ctx, "EnrollTOTP", userID); err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("enrollTOTP(): %w", err)
+	}
+	return a.service.EnrollTOTP(ctx, userID)
+}
+
+func (a *authorizer) ConfirmTOTP(ctx context.Context, userID ID, code string) ([]string, error) {
+	if err := a.authorize(ctx, "ConfirmTOTP", userID); err != nil {
+		return nil, fmt.Errorf("confirmTOTP(): %w", err)
+	}
+	return a.service.ConfirmTOTP(ctx, userID, code)
+}
+
+func (a *authorizer) DisableTOTP(ctx context.Context, userID ID, code, password string) error {
+	if err := a.authorize(ctx, "DisableTOTP", userID); err != nil {
+		return fmt.Errorf("disableTOTP(): %w", err)
+	}
+	return a.service.DisableTOTP(ctx, userID, code, password)
+}
+
+func (a *authorizer) RegenerateRecoveryCodes(ctx context.Context, userID ID, code string) ([]string, error) {
+	if err := a.authorize(ctx, "RegenerateRecoveryCodes", userID); err != nil {
+		return nil, fmt.Errorf("regenerateRecoveryCodes(): %w", err)
+	}
+	return a.service.RegenerateRecoveryCodes(ctx, userID, code)
+}
+
+func (a *authorizer) BeginPasskeyRegistration(ctx context.Context, userID ID) (PasskeyCeremony, error) {
+	if err := a.authorize(ctx, "BeginPasskeyRegistration", userID); err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeyRegistration(): %w", err)
+	}
+	return a.service.BeginPasskeyRegistration(ctx, userID)
+}
+
+func (a *authorizer) FinishPasskeyRegistration(ctx context.Context, inp FinishPasskeyInput) (Passkey, error) {
+	if err := a.authorize(ctx, "FinishPasskeyRegistration", inp.UserID); err != nil {
+		return Passkey{}, fmt.Errorf("finishPasskeyRegistration(): %w", err)
+	}
+	return a.service.FinishPasskeyRegistration(ctx, inp)
+}
+
+func (a *authorizer) Passkeys(ctx context.Context, userID ID) ([]Passkey, error) {
+	if err := a.authorize(ctx, "Passkeys", userID); err != nil {
+		return nil, fmt.Errorf("passkeys(): %w", err)
+	}
+	return a.service.Passkeys(ctx, userID)
+}
+
+func (a *authorizer) DeletePasskey(ctx context.Context, userID ID, passkeyID string) error {
+	if err := a.authorize(ctx, "DeletePasskey", userID); err != nil {
+		return fmt.Errorf("deletePasskey(): %w", err)
+	}
+	return a.service.DeletePasskey(ctx, userID, passkeyID)
+}
+
+func (a *authorizer) BeginOIDCLink(ctx context.Context, userID ID, provider string) (OIDCAuthorization, error) {
+	if err := a.authorize(ctx, "BeginOIDCLink", userID); err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCLink(): %w", err)
+	}
+	return a.service.BeginOIDCLink(ctx, userID, provider)
+}
+
+func (a *authorizer) FinishOIDCLink(ctx context.Context, userID ID, inp FinishOIDCInput) (Identity, error) {
+	if err := a.authorize(ctx, "FinishOIDCLink", userID); err != nil {
+		return Identity{}, fmt.Errorf("finishOIDCLink(): %w", err)
+	}
+	return a.service.FinishOIDCLink(ctx, userID, inp)
+}
+
+func (a *authorizer) Identities(ctx context.Context, userID ID) ([]Identity, error) {
+	if err := a.authorize(ctx, "Identities", userID); err != nil {
+		return nil, fmt.Errorf("identities(): %w", err)
+	}
+	return a.service.Identities(ctx, userID)
+}
+
+func (a *authorizer) UnlinkIdentity(ctx context.Context, userID ID, provider string) error {
+	if err := a.authorize(ctx, "UnlinkIdentity", userID); err != nil {
+		return fmt.Errorf("unlinkIdentity(): %w", err)
+	}
+	return a.service.UnlinkIdentity(ctx, userID, provider)
+}
+
+func (a *authorizer) Authorize(ctx context.Context, userID ID, inp AuthorizeInput) (string, error) {
+	if err := a.authorize(ctx, "Authorize", userID); err != nil {
+		return "", fmt.Errorf("authorize(): %w", err)
+	}
+	return a.service.Authorize(ctx, userID, inp)
+}
+
+func (a *authorizer) GrantConsent(ctx context.Context, userID ID, clientID string, scopes []string) error {
+	if err := a.authorize(ctx, "GrantConsent", userID); err != nil {
+		return fmt.Errorf("grantConsent(): %w", err)
+	}
+	return a.service.GrantConsent(ctx, userID, clientID, scopes)
+}
+
+func (a *authorizer) Consents(ctx context.Context, userID ID) ([]Consent, error) {
+	if err := a.authorize(ctx, "Consents", userID); err != nil {
+		return nil, fmt.Errorf("consents(): %w", err)
+	}
+	return a.service.Consents(ctx, userID)
+}
+
+func (a *authorizer) RevokeConsent(ctx context.Context, userID ID, clientID string) error {
+	if err := a.authorize(ctx, "RevokeConsent", userID); err != nil {
+		return fmt.Errorf("revokeConsent(): %w", err)
+	}
+	return a.service.RevokeConsent(ctx, userID, clientID)
+}
+
+func (a *authorizer) CreateAPIKey(ctx context.Context, inp CreateAPIKeyInput) (APIKeyCreation, error) {
+	if err := a.authorize(ctx, "CreateAPIKey", 0); err != nil {
+		return APIKeyCreation{}, fmt.Errorf("createAPIKey(): %w", err)
+	}
+	return a.service.CreateAPIKey(ctx, inp)
+}
+
+func (a *authorizer) APIKeys(ctx context.Context, owner APIKeyOwner) ([]APIKey, error) {
+	if err := a.authorize(ctx, "APIKeys", 0); err != nil {
+		return nil, fmt.Errorf("apiKeys(): %w", err)
+	}
+	return a.service.APIKeys(ctx, owner)
+}
+
+func (a *authorizer) RevokeAPIKey(ctx context.Context, id string) error {
+	if err := a.authorize(ctx, "RevokeAPIKey", 0); err != nil {
+		return fmt.Errorf("revokeAPIKey(): %w", err)
+	}
+	return a.service.RevokeAPIKey(ctx, id)
+}
+
+func (a *authorizer) RegisterOAuthClient(ctx context.Context, inp RegisterClientInput) (ClientRegistration, error) {
+	if err := a.authorize(ctx, "RegisterOAuthClient", 0); err != nil {
+		return ClientRegistration{}, fmt.Errorf("registerOAuthClient(): %w", err)
+	}
+	return a.service.RegisterOAuthClient(ctx, inp)
+}
+
+func (a *authorizer) OAuthClient(ctx context.Context, clientID string) (OAuthClient, error) {
+	if err := a.authorize(ctx, "OAuthClient", 0); err != nil {
+		return OAuthClient{}, fmt.Errorf("oauthClient(): %w", err)
+	}
+	return a.service.OAuthClient(ctx, clientID)
+}
+
+func (a *authorizer) OAuthClients(ctx context.Context) ([]OAuthClient, error) {
+	if err := a.authorize(ctx, "OAuthClients", 0); err != nil {
+		return nil, fmt.Errorf("oauthClients(): %w", err)
+	}
+	return a.service.OAuthClients(ctx)
+}
+
+func (a *authorizer) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	if err := a.authorize(ctx, "DeleteOAuthClient", 0); err != nil {
+		return fmt.Errorf("deleteOAuthClient(): %w", err)
+	}
+	return a.service.DeleteOAuthClient(ctx, clientID)
+}
+
+// Methods below are public, they are wrapped only so every method goes through authorize.
+
+func (a *authorizer) RequestSignUp(ctx context.Context, inp RequestSignUpInput) error {
+	if err := a.authorize(ctx, "RequestSignUp", 0); err != nil {
+		return fmt.Errorf("requestSignUp(): %w", err)
+	}
+	return a.service.RequestSignUp(ctx, inp)
+}
+
+func (a *authorizer) SignUp(ctx context.Context, inp SignUpInput) (SignInOutput, error) {
+	if err := a.authorize(ctx, "SignUp", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("signUp(): %w", err)
+	}
+	return a.service.SignUp(ctx, inp)
+}
+
+func (a *authorizer) RequestSignIn(ctx context.Context, inp RequestSignInInput) error {
+	if err := a.authorize(ctx, "RequestSignIn", 0); err != nil {
+		return fmt.Errorf("requestSignIn(): %w", err)
+	}
+	return a.service.RequestSignIn(ctx, inp)
+}
+
+func (a *authorizer) SignIn(ctx context.Context, inp SignInInput) (SignInOutput, error) {
+	if err := a.authorize(ctx, "SignIn", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("signIn(): %w", err)
+	}
+	return a.service.SignIn(ctx, inp)
+}
+
+func (a *authorizer) SignInEmailPassword(ctx context.Context, email, password string) (SignInOutput, error) {
+	if err := a.authorize(ctx, "SignInEmailPassword", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", err)
+	}
+	return a.service.SignInEmailPassword(ctx, email, password)
+}
+
+func (a *authorizer) Refresh(ctx context.Context, refreshKey string) (SignInOutput, error) {
+	if err := a.authorize(ctx, "Refresh", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	}
+	return a.service.Refresh(ctx, refreshKey)
+}
+
+func (a *authorizer) RequestPasswordReset(ctx context.Context, email string) error {
+	if err := a.authorize(ctx, "RequestPasswordReset", 0); err != nil {
+		return fmt.Errorf("requestPasswordReset(): %w", err)
+	}
+	return a.service.RequestPasswordReset(ctx, email)
+}
+
+func (a *authorizer) ResetPassword(ctx context.Context, inp ResetPasswordInput) error {
+	if err := a.authorize(ctx, "ResetPassword", 0); err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+	return a.service.ResetPassword(ctx, inp)
+}
+
+func (a *authorizer) SignOut(ctx context.Context, refreshKey string) error {
+	if err := a.authorize(ctx, "SignOut", 0); err != nil {
+		return fmt.Errorf("signOut(): %w", err)
+	}
+	return a.service.SignOut(ctx, refreshKey)
+}
+
+func (a *authorizer) RevokeOtherSessions(ctx context.Context, refreshKey string) error {
+	if err := a.authorize(ctx, "RevokeOtherSessions", 0); err != nil {
+		return fmt.Errorf("revokeOtherSessions(): %w", err)
+	}
+	return a.service.RevokeOtherSessions(ctx, refreshKey)
+}
+
+func (a *authorizer) VerifySecondFactor(ctx context.Context, challengeToken, code string) (SignInOutput, error) {
+	if err := a.authorize(ctx, "VerifySecondFactor", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+	return a.service.VerifySecondFactor(ctx, challengeToken, code)
+}
+
+func (a *authorizer) BeginPasskeySignIn(ctx context.Context, email string) (PasskeyCeremony, error) {
+	if err := a.authorize(ctx, "BeginPasskeySignIn", 0); err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeySignIn(): %w", err)
+	}
+	return a.service.BeginPasskeySignIn(ctx, email)
+}
+
+func (a *authorizer) FinishPasskeySignIn(ctx context.Context, inp FinishPasskeyInput) (SignInOutput, error) {
+	if err := a.authorize(ctx, "FinishPasskeySignIn", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+	return a.service.FinishPasskeySignIn(ctx, inp)
+}
+
+func (a *authorizer) BeginOIDCSignIn(ctx context.Context, provider string) (OIDCAuthorization, error) {
+	if err := a.authorize(ctx, "BeginOIDCSignIn", 0); err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCSignIn(): %w", err)
+	}
+	return a.service.BeginOIDCSignIn(ctx, provider)
+}
+
+func (a *authorizer) FinishOIDCSignIn(ctx context.Context, inp FinishOIDCInput) (SignInOutput, error) {
+	if err := a.authorize(ctx, "FinishOIDCSignIn", 0); err != nil {
+		return SignInOutput{}, fmt.Errorf("finishOIDCSignIn(): %w", err)
+	}
+	return a.service.FinishOIDCSignIn(ctx, inp)
+}
+
+func (a *authorizer) Token(ctx context.Context, inp TokenInput) (TokenOutput, error) {
+	if err := a.authorize(ctx, "Token", 0); err != nil {
+		return TokenOutput{}, fmt.Errorf("token(): %w", err)
+	}
+	return a.service.Token(ctx, inp)
+}
+
+func (a *authorizer) UserInfo(ctx context.Context, accessKey string) (UserInfo, error) {
+	if err := a.authorize(ctx, "UserInfo", 0); err != nil {
+		return UserInfo{}, fmt.Errorf("userInfo(): %w", err)
+	}
+	return a.service.UserInfo(ctx, accessKey)
+}
+
+func (a *authorizer) Authenticate(ctx context.Context, accessKey string) (AccessClaims, error) {
+	if err := a.authorize(ctx, "Authenticate", 0); err != nil {
+		return AccessClaims{}, fmt.Errorf("authenticate(): %w", err)
+	}
+	return a.service.Authenticate(ctx, accessKey)
+}
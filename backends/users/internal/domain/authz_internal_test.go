@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEveryMethodHasPolicy catches new methods of Service that
+// nobody decided about, authorizer would deny them to everybody.
+func TestEveryMethodHasPolicy(t *testing.T) {
+	methods := reflect.TypeOf((*Service)(nil)).Elem()
+	for i := 0; i < methods.NumMethod(); i++ {
+		name := methods.Method(i).Name
+		if _, ok := policies[name]; !ok {
+			t.Errorf("%s has no policy", name)
+		}
+	}
+	for name := range policies {
+		if _, ok := methods.MethodByName(name); !ok {
+			t.Errorf("policy of %s which is not a method of Service", name)
+		}
+	}
+}
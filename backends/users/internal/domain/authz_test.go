@@ -0,0 +1,309 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestAuthorizer(t *testing.T) {
+	var (
+		owner = domain.User{ID: 1, Roles: []domain.Role{domain.RoleOwner}}
+		admin = domain.User{ID: 2, Roles: []domain.Role{domain.RoleAdmin}, Permissions: []string{
+			domain.PermissionRolesAssign, domain.PermissionRolesManage, domain.PermissionUsersRead, domain.PermissionUsersWrite,
+		}}
+		user = domain.User{ID: 3, Roles: []domain.Role{domain.RoleUser}}
+		// support is a role admins made
+		support = domain.User{ID: 4, Roles: []domain.Role{6}, Permissions: []string{domain.PermissionRolesAssign, domain.PermissionRolesManage, domain.PermissionUsersRead}}
+		stored  = map[domain.ID]domain.User{owner.ID: owner, admin.ID: admin, user.ID: user, support.ID: support}
+		roles   = []domain.RoleDefinition{
+			{ID: domain.RoleOwner},
+			{ID: domain.RoleAdmin, Permissions: admin.Permissions},
+			{ID: domain.RoleModerator, Permissions: []string{domain.PermissionUsersRead}},
+			{ID: domain.RoleUser},
+			{ID: 6, Permissions: support.Permissions},
+		}
+		requests = map[string]domain.RoleRequest{
+			"courier": {ID: "courier", UserID: user.ID, Role: domain.RoleModerator, Status: domain.RoleRequestPending},
+			"promote": {ID: "promote", UserID: user.ID, Role: domain.RoleAdmin, Status: domain.RoleRequestPending},
+			"own":     {ID: "own", UserID: support.ID, Role: domain.RoleModerator, Status: domain.RoleRequestPending},
+		}
+	)
+	review := func(id string) func(ctx context.Context, s domain.Service) error {
+		return func(ctx context.Context, s domain.Service) error {
+			_, err := s.ReviewRoleRequest(ctx, domain.ReviewRoleRequestInput{ID: id, Approve: true})
+			return err
+		}
+	}
+	as := func(u domain.User) *domain.AccessClaims {
+		return &domain.AccessClaims{UserID: u.ID, Roles: u.Roles, Permissions: u.Permissions}
+	}
+	update := func(id domain.ID) func(ctx context.Context, s domain.Service) error {
+		return func(ctx context.Context, s domain.Service) error {
+			return s.Update(ctx, domain.UpdateInput{
+				ID:          id,
+				FullName:    "Pizza Lover",
+				PhoneNumber: "+996700000000",
+				Email:       "pizza@lover.com",
+			})
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		caller *domain.AccessClaims
+		call   func(ctx context.Context, s domain.Service) error
+		// calls tells which repository method is reached when allowed
+		calls string
+		err   error
+	}{
+		{
+			name:   "user updates themselves",
+			caller: as(user),
+			call:   update(user.ID),
+			calls:  "Update",
+		},
+		{
+			name:   "fail when user updates somebody else",
+			caller: as(user),
+			call:   update(admin.ID),
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "admin updates user",
+			caller: as(admin),
+			call:   update(user.ID),
+			calls:  "Update",
+		},
+		{
+			name:   "fail when admin updates owner",
+			caller: as(admin),
+			call:   update(owner.ID),
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "api key updates user with its scope",
+			caller: &domain.AccessClaims{APIKeyID: "key", Scopes: []string{domain.ScopeUsersWrite}},
+			call:   update(user.ID),
+			calls:  "Update",
+		},
+		{
+			name:   "fail when client updates user it has token of",
+			caller: &domain.AccessClaims{UserID: user.ID, ClientID: "aggregator", Scopes: []string{"openid"}},
+			call:   update(user.ID),
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "admin adds role",
+			caller: as(admin),
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.AddRole(ctx, domain.AddRoleInput{UserID: user.ID, Role: domain.RoleModerator})
+			},
+			calls: "AddRole",
+		},
+		{
+			name:   "fail when user adds role to themselves",
+			caller: as(user),
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.AddRole(ctx, domain.AddRoleInput{UserID: user.ID, Role: domain.RoleAdmin})
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "owner removes role of admin",
+			caller: as(owner),
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.RemoveRole(ctx, admin.ID, domain.RoleAdmin, "")
+			},
+			calls: "RemoveRole",
+		},
+		{
+			name:   "custom role assigns role with permissions it has",
+			caller: as(support),
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.AddRole(ctx, domain.AddRoleInput{UserID: user.ID, Role: domain.RoleModerator})
+			},
+			calls: "AddRole",
+		},
+		{
+			name:   "fail when custom role assigns role with more permissions",
+			caller: as(support),
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.AddRole(ctx, domain.AddRoleInput{UserID: support.ID, Role: domain.RoleAdmin})
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "custom role reads user by permission",
+			caller: as(support),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.Read(ctx, user.ID)
+				return err
+			},
+		},
+		{
+			name:   "fail when giving own role permission caller does not have",
+			caller: as(support),
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.SetRolePermissions(ctx, 6, append(support.Permissions, domain.PermissionUsersWrite))
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "admin reads all",
+			caller: as(admin),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.ReadAll(ctx, domain.ReadAllInput{})
+				return err
+			},
+			calls: "ReadAll",
+		},
+		{
+			name:   "fail when user reads all",
+			caller: as(user),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.ReadAll(ctx, domain.ReadAllInput{})
+				return err
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "user asks for a role",
+			caller: as(user),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.RequestRole(ctx, domain.RequestRoleInput{UserID: user.ID, Role: domain.RoleModerator, Justification: "I drive"})
+				return err
+			},
+			calls: "CreateRoleRequest",
+		},
+		{
+			name:   "fail when user asks a role for somebody else",
+			caller: as(user),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.RequestRole(ctx, domain.RequestRoleInput{UserID: support.ID, Role: domain.RoleModerator, Justification: "I drive"})
+				return err
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "fail when user reads requests of everybody",
+			caller: as(user),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.RoleRequests(ctx, domain.ReadRoleRequestsInput{})
+				return err
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "support approves a request",
+			caller: as(support),
+			call:   review("courier"),
+			calls:  "ReviewRoleRequest",
+		},
+		{
+			name:   "fail when support approves a role they can not grant",
+			caller: as(support),
+			call:   review("promote"),
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "fail when support approves their own request",
+			caller: as(support),
+			call:   review("own"),
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "fail when user reviews a request that does not exist",
+			caller: as(user),
+			call:   review("missing"),
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "fail when user reads a request that does not exist",
+			caller: as(user),
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.RoleRequest(ctx, "missing")
+				return err
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name:   "support learns that a request does not exist",
+			caller: as(support),
+			call:   review("missing"),
+			err:    domain.ErrRoleRequestNotFound,
+		},
+		{
+			name: "fail without caller",
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.Read(ctx, user.ID)
+				return err
+			},
+			err: domain.ErrNotAllowed,
+		},
+		{
+			name: "public methods work without caller",
+			call: func(ctx context.Context, s domain.Service) error {
+				_, err := s.SignInEmailPassword(ctx, "nobody@gmail.com", "password")
+				return err
+			},
+			calls: "ReadByEmail",
+			err:   domain.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.repo.EXPECT().Read(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, id domain.ID) (domain.User, error) {
+					return stored[id], nil
+				}).AnyTimes()
+			deps.repo.EXPECT().ReadRoles(gomock.Any()).Return(roles, nil).AnyTimes()
+			deps.repo.EXPECT().ReadRole(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, role domain.Role) (domain.RoleDefinition, error) {
+					for _, v := range roles {
+						if v.ID == role {
+							return v, nil
+						}
+					}
+					return domain.RoleDefinition{}, domain.ErrRoleNotFound
+				}).AnyTimes()
+			deps.repo.EXPECT().ReadRoleRequest(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, id string) (domain.RoleRequest, error) {
+					req, ok := requests[id]
+					if !ok {
+						return domain.RoleRequest{}, domain.ErrRoleRequestNotFound
+					}
+					return req, nil
+				}).AnyTimes()
+			switch tc.calls {
+			case "Update":
+				deps.repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			case "AddRole":
+				deps.repo.EXPECT().AddRole(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			case "RemoveRole":
+				deps.repo.EXPECT().RemoveRole(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			case "CreateRoleRequest":
+				deps.repo.EXPECT().CreateRoleRequest(gomock.Any(), gomock.Any()).Return(nil)
+			case "ReviewRoleRequest":
+				deps.repo.EXPECT().ReviewRoleRequest(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).Return(nil)
+			case "ReadByEmail":
+				deps.repo.EXPECT().ReadByEmail(gomock.Any(), gomock.Any()).Return(domain.User{}, domain.ErrNoUsers)
+			case "ReadAll":
+				deps.repo.EXPECT().ReadAll(gomock.Any(), gomock.Any()).Return([]domain.User{user}, nil)
+			}
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = domain.WithCaller(ctx, *tc.caller)
+			}
+			if err := tc.call(ctx, domain.NewAuthorizer(s)); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
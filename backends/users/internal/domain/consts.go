@@ -8,21 +8,67 @@ const (
 	RoleModerator
 	RoleDeliveryMan
 	RoleUser
+)
 
+// sortings have their own iota so they start from zero
+const (
 	ReadAllSortByID Sorting = iota
 	ReadAllSortByFullNameASC
 	ReadAllSortByFullNameDESC
 	ReadAllSortByEmailASC
 	ReadAllSortByEmailDESC
+)
+
+const (
+	RoleRequestPending  RoleRequestStatus = "pending"
+	RoleRequestApproved RoleRequestStatus = "approved"
+	RoleRequestRejected RoleRequestStatus = "rejected"
 
 	AuthRefreshExp = time.Hour * 24
 	AuthAccessExp  = time.Hour
 
 	CodeLength = 6
+	CodeExp    = time.Minute * 5
+
+	// After CodeMaxAttempts wrong codes the code is invalidated and
+	// destination is locked. Every next lockout within CodeLockoutMemory
+	// is twice as long as the previous one but not longer than CodeLockoutMax.
+	CodeMaxAttempts   = 5
+	CodeLockoutBase   = time.Minute
+	CodeLockoutMax    = time.Hour * 24
+	CodeLockoutMemory = time.Hour * 24
 
 	PasswordMinLength = 8
 	PasswordMaxLength = 64
 
+	// TOTP parameters every authenticator app understands. Codes from
+	// TOTPSkew periods before and after the current one are accepted too.
+	TOTPIssuer        = "Micro-Pizzas"
+	TOTPDigits        = 6
+	TOTPPeriod        = time.Second * 30
+	TOTPSkew          = 1
+	RecoveryCodeCount = 10
+	// user has SecondFactorChallengeExp to type the code after password
+	SecondFactorChallengeExp = time.Minute * 5
+
+	// user has PasskeyCeremonyExp to touch the authenticator
+	PasskeyCeremonyExp = time.Minute * 5
+
+	// user has OIDCStateExp to sign in at the identity provider
+	OIDCStateExp = time.Minute * 10
+
+	// clients exchange authorization codes right after the redirect
+	OAuthCodeExp = time.Minute
+	// signed client assertions may not live longer than ClientAssertionMaxExp,
+	// so we do not have to remember their ids for long
+	ClientAssertionMaxExp = time.Minute * 5
+
+	// last use of an api key is written at most once per APIKeyLastUsedPrecision
+	APIKeyLastUsedPrecision = time.Minute
+
+	// reset links are valid only for PasswordResetExp and only once
+	PasswordResetExp = time.Minute * 30
+
 	RequestSignUpEmailTitle = `
 	Micro-Pizzas sign up code
 	`
@@ -50,4 +96,29 @@ const (
 	RequestSignInSMSMessage = `
 	Here is your code for signin in
 	`
+
+	PasswordResetEmailTitle = `
+	Micro-Pizzas password reset
+	`
+	PasswordResetEmailMessage = `
+	Here is your token for resetting password, ignore this email if you did not ask for it
+	`
+
+	RoleRequestReviewedTitle = `
+	Micro-Pizzas role request
+	`
+	RoleRequestApprovedMessage = `
+	Your role request was approved
+	`
+	RoleRequestRejectedMessage = `
+	Your role request was rejected
+	`
+
+	AccountLockedEmailTitle = `
+	Micro-Pizzas account locked
+	`
+	AccountLockedEmailMessage = `
+	There were too many sign in attempts with a wrong password, so your account is locked for a while.
+	If it was not you, reset your password
+	`
 )
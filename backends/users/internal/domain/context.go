@@ -0,0 +1,41 @@
+package domain
+
+import "context"
+
+type clientInfoKey struct{}
+
+// ClientInfo describes who is calling the service.
+// Transports put it into context for every request.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+type callerKey struct{}
+
+// WithCaller puts claims of the token request came with into context
+func WithCaller(ctx context.Context, caller AccessClaims) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns false if request came without a token
+func CallerFromContext(ctx context.Context) (AccessClaims, bool) {
+	caller, ok := ctx.Value(callerKey{}).(AccessClaims)
+	return caller, ok
+}
+
+// callerIs tells if the user is the one calling. Admins and clients
+// with tokens of the user do not count, some things only users can do.
+func callerIs(ctx context.Context, userID ID) bool {
+	caller, ok := CallerFromContext(ctx)
+	return ok && !caller.Scoped() && userID != 0 && caller.UserID == userID
+}
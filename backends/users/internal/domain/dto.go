@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type (
 	RequestSignUpInput struct {
@@ -18,6 +21,9 @@ type (
 		FullName  string    `json:"fullName"`
 		BirthDate time.Time `json:"birthDate"`
 		Address   Address   `json:"address"`
+
+		// optional, without it user signs in only with codes
+		Password string `json:"password"`
 	}
 
 	RequestSignInInput struct {
@@ -34,6 +40,179 @@ type (
 	SignInOutput struct {
 		AccessKey  string `json:"accessKey"`
 		RefreshKey string `json:"refreshKey"`
+
+		// RecoveryCodes are set only when TOTP was enrolled during sign in,
+		// user has to save them since they are never shown again
+		RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+
+		// jti and expiration of RefreshKey, we need them to store the token
+		RefreshID        string    `json:"-"`
+		RefreshExpiresAt time.Time `json:"-"`
+	}
+
+	// TOTPEnrollment is shown to user once, URI is usually rendered as qr code
+	TOTPEnrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+
+	// PasskeyCeremony is the first half of WebAuthn registration or sign in.
+	// Options go to navigator.credentials and ID comes back with the response.
+	PasskeyCeremony struct {
+		ID      string          `json:"ceremonyID"`
+		Options json.RawMessage `json:"options"`
+	}
+
+	FinishPasskeyInput struct {
+		CeremonyID string          `json:"ceremonyID"`
+		Response   json.RawMessage `json:"response"`
+		// UserID and Name are only used for registration,
+		// ceremony started for another user is rejected
+		UserID ID     `json:"-"`
+		Name   string `json:"name"`
+	}
+
+	// OIDCAuthorization is where to send the user to sign in
+	// at the provider, the provider sends state back with the code.
+	OIDCAuthorization struct {
+		URL   string `json:"url"`
+		State string `json:"state"`
+	}
+
+	FinishOIDCInput struct {
+		State string `json:"state"`
+		Code  string `json:"code"`
+	}
+
+	// IDTokenClaims are claims of an id token that passed verification
+	IDTokenClaims struct {
+		Subject       string
+		Email         string
+		EmailVerified bool
+		Name          string
+		Nonce         string
+	}
+
+	// AuthorizeInput is the authorization request of RFC 6749 and RFC 7636,
+	// only code response type with S256 code challenge is supported.
+	AuthorizeInput struct {
+		ResponseType        string `json:"responseType"`
+		ClientID            string `json:"clientID"`
+		RedirectURI         string `json:"redirectURI"`
+		Scope               string `json:"scope"`
+		State               string `json:"state"`
+		Nonce               string `json:"nonce"`
+		CodeChallenge       string `json:"codeChallenge"`
+		CodeChallengeMethod string `json:"codeChallengeMethod"`
+	}
+
+	// TokenInput is a request to the token endpoint,
+	// fields the grant type does not need are ignored.
+	TokenInput struct {
+		GrantType    string
+		ClientID     string
+		ClientSecret string
+		// client assertion of RFC 7523 is the other way to authenticate
+		ClientAssertionType string
+		ClientAssertion     string
+
+		Code         string
+		RedirectURI  string
+		CodeVerifier string
+		RefreshToken string
+		Scope        string
+	}
+
+	// TokenOutput is the token response of RFC 6749
+	TokenOutput struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		IDToken      string `json:"id_token,omitempty"`
+		Scope        string `json:"scope,omitempty"`
+	}
+
+	// UserInfo are standard OpenID Connect claims,
+	// only the ones allowed by scopes are set.
+	UserInfo struct {
+		Subject       string `json:"sub"`
+		Name          string `json:"name,omitempty"`
+		Email         string `json:"email,omitempty"`
+		EmailVerified bool   `json:"email_verified,omitempty"`
+		PhoneNumber   string `json:"phone_number,omitempty"`
+	}
+
+	RegisterClientInput struct {
+		Name         string   `json:"name"`
+		RedirectURIs []string `json:"redirectURIs"`
+		GrantTypes   []string `json:"grantTypes"`
+		Scopes       []string `json:"scopes"`
+		Public       bool     `json:"public"`
+		Trusted      bool     `json:"trusted"`
+		// JWKS makes client authenticate with signed assertions
+		JWKS json.RawMessage `json:"jwks,omitempty"`
+	}
+
+	// ClientRegistration is shown once, we keep only a hash of Secret.
+	// Public clients and clients with JWKS get no secret.
+	ClientRegistration struct {
+		Client OAuthClient `json:"client"`
+		Secret string      `json:"secret,omitempty"`
+	}
+
+	AddRoleInput struct {
+		UserID ID   `json:"-"`
+		Role   Role `json:"-"`
+		// Scope limits the role to a branch or a store, empty means everywhere
+		Scope     string     `json:"scope"`
+		ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+		Reason    string     `json:"reason"`
+	}
+
+	RequestRoleInput struct {
+		UserID        ID     `json:"-"`
+		Role          Role   `json:"-"`
+		Scope         string `json:"scope"`
+		Justification string `json:"justification"`
+	}
+
+	// ReadRoleRequestsInput with zero UserID reads requests of everybody
+	ReadRoleRequestsInput struct {
+		UserID ID                `json:"userID"`
+		Status RoleRequestStatus `json:"status"`
+		Limit  uint64            `json:"limit"`
+		Offset uint64            `json:"offset"`
+	}
+
+	ReviewRoleRequestInput struct {
+		ID      string `json:"-"`
+		Approve bool   `json:"approve"`
+		Comment string `json:"comment"`
+	}
+
+	CreateRoleInput struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+
+	CreateAPIKeyInput struct {
+		Name      string      `json:"name"`
+		Owner     APIKeyOwner `json:"owner"`
+		Scopes    []string    `json:"scopes"`
+		ExpiresAt *time.Time  `json:"expiresAt,omitempty"`
+	}
+
+	// APIKeyCreation is the only time Key is shown
+	APIKeyCreation struct {
+		APIKey APIKey `json:"apiKey"`
+		Key    string `json:"key"`
+	}
+
+	ResetPasswordInput struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
 	}
 
 	UpdateInput struct {
@@ -59,10 +238,46 @@ type (
 	// Structs bellow are for JWTmanager's use
 
 	AccessClaims struct {
-		UserID ID     `json:"userID"`
-		Roles  []Role `json:"roles"`
+		UserID    ID     `json:"userID"`
+		SessionID string `json:"sessionID"`
+		Roles     []Role `json:"roles"`
+		// Permissions roles of the user had when the token was issued
+		Permissions []string `json:"permissions,omitempty"`
+		// Grants are only the scoped ones, others are in Roles
+		Grants []RoleGrant `json:"grants,omitempty"`
+
+		// ClientID and Scopes are set only for tokens of OAuth clients
+		ClientID string   `json:"clientID,omitempty"`
+		Scopes   []string `json:"scopes,omitempty"`
+		// APIKeyID is set instead of a client when caller came with an api key
+		APIKeyID string `json:"apiKeyID,omitempty"`
 	}
 	RefreshClaims struct {
-		UserID ID `json:"userID"`
+		ID       string `json:"id"`
+		FamilyID string `json:"familyID"`
+		UserID   ID     `json:"userID"`
+
+		ClientID string   `json:"clientID,omitempty"`
+		Scopes   []string `json:"scopes,omitempty"`
+	}
+
+	// ClientAssertion is a verified JWT a client signed to authenticate,
+	// ClientID is its issuer and subject.
+	ClientAssertion struct {
+		ClientID  string
+		ID        string
+		ExpiresAt time.Time
+	}
+
+	// TokenGrant is what a token of an OAuth client allows,
+	// UserID is zero when client acts on its own behalf.
+	TokenGrant struct {
+		ClientID    string
+		UserID      ID
+		Roles       []Role
+		Permissions []string
+		// token does not live longer than any of Grants
+		Grants []RoleGrant
+		Scopes []string
 	}
 )
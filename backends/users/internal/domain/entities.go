@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type (
 	ID   uint64
@@ -19,7 +22,12 @@ type (
 		ID ID `json:"id"`
 
 		FullName string `json:"fullName"`
-		Roles    []Role `json:"roles"`
+		// Roles are active grants that are not limited to a scope
+		Roles []Role `json:"roles"`
+		// Permissions are what all of the Roles give together
+		Permissions []string `json:"permissions"`
+		// Grants are all active grants, scoped ones too
+		Grants []RoleGrant `json:"grants"`
 
 		Email       string `json:"-"`
 		PhoneNumber string `json:"phoneNumber"`
@@ -32,4 +40,165 @@ type (
 		CreatedAt time.Time `json:"createdAt"`
 		UpdatedAt time.Time `json:"updatedAt"`
 	}
+
+	// RoleGrant is a role given to a user, maybe only till ExpiresAt
+	// and only within Scope, like a branch or a store.
+	RoleGrant struct {
+		Role      Role       `json:"role"`
+		Scope     string     `json:"scope,omitempty"`
+		ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+		GrantedBy ID         `json:"grantedBy,omitempty"`
+		Reason    string     `json:"reason,omitempty"`
+		GrantedAt time.Time  `json:"grantedAt"`
+	}
+
+	// RoleDefinition is a row of the roles table with permissions
+	// the role gives, built in roles have ids of the Role constants.
+	RoleDefinition struct {
+		ID          Role     `json:"id"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+
+	// RefreshToken is a record about issued refresh token. Every token
+	// can be exchanged only once and the new token stays in the same
+	// family, so when a rotated token shows up again we know
+	// it was stolen and revoke the whole family.
+	RefreshToken struct {
+		ID       string
+		FamilyID string
+		UserID   ID
+
+		IssuedAt  time.Time
+		ExpiresAt time.Time
+	}
+
+	// Session is one device the user is signed in from. It lives as long
+	// as its refresh token family, so ID of a session is the family id.
+	Session struct {
+		ID     string `json:"id"`
+		UserID ID     `json:"userID"`
+
+		UserAgent string `json:"userAgent"`
+		IP        string `json:"ip"`
+
+		CreatedAt  time.Time  `json:"createdAt"`
+		LastUsedAt time.Time  `json:"lastUsedAt"`
+		RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	}
+
+	// TOTP is a secret shared with authenticator app of the user.
+	// It is not used for sign in until user proves the app has it.
+	TOTP struct {
+		UserID ID
+		Secret string
+
+		CreatedAt   time.Time
+		ConfirmedAt *time.Time
+	}
+
+	// Passkey is a WebAuthn credential, private key never leaves
+	// the authenticator of the user, we keep only the public one.
+	Passkey struct {
+		// base64url of credential id the authenticator made
+		ID     string `json:"id"`
+		UserID ID     `json:"userID"`
+		Name   string `json:"name"`
+
+		// COSE_Key as authenticator sent it
+		PublicKey []byte `json:"-"`
+		// SignCount grows with every assertion if authenticator
+		// supports it, going back means the key was cloned
+		SignCount uint32 `json:"-"`
+		AAGUID    []byte `json:"-"`
+
+		CreatedAt  time.Time  `json:"createdAt"`
+		LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	}
+
+	// Identity is an account at an OpenID Connect provider
+	// linked to a user, subject is its id at the provider.
+	Identity struct {
+		Provider string `json:"provider"`
+		Subject  string `json:"subject"`
+		UserID   ID     `json:"userID"`
+		// Email is what provider told when identity was linked
+		Email     string    `json:"email,omitempty"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	// OAuthClient is an application that gets tokens from us through
+	// OAuth 2.0 flows. Public clients like SPAs and mobile apps can not
+	// keep a secret, so they prove themselves only with PKCE. Users are
+	// not asked for consent when a trusted client, which is ours, signs them in.
+	OAuthClient struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		SecretHash string `json:"-"`
+
+		RedirectURIs []string `json:"redirectURIs"`
+		GrantTypes   []string `json:"grantTypes"`
+		// Scopes are everything the client may ask for
+		Scopes  []string `json:"scopes"`
+		Public  bool     `json:"public"`
+		Trusted bool     `json:"trusted"`
+		// JWKS are public keys of a client that signs assertions
+		// instead of sending a secret, such clients have no secret.
+		JWKS json.RawMessage `json:"jwks,omitempty"`
+
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	// Consent is what user allowed a client to do on their behalf
+	Consent struct {
+		UserID    ID        `json:"userID"`
+		ClientID  string    `json:"clientID"`
+		Scopes    []string  `json:"scopes"`
+		GrantedAt time.Time `json:"grantedAt"`
+	}
+
+	// APIKey is a long lived credential of a partner, only
+	// a hash of the key is stored. ExpiresAt is nil for keys
+	// that work till they are revoked.
+	APIKey struct {
+		ID     string      `json:"id"`
+		Name   string      `json:"name"`
+		Owner  APIKeyOwner `json:"owner"`
+		Hash   string      `json:"-"`
+		Scopes []string    `json:"scopes"`
+
+		CreatedAt  time.Time  `json:"createdAt"`
+		ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+		LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+		RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	}
+
+	// APIKeyOwner is either one of our users or a partner organization
+	APIKeyOwner struct {
+		UserID       ID     `json:"userID,omitempty"`
+		Organization string `json:"organization,omitempty"`
+	}
+
+	// RoleRequest is a user asking for a role, admins approve or reject it
+	// with a comment and approved ones are granted with AddRole.
+	RoleRequest struct {
+		ID            string            `json:"id"`
+		UserID        ID                `json:"userID"`
+		Role          Role              `json:"role"`
+		Scope         string            `json:"scope,omitempty"`
+		Justification string            `json:"justification"`
+		Status        RoleRequestStatus `json:"status"`
+		ReviewerID    ID                `json:"reviewerID,omitempty"`
+		Comment       string            `json:"comment,omitempty"`
+
+		CreatedAt  time.Time  `json:"createdAt"`
+		ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	}
+
+	RoleRequestStatus string
 )
+
+func (t TOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
@@ -10,16 +10,69 @@ var (
 	ErrInvalidSignUpInput = errors.New("domain: sign up requires at least phone number or email")
 	ErrInvalidSignInInput = errors.New("domain: sign in requires at least phone number or email")
 	ErrInvalidToken       = errors.New("domain: provided jwt is invalid")
+	ErrRefreshTokenReused = errors.New("domain: refresh token was already used")
+	ErrSessionRevoked     = errors.New("domain: session was revoked")
+	ErrSessionNotFound    = errors.New("domain: session not found")
 	ErrInvalidPhoneNumber = errors.New("domain: provided phone number is invalid")
+	ErrInvalidEmail       = errors.New("domain: provided email is invalid")
 	ErrInvalidFullName    = errors.New("domain: provided full name is invalid")
 	ErrInvalidCode        = errors.New("domain: provided registration code is invalid")
+	ErrTooManyAttempts    = errors.New("domain: too many attempts, try again later")
+	ErrRateLimited        = errors.New("domain: too many requests")
+	ErrAccountLocked      = errors.New("domain: account is temporarily locked")
 
 	ErrOwnerCantBeRemoved = errors.New("domain: owner can't be deleted or updated")
 	ErrNotAllowed         = errors.New("domain: not allowed")
 
-	ErrInvalidRole = errors.New("domain: invalid role")
+	ErrInvalidRole  = errors.New("domain: invalid role")
+	ErrRoleNotFound = errors.New("domain: role not found")
+	ErrRoleExists   = errors.New("domain: role with this name already exists")
+
+	ErrInvalidRoleRequest    = errors.New("domain: role request needs a role and a justification")
+	ErrRoleRequestNotFound   = errors.New("domain: role request not found")
+	ErrRoleRequestPending    = errors.New("domain: the same role was already requested")
+	ErrRoleRequestNotPending = errors.New("domain: role request was already reviewed")
 
 	ErrPasswordIsNotSecure = errors.New("domain: password is not secure enough")
+	ErrInvalidCredentials  = errors.New("domain: email or password is incorrect")
+	ErrInvalidResetToken   = errors.New("domain: password reset token is invalid or expired")
+
+	ErrSecondFactorRequired = errors.New("domain: second factor is required")
+	ErrInvalidChallenge     = errors.New("domain: second factor challenge is invalid or expired")
+	ErrTOTPNotFound         = errors.New("domain: totp is not enabled")
+	ErrTOTPAlreadyEnabled   = errors.New("domain: totp is already enabled")
+
+	ErrPasskeysDisabled = errors.New("domain: passkeys are not configured")
+	ErrInvalidCeremony  = errors.New("domain: passkey ceremony is invalid or expired")
+	ErrInvalidPasskey   = errors.New("domain: passkey response is invalid")
+	ErrPasskeyNotFound  = errors.New("domain: passkey not found")
+	ErrPasskeyExists    = errors.New("domain: passkey is already registered")
+
+	ErrUnknownProvider   = errors.New("domain: identity provider is not configured")
+	ErrInvalidState      = errors.New("domain: sign in state is invalid or expired")
+	ErrInvalidIDToken    = errors.New("domain: id token of identity provider is invalid")
+	ErrIdentityNotFound  = errors.New("domain: identity not found")
+	ErrIdentityExists    = errors.New("domain: identity is already linked")
+	ErrIdentityNotLinked = errors.New("domain: account with this email exists, sign in and link the provider first")
+
+	// errors of our own authorization server, they follow error codes of RFC 6749
+	ErrInvalidClient           = errors.New("domain: oauth client authentication failed")
+	ErrClientNotFound          = errors.New("domain: oauth client not found")
+	ErrInvalidClientMetadata   = errors.New("domain: oauth client metadata is invalid")
+	ErrInvalidRedirectURI      = errors.New("domain: redirect uri is not registered for the client")
+	ErrUnsupportedResponseType = errors.New("domain: only code response type is supported")
+	ErrUnsupportedGrantType    = errors.New("domain: grant type is not supported")
+	ErrUnauthorizedClient      = errors.New("domain: client is not allowed to use this grant type")
+	ErrInvalidScope            = errors.New("domain: requested scope is invalid")
+	ErrPKCERequired            = errors.New("domain: pkce with S256 code challenge is required")
+	ErrInvalidGrant            = errors.New("domain: authorization grant is invalid or expired")
+	ErrConsentRequired         = errors.New("domain: user has not allowed the client to do that")
+	ErrConsentNotFound         = errors.New("domain: consent not found")
+
+	ErrInvalidAPIKeyInput = errors.New("domain: api key needs a name, exactly one owner and known scopes")
+	ErrAPIKeyNotFound     = errors.New("domain: api key not found")
 
 	ErrNoUsers = errors.New("domain: no users found")
+
+	ErrCacheMiss = errors.New("domain: nothing is cached under this key")
 )
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// scopes look like kind:id, for now only branches and stores have roles of their own
+var scopeFormat = regexp.MustCompile(`^(branch|store):[a-z0-9_-]{1,64}$`)
+
+func normalizeScope(scope string) (string, error) {
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	if scope != "" && !scopeFormat.MatchString(scope) {
+		return "", fmt.Errorf("%w: scope has to look like branch:<id> or store:<id>", ErrInvalidRole)
+	}
+	return scope, nil
+}
+
+// newRoleGrant checks the input and remembers who gave the role
+func newRoleGrant(ctx context.Context, inp AddRoleInput) (RoleGrant, error) {
+	scope, err := normalizeScope(inp.Scope)
+	if err != nil {
+		return RoleGrant{}, err
+	}
+	now := time.Now().UTC()
+	grant := RoleGrant{
+		Role:      inp.Role,
+		Scope:     scope,
+		Reason:    strings.TrimSpace(inp.Reason),
+		GrantedAt: now,
+	}
+	if utf8.RuneCountInString(grant.Reason) > 500 {
+		return RoleGrant{}, fmt.Errorf("%w: reason can not be longer than 500 characters", ErrInvalidRole)
+	}
+	if inp.ExpiresAt != nil {
+		if !inp.ExpiresAt.After(now) {
+			return RoleGrant{}, fmt.Errorf("%w: expiry has to be in the future", ErrInvalidRole)
+		}
+		expiresAt := inp.ExpiresAt.UTC()
+		grant.ExpiresAt = &expiresAt
+	}
+	// clients and api keys are not people, so nobody is written down for them
+	if caller, ok := CallerFromContext(ctx); ok && !caller.Scoped() {
+		grant.GrantedBy = caller.UserID
+	}
+	return grant, nil
+}
+
+// roleGrant checks that the role can be granted, approved
+// role requests are granted the same way AddRole does
+func (s *service) roleGrant(ctx context.Context, inp AddRoleInput) (RoleGrant, error) {
+	if inp.Role == RoleOwner {
+		return RoleGrant{}, fmt.Errorf("owners can be asigned only manualy %w", ErrInvalidRole)
+	}
+	grant, err := newRoleGrant(ctx, inp)
+	if err != nil {
+		return RoleGrant{}, err
+	}
+	if _, err := s.repo.ReadRole(ctx, inp.Role); err != nil {
+		return RoleGrant{}, err
+	}
+	return grant, nil
+}
+
+// Active is false once the grant is expired
+func (g RoleGrant) Active(now time.Time) bool {
+	return g.ExpiresAt == nil || g.ExpiresAt.After(now)
+}
+
+func (s *service) PurgeExpiredGrants(ctx context.Context) (int64, error) {
+	n, err := s.repo.DeleteExpiredGrants(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("purgeExpiredGrants(): %w", err)
+	}
+	return n, nil
+}
+
+// StartGrantPurge deletes expired role grants every interval
+// till stop is called.
+func StartGrantPurge(s Service, interval time.Duration, onError func(error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.PurgeExpiredGrants(context.Background()); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
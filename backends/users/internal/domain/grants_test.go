@@ -0,0 +1,74 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestAddRoleGrant(t *testing.T) {
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name  string
+		inp   domain.AddRoleInput
+		scope string
+		err   error
+	}{
+		{
+			name:  "grant in a branch till it expires",
+			inp:   domain.AddRoleInput{UserID: 7, Role: domain.RoleModerator, Scope: " Branch:12 ", ExpiresAt: &future, Reason: "covers a shift"},
+			scope: "branch:12",
+		},
+		{
+			name: "grant everywhere forever",
+			inp:  domain.AddRoleInput{UserID: 7, Role: domain.RoleModerator},
+		},
+		{
+			name: "fail with expiry in the past",
+			inp:  domain.AddRoleInput{UserID: 7, Role: domain.RoleModerator, ExpiresAt: &past},
+			err:  domain.ErrInvalidRole,
+		},
+		{
+			name: "fail with unknown kind of scope",
+			inp:  domain.AddRoleInput{UserID: 7, Role: domain.RoleModerator, Scope: "planet:earth"},
+			err:  domain.ErrInvalidRole,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			ctx := domain.WithCaller(context.Background(), domain.AccessClaims{UserID: 1, Roles: []domain.Role{domain.RoleAdmin}})
+			if tc.err == nil {
+				deps.repo.EXPECT().ReadRole(gomock.Any(), tc.inp.Role).Return(domain.RoleDefinition{ID: tc.inp.Role}, nil)
+				deps.repo.EXPECT().AddRole(gomock.Any(), tc.inp.UserID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ domain.ID, g domain.RoleGrant) error {
+						if g.Scope != tc.scope || g.GrantedBy != 1 || g.GrantedAt.IsZero() ||
+							(tc.inp.ExpiresAt == nil) != (g.ExpiresAt == nil) || g.Reason != tc.inp.Reason {
+							t.Errorf("unexpected grant %+v", g)
+						}
+						return nil
+					})
+			}
+
+			if err := s.AddRole(ctx, tc.inp); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredGrants(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.repo.EXPECT().DeleteExpiredGrants(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+
+	n, err := s.PurgeExpiredGrants(context.Background())
+	if err != nil || n != 3 {
+		t.Errorf("got %d, %v", n, err)
+	}
+}
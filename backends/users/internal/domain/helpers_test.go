@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain/mocks"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/memory"
+)
+
+var testPasswordParams = domain.PasswordParams{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type testDeps struct {
+	repo    *mocks.MockRepository
+	sms     *mocks.MockSMSsender
+	emailer *mocks.MockEmailer
+	jwt     *mocks.MockJWTmanager
+	logger  *mocks.MockLogger
+	cache   *memory.Cache
+}
+
+// newTestService builds service with mocked dependencies
+// except for cache, flows with codes are easier to test on a real one.
+func newTestService(t *testing.T, opts ...domain.Option) (domain.Service, testDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := testDeps{
+		repo:    mocks.NewMockRepository(ctrl),
+		sms:     mocks.NewMockSMSsender(ctrl),
+		emailer: mocks.NewMockEmailer(ctrl),
+		jwt:     mocks.NewMockJWTmanager(ctrl),
+		logger:  mocks.NewMockLogger(ctrl),
+		cache:   memory.NewCache(0, time.Minute),
+	}
+	t.Cleanup(deps.cache.Close)
+	deps.jwt.EXPECT().SetExp(gomock.Any(), gomock.Any()).AnyTimes()
+
+	s, err := domain.NewService(
+		deps.repo,
+		deps.sms,
+		deps.emailer,
+		deps.cache,
+		deps.logger,
+		deps.jwt,
+		// real parameters make every test take seconds
+		append([]domain.Option{domain.WithPasswordParams(testPasswordParams)}, opts...)...,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, deps
+}
+
+// expectCode catches the code sent through sms
+func (d testDeps) expectCode(phoneNumber string) *string {
+	code := new(string)
+	d.sms.EXPECT().Send(phoneNumber, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _, text string) error {
+			*code = text[len(text)-domain.CodeLength:]
+			return nil
+		})
+	return code
+}
+
+// expectEmail catches whatever is sent after the last ":" in email text
+func (d testDeps) expectEmail(email string) *string {
+	secret := new(string)
+	d.emailer.EXPECT().Send(email, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _, text string) error {
+			*secret = text[strings.LastIndex(text, ":")+1:]
+			return nil
+		})
+	return secret
+}
+
+// expectNoTOTP lets users sign in without the second factor
+func (d testDeps) expectNoTOTP() {
+	d.repo.EXPECT().ReadTOTP(gomock.Any(), gomock.Any()).
+		Return(domain.TOTP{}, domain.ErrTOTPNotFound).AnyTimes()
+}
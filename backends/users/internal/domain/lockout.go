@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Wrong passwords are counted per account and per client ip within
+// LockoutConfig.Window. Too many of them lock the account, so nobody
+// can guess the password of a single user, and block the ip, so nobody
+// can try one password against many accounts. Owner of a locked account
+// is notified by email and admins can unlock it before the lock expires.
+
+type LockoutConfig struct {
+	// Zero thresholds are not checked
+	AccountThreshold int64
+	IPThreshold      int64
+	Window           time.Duration
+	Duration         time.Duration
+}
+
+var DefaultLockoutConfig = LockoutConfig{
+	AccountThreshold: 5,
+	IPThreshold:      50,
+	Window:           time.Minute * 15,
+	Duration:         time.Minute * 15,
+}
+
+func accountLockKey(userID ID) string {
+	return "lockout:user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func accountFailuresKey(userID ID) string {
+	return "lockout:failures:user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func ipLockKey(ip string) string {
+	return "lockout:ip:" + ip
+}
+
+func ipFailuresKey(ip string) string {
+	return "lockout:failures:ip:" + ip
+}
+
+func (s *service) UnlockAccount(ctx context.Context, userID ID) error {
+	if _, err := s.repo.Read(ctx, userID); err != nil {
+		return fmt.Errorf("unlockAccount(): %w", err)
+	}
+	if err := s.cache.Delete(accountLockKey(userID)); err != nil {
+		return fmt.Errorf("unlockAccount(): %w", err)
+	}
+	if err := s.cache.Delete(accountFailuresKey(userID)); err != nil {
+		return fmt.Errorf("unlockAccount(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) checkIPLock(ctx context.Context) error {
+	ip := ClientInfoFromContext(ctx).IP
+	if ip == "" || s.lockout.IPThreshold <= 0 {
+		return nil
+	}
+	locked, err := s.locked(ipLockKey(ip))
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+func (s *service) checkAccountLock(userID ID) error {
+	if s.lockout.AccountThreshold <= 0 {
+		return nil
+	}
+	locked, err := s.locked(accountLockKey(userID))
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrAccountLocked
+	}
+	return nil
+}
+
+func (s *service) locked(key string) (bool, error) {
+	_, err := s.cache.Get(key)
+	if errors.Is(err, ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not check lock: %w", err)
+	}
+	return true, nil
+}
+
+// failIPAttempt counts a wrong password from the client ip,
+// it blocks the ip once there are too many of them.
+func (s *service) failIPAttempt(ctx context.Context) error {
+	ip := ClientInfoFromContext(ctx).IP
+	if ip == "" || s.lockout.IPThreshold <= 0 {
+		return nil
+	}
+	failures, err := s.cache.Incr(ipFailuresKey(ip), s.lockout.Window)
+	if err != nil {
+		return fmt.Errorf("could not count failure: %w", err)
+	}
+	if failures < s.lockout.IPThreshold {
+		return nil
+	}
+	if err := s.cache.Store(ipLockKey(ip), "1", s.lockout.Duration); err != nil {
+		return fmt.Errorf("could not lock: %w", err)
+	}
+	if err := s.cache.Delete(ipFailuresKey(ip)); err != nil {
+		return fmt.Errorf("could not reset failures: %w", err)
+	}
+	return nil
+}
+
+// failPasswordAttempt counts a wrong password for u and the client ip.
+// It returns ErrAccountLocked if this attempt locked the account
+// and ErrInvalidCredentials otherwise.
+func (s *service) failPasswordAttempt(ctx context.Context, u User) error {
+	if err := s.failIPAttempt(ctx); err != nil {
+		return err
+	}
+	if s.lockout.AccountThreshold <= 0 {
+		return ErrInvalidCredentials
+	}
+	failures, err := s.cache.Incr(accountFailuresKey(u.ID), s.lockout.Window)
+	if err != nil {
+		return fmt.Errorf("could not count failure: %w", err)
+	}
+	if failures < s.lockout.AccountThreshold {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.cache.Store(accountLockKey(u.ID), "1", s.lockout.Duration); err != nil {
+		return fmt.Errorf("could not lock: %w", err)
+	}
+	if err := s.cache.Delete(accountFailuresKey(u.ID)); err != nil {
+		return fmt.Errorf("could not reset failures: %w", err)
+	}
+	// the account is locked anyway, a lost email should not hide it
+	if u.Email != "" {
+		if err := s.emailer.Send(u.Email, AccountLockedEmailTitle, AccountLockedEmailMessage); err != nil {
+			s.logger.Errorf("could not notify user %s about lockout: %s",
+				strconv.FormatUint(uint64(u.ID), 10), err.Error())
+		}
+	}
+	return ErrAccountLocked
+}
+
+// resetPasswordFailures forgets wrong passwords after a successful sign in
+func (s *service) resetPasswordFailures(userID ID) error {
+	if s.lockout.AccountThreshold <= 0 {
+		return nil
+	}
+	if err := s.cache.Delete(accountFailuresKey(userID)); err != nil {
+		return fmt.Errorf("could not reset failures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testLockout = domain.LockoutConfig{
+	AccountThreshold: 3,
+	IPThreshold:      5,
+	Window:           time.Minute,
+	Duration:         time.Minute,
+}
+
+func TestAccountLockout(t *testing.T) {
+	email := "pizzas@gmail.com"
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := domain.User{ID: 1, Email: email, Password: string(hash)}
+	ctx := context.Background()
+
+	s, deps := newTestService(t, domain.WithLockout(testLockout))
+	deps.expectNoTOTP()
+	deps.repo.EXPECT().ReadByEmail(gomock.Any(), email).Return(user, nil).AnyTimes()
+	deps.repo.EXPECT().Read(gomock.Any(), domain.ID(1)).Return(user, nil).AnyTimes()
+	deps.repo.EXPECT().UpdatePassword(gomock.Any(), domain.ID(1), gomock.Any()).Return(nil).AnyTimes()
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	deps.jwt.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.SignInOutput{}, nil).AnyTimes()
+
+	signIn := func(password string, want error) {
+		t.Helper()
+		if _, err := s.SignInEmailPassword(ctx, email, password); !errors.Is(err, want) {
+			t.Fatalf("expected %v, got %v", want, err)
+		}
+	}
+
+	// successful sign in forgets previous failures
+	signIn("wrong", domain.ErrInvalidCredentials)
+	signIn("wrong", domain.ErrInvalidCredentials)
+	signIn("password", nil)
+	signIn("wrong", domain.ErrInvalidCredentials)
+	signIn("wrong", domain.ErrInvalidCredentials)
+
+	deps.emailer.EXPECT().Send(email, domain.AccountLockedEmailTitle, domain.AccountLockedEmailMessage).Return(nil)
+	signIn("wrong", domain.ErrAccountLocked)
+	// even the right password does not work till the lock expires
+	signIn("password", domain.ErrAccountLocked)
+
+	if err := s.UnlockAccount(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	signIn("password", nil)
+}
+
+func TestUnlockUnknownAccount(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.repo.EXPECT().Read(gomock.Any(), domain.ID(42)).Return(domain.User{}, domain.ErrNoUsers)
+	if err := s.UnlockAccount(context.Background(), 42); !errors.Is(err, domain.ErrNoUsers) {
+		t.Fatalf("expected %v, got %v", domain.ErrNoUsers, err)
+	}
+}
+
+func TestIPLockout(t *testing.T) {
+	s, deps := newTestService(t, domain.WithLockout(testLockout))
+	attacker := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "10.0.0.1"})
+	other := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "10.0.0.2"})
+
+	deps.repo.EXPECT().ReadByEmail(gomock.Any(), gomock.Any()).
+		Return(domain.User{}, domain.ErrNoUsers).Times(int(testLockout.IPThreshold) + 1)
+	for i := int64(0); i < testLockout.IPThreshold; i++ {
+		if _, err := s.SignInEmailPassword(attacker, "nobody@gmail.com", "password"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("expected %v, got %v", domain.ErrInvalidCredentials, err)
+		}
+	}
+	// blocked before the repository is asked
+	if _, err := s.SignInEmailPassword(attacker, "nobody@gmail.com", "password"); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("expected %v, got %v", domain.ErrTooManyAttempts, err)
+	}
+	if _, err := s.SignInEmailPassword(other, "nobody@gmail.com", "password"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("expected %v, got %v", domain.ErrInvalidCredentials, err)
+	}
+}
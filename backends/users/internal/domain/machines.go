@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Other microservices are OAuth clients too, they get tokens of their own
+// with client credentials grant. A client proves it is itself either with
+// a secret or with a JWT signed by one of its keys (private_key_jwt of
+// OpenID Connect), then the key never leaves the service. What such
+// tokens allow is decided by their scopes, not by roles.
+
+const (
+	// ScopeUsersRead lets a service look users up
+	ScopeUsersRead = "users:read"
+	// ScopeUsersWrite lets a service change profiles of users
+	ScopeUsersWrite = "users:write"
+
+	ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// serviceScopes are given only to clients acting on their own behalf,
+// users can not hand them over to a client.
+var serviceScopes = map[string]bool{
+	ScopeUsersRead:  true,
+	ScopeUsersWrite: true,
+}
+
+// Scoped tells if caller is a client or an api key,
+// such callers may do only what their scopes allow.
+func (c AccessClaims) Scoped() bool {
+	return c.ClientID != "" || c.APIKeyID != ""
+}
+
+func (c AccessClaims) HasScope(scope string) bool {
+	return contains(c.Scopes, scope)
+}
+
+func (s *service) Authenticate(ctx context.Context, accessKey string) (AccessClaims, error) {
+	if strings.HasPrefix(accessKey, apiKeyPrefix) {
+		claims, err := s.authenticateAPIKey(ctx, accessKey)
+		if err != nil {
+			return AccessClaims{}, fmt.Errorf("authenticate(): %w", err)
+		}
+		return claims, nil
+	}
+	claims, err := s.jwtManager.DecodeAccess(accessKey)
+	if err != nil {
+		return AccessClaims{}, fmt.Errorf("authenticate(): %w", err)
+	}
+	if claims.ClientID == "" {
+		return claims, nil
+	}
+	_, err = s.repo.ReadOAuthClient(ctx, claims.ClientID)
+	if errors.Is(err, ErrClientNotFound) {
+		return AccessClaims{}, fmt.Errorf("authenticate(): client was deleted: %w", ErrInvalidToken)
+	}
+	if err != nil {
+		return AccessClaims{}, fmt.Errorf("authenticate(): %w", err)
+	}
+	return claims, nil
+}
+
+// verifyClientAssertion checks that client signed the assertion and that
+// it is used only once. Ids are remembered till assertions expire.
+func (s *service) verifyClientAssertion(client OAuthClient, assertionType, assertion string) error {
+	if assertionType != ClientAssertionTypeJWTBearer || len(client.JWKS) == 0 {
+		return ErrInvalidClient
+	}
+	claims, err := s.jwtManager.DecodeClientAssertion(assertion, client.JWKS)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidClient, err)
+	}
+	ttl := time.Until(claims.ExpiresAt)
+	switch {
+	case claims.ClientID != client.ID:
+		return fmt.Errorf("%w: assertion is issued by another client", ErrInvalidClient)
+	case claims.ID == "":
+		return fmt.Errorf("%w: assertion has no id", ErrInvalidClient)
+	case ttl > ClientAssertionMaxExp:
+		return fmt.Errorf("%w: assertion lives too long", ErrInvalidClient)
+	}
+	// jwt manager accepts expired assertions with some leeway,
+	// so ids are kept twice as long as an assertion may live
+	used, err := s.cache.Incr("oauth:assertion:"+client.ID+":"+claims.ID, ClientAssertionMaxExp*2)
+	if err != nil {
+		return fmt.Errorf("could not remember assertion: %w", err)
+	}
+	if used > 1 {
+		return fmt.Errorf("%w: assertion was already used", ErrInvalidClient)
+	}
+	return nil
+}
+
+// validateJWKS checks only that there are keys,
+// jwt manager tells if it can use them.
+func validateJWKS(jwks json.RawMessage) error {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(jwks, &set); err != nil || len(set.Keys) == 0 {
+		return fmt.Errorf("%w: jwks has to be a set with at least one key", ErrInvalidClientMetadata)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package domain_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var orders = domain.OAuthClient{
+	ID:         "orders",
+	Name:       "Orders",
+	GrantTypes: []string{domain.GrantClientCredentials},
+	Scopes:     []string{domain.ScopeUsersRead},
+	JWKS:       json.RawMessage(`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`),
+}
+
+func TestClientAssertionGrant(t *testing.T) {
+	assertion := domain.TokenInput{
+		GrantType:           domain.GrantClientCredentials,
+		ClientID:            orders.ID,
+		ClientAssertionType: domain.ClientAssertionTypeJWTBearer,
+		ClientAssertion:     "assertion",
+	}
+
+	testCases := []struct {
+		name    string
+		inp     func() domain.TokenInput
+		claims  domain.ClientAssertion
+		decodes bool
+		err     error
+	}{
+		{
+			name:    "get token with assertion",
+			inp:     func() domain.TokenInput { return assertion },
+			claims:  domain.ClientAssertion{ClientID: orders.ID, ID: "1", ExpiresAt: time.Now().Add(time.Minute)},
+			decodes: true,
+		},
+		{
+			name: "fail with secret instead of assertion",
+			inp: func() domain.TokenInput {
+				return domain.TokenInput{GrantType: domain.GrantClientCredentials, ClientID: orders.ID, ClientSecret: "secret"}
+			},
+			err: domain.ErrInvalidClient,
+		},
+		{
+			name: "fail with both secret and assertion",
+			inp: func() domain.TokenInput {
+				inp := assertion
+				inp.ClientSecret = "secret"
+				return inp
+			},
+			err: domain.ErrInvalidClient,
+		},
+		{
+			name: "fail with unknown assertion type",
+			inp: func() domain.TokenInput {
+				inp := assertion
+				inp.ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:saml2-bearer"
+				return inp
+			},
+			err: domain.ErrInvalidClient,
+		},
+		{
+			name:    "fail with assertion of another client",
+			inp:     func() domain.TokenInput { return assertion },
+			claims:  domain.ClientAssertion{ClientID: "products", ID: "1", ExpiresAt: time.Now().Add(time.Minute)},
+			decodes: true,
+			err:     domain.ErrInvalidClient,
+		},
+		{
+			name:    "fail with assertion that lives for a day",
+			inp:     func() domain.TokenInput { return assertion },
+			claims:  domain.ClientAssertion{ClientID: orders.ID, ID: "1", ExpiresAt: time.Now().Add(time.Hour * 24)},
+			decodes: true,
+			err:     domain.ErrInvalidClient,
+		},
+		{
+			name: "fail with assertion for a client with secret",
+			inp: func() domain.TokenInput {
+				inp := assertion
+				inp.ClientID = aggregator.ID
+				return inp
+			},
+			err: domain.ErrInvalidClient,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage(nil, orders, aggregator)
+			if tc.decodes {
+				deps.jwt.EXPECT().DecodeClientAssertion("assertion", []byte(orders.JWKS)).Return(tc.claims, nil)
+			}
+			if tc.err == nil {
+				deps.jwt.EXPECT().GenerateGrant(domain.TokenGrant{ClientID: orders.ID, Scopes: []string{domain.ScopeUsersRead}}, "").
+					Return(domain.SignInOutput{AccessKey: "access"}, nil)
+			}
+
+			if _, err := s.Token(context.Background(), tc.inp()); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	t.Run("fail when assertion is replayed", func(t *testing.T) {
+		s, deps := newTestService(t)
+		deps.expectOAuthStorage(nil, orders)
+		deps.jwt.EXPECT().DecodeClientAssertion("assertion", gomock.Any()).
+			Return(domain.ClientAssertion{ClientID: orders.ID, ID: "1", ExpiresAt: time.Now().Add(time.Minute)}, nil).Times(2)
+		deps.jwt.EXPECT().GenerateGrant(gomock.Any(), "").Return(domain.SignInOutput{AccessKey: "access"}, nil)
+
+		if _, err := s.Token(context.Background(), assertion); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Token(context.Background(), assertion); !errors.Is(err, domain.ErrInvalidClient) {
+			t.Errorf("expected %v, got %v", domain.ErrInvalidClient, err)
+		}
+	})
+}
+
+func TestServiceScopesAreNotDelegated(t *testing.T) {
+	user := domain.User{ID: 1}
+	client := aggregator
+	client.Scopes = append([]string{domain.ScopeUsersRead}, aggregator.Scopes...)
+	s, deps := newTestService(t)
+	deps.expectOAuthStorage([]domain.User{user}, client)
+
+	if _, err := s.Authorize(signedIn(user.ID), user.ID, authorizeInput(client, "openid users:read")); !errors.Is(err, domain.ErrInvalidScope) {
+		t.Errorf("expected %v, got %v", domain.ErrInvalidScope, err)
+	}
+	if err := s.GrantConsent(signedIn(user.ID), user.ID, client.ID, []string{domain.ScopeUsersRead}); !errors.Is(err, domain.ErrInvalidScope) {
+		t.Errorf("expected %v, got %v", domain.ErrInvalidScope, err)
+	}
+	// by default client asks for everything users may allow
+	_, err := s.Authorize(signedIn(user.ID), user.ID, authorizeInput(client, ""))
+	var required *domain.ConsentRequiredError
+	if !errors.As(err, &required) || len(required.Scopes) != len(aggregator.Scopes) {
+		t.Errorf("expected consent for %v, got %v", aggregator.Scopes, err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claims domain.AccessClaims
+		err    error
+	}{
+		{
+			name:   "user",
+			claims: domain.AccessClaims{UserID: 1, SessionID: "family"},
+		},
+		{
+			name:   "client",
+			claims: domain.AccessClaims{ClientID: orders.ID, Scopes: []string{domain.ScopeUsersRead}},
+		},
+		{
+			name:   "fail with token of deleted client",
+			claims: domain.AccessClaims{ClientID: "deleted", Scopes: []string{domain.ScopeUsersRead}},
+			err:    domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage(nil, orders)
+			deps.jwt.EXPECT().DecodeAccess("access").Return(tc.claims, nil)
+
+			claims, err := s.Authenticate(context.Background(), "access")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && claims.ClientID != tc.claims.ClientID {
+				t.Errorf("got %+v", claims)
+			}
+		})
+	}
+}
+
+func TestRegisterClientWithKeys(t *testing.T) {
+	testCases := []struct {
+		name   string
+		public bool
+		jwks   string
+		err    error
+	}{
+		{
+			name: "no secret for client with keys",
+			jwks: string(orders.JWKS),
+		},
+		{
+			name: "fail with empty key set",
+			jwks: `{"keys":[]}`,
+			err:  domain.ErrInvalidClientMetadata,
+		},
+		{
+			name:   "fail for public client",
+			public: true,
+			jwks:   string(orders.JWKS),
+			err:    domain.ErrInvalidClientMetadata,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			grants := []string{domain.GrantClientCredentials}
+			if tc.public {
+				grants = []string{domain.GrantAuthorizationCode}
+			}
+			if tc.err == nil {
+				deps.repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			reg, err := s.RegisterOAuthClient(context.Background(), domain.RegisterClientInput{
+				Name:         "Orders",
+				RedirectURIs: []string{testRedirectURI},
+				GrantTypes:   grants,
+				Scopes:       []string{domain.ScopeUsersRead},
+				Public:       tc.public,
+				JWKS:         json.RawMessage(tc.jwks),
+			})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && (reg.Secret != "" || reg.Client.SecretHash != "") {
+				t.Errorf("client with keys got a secret")
+			}
+		})
+	}
+}
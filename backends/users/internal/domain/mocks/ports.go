@@ -36,51 +36,1254 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// APIKeys mocks base method.
+func (m *MockService) APIKeys(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APIKeys", ctx, owner)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// APIKeys indicates an expected call of APIKeys.
+func (mr *MockServiceMockRecorder) APIKeys(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APIKeys", reflect.TypeOf((*MockService)(nil).APIKeys), ctx, owner)
+}
+
+// AddRole mocks base method.
+func (m *MockService) AddRole(ctx context.Context, inp domain.AddRoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRole", ctx, inp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRole indicates an expected call of AddRole.
+func (mr *MockServiceMockRecorder) AddRole(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockService)(nil).AddRole), ctx, inp)
+}
+
+// Authenticate mocks base method.
+func (m *MockService) Authenticate(ctx context.Context, accessKey string) (domain.AccessClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, accessKey)
+	ret0, _ := ret[0].(domain.AccessClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceMockRecorder) Authenticate(ctx, accessKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockService)(nil).Authenticate), ctx, accessKey)
+}
+
+// Authorize mocks base method.
+func (m *MockService) Authorize(ctx context.Context, userID domain.ID, inp domain.AuthorizeInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userID, inp)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockServiceMockRecorder) Authorize(ctx, userID, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockService)(nil).Authorize), ctx, userID, inp)
+}
+
+// BeginOIDCLink mocks base method.
+func (m *MockService) BeginOIDCLink(ctx context.Context, userID domain.ID, provider string) (domain.OIDCAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginOIDCLink", ctx, userID, provider)
+	ret0, _ := ret[0].(domain.OIDCAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginOIDCLink indicates an expected call of BeginOIDCLink.
+func (mr *MockServiceMockRecorder) BeginOIDCLink(ctx, userID, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginOIDCLink", reflect.TypeOf((*MockService)(nil).BeginOIDCLink), ctx, userID, provider)
+}
+
+// BeginOIDCSignIn mocks base method.
+func (m *MockService) BeginOIDCSignIn(ctx context.Context, provider string) (domain.OIDCAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginOIDCSignIn", ctx, provider)
+	ret0, _ := ret[0].(domain.OIDCAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginOIDCSignIn indicates an expected call of BeginOIDCSignIn.
+func (mr *MockServiceMockRecorder) BeginOIDCSignIn(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginOIDCSignIn", reflect.TypeOf((*MockService)(nil).BeginOIDCSignIn), ctx, provider)
+}
+
+// BeginPasskeyRegistration mocks base method.
+func (m *MockService) BeginPasskeyRegistration(ctx context.Context, userID domain.ID) (domain.PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyRegistration", ctx, userID)
+	ret0, _ := ret[0].(domain.PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginPasskeyRegistration indicates an expected call of BeginPasskeyRegistration.
+func (mr *MockServiceMockRecorder) BeginPasskeyRegistration(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyRegistration", reflect.TypeOf((*MockService)(nil).BeginPasskeyRegistration), ctx, userID)
+}
+
+// BeginPasskeySignIn mocks base method.
+func (m *MockService) BeginPasskeySignIn(ctx context.Context, email string) (domain.PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeySignIn", ctx, email)
+	ret0, _ := ret[0].(domain.PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginPasskeySignIn indicates an expected call of BeginPasskeySignIn.
+func (mr *MockServiceMockRecorder) BeginPasskeySignIn(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeySignIn", reflect.TypeOf((*MockService)(nil).BeginPasskeySignIn), ctx, email)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockService) ConfirmTOTP(ctx context.Context, userID domain.ID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockServiceMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockService)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// Consents mocks base method.
+func (m *MockService) Consents(ctx context.Context, userID domain.ID) ([]domain.Consent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consents", ctx, userID)
+	ret0, _ := ret[0].([]domain.Consent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consents indicates an expected call of Consents.
+func (mr *MockServiceMockRecorder) Consents(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consents", reflect.TypeOf((*MockService)(nil).Consents), ctx, userID)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockService) CreateAPIKey(ctx context.Context, inp domain.CreateAPIKeyInput) (domain.APIKeyCreation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, inp)
+	ret0, _ := ret[0].(domain.APIKeyCreation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockServiceMockRecorder) CreateAPIKey(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockService)(nil).CreateAPIKey), ctx, inp)
+}
+
+// CreateRole mocks base method.
+func (m *MockService) CreateRole(ctx context.Context, inp domain.CreateRoleInput) (domain.RoleDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, inp)
+	ret0, _ := ret[0].(domain.RoleDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockServiceMockRecorder) CreateRole(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockService)(nil).CreateRole), ctx, inp)
+}
+
+// Delete mocks base method.
+func (m *MockService) Delete(ctx context.Context, userID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockServiceMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, userID)
+}
+
+// DeleteOAuthClient mocks base method.
+func (m *MockService) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockServiceMockRecorder) DeleteOAuthClient(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockService)(nil).DeleteOAuthClient), ctx, clientID)
+}
+
+// DeletePasskey mocks base method.
+func (m *MockService) DeletePasskey(ctx context.Context, userID domain.ID, passkeyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, passkeyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockServiceMockRecorder) DeletePasskey(ctx, userID, passkeyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockService)(nil).DeletePasskey), ctx, userID, passkeyID)
+}
+
+// DeleteRole mocks base method.
+func (m *MockService) DeleteRole(ctx context.Context, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockServiceMockRecorder) DeleteRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockService)(nil).DeleteRole), ctx, role)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockService) DisableTOTP(ctx context.Context, userID domain.ID, code, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, code, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockServiceMockRecorder) DisableTOTP(ctx, userID, code, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockService)(nil).DisableTOTP), ctx, userID, code, password)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockService) EnrollTOTP(ctx context.Context, userID domain.ID) (domain.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(domain.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockServiceMockRecorder) EnrollTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockService)(nil).EnrollTOTP), ctx, userID)
+}
+
+// FinishOIDCLink mocks base method.
+func (m *MockService) FinishOIDCLink(ctx context.Context, userID domain.ID, inp domain.FinishOIDCInput) (domain.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishOIDCLink", ctx, userID, inp)
+	ret0, _ := ret[0].(domain.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishOIDCLink indicates an expected call of FinishOIDCLink.
+func (mr *MockServiceMockRecorder) FinishOIDCLink(ctx, userID, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishOIDCLink", reflect.TypeOf((*MockService)(nil).FinishOIDCLink), ctx, userID, inp)
+}
+
+// FinishOIDCSignIn mocks base method.
+func (m *MockService) FinishOIDCSignIn(ctx context.Context, inp domain.FinishOIDCInput) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishOIDCSignIn", ctx, inp)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishOIDCSignIn indicates an expected call of FinishOIDCSignIn.
+func (mr *MockServiceMockRecorder) FinishOIDCSignIn(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishOIDCSignIn", reflect.TypeOf((*MockService)(nil).FinishOIDCSignIn), ctx, inp)
+}
+
+// FinishPasskeyRegistration mocks base method.
+func (m *MockService) FinishPasskeyRegistration(ctx context.Context, inp domain.FinishPasskeyInput) (domain.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyRegistration", ctx, inp)
+	ret0, _ := ret[0].(domain.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeyRegistration indicates an expected call of FinishPasskeyRegistration.
+func (mr *MockServiceMockRecorder) FinishPasskeyRegistration(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyRegistration", reflect.TypeOf((*MockService)(nil).FinishPasskeyRegistration), ctx, inp)
+}
+
+// FinishPasskeySignIn mocks base method.
+func (m *MockService) FinishPasskeySignIn(ctx context.Context, inp domain.FinishPasskeyInput) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeySignIn", ctx, inp)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeySignIn indicates an expected call of FinishPasskeySignIn.
+func (mr *MockServiceMockRecorder) FinishPasskeySignIn(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeySignIn", reflect.TypeOf((*MockService)(nil).FinishPasskeySignIn), ctx, inp)
+}
+
+// GrantConsent mocks base method.
+func (m *MockService) GrantConsent(ctx context.Context, userID domain.ID, clientID string, scopes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantConsent", ctx, userID, clientID, scopes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantConsent indicates an expected call of GrantConsent.
+func (mr *MockServiceMockRecorder) GrantConsent(ctx, userID, clientID, scopes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantConsent", reflect.TypeOf((*MockService)(nil).GrantConsent), ctx, userID, clientID, scopes)
+}
+
+// Identities mocks base method.
+func (m *MockService) Identities(ctx context.Context, userID domain.ID) ([]domain.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Identities", ctx, userID)
+	ret0, _ := ret[0].([]domain.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Identities indicates an expected call of Identities.
+func (mr *MockServiceMockRecorder) Identities(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Identities", reflect.TypeOf((*MockService)(nil).Identities), ctx, userID)
+}
+
+// OAuthClient mocks base method.
+func (m *MockService) OAuthClient(ctx context.Context, clientID string) (domain.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OAuthClient", ctx, clientID)
+	ret0, _ := ret[0].(domain.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OAuthClient indicates an expected call of OAuthClient.
+func (mr *MockServiceMockRecorder) OAuthClient(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OAuthClient", reflect.TypeOf((*MockService)(nil).OAuthClient), ctx, clientID)
+}
+
+// OAuthClients mocks base method.
+func (m *MockService) OAuthClients(ctx context.Context) ([]domain.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OAuthClients", ctx)
+	ret0, _ := ret[0].([]domain.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OAuthClients indicates an expected call of OAuthClients.
+func (mr *MockServiceMockRecorder) OAuthClients(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OAuthClients", reflect.TypeOf((*MockService)(nil).OAuthClients), ctx)
+}
+
+// Passkeys mocks base method.
+func (m *MockService) Passkeys(ctx context.Context, userID domain.ID) ([]domain.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Passkeys", ctx, userID)
+	ret0, _ := ret[0].([]domain.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Passkeys indicates an expected call of Passkeys.
+func (mr *MockServiceMockRecorder) Passkeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Passkeys", reflect.TypeOf((*MockService)(nil).Passkeys), ctx, userID)
+}
+
+// PurgeExpiredGrants mocks base method.
+func (m *MockService) PurgeExpiredGrants(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredGrants", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredGrants indicates an expected call of PurgeExpiredGrants.
+func (mr *MockServiceMockRecorder) PurgeExpiredGrants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredGrants", reflect.TypeOf((*MockService)(nil).PurgeExpiredGrants), ctx)
+}
+
+// Read mocks base method.
+func (m *MockService) Read(ctx context.Context, id domain.ID) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, id)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockServiceMockRecorder) Read(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockService)(nil).Read), ctx, id)
+}
+
+// ReadAll mocks base method.
+func (m *MockService) ReadAll(ctx context.Context, cfg domain.ReadAllInput) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAll", ctx, cfg)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll.
+func (mr *MockServiceMockRecorder) ReadAll(ctx, cfg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockService)(nil).ReadAll), ctx, cfg)
+}
+
+// ReadByEmail mocks base method.
+func (m *MockService) ReadByEmail(ctx context.Context, email string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByEmail", ctx, email)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByEmail indicates an expected call of ReadByEmail.
+func (mr *MockServiceMockRecorder) ReadByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByEmail", reflect.TypeOf((*MockService)(nil).ReadByEmail), ctx, email)
+}
+
+// ReadByPhoneNumber mocks base method.
+func (m *MockService) ReadByPhoneNumber(ctx context.Context, phoneNumber string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByPhoneNumber", ctx, phoneNumber)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByPhoneNumber indicates an expected call of ReadByPhoneNumber.
+func (mr *MockServiceMockRecorder) ReadByPhoneNumber(ctx, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByPhoneNumber", reflect.TypeOf((*MockService)(nil).ReadByPhoneNumber), ctx, phoneNumber)
+}
+
+// Refresh mocks base method.
+func (m *MockService) Refresh(ctx context.Context, refreshKey string) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshKey)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockServiceMockRecorder) Refresh(ctx, refreshKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockService)(nil).Refresh), ctx, refreshKey)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockService) RegenerateRecoveryCodes(ctx context.Context, userID domain.ID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockServiceMockRecorder) RegenerateRecoveryCodes(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockService)(nil).RegenerateRecoveryCodes), ctx, userID, code)
+}
+
+// RegisterOAuthClient mocks base method.
+func (m *MockService) RegisterOAuthClient(ctx context.Context, inp domain.RegisterClientInput) (domain.ClientRegistration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterOAuthClient", ctx, inp)
+	ret0, _ := ret[0].(domain.ClientRegistration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterOAuthClient indicates an expected call of RegisterOAuthClient.
+func (mr *MockServiceMockRecorder) RegisterOAuthClient(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterOAuthClient", reflect.TypeOf((*MockService)(nil).RegisterOAuthClient), ctx, inp)
+}
+
+// RemoveRole mocks base method.
+func (m *MockService) RemoveRole(ctx context.Context, userID domain.ID, role domain.Role, scope string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRole", ctx, userID, role, scope)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRole indicates an expected call of RemoveRole.
+func (mr *MockServiceMockRecorder) RemoveRole(ctx, userID, role, scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRole", reflect.TypeOf((*MockService)(nil).RemoveRole), ctx, userID, role, scope)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockServiceMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// RequestRole mocks base method.
+func (m *MockService) RequestRole(ctx context.Context, inp domain.RequestRoleInput) (domain.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRole", ctx, inp)
+	ret0, _ := ret[0].(domain.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestRole indicates an expected call of RequestRole.
+func (mr *MockServiceMockRecorder) RequestRole(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRole", reflect.TypeOf((*MockService)(nil).RequestRole), ctx, inp)
+}
+
+// RequestSignIn mocks base method.
+func (m *MockService) RequestSignIn(ctx context.Context, inp domain.RequestSignInInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestSignIn", ctx, inp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestSignIn indicates an expected call of RequestSignIn.
+func (mr *MockServiceMockRecorder) RequestSignIn(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestSignIn", reflect.TypeOf((*MockService)(nil).RequestSignIn), ctx, inp)
+}
+
+// RequestSignUp mocks base method.
+func (m *MockService) RequestSignUp(arg0 context.Context, arg1 domain.RequestSignUpInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestSignUp", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestSignUp indicates an expected call of RequestSignUp.
+func (mr *MockServiceMockRecorder) RequestSignUp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestSignUp", reflect.TypeOf((*MockService)(nil).RequestSignUp), arg0, arg1)
+}
+
+// ResetPassword mocks base method.
+func (m *MockService) ResetPassword(ctx context.Context, inp domain.ResetPasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, inp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockServiceMockRecorder) ResetPassword(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), ctx, inp)
+}
+
+// ReviewRoleRequest mocks base method.
+func (m *MockService) ReviewRoleRequest(ctx context.Context, inp domain.ReviewRoleRequestInput) (domain.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewRoleRequest", ctx, inp)
+	ret0, _ := ret[0].(domain.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewRoleRequest indicates an expected call of ReviewRoleRequest.
+func (mr *MockServiceMockRecorder) ReviewRoleRequest(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewRoleRequest", reflect.TypeOf((*MockService)(nil).ReviewRoleRequest), ctx, inp)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockService) RevokeAPIKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServiceMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockService)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RevokeConsent mocks base method.
+func (m *MockService) RevokeConsent(ctx context.Context, userID domain.ID, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeConsent", ctx, userID, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeConsent indicates an expected call of RevokeConsent.
+func (mr *MockServiceMockRecorder) RevokeConsent(ctx, userID, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeConsent", reflect.TypeOf((*MockService)(nil).RevokeConsent), ctx, userID, clientID)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockService) RevokeOtherSessions(ctx context.Context, refreshKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", ctx, refreshKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockServiceMockRecorder) RevokeOtherSessions(ctx, refreshKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockService)(nil).RevokeOtherSessions), ctx, refreshKey)
+}
+
+// RevokeSession mocks base method.
+func (m *MockService) RevokeSession(ctx context.Context, userID domain.ID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockServiceMockRecorder) RevokeSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockService)(nil).RevokeSession), ctx, userID, sessionID)
+}
+
+// RoleRequest mocks base method.
+func (m *MockService) RoleRequest(ctx context.Context, id string) (domain.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleRequest", ctx, id)
+	ret0, _ := ret[0].(domain.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoleRequest indicates an expected call of RoleRequest.
+func (mr *MockServiceMockRecorder) RoleRequest(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleRequest", reflect.TypeOf((*MockService)(nil).RoleRequest), ctx, id)
+}
+
+// RoleRequests mocks base method.
+func (m *MockService) RoleRequests(ctx context.Context, inp domain.ReadRoleRequestsInput) ([]domain.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleRequests", ctx, inp)
+	ret0, _ := ret[0].([]domain.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoleRequests indicates an expected call of RoleRequests.
+func (mr *MockServiceMockRecorder) RoleRequests(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleRequests", reflect.TypeOf((*MockService)(nil).RoleRequests), ctx, inp)
+}
+
+// Roles mocks base method.
+func (m *MockService) Roles(ctx context.Context) ([]domain.RoleDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Roles", ctx)
+	ret0, _ := ret[0].([]domain.RoleDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Roles indicates an expected call of Roles.
+func (mr *MockServiceMockRecorder) Roles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Roles", reflect.TypeOf((*MockService)(nil).Roles), ctx)
+}
+
+// Sessions mocks base method.
+func (m *MockService) Sessions(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sessions", ctx, userID)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sessions indicates an expected call of Sessions.
+func (mr *MockServiceMockRecorder) Sessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sessions", reflect.TypeOf((*MockService)(nil).Sessions), ctx, userID)
+}
+
+// SetRolePermissions mocks base method.
+func (m *MockService) SetRolePermissions(ctx context.Context, role domain.Role, permissions []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRolePermissions", ctx, role, permissions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRolePermissions indicates an expected call of SetRolePermissions.
+func (mr *MockServiceMockRecorder) SetRolePermissions(ctx, role, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRolePermissions", reflect.TypeOf((*MockService)(nil).SetRolePermissions), ctx, role, permissions)
+}
+
+// SignIn mocks base method.
+func (m *MockService) SignIn(ctx context.Context, inp domain.SignInInput) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIn", ctx, inp)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignIn indicates an expected call of SignIn.
+func (mr *MockServiceMockRecorder) SignIn(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockService)(nil).SignIn), ctx, inp)
+}
+
+// SignInEmailPassword mocks base method.
+func (m *MockService) SignInEmailPassword(ctx context.Context, email, password string) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInEmailPassword", ctx, email, password)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInEmailPassword indicates an expected call of SignInEmailPassword.
+func (mr *MockServiceMockRecorder) SignInEmailPassword(ctx, email, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInEmailPassword", reflect.TypeOf((*MockService)(nil).SignInEmailPassword), ctx, email, password)
+}
+
+// SignOut mocks base method.
+func (m *MockService) SignOut(ctx context.Context, refreshKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignOut", ctx, refreshKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignOut indicates an expected call of SignOut.
+func (mr *MockServiceMockRecorder) SignOut(ctx, refreshKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOut", reflect.TypeOf((*MockService)(nil).SignOut), ctx, refreshKey)
+}
+
+// SignUp mocks base method.
+func (m *MockService) SignUp(arg0 context.Context, arg1 domain.SignUpInput) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignUp", arg0, arg1)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignUp indicates an expected call of SignUp.
+func (mr *MockServiceMockRecorder) SignUp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockService)(nil).SignUp), arg0, arg1)
+}
+
+// Token mocks base method.
+func (m *MockService) Token(ctx context.Context, inp domain.TokenInput) (domain.TokenOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", ctx, inp)
+	ret0, _ := ret[0].(domain.TokenOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockServiceMockRecorder) Token(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockService)(nil).Token), ctx, inp)
+}
+
+// UnlinkIdentity mocks base method.
+func (m *MockService) UnlinkIdentity(ctx context.Context, userID domain.ID, provider string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlinkIdentity", ctx, userID, provider)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlinkIdentity indicates an expected call of UnlinkIdentity.
+func (mr *MockServiceMockRecorder) UnlinkIdentity(ctx, userID, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlinkIdentity", reflect.TypeOf((*MockService)(nil).UnlinkIdentity), ctx, userID, provider)
+}
+
+// UnlockAccount mocks base method.
+func (m *MockService) UnlockAccount(ctx context.Context, userID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockAccount", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockAccount indicates an expected call of UnlockAccount.
+func (mr *MockServiceMockRecorder) UnlockAccount(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockAccount", reflect.TypeOf((*MockService)(nil).UnlockAccount), ctx, userID)
+}
+
+// Update mocks base method.
+func (m *MockService) Update(ctx context.Context, changeset domain.UpdateInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, changeset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockServiceMockRecorder) Update(ctx, changeset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, changeset)
+}
+
+// UserInfo mocks base method.
+func (m *MockService) UserInfo(ctx context.Context, accessKey string) (domain.UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserInfo", ctx, accessKey)
+	ret0, _ := ret[0].(domain.UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserInfo indicates an expected call of UserInfo.
+func (mr *MockServiceMockRecorder) UserInfo(ctx, accessKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserInfo", reflect.TypeOf((*MockService)(nil).UserInfo), ctx, accessKey)
+}
+
+// VerifySecondFactor mocks base method.
+func (m *MockService) VerifySecondFactor(ctx context.Context, challengeToken, code string) (domain.SignInOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySecondFactor", ctx, challengeToken, code)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySecondFactor indicates an expected call of VerifySecondFactor.
+func (mr *MockServiceMockRecorder) VerifySecondFactor(ctx, challengeToken, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySecondFactor", reflect.TypeOf((*MockService)(nil).VerifySecondFactor), ctx, challengeToken, code)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
 // AddRole mocks base method.
-func (m *MockService) AddRole(ctx context.Context, userID domain.ID, role domain.Role) error {
+func (m *MockRepository) AddRole(ctx context.Context, userID domain.ID, grant domain.RoleGrant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRole", ctx, userID, grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRole indicates an expected call of AddRole.
+func (mr *MockRepositoryMockRecorder) AddRole(ctx, userID, grant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockRepository)(nil).AddRole), ctx, userID, grant)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockRepository) ConfirmTOTP(ctx context.Context, userID domain.ID, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockRepositoryMockRecorder) ConfirmTOTP(ctx, userID, recoveryCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockRepository)(nil).ConfirmTOTP), ctx, userID, recoveryCodes)
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(arg0 context.Context, arg1 domain.User) (domain.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(domain.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockRepository) CreateAPIKey(ctx context.Context, k domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockRepositoryMockRecorder) CreateAPIKey(ctx, k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepository)(nil).CreateAPIKey), ctx, k)
+}
+
+// CreateIdentity mocks base method.
+func (m *MockRepository) CreateIdentity(ctx context.Context, i domain.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdentity", ctx, i)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdentity indicates an expected call of CreateIdentity.
+func (mr *MockRepositoryMockRecorder) CreateIdentity(ctx, i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdentity", reflect.TypeOf((*MockRepository)(nil).CreateIdentity), ctx, i)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockRepository) CreateOAuthClient(ctx context.Context, c domain.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockRepositoryMockRecorder) CreateOAuthClient(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockRepository)(nil).CreateOAuthClient), ctx, c)
+}
+
+// CreatePasskey mocks base method.
+func (m *MockRepository) CreatePasskey(ctx context.Context, p domain.Passkey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasskey", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasskey indicates an expected call of CreatePasskey.
+func (mr *MockRepositoryMockRecorder) CreatePasskey(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasskey", reflect.TypeOf((*MockRepository)(nil).CreatePasskey), ctx, p)
+}
+
+// CreateRole mocks base method.
+func (m *MockRepository) CreateRole(ctx context.Context, r domain.RoleDefinition) (domain.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, r)
+	ret0, _ := ret[0].(domain.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockRepositoryMockRecorder) CreateRole(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockRepository)(nil).CreateRole), ctx, r)
+}
+
+// CreateRoleRequest mocks base method.
+func (m *MockRepository) CreateRoleRequest(ctx context.Context, r domain.RoleRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRoleRequest", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRoleRequest indicates an expected call of CreateRoleRequest.
+func (mr *MockRepositoryMockRecorder) CreateRoleRequest(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoleRequest", reflect.TypeOf((*MockRepository)(nil).CreateRoleRequest), ctx, r)
+}
+
+// CreateSession mocks base method.
+func (m *MockRepository) CreateSession(ctx context.Context, session domain.Session, first domain.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session, first)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockRepositoryMockRecorder) CreateSession(ctx, session, first interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockRepository)(nil).CreateSession), ctx, session, first)
+}
+
+// CreateTOTP mocks base method.
+func (m *MockRepository) CreateTOTP(ctx context.Context, t domain.TOTP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTOTP", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTOTP indicates an expected call of CreateTOTP.
+func (mr *MockRepositoryMockRecorder) CreateTOTP(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTOTP", reflect.TypeOf((*MockRepository)(nil).CreateTOTP), ctx, t)
+}
+
+// CreateWithIdentity mocks base method.
+func (m *MockRepository) CreateWithIdentity(ctx context.Context, u domain.User, i domain.Identity) (domain.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithIdentity", ctx, u, i)
+	ret0, _ := ret[0].(domain.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithIdentity indicates an expected call of CreateWithIdentity.
+func (mr *MockRepositoryMockRecorder) CreateWithIdentity(ctx, u, i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithIdentity", reflect.TypeOf((*MockRepository)(nil).CreateWithIdentity), ctx, u, i)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(arg0 context.Context, arg1 domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), arg0, arg1)
+}
+
+// DeleteConsent mocks base method.
+func (m *MockRepository) DeleteConsent(ctx context.Context, userID domain.ID, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteConsent", ctx, userID, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteConsent indicates an expected call of DeleteConsent.
+func (mr *MockRepositoryMockRecorder) DeleteConsent(ctx, userID, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConsent", reflect.TypeOf((*MockRepository)(nil).DeleteConsent), ctx, userID, clientID)
+}
+
+// DeleteExpiredGrants mocks base method.
+func (m *MockRepository) DeleteExpiredGrants(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredGrants", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredGrants indicates an expected call of DeleteExpiredGrants.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredGrants(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredGrants", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredGrants), ctx, now)
+}
+
+// DeleteIdentity mocks base method.
+func (m *MockRepository) DeleteIdentity(ctx context.Context, userID domain.ID, provider string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdentity", ctx, userID, provider)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdentity indicates an expected call of DeleteIdentity.
+func (mr *MockRepositoryMockRecorder) DeleteIdentity(ctx, userID, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdentity", reflect.TypeOf((*MockRepository)(nil).DeleteIdentity), ctx, userID, provider)
+}
+
+// DeleteOAuthClient mocks base method.
+func (m *MockRepository) DeleteOAuthClient(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockRepositoryMockRecorder) DeleteOAuthClient(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockRepository)(nil).DeleteOAuthClient), ctx, id)
+}
+
+// DeletePasskey mocks base method.
+func (m *MockRepository) DeletePasskey(ctx context.Context, userID domain.ID, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddRole", ctx, userID, role)
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddRole indicates an expected call of AddRole.
-func (mr *MockServiceMockRecorder) AddRole(ctx, userID, role interface{}) *gomock.Call {
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockRepositoryMockRecorder) DeletePasskey(ctx, userID, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockService)(nil).AddRole), ctx, userID, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockRepository)(nil).DeletePasskey), ctx, userID, id)
 }
 
-// Delete mocks base method.
-func (m *MockService) Delete(ctx context.Context, userID domain.ID) error {
+// DeleteRole mocks base method.
+func (m *MockRepository) DeleteRole(ctx context.Context, role domain.Role) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, role)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Delete indicates an expected call of Delete.
-func (mr *MockServiceMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockRepositoryMockRecorder) DeleteRole(ctx, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockRepository)(nil).DeleteRole), ctx, role)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockRepository) DeleteTOTP(ctx context.Context, userID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockRepositoryMockRecorder) DeleteTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockRepository)(nil).DeleteTOTP), ctx, userID)
 }
 
 // Read mocks base method.
-func (m *MockService) Read(ctx context.Context, id domain.ID) (domain.User, error) {
+func (m *MockRepository) Read(arg0 context.Context, arg1 domain.ID) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Read", ctx, id)
+	ret := m.ctrl.Call(m, "Read", arg0, arg1)
 	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Read indicates an expected call of Read.
-func (mr *MockServiceMockRecorder) Read(ctx, id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockService)(nil).Read), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockRepository)(nil).Read), arg0, arg1)
+}
+
+// ReadAPIKey mocks base method.
+func (m *MockRepository) ReadAPIKey(ctx context.Context, id string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAPIKey", ctx, id)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAPIKey indicates an expected call of ReadAPIKey.
+func (mr *MockRepositoryMockRecorder) ReadAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAPIKey", reflect.TypeOf((*MockRepository)(nil).ReadAPIKey), ctx, id)
+}
+
+// ReadAPIKeys mocks base method.
+func (m *MockRepository) ReadAPIKeys(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAPIKeys", ctx, owner)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAPIKeys indicates an expected call of ReadAPIKeys.
+func (mr *MockRepositoryMockRecorder) ReadAPIKeys(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAPIKeys", reflect.TypeOf((*MockRepository)(nil).ReadAPIKeys), ctx, owner)
 }
 
 // ReadAll mocks base method.
-func (m *MockService) ReadAll(ctx context.Context, cfg domain.ReadAllInput) ([]domain.User, error) {
+func (m *MockRepository) ReadAll(ctx context.Context, cfg domain.ReadAllInput) ([]domain.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadAll", ctx, cfg)
 	ret0, _ := ret[0].([]domain.User)
@@ -89,13 +1292,13 @@ func (m *MockService) ReadAll(ctx context.Context, cfg domain.ReadAllInput) ([]d
 }
 
 // ReadAll indicates an expected call of ReadAll.
-func (mr *MockServiceMockRecorder) ReadAll(ctx, cfg interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) ReadAll(ctx, cfg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockService)(nil).ReadAll), ctx, cfg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockRepository)(nil).ReadAll), ctx, cfg)
 }
 
 // ReadByEmail mocks base method.
-func (m *MockService) ReadByEmail(ctx context.Context, email string) (domain.User, error) {
+func (m *MockRepository) ReadByEmail(ctx context.Context, email string) (domain.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadByEmail", ctx, email)
 	ret0, _ := ret[0].(domain.User)
@@ -104,13 +1307,28 @@ func (m *MockService) ReadByEmail(ctx context.Context, email string) (domain.Use
 }
 
 // ReadByEmail indicates an expected call of ReadByEmail.
-func (mr *MockServiceMockRecorder) ReadByEmail(ctx, email interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) ReadByEmail(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByEmail", reflect.TypeOf((*MockService)(nil).ReadByEmail), ctx, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByEmail", reflect.TypeOf((*MockRepository)(nil).ReadByEmail), ctx, email)
+}
+
+// ReadByName mocks base method.
+func (m *MockRepository) ReadByName(ctx context.Context, fullName string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByName", ctx, fullName)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByName indicates an expected call of ReadByName.
+func (mr *MockRepositoryMockRecorder) ReadByName(ctx, fullName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByName", reflect.TypeOf((*MockRepository)(nil).ReadByName), ctx, fullName)
 }
 
 // ReadByPhoneNumber mocks base method.
-func (m *MockService) ReadByPhoneNumber(ctx context.Context, phoneNumber string) (domain.User, error) {
+func (m *MockRepository) ReadByPhoneNumber(ctx context.Context, phoneNumber string) (domain.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadByPhoneNumber", ctx, phoneNumber)
 	ret0, _ := ret[0].(domain.User)
@@ -119,280 +1337,402 @@ func (m *MockService) ReadByPhoneNumber(ctx context.Context, phoneNumber string)
 }
 
 // ReadByPhoneNumber indicates an expected call of ReadByPhoneNumber.
-func (mr *MockServiceMockRecorder) ReadByPhoneNumber(ctx, phoneNumber interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) ReadByPhoneNumber(ctx, phoneNumber interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByPhoneNumber", reflect.TypeOf((*MockService)(nil).ReadByPhoneNumber), ctx, phoneNumber)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByPhoneNumber", reflect.TypeOf((*MockRepository)(nil).ReadByPhoneNumber), ctx, phoneNumber)
 }
 
-// Refresh mocks base method.
-func (m *MockService) Refresh(ctx context.Context, refreshKey string) (domain.SignInOutput, error) {
+// ReadConsent mocks base method.
+func (m *MockRepository) ReadConsent(ctx context.Context, userID domain.ID, clientID string) (domain.Consent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Refresh", ctx, refreshKey)
-	ret0, _ := ret[0].(domain.SignInOutput)
+	ret := m.ctrl.Call(m, "ReadConsent", ctx, userID, clientID)
+	ret0, _ := ret[0].(domain.Consent)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Refresh indicates an expected call of Refresh.
-func (mr *MockServiceMockRecorder) Refresh(ctx, refreshKey interface{}) *gomock.Call {
+// ReadConsent indicates an expected call of ReadConsent.
+func (mr *MockRepositoryMockRecorder) ReadConsent(ctx, userID, clientID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockService)(nil).Refresh), ctx, refreshKey)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadConsent", reflect.TypeOf((*MockRepository)(nil).ReadConsent), ctx, userID, clientID)
 }
 
-// RemoveRole mocks base method.
-func (m *MockService) RemoveRole(ctx context.Context, userID domain.ID, role domain.Role) error {
+// ReadConsents mocks base method.
+func (m *MockRepository) ReadConsents(ctx context.Context, userID domain.ID) ([]domain.Consent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveRole", ctx, userID, role)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ReadConsents", ctx, userID)
+	ret0, _ := ret[0].([]domain.Consent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// RemoveRole indicates an expected call of RemoveRole.
-func (mr *MockServiceMockRecorder) RemoveRole(ctx, userID, role interface{}) *gomock.Call {
+// ReadConsents indicates an expected call of ReadConsents.
+func (mr *MockRepositoryMockRecorder) ReadConsents(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRole", reflect.TypeOf((*MockService)(nil).RemoveRole), ctx, userID, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadConsents", reflect.TypeOf((*MockRepository)(nil).ReadConsents), ctx, userID)
 }
 
-// RequestSignIn mocks base method.
-func (m *MockService) RequestSignIn(ctx context.Context, inp domain.RequestSignInInput) error {
+// ReadIdentities mocks base method.
+func (m *MockRepository) ReadIdentities(ctx context.Context, userID domain.ID) ([]domain.Identity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RequestSignIn", ctx, inp)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ReadIdentities", ctx, userID)
+	ret0, _ := ret[0].([]domain.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// RequestSignIn indicates an expected call of RequestSignIn.
-func (mr *MockServiceMockRecorder) RequestSignIn(ctx, inp interface{}) *gomock.Call {
+// ReadIdentities indicates an expected call of ReadIdentities.
+func (mr *MockRepositoryMockRecorder) ReadIdentities(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestSignIn", reflect.TypeOf((*MockService)(nil).RequestSignIn), ctx, inp)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadIdentities", reflect.TypeOf((*MockRepository)(nil).ReadIdentities), ctx, userID)
 }
 
-// RequestSignUp mocks base method.
-func (m *MockService) RequestSignUp(arg0 context.Context, arg1 domain.RequestSignUpInput) error {
+// ReadIdentity mocks base method.
+func (m *MockRepository) ReadIdentity(ctx context.Context, provider, subject string) (domain.Identity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RequestSignUp", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ReadIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(domain.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// RequestSignUp indicates an expected call of RequestSignUp.
-func (mr *MockServiceMockRecorder) RequestSignUp(arg0, arg1 interface{}) *gomock.Call {
+// ReadIdentity indicates an expected call of ReadIdentity.
+func (mr *MockRepositoryMockRecorder) ReadIdentity(ctx, provider, subject interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestSignUp", reflect.TypeOf((*MockService)(nil).RequestSignUp), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadIdentity", reflect.TypeOf((*MockRepository)(nil).ReadIdentity), ctx, provider, subject)
 }
 
-// SignIn mocks base method.
-func (m *MockService) SignIn(ctx context.Context, inp domain.SignInInput) (domain.SignInOutput, error) {
+// ReadOAuthClient mocks base method.
+func (m *MockRepository) ReadOAuthClient(ctx context.Context, id string) (domain.OAuthClient, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", ctx, inp)
-	ret0, _ := ret[0].(domain.SignInOutput)
+	ret := m.ctrl.Call(m, "ReadOAuthClient", ctx, id)
+	ret0, _ := ret[0].(domain.OAuthClient)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SignIn indicates an expected call of SignIn.
-func (mr *MockServiceMockRecorder) SignIn(ctx, inp interface{}) *gomock.Call {
+// ReadOAuthClient indicates an expected call of ReadOAuthClient.
+func (mr *MockRepositoryMockRecorder) ReadOAuthClient(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockService)(nil).SignIn), ctx, inp)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOAuthClient", reflect.TypeOf((*MockRepository)(nil).ReadOAuthClient), ctx, id)
 }
 
-// SignInEmailPassword mocks base method.
-func (m *MockService) SignInEmailPassword(ctx context.Context, email, password string) (domain.SignInOutput, error) {
+// ReadOAuthClients mocks base method.
+func (m *MockRepository) ReadOAuthClients(ctx context.Context) ([]domain.OAuthClient, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignInEmailPassword", ctx, email, password)
-	ret0, _ := ret[0].(domain.SignInOutput)
+	ret := m.ctrl.Call(m, "ReadOAuthClients", ctx)
+	ret0, _ := ret[0].([]domain.OAuthClient)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SignInEmailPassword indicates an expected call of SignInEmailPassword.
-func (mr *MockServiceMockRecorder) SignInEmailPassword(ctx, email, password interface{}) *gomock.Call {
+// ReadOAuthClients indicates an expected call of ReadOAuthClients.
+func (mr *MockRepositoryMockRecorder) ReadOAuthClients(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInEmailPassword", reflect.TypeOf((*MockService)(nil).SignInEmailPassword), ctx, email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOAuthClients", reflect.TypeOf((*MockRepository)(nil).ReadOAuthClients), ctx)
 }
 
-// SignUp mocks base method.
-func (m *MockService) SignUp(arg0 context.Context, arg1 domain.SignUpInput) (domain.SignInOutput, error) {
+// ReadPasskey mocks base method.
+func (m *MockRepository) ReadPasskey(ctx context.Context, id string) (domain.Passkey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", arg0, arg1)
-	ret0, _ := ret[0].(domain.SignInOutput)
+	ret := m.ctrl.Call(m, "ReadPasskey", ctx, id)
+	ret0, _ := ret[0].(domain.Passkey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SignUp indicates an expected call of SignUp.
-func (mr *MockServiceMockRecorder) SignUp(arg0, arg1 interface{}) *gomock.Call {
+// ReadPasskey indicates an expected call of ReadPasskey.
+func (mr *MockRepositoryMockRecorder) ReadPasskey(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockService)(nil).SignUp), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPasskey", reflect.TypeOf((*MockRepository)(nil).ReadPasskey), ctx, id)
 }
 
-// Update mocks base method.
-func (m *MockService) Update(ctx context.Context, changeset domain.UpdateInput) error {
+// ReadPasskeys mocks base method.
+func (m *MockRepository) ReadPasskeys(ctx context.Context, userID domain.ID) ([]domain.Passkey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, changeset)
+	ret := m.ctrl.Call(m, "ReadPasskeys", ctx, userID)
+	ret0, _ := ret[0].([]domain.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPasskeys indicates an expected call of ReadPasskeys.
+func (mr *MockRepositoryMockRecorder) ReadPasskeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPasskeys", reflect.TypeOf((*MockRepository)(nil).ReadPasskeys), ctx, userID)
+}
+
+// ReadRole mocks base method.
+func (m *MockRepository) ReadRole(ctx context.Context, role domain.Role) (domain.RoleDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRole", ctx, role)
+	ret0, _ := ret[0].(domain.RoleDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRole indicates an expected call of ReadRole.
+func (mr *MockRepositoryMockRecorder) ReadRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRole", reflect.TypeOf((*MockRepository)(nil).ReadRole), ctx, role)
+}
+
+// ReadRoleRequest mocks base method.
+func (m *MockRepository) ReadRoleRequest(ctx context.Context, id string) (domain.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRoleRequest", ctx, id)
+	ret0, _ := ret[0].(domain.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRoleRequest indicates an expected call of ReadRoleRequest.
+func (mr *MockRepositoryMockRecorder) ReadRoleRequest(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRoleRequest", reflect.TypeOf((*MockRepository)(nil).ReadRoleRequest), ctx, id)
+}
+
+// ReadRoleRequests mocks base method.
+func (m *MockRepository) ReadRoleRequests(ctx context.Context, inp domain.ReadRoleRequestsInput) ([]domain.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRoleRequests", ctx, inp)
+	ret0, _ := ret[0].([]domain.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRoleRequests indicates an expected call of ReadRoleRequests.
+func (mr *MockRepositoryMockRecorder) ReadRoleRequests(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRoleRequests", reflect.TypeOf((*MockRepository)(nil).ReadRoleRequests), ctx, inp)
+}
+
+// ReadRoles mocks base method.
+func (m *MockRepository) ReadRoles(ctx context.Context) ([]domain.RoleDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRoles", ctx)
+	ret0, _ := ret[0].([]domain.RoleDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRoles indicates an expected call of ReadRoles.
+func (mr *MockRepositoryMockRecorder) ReadRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRoles", reflect.TypeOf((*MockRepository)(nil).ReadRoles), ctx)
+}
+
+// ReadSession mocks base method.
+func (m *MockRepository) ReadSession(ctx context.Context, id string) (domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSession", ctx, id)
+	ret0, _ := ret[0].(domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSession indicates an expected call of ReadSession.
+func (mr *MockRepositoryMockRecorder) ReadSession(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSession", reflect.TypeOf((*MockRepository)(nil).ReadSession), ctx, id)
+}
+
+// ReadSessions mocks base method.
+func (m *MockRepository) ReadSessions(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSessions", ctx, userID)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSessions indicates an expected call of ReadSessions.
+func (mr *MockRepositoryMockRecorder) ReadSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSessions", reflect.TypeOf((*MockRepository)(nil).ReadSessions), ctx, userID)
+}
+
+// ReadTOTP mocks base method.
+func (m *MockRepository) ReadTOTP(ctx context.Context, userID domain.ID) (domain.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTOTP", ctx, userID)
+	ret0, _ := ret[0].(domain.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTOTP indicates an expected call of ReadTOTP.
+func (mr *MockRepositoryMockRecorder) ReadTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTOTP", reflect.TypeOf((*MockRepository)(nil).ReadTOTP), ctx, userID)
+}
+
+// RemoveRole mocks base method.
+func (m *MockRepository) RemoveRole(ctx context.Context, userID domain.ID, role domain.Role, scope string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRole", ctx, userID, role, scope)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update.
-func (mr *MockServiceMockRecorder) Update(ctx, changeset interface{}) *gomock.Call {
+// RemoveRole indicates an expected call of RemoveRole.
+func (mr *MockRepositoryMockRecorder) RemoveRole(ctx, userID, role, scope interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, changeset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRole", reflect.TypeOf((*MockRepository)(nil).RemoveRole), ctx, userID, role, scope)
 }
 
-// MockRepository is a mock of Repository interface.
-type MockRepository struct {
-	ctrl     *gomock.Controller
-	recorder *MockRepositoryMockRecorder
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockRepository) ReplaceRecoveryCodes(ctx context.Context, userID domain.ID, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// MockRepositoryMockRecorder is the mock recorder for MockRepository.
-type MockRepositoryMockRecorder struct {
-	mock *MockRepository
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, recoveryCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockRepository)(nil).ReplaceRecoveryCodes), ctx, userID, recoveryCodes)
 }
 
-// NewMockRepository creates a new mock instance.
-func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
-	mock := &MockRepository{ctrl: ctrl}
-	mock.recorder = &MockRepositoryMockRecorder{mock}
-	return mock
+// ReviewRoleRequest mocks base method.
+func (m *MockRepository) ReviewRoleRequest(ctx context.Context, r domain.RoleRequest, grant *domain.RoleGrant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewRoleRequest", ctx, r, grant)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
-	return m.recorder
+// ReviewRoleRequest indicates an expected call of ReviewRoleRequest.
+func (mr *MockRepositoryMockRecorder) ReviewRoleRequest(ctx, r, grant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewRoleRequest", reflect.TypeOf((*MockRepository)(nil).ReviewRoleRequest), ctx, r, grant)
 }
 
-// AddRole mocks base method.
-func (m *MockRepository) AddRole(arg0 context.Context, arg1 domain.ID, arg2 domain.Role) error {
+// RevokeAPIKey mocks base method.
+func (m *MockRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddRole", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id, revokedAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddRole indicates an expected call of AddRole.
-func (mr *MockRepositoryMockRecorder) AddRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockRepositoryMockRecorder) RevokeAPIKey(ctx, id, revokedAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockRepository)(nil).AddRole), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockRepository)(nil).RevokeAPIKey), ctx, id, revokedAt)
 }
 
-// Create mocks base method.
-func (m *MockRepository) Create(arg0 context.Context, arg1 domain.User) (domain.ID, error) {
+// RevokeAllSessions mocks base method.
+func (m *MockRepository) RevokeAllSessions(ctx context.Context, userID domain.ID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1)
-	ret0, _ := ret[0].(domain.ID)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockRepositoryMockRecorder) RevokeAllSessions(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockRepository)(nil).RevokeAllSessions), ctx, userID)
 }
 
-// Delete mocks base method.
-func (m *MockRepository) Delete(arg0 context.Context, arg1 domain.ID) error {
+// RevokeOtherSessions mocks base method.
+func (m *MockRepository) RevokeOtherSessions(ctx context.Context, userID domain.ID, keepID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", ctx, userID, keepID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Delete indicates an expected call of Delete.
-func (mr *MockRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockRepositoryMockRecorder) RevokeOtherSessions(ctx, userID, keepID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockRepository)(nil).RevokeOtherSessions), ctx, userID, keepID)
 }
 
-// Read mocks base method.
-func (m *MockRepository) Read(arg0 context.Context, arg1 domain.ID) (domain.User, error) {
+// RevokeRefreshFamily mocks base method.
+func (m *MockRepository) RevokeRefreshFamily(ctx context.Context, familyID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Read", arg0, arg1)
-	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RevokeRefreshFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Read indicates an expected call of Read.
-func (mr *MockRepositoryMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call {
+// RevokeRefreshFamily indicates an expected call of RevokeRefreshFamily.
+func (mr *MockRepositoryMockRecorder) RevokeRefreshFamily(ctx, familyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockRepository)(nil).Read), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshFamily", reflect.TypeOf((*MockRepository)(nil).RevokeRefreshFamily), ctx, familyID)
 }
 
-// ReadAll mocks base method.
-func (m *MockRepository) ReadAll(ctx context.Context, cfg domain.ReadAllInput) ([]domain.User, error) {
+// RevokeSession mocks base method.
+func (m *MockRepository) RevokeSession(ctx context.Context, userID domain.ID, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadAll", ctx, cfg)
-	ret0, _ := ret[0].([]domain.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadAll indicates an expected call of ReadAll.
-func (mr *MockRepositoryMockRecorder) ReadAll(ctx, cfg interface{}) *gomock.Call {
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockRepositoryMockRecorder) RevokeSession(ctx, userID, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockRepository)(nil).ReadAll), ctx, cfg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockRepository)(nil).RevokeSession), ctx, userID, id)
 }
 
-// ReadByEmail mocks base method.
-func (m *MockRepository) ReadByEmail(ctx context.Context, email string) (domain.User, error) {
+// RotateRefreshToken mocks base method.
+func (m *MockRepository) RotateRefreshToken(ctx context.Context, oldID string, next domain.RefreshToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadByEmail", ctx, email)
-	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, oldID, next)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadByEmail indicates an expected call of ReadByEmail.
-func (mr *MockRepositoryMockRecorder) ReadByEmail(ctx, email interface{}) *gomock.Call {
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockRepositoryMockRecorder) RotateRefreshToken(ctx, oldID, next interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByEmail", reflect.TypeOf((*MockRepository)(nil).ReadByEmail), ctx, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockRepository)(nil).RotateRefreshToken), ctx, oldID, next)
 }
 
-// ReadByName mocks base method.
-func (m *MockRepository) ReadByName(ctx context.Context, fullName string) (domain.User, error) {
+// SaveConsent mocks base method.
+func (m *MockRepository) SaveConsent(ctx context.Context, c domain.Consent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadByName", ctx, fullName)
-	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SaveConsent", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadByName indicates an expected call of ReadByName.
-func (mr *MockRepositoryMockRecorder) ReadByName(ctx, fullName interface{}) *gomock.Call {
+// SaveConsent indicates an expected call of SaveConsent.
+func (mr *MockRepositoryMockRecorder) SaveConsent(ctx, c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByName", reflect.TypeOf((*MockRepository)(nil).ReadByName), ctx, fullName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveConsent", reflect.TypeOf((*MockRepository)(nil).SaveConsent), ctx, c)
 }
 
-// ReadByPhoneNumber mocks base method.
-func (m *MockRepository) ReadByPhoneNumber(ctx context.Context, phoneNumber string) (domain.User, error) {
+// SetRolePermissions mocks base method.
+func (m *MockRepository) SetRolePermissions(ctx context.Context, role domain.Role, permissions []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadByPhoneNumber", ctx, phoneNumber)
-	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetRolePermissions", ctx, role, permissions)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadByPhoneNumber indicates an expected call of ReadByPhoneNumber.
-func (mr *MockRepositoryMockRecorder) ReadByPhoneNumber(ctx, phoneNumber interface{}) *gomock.Call {
+// SetRolePermissions indicates an expected call of SetRolePermissions.
+func (mr *MockRepositoryMockRecorder) SetRolePermissions(ctx, role, permissions interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByPhoneNumber", reflect.TypeOf((*MockRepository)(nil).ReadByPhoneNumber), ctx, phoneNumber)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRolePermissions", reflect.TypeOf((*MockRepository)(nil).SetRolePermissions), ctx, role, permissions)
 }
 
-// RemoveRole mocks base method.
-func (m *MockRepository) RemoveRole(arg0 context.Context, arg1 domain.ID, arg2 domain.Role) error {
+// TouchAPIKey mocks base method.
+func (m *MockRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveRole", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id, usedAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RemoveRole indicates an expected call of RemoveRole.
-func (mr *MockRepositoryMockRecorder) RemoveRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockRepositoryMockRecorder) TouchAPIKey(ctx, id, usedAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRole", reflect.TypeOf((*MockRepository)(nil).RemoveRole), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockRepository)(nil).TouchAPIKey), ctx, id, usedAt)
 }
 
 // Update mocks base method.
@@ -409,6 +1749,48 @@ func (mr *MockRepositoryMockRecorder) Update(ctx, changeset interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, changeset)
 }
 
+// UpdatePasskeySignCount mocks base method.
+func (m *MockRepository) UpdatePasskeySignCount(ctx context.Context, id string, signCount uint32, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasskeySignCount", ctx, id, signCount, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasskeySignCount indicates an expected call of UpdatePasskeySignCount.
+func (mr *MockRepositoryMockRecorder) UpdatePasskeySignCount(ctx, id, signCount, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasskeySignCount", reflect.TypeOf((*MockRepository)(nil).UpdatePasskeySignCount), ctx, id, signCount, usedAt)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockRepository) UpdatePassword(ctx context.Context, userID domain.ID, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockRepositoryMockRecorder) UpdatePassword(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepository)(nil).UpdatePassword), ctx, userID, passwordHash)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockRepository) UseRecoveryCode(ctx context.Context, userID domain.ID, recoveryCode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, recoveryCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockRepositoryMockRecorder) UseRecoveryCode(ctx, userID, recoveryCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockRepository)(nil).UseRecoveryCode), ctx, userID, recoveryCode)
+}
+
 // MockSMSsender is a mock of SMSsender interface.
 type MockSMSsender struct {
 	ctrl     *gomock.Controller
@@ -506,6 +1888,20 @@ func (m *MockCache) EXPECT() *MockCacheMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockCache) Delete(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCacheMockRecorder) Delete(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCache)(nil).Delete), key)
+}
+
 // Get mocks base method.
 func (m *MockCache) Get(key string) (string, error) {
 	m.ctrl.T.Helper()
@@ -521,18 +1917,288 @@ func (mr *MockCacheMockRecorder) Get(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache)(nil).Get), key)
 }
 
+// Incr mocks base method.
+func (m *MockCache) Incr(key string, ttl time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", key, ttl)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockCacheMockRecorder) Incr(key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockCache)(nil).Incr), key, ttl)
+}
+
+// Pop mocks base method.
+func (m *MockCache) Pop(key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pop", key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pop indicates an expected call of Pop.
+func (mr *MockCacheMockRecorder) Pop(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pop", reflect.TypeOf((*MockCache)(nil).Pop), key)
+}
+
 // Store mocks base method.
-func (m *MockCache) Store(key, value string) error {
+func (m *MockCache) Store(key, value string, ttl time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", key, value)
+	ret := m.ctrl.Call(m, "Store", key, value, ttl)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store.
-func (mr *MockCacheMockRecorder) Store(key, value interface{}) *gomock.Call {
+func (mr *MockCacheMockRecorder) Store(key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCache)(nil).Store), key, value, ttl)
+}
+
+// MockRateLimitStore is a mock of RateLimitStore interface.
+type MockRateLimitStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitStoreMockRecorder
+}
+
+// MockRateLimitStoreMockRecorder is the mock recorder for MockRateLimitStore.
+type MockRateLimitStoreMockRecorder struct {
+	mock *MockRateLimitStore
+}
+
+// NewMockRateLimitStore creates a new mock instance.
+func NewMockRateLimitStore(ctrl *gomock.Controller) *MockRateLimitStore {
+	mock := &MockRateLimitStore{ctrl: ctrl}
+	mock.recorder = &MockRateLimitStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitStore) EXPECT() *MockRateLimitStoreMockRecorder {
+	return m.recorder
+}
+
+// Hit mocks base method.
+func (m *MockRateLimitStore) Hit(key string, window time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hit", key, window)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hit indicates an expected call of Hit.
+func (mr *MockRateLimitStoreMockRecorder) Hit(key, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hit", reflect.TypeOf((*MockRateLimitStore)(nil).Hit), key, window)
+}
+
+// MockBreachedPasswords is a mock of BreachedPasswords interface.
+type MockBreachedPasswords struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreachedPasswordsMockRecorder
+}
+
+// MockBreachedPasswordsMockRecorder is the mock recorder for MockBreachedPasswords.
+type MockBreachedPasswordsMockRecorder struct {
+	mock *MockBreachedPasswords
+}
+
+// NewMockBreachedPasswords creates a new mock instance.
+func NewMockBreachedPasswords(ctrl *gomock.Controller) *MockBreachedPasswords {
+	mock := &MockBreachedPasswords{ctrl: ctrl}
+	mock.recorder = &MockBreachedPasswordsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreachedPasswords) EXPECT() *MockBreachedPasswordsMockRecorder {
+	return m.recorder
+}
+
+// Range mocks base method.
+func (m *MockBreachedPasswords) Range(ctx context.Context, prefix string) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Range", ctx, prefix)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockBreachedPasswordsMockRecorder) Range(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockBreachedPasswords)(nil).Range), ctx, prefix)
+}
+
+// MockWebAuthn is a mock of WebAuthn interface.
+type MockWebAuthn struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebAuthnMockRecorder
+}
+
+// MockWebAuthnMockRecorder is the mock recorder for MockWebAuthn.
+type MockWebAuthnMockRecorder struct {
+	mock *MockWebAuthn
+}
+
+// NewMockWebAuthn creates a new mock instance.
+func NewMockWebAuthn(ctrl *gomock.Controller) *MockWebAuthn {
+	mock := &MockWebAuthn{ctrl: ctrl}
+	mock.recorder = &MockWebAuthnMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebAuthn) EXPECT() *MockWebAuthnMockRecorder {
+	return m.recorder
+}
+
+// AssertedPasskey mocks base method.
+func (m *MockWebAuthn) AssertedPasskey(response []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssertedPasskey", response)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssertedPasskey indicates an expected call of AssertedPasskey.
+func (mr *MockWebAuthnMockRecorder) AssertedPasskey(response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssertedPasskey", reflect.TypeOf((*MockWebAuthn)(nil).AssertedPasskey), response)
+}
+
+// AssertionOptions mocks base method.
+func (m *MockWebAuthn) AssertionOptions(challenge []byte, allow []domain.Passkey) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssertionOptions", challenge, allow)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssertionOptions indicates an expected call of AssertionOptions.
+func (mr *MockWebAuthnMockRecorder) AssertionOptions(challenge, allow interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssertionOptions", reflect.TypeOf((*MockWebAuthn)(nil).AssertionOptions), challenge, allow)
+}
+
+// RegistrationOptions mocks base method.
+func (m *MockWebAuthn) RegistrationOptions(challenge []byte, u domain.User, exclude []domain.Passkey) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegistrationOptions", challenge, u, exclude)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegistrationOptions indicates an expected call of RegistrationOptions.
+func (mr *MockWebAuthnMockRecorder) RegistrationOptions(challenge, u, exclude interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegistrationOptions", reflect.TypeOf((*MockWebAuthn)(nil).RegistrationOptions), challenge, u, exclude)
+}
+
+// VerifyAssertion mocks base method.
+func (m *MockWebAuthn) VerifyAssertion(challenge []byte, p domain.Passkey, response []byte) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAssertion", challenge, p, response)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAssertion indicates an expected call of VerifyAssertion.
+func (mr *MockWebAuthnMockRecorder) VerifyAssertion(challenge, p, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAssertion", reflect.TypeOf((*MockWebAuthn)(nil).VerifyAssertion), challenge, p, response)
+}
+
+// VerifyRegistration mocks base method.
+func (m *MockWebAuthn) VerifyRegistration(challenge, response []byte) (domain.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyRegistration", challenge, response)
+	ret0, _ := ret[0].(domain.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyRegistration indicates an expected call of VerifyRegistration.
+func (mr *MockWebAuthnMockRecorder) VerifyRegistration(challenge, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRegistration", reflect.TypeOf((*MockWebAuthn)(nil).VerifyRegistration), challenge, response)
+}
+
+// MockOIDCProvider is a mock of OIDCProvider interface.
+type MockOIDCProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCProviderMockRecorder
+}
+
+// MockOIDCProviderMockRecorder is the mock recorder for MockOIDCProvider.
+type MockOIDCProviderMockRecorder struct {
+	mock *MockOIDCProvider
+}
+
+// NewMockOIDCProvider creates a new mock instance.
+func NewMockOIDCProvider(ctrl *gomock.Controller) *MockOIDCProvider {
+	mock := &MockOIDCProvider{ctrl: ctrl}
+	mock.recorder = &MockOIDCProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCProvider) EXPECT() *MockOIDCProviderMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockOIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", state, nonce, codeChallenge)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockOIDCProviderMockRecorder) AuthCodeURL(state, nonce, codeChallenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDCProvider)(nil).AuthCodeURL), state, nonce, codeChallenge)
+}
+
+// Exchange mocks base method.
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (domain.IDTokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", ctx, code, codeVerifier)
+	ret0, _ := ret[0].(domain.IDTokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockOIDCProviderMockRecorder) Exchange(ctx, code, codeVerifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockOIDCProvider)(nil).Exchange), ctx, code, codeVerifier)
+}
+
+// Name mocks base method.
+func (m *MockOIDCProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockOIDCProviderMockRecorder) Name() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCache)(nil).Store), key, value)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockOIDCProvider)(nil).Name))
 }
 
 // MockLogger is a mock of Logger interface.
@@ -630,6 +2296,21 @@ func (mr *MockJWTmanagerMockRecorder) DecodeAccess(accessKey interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeAccess", reflect.TypeOf((*MockJWTmanager)(nil).DecodeAccess), accessKey)
 }
 
+// DecodeClientAssertion mocks base method.
+func (m *MockJWTmanager) DecodeClientAssertion(assertion string, jwks []byte) (domain.ClientAssertion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeClientAssertion", assertion, jwks)
+	ret0, _ := ret[0].(domain.ClientAssertion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecodeClientAssertion indicates an expected call of DecodeClientAssertion.
+func (mr *MockJWTmanagerMockRecorder) DecodeClientAssertion(assertion, jwks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeClientAssertion", reflect.TypeOf((*MockJWTmanager)(nil).DecodeClientAssertion), assertion, jwks)
+}
+
 // DecodeRefresh mocks base method.
 func (m *MockJWTmanager) DecodeRefresh(refreshKey string) (domain.RefreshClaims, error) {
 	m.ctrl.T.Helper()
@@ -646,40 +2327,58 @@ func (mr *MockJWTmanagerMockRecorder) DecodeRefresh(refreshKey interface{}) *gom
 }
 
 // Generate mocks base method.
-func (m *MockJWTmanager) Generate(userID domain.ID, roles []domain.Role) (domain.SignInOutput, error) {
+func (m *MockJWTmanager) Generate(userID domain.ID, roles []domain.Role, permissions []string, grants []domain.RoleGrant, familyID string) (domain.SignInOutput, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Generate", userID, roles)
+	ret := m.ctrl.Call(m, "Generate", userID, roles, permissions, grants, familyID)
 	ret0, _ := ret[0].(domain.SignInOutput)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Generate indicates an expected call of Generate.
-func (mr *MockJWTmanagerMockRecorder) Generate(userID, roles interface{}) *gomock.Call {
+func (mr *MockJWTmanagerMockRecorder) Generate(userID, roles, permissions, grants, familyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockJWTmanager)(nil).Generate), userID, roles)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockJWTmanager)(nil).Generate), userID, roles, permissions, grants, familyID)
 }
 
-// SetExp mocks base method.
-func (m *MockJWTmanager) SetExp(access, refresh time.Duration) {
+// GenerateGrant mocks base method.
+func (m *MockJWTmanager) GenerateGrant(grant domain.TokenGrant, familyID string) (domain.SignInOutput, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetExp", access, refresh)
+	ret := m.ctrl.Call(m, "GenerateGrant", grant, familyID)
+	ret0, _ := ret[0].(domain.SignInOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SetExp indicates an expected call of SetExp.
-func (mr *MockJWTmanagerMockRecorder) SetExp(access, refresh interface{}) *gomock.Call {
+// GenerateGrant indicates an expected call of GenerateGrant.
+func (mr *MockJWTmanagerMockRecorder) GenerateGrant(grant, familyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExp", reflect.TypeOf((*MockJWTmanager)(nil).SetExp), access, refresh)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateGrant", reflect.TypeOf((*MockJWTmanager)(nil).GenerateGrant), grant, familyID)
+}
+
+// GenerateIDToken mocks base method.
+func (m *MockJWTmanager) GenerateIDToken(clientID, nonce string, info domain.UserInfo) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateIDToken", clientID, nonce, info)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateIDToken indicates an expected call of GenerateIDToken.
+func (mr *MockJWTmanagerMockRecorder) GenerateIDToken(clientID, nonce, info interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateIDToken", reflect.TypeOf((*MockJWTmanager)(nil).GenerateIDToken), clientID, nonce, info)
 }
 
-// SetKey mocks base method.
-func (m *MockJWTmanager) SetKey(key []byte) {
+// SetExp mocks base method.
+func (m *MockJWTmanager) SetExp(access, refresh time.Duration) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetKey", key)
+	m.ctrl.Call(m, "SetExp", access, refresh)
 }
 
-// SetKey indicates an expected call of SetKey.
-func (mr *MockJWTmanagerMockRecorder) SetKey(key interface{}) *gomock.Call {
+// SetExp indicates an expected call of SetExp.
+func (mr *MockJWTmanagerMockRecorder) SetExp(access, refresh interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKey", reflect.TypeOf((*MockJWTmanager)(nil).SetKey), key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExp", reflect.TypeOf((*MockJWTmanager)(nil).SetExp), access, refresh)
 }
@@ -0,0 +1,661 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Authorization server for other applications: authorization code flow
+// with PKCE, client credentials and refresh tokens of RFC 6749 plus
+// id tokens and userinfo of OpenID Connect. Codes are kept in cache under
+// a hash of the code the same way as our other one time secrets.
+// Refresh tokens of clients live in sessions like our own ones, so users
+// see and revoke them in the same place.
+
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+	// ScopeOfflineAccess asks for a refresh token
+	ScopeOfflineAccess = "offline_access"
+	// ScopeRoles puts roles and permissions of the user into access tokens,
+	// without it a client can do only what any user can.
+	ScopeRoles = "roles"
+
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+
+	ResponseTypeCode = "code"
+	PKCEMethodS256   = "S256"
+	TokenTypeBearer  = "Bearer"
+
+	clientSecretLength = 32
+)
+
+// userScopes make sense only when client acts on behalf of a user
+var userScopes = map[string]bool{
+	ScopeOpenID:        true,
+	ScopeProfile:       true,
+	ScopeEmail:         true,
+	ScopePhone:         true,
+	ScopeOfflineAccess: true,
+	ScopeRoles:         true,
+}
+
+var grantTypes = map[string]bool{
+	GrantAuthorizationCode: true,
+	GrantClientCredentials: true,
+	GrantRefreshToken:      true,
+}
+
+type (
+	// ConsentRequiredError is returned by Authorize when user has to allow
+	// the client to use Scopes first, errors.Is(err, ErrConsentRequired) works with it.
+	ConsentRequiredError struct {
+		Client OAuthClient
+		Scopes []string
+	}
+
+	// authorizationCode is what we remember about a code till the client exchanges it
+	authorizationCode struct {
+		ClientID    string   `json:"clientID"`
+		UserID      ID       `json:"userID"`
+		RedirectURI string   `json:"redirectURI"`
+		Scopes      []string `json:"scopes"`
+		Nonce       string   `json:"nonce,omitempty"`
+		Challenge   string   `json:"challenge"`
+	}
+)
+
+func (e *ConsentRequiredError) Error() string {
+	return ErrConsentRequired.Error() + ": " + strings.Join(e.Scopes, " ")
+}
+
+func (e *ConsentRequiredError) Is(target error) bool {
+	return target == ErrConsentRequired
+}
+
+func authorizationCodeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return "oauth:code:" + hex.EncodeToString(sum[:])
+}
+
+// client secrets are 256 random bits, unlike passwords they
+// can not be guessed, so sha256 is enough for them
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseScopes(scope string) []string {
+	return strings.Fields(scope)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// missingScopes returns scopes that are not in allowed
+func missingScopes(scopes, allowed []string) []string {
+	var missing []string
+	for _, v := range scopes {
+		if !contains(allowed, v) {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// delegableScopes leaves out scopes users can not give to a client
+func delegableScopes(scopes []string) []string {
+	var delegable []string
+	for _, v := range scopes {
+		if !serviceScopes[v] {
+			delegable = append(delegable, v)
+		}
+	}
+	return delegable
+}
+
+// DelegableScopes are all scopes users can give to a client,
+// that is what authorization requests may ask for.
+func DelegableScopes() []string {
+	scopes := make([]string, 0, len(userScopes))
+	for v := range userScopes {
+		scopes = append(scopes, v)
+	}
+	scopes = delegableScopes(scopes)
+	sort.Strings(scopes)
+	return scopes
+}
+
+func (c OAuthClient) allows(grantType string) bool {
+	return contains(c.GrantTypes, grantType)
+}
+
+// redirectURI may be left out only if there is nothing to choose from,
+// both in authorization and token requests
+func (c OAuthClient) redirectURI(uri string) string {
+	if uri == "" && len(c.RedirectURIs) == 1 {
+		return c.RedirectURIs[0]
+	}
+	return uri
+}
+
+// Authorize issues codes only to the user who is calling,
+// nobody else may let clients act on behalf of the user.
+func (s *service) Authorize(ctx context.Context, userID ID, inp AuthorizeInput) (string, error) {
+	if !callerIs(ctx, userID) {
+		return "", fmt.Errorf("authorize(): %w", ErrNotAllowed)
+	}
+	client, err := s.repo.ReadOAuthClient(ctx, inp.ClientID)
+	if errors.Is(err, ErrClientNotFound) {
+		return "", fmt.Errorf("authorize(): %w", ErrInvalidClient)
+	}
+	if err != nil {
+		return "", fmt.Errorf("authorize(): %w", err)
+	}
+	redirectURI := client.redirectURI(inp.RedirectURI)
+	if !contains(client.RedirectURIs, redirectURI) {
+		return "", fmt.Errorf("authorize(): %w", ErrInvalidRedirectURI)
+	}
+
+	switch {
+	case inp.ResponseType != ResponseTypeCode:
+		return "", fmt.Errorf("authorize(): %w", ErrUnsupportedResponseType)
+	case !client.allows(GrantAuthorizationCode):
+		return "", fmt.Errorf("authorize(): %w", ErrUnauthorizedClient)
+	// plain challenge is the verifier itself, it protects nothing
+	case inp.CodeChallengeMethod != PKCEMethodS256 || len(inp.CodeChallenge) != base64.RawURLEncoding.EncodedLen(sha256.Size):
+		return "", fmt.Errorf("authorize(): %w", ErrPKCERequired)
+	}
+	scopes := parseScopes(inp.Scope)
+	if len(scopes) == 0 {
+		scopes = delegableScopes(client.Scopes)
+	}
+	if len(scopes) == 0 || len(missingScopes(scopes, delegableScopes(client.Scopes))) != 0 {
+		return "", fmt.Errorf("authorize(): %w", ErrInvalidScope)
+	}
+
+	if _, err := s.repo.Read(ctx, userID); err != nil {
+		return "", fmt.Errorf("authorize(): %w", err)
+	}
+	if err := s.checkConsent(ctx, userID, client, scopes); err != nil {
+		return "", fmt.Errorf("authorize(): %w", err)
+	}
+
+	code, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("authorize(): could not generate code: %w", err)
+	}
+	value, err := json.Marshal(authorizationCode{
+		ClientID:    client.ID,
+		UserID:      userID,
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+		Nonce:       inp.Nonce,
+		Challenge:   inp.CodeChallenge,
+	})
+	if err != nil {
+		return "", fmt.Errorf("authorize(): %w", err)
+	}
+	if err := s.cache.Store(authorizationCodeKey(code), string(value), OAuthCodeExp); err != nil {
+		return "", fmt.Errorf("authorize(): could not cache code: %w", err)
+	}
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", fmt.Errorf("authorize(): %w", ErrInvalidRedirectURI)
+	}
+	q := redirect.Query()
+	q.Set("code", code)
+	if inp.State != "" {
+		q.Set("state", inp.State)
+	}
+	redirect.RawQuery = q.Encode()
+	return redirect.String(), nil
+}
+
+// checkConsent returns *ConsentRequiredError if user has not
+// allowed client to use every one of scopes yet
+func (s *service) checkConsent(ctx context.Context, userID ID, client OAuthClient, scopes []string) error {
+	if client.Trusted {
+		return nil
+	}
+	consent, err := s.repo.ReadConsent(ctx, userID, client.ID)
+	if err != nil && !errors.Is(err, ErrConsentNotFound) {
+		return err
+	}
+	if missing := missingScopes(scopes, consent.Scopes); len(missing) != 0 {
+		return &ConsentRequiredError{Client: client, Scopes: missing}
+	}
+	return nil
+}
+
+func (s *service) Token(ctx context.Context, inp TokenInput) (TokenOutput, error) {
+	if !grantTypes[inp.GrantType] {
+		return TokenOutput{}, fmt.Errorf("token(): %w", ErrUnsupportedGrantType)
+	}
+	client, err := s.authenticateClient(ctx, inp)
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("token(): %w", err)
+	}
+	if !client.allows(inp.GrantType) {
+		return TokenOutput{}, fmt.Errorf("token(): %w", ErrUnauthorizedClient)
+	}
+
+	var out TokenOutput
+	switch inp.GrantType {
+	case GrantAuthorizationCode:
+		out, err = s.exchangeCode(ctx, client, inp)
+	case GrantRefreshToken:
+		out, err = s.refreshGrant(ctx, client, inp)
+	case GrantClientCredentials:
+		out, err = s.clientCredentials(client, inp)
+	}
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("token(): %w", err)
+	}
+	return out, nil
+}
+
+// authenticateClient checks secret or assertion of a confidential
+// client, public ones only have to exist.
+func (s *service) authenticateClient(ctx context.Context, inp TokenInput) (OAuthClient, error) {
+	client, err := s.repo.ReadOAuthClient(ctx, inp.ClientID)
+	if errors.Is(err, ErrClientNotFound) {
+		return OAuthClient{}, ErrInvalidClient
+	}
+	if err != nil {
+		return OAuthClient{}, err
+	}
+	assertion := inp.ClientAssertionType != "" || inp.ClientAssertion != ""
+	switch {
+	// only one way of authentication is allowed
+	case assertion && inp.ClientSecret != "":
+		return OAuthClient{}, ErrInvalidClient
+	case client.Public:
+		if inp.ClientSecret != "" || assertion {
+			return OAuthClient{}, ErrInvalidClient
+		}
+		return client, nil
+	case assertion || len(client.JWKS) != 0:
+		if err := s.verifyClientAssertion(client, inp.ClientAssertionType, inp.ClientAssertion); err != nil {
+			return OAuthClient{}, err
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashClientSecret(inp.ClientSecret)), []byte(client.SecretHash)) != 1 {
+		return OAuthClient{}, ErrInvalidClient
+	}
+	return client, nil
+}
+
+func (s *service) exchangeCode(ctx context.Context, client OAuthClient, inp TokenInput) (TokenOutput, error) {
+	// Pop is atomic, so a code works only once
+	value, err := s.cache.Pop(authorizationCodeKey(inp.Code))
+	if errors.Is(err, ErrCacheMiss) {
+		return TokenOutput{}, ErrInvalidGrant
+	}
+	if err != nil {
+		return TokenOutput{}, err
+	}
+	var code authorizationCode
+	if err := json.Unmarshal([]byte(value), &code); err != nil {
+		return TokenOutput{}, ErrInvalidGrant
+	}
+
+	verifier := sha256.Sum256([]byte(inp.CodeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(verifier[:])
+	switch {
+	case code.ClientID != client.ID:
+		return TokenOutput{}, fmt.Errorf("%w: code was issued to another client", ErrInvalidGrant)
+	case code.RedirectURI != client.redirectURI(inp.RedirectURI):
+		return TokenOutput{}, fmt.Errorf("%w: redirect uri does not match", ErrInvalidGrant)
+	case subtle.ConstantTimeCompare([]byte(challenge), []byte(code.Challenge)) != 1:
+		return TokenOutput{}, fmt.Errorf("%w: code verifier does not match", ErrInvalidGrant)
+	}
+
+	u, err := s.repo.Read(ctx, code.UserID)
+	if err != nil {
+		return TokenOutput{}, err
+	}
+	return s.issueGrant(ctx, client, u, code.Scopes, code.Nonce)
+}
+
+// issueGrant makes tokens of client for user, refresh token is made only
+// if user allowed offline access and id token only for openid scope.
+func (s *service) issueGrant(ctx context.Context, client OAuthClient, u User, scopes []string, nonce string) (TokenOutput, error) {
+	var familyID string
+	if contains(scopes, ScopeOfflineAccess) && client.allows(GrantRefreshToken) {
+		var err error
+		if familyID, err = newTokenID(); err != nil {
+			return TokenOutput{}, fmt.Errorf("could not generate family id: %w", err)
+		}
+	}
+	keys, err := s.jwtManager.GenerateGrant(userGrant(client, u, scopes), familyID)
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("could not generate jwt due to: %w", err)
+	}
+	if familyID != "" {
+		if err := s.startSession(ctx, u.ID, familyID, keys); err != nil {
+			return TokenOutput{}, err
+		}
+	}
+
+	out := tokenOutput(keys, scopes)
+	if contains(scopes, ScopeOpenID) {
+		if out.IDToken, err = s.jwtManager.GenerateIDToken(client.ID, nonce, userInfo(u, scopes)); err != nil {
+			return TokenOutput{}, fmt.Errorf("could not generate id token: %w", err)
+		}
+	}
+	return out, nil
+}
+
+func userGrant(client OAuthClient, u User, scopes []string) TokenGrant {
+	grant := TokenGrant{ClientID: client.ID, UserID: u.ID, Scopes: scopes}
+	if contains(scopes, ScopeRoles) {
+		grant.Roles, grant.Permissions, grant.Grants = u.Roles, u.Permissions, u.Grants
+	}
+	return grant
+}
+
+func (s *service) refreshGrant(ctx context.Context, client OAuthClient, inp TokenInput) (TokenOutput, error) {
+	claims, err := s.jwtManager.DecodeRefresh(inp.RefreshToken)
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("%w: %s", ErrInvalidGrant, err)
+	}
+	if claims.ID == "" || claims.FamilyID == "" || claims.ClientID != client.ID {
+		return TokenOutput{}, ErrInvalidGrant
+	}
+	// client may ask for less than it was given but never for more
+	scopes := parseScopes(inp.Scope)
+	if len(scopes) == 0 {
+		scopes = claims.Scopes
+	}
+	if len(missingScopes(scopes, claims.Scopes)) != 0 {
+		return TokenOutput{}, ErrInvalidScope
+	}
+
+	if err := s.checkSession(ctx, claims); err != nil {
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrSessionRevoked) {
+			return TokenOutput{}, fmt.Errorf("%w: %s", ErrInvalidGrant, err)
+		}
+		return TokenOutput{}, err
+	}
+	// revoked consent takes away refresh tokens as well
+	if err := s.checkConsent(ctx, claims.UserID, client, scopes); errors.Is(err, ErrConsentRequired) {
+		return TokenOutput{}, fmt.Errorf("%w: %s", ErrInvalidGrant, err)
+	} else if err != nil {
+		return TokenOutput{}, err
+	}
+	u, err := s.repo.Read(ctx, claims.UserID)
+	if err != nil {
+		return TokenOutput{}, err
+	}
+
+	keys, err := s.jwtManager.GenerateGrant(userGrant(client, u, claims.Scopes), claims.FamilyID)
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("could not generate jwt due to: %w", err)
+	}
+	// access token may be narrowed, but refresh token
+	// has to keep every scope it had
+	if len(scopes) != len(claims.Scopes) {
+		narrowed, err := s.jwtManager.GenerateGrant(userGrant(client, u, scopes), "")
+		if err != nil {
+			return TokenOutput{}, fmt.Errorf("could not generate jwt due to: %w", err)
+		}
+		keys.AccessKey = narrowed.AccessKey
+	}
+	if err := s.rotateRefreshToken(ctx, claims, keys); errors.Is(err, ErrRefreshTokenReused) {
+		return TokenOutput{}, fmt.Errorf("%w: %s", ErrInvalidGrant, err)
+	} else if err != nil {
+		return TokenOutput{}, err
+	}
+	return tokenOutput(keys, scopes), nil
+}
+
+// clientCredentials gives client a token of its own, there is
+// no user and nobody to refresh it for.
+func (s *service) clientCredentials(client OAuthClient, inp TokenInput) (TokenOutput, error) {
+	scopes := parseScopes(inp.Scope)
+	if len(scopes) == 0 {
+		for _, v := range client.Scopes {
+			if !userScopes[v] {
+				scopes = append(scopes, v)
+			}
+		}
+	}
+	for _, v := range scopes {
+		if userScopes[v] || !contains(client.Scopes, v) {
+			return TokenOutput{}, ErrInvalidScope
+		}
+	}
+	keys, err := s.jwtManager.GenerateGrant(TokenGrant{ClientID: client.ID, Scopes: scopes}, "")
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("could not generate jwt due to: %w", err)
+	}
+	return tokenOutput(keys, scopes), nil
+}
+
+func tokenOutput(keys SignInOutput, scopes []string) TokenOutput {
+	return TokenOutput{
+		AccessToken:  keys.AccessKey,
+		TokenType:    TokenTypeBearer,
+		ExpiresIn:    int64(AuthAccessExp / time.Second),
+		RefreshToken: keys.RefreshKey,
+		Scope:        strings.Join(scopes, " "),
+	}
+}
+
+// userInfo returns claims scopes allow, emails and phone
+// numbers of our users are always verified with a code.
+func userInfo(u User, scopes []string) UserInfo {
+	info := UserInfo{Subject: strconv.FormatUint(uint64(u.ID), 10)}
+	if contains(scopes, ScopeProfile) {
+		info.Name = u.FullName
+	}
+	if contains(scopes, ScopeEmail) && u.Email != "" {
+		info.Email = u.Email
+		info.EmailVerified = true
+	}
+	if contains(scopes, ScopePhone) {
+		info.PhoneNumber = u.PhoneNumber
+	}
+	return info
+}
+
+func (s *service) UserInfo(ctx context.Context, accessKey string) (UserInfo, error) {
+	claims, err := s.jwtManager.DecodeAccess(accessKey)
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("userInfo(): %w", err)
+	}
+	// tokens of client credentials have nobody to tell about
+	if claims.UserID == 0 {
+		return UserInfo{}, fmt.Errorf("userInfo(): %w", ErrInvalidToken)
+	}
+	scopes := claims.Scopes
+	if claims.ClientID != "" && !contains(scopes, ScopeOpenID) {
+		return UserInfo{}, fmt.Errorf("userInfo(): %w", ErrNotAllowed)
+	}
+	// our own tokens are not limited by scopes
+	if claims.ClientID == "" {
+		scopes = []string{ScopeProfile, ScopeEmail, ScopePhone}
+	}
+	u, err := s.repo.Read(ctx, claims.UserID)
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("userInfo(): %w", err)
+	}
+	return userInfo(u, scopes), nil
+}
+
+func (s *service) GrantConsent(ctx context.Context, userID ID, clientID string, scopes []string) error {
+	if !callerIs(ctx, userID) {
+		return fmt.Errorf("grantConsent(): %w", ErrNotAllowed)
+	}
+	client, err := s.repo.ReadOAuthClient(ctx, clientID)
+	if err != nil {
+		return fmt.Errorf("grantConsent(): %w", err)
+	}
+	if len(scopes) == 0 || len(missingScopes(scopes, delegableScopes(client.Scopes))) != 0 {
+		return fmt.Errorf("grantConsent(): %w", ErrInvalidScope)
+	}
+	if err := s.repo.SaveConsent(ctx, Consent{
+		UserID:    userID,
+		ClientID:  clientID,
+		Scopes:    scopes,
+		GrantedAt: time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("grantConsent(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) Consents(ctx context.Context, userID ID) ([]Consent, error) {
+	consents, err := s.repo.ReadConsents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("consents(): %w", err)
+	}
+	return consents, nil
+}
+
+func (s *service) RevokeConsent(ctx context.Context, userID ID, clientID string) error {
+	if err := s.repo.DeleteConsent(ctx, userID, clientID); err != nil {
+		return fmt.Errorf("revokeConsent(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) RegisterOAuthClient(ctx context.Context, inp RegisterClientInput) (ClientRegistration, error) {
+	if err := validateClient(inp); err != nil {
+		return ClientRegistration{}, fmt.Errorf("registerOAuthClient(): %w", err)
+	}
+	id, err := newTokenID()
+	if err != nil {
+		return ClientRegistration{}, fmt.Errorf("registerOAuthClient(): could not generate id: %w", err)
+	}
+	reg := ClientRegistration{Client: OAuthClient{
+		ID:           id,
+		Name:         strings.TrimSpace(inp.Name),
+		RedirectURIs: inp.RedirectURIs,
+		GrantTypes:   inp.GrantTypes,
+		Scopes:       inp.Scopes,
+		Public:       inp.Public,
+		Trusted:      inp.Trusted,
+		JWKS:         inp.JWKS,
+		CreatedAt:    time.Now().UTC(),
+	}}
+	if !inp.Public && len(inp.JWKS) == 0 {
+		b := make([]byte, clientSecretLength)
+		if _, err := rand.Read(b); err != nil {
+			return ClientRegistration{}, fmt.Errorf("registerOAuthClient(): could not generate secret: %w", err)
+		}
+		reg.Secret = base64.RawURLEncoding.EncodeToString(b)
+		reg.Client.SecretHash = hashClientSecret(reg.Secret)
+	}
+	if err := s.repo.CreateOAuthClient(ctx, reg.Client); err != nil {
+		return ClientRegistration{}, fmt.Errorf("registerOAuthClient(): %w", err)
+	}
+	return reg, nil
+}
+
+func validateClient(inp RegisterClientInput) error {
+	if strings.TrimSpace(inp.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidClientMetadata)
+	}
+	if len(inp.GrantTypes) == 0 {
+		return fmt.Errorf("%w: at least one grant type is required", ErrInvalidClientMetadata)
+	}
+	for _, v := range inp.GrantTypes {
+		if !grantTypes[v] {
+			return fmt.Errorf("%w: unknown grant type %q", ErrInvalidClientMetadata, v)
+		}
+	}
+	// nobody can tell a public client from someone who pretends to be it
+	if inp.Public && contains(inp.GrantTypes, GrantClientCredentials) {
+		return fmt.Errorf("%w: public clients can not use client credentials", ErrInvalidClientMetadata)
+	}
+	if len(inp.JWKS) != 0 {
+		if inp.Public {
+			return fmt.Errorf("%w: public clients can not have keys", ErrInvalidClientMetadata)
+		}
+		if err := validateJWKS(inp.JWKS); err != nil {
+			return err
+		}
+	}
+	if contains(inp.GrantTypes, GrantAuthorizationCode) && len(inp.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: at least one redirect uri is required", ErrInvalidClientMetadata)
+	}
+	for _, v := range inp.RedirectURIs {
+		if err := validateRedirectURI(v); err != nil {
+			return err
+		}
+	}
+	if len(inp.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidClientMetadata)
+	}
+	for _, v := range inp.Scopes {
+		if v == "" || strings.ContainsAny(v, " \t\n\"\\") {
+			return fmt.Errorf("%w: invalid scope %q", ErrInvalidClientMetadata, v)
+		}
+	}
+	return nil
+}
+
+// validateRedirectURI accepts absolute uris without fragments. Plain http
+// is allowed only for loopback, mobile apps use their own schemes.
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.Fragment != "" || u.Opaque != "" {
+		return fmt.Errorf("%w: invalid redirect uri %q", ErrInvalidClientMetadata, uri)
+	}
+	if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" && u.Hostname() != "::1" {
+		return fmt.Errorf("%w: redirect uri %q has to use https", ErrInvalidClientMetadata, uri)
+	}
+	if (u.Scheme == "http" || u.Scheme == "https") && u.Host == "" {
+		return fmt.Errorf("%w: invalid redirect uri %q", ErrInvalidClientMetadata, uri)
+	}
+	return nil
+}
+
+func (s *service) OAuthClient(ctx context.Context, clientID string) (OAuthClient, error) {
+	client, err := s.repo.ReadOAuthClient(ctx, clientID)
+	if err != nil {
+		return OAuthClient{}, fmt.Errorf("oauthClient(): %w", err)
+	}
+	return client, nil
+}
+
+func (s *service) OAuthClients(ctx context.Context) ([]OAuthClient, error) {
+	clients, err := s.repo.ReadOAuthClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("oauthClients(): %w", err)
+	}
+	return clients, nil
+}
+
+func (s *service) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	if err := s.repo.DeleteOAuthClient(ctx, clientID); err != nil {
+		return fmt.Errorf("deleteOAuthClient(): %w", err)
+	}
+	return nil
+}
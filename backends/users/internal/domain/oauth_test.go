@@ -0,0 +1,686 @@
+package domain_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+const (
+	testVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testRedirectURI = "https://aggregator.com/callback"
+)
+
+func testChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+var (
+	// secret of the aggregator is "secret"
+	aggregator = domain.OAuthClient{
+		ID:           "aggregator",
+		Name:         "Food Aggregator",
+		SecretHash:   "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken, domain.GrantClientCredentials},
+		Scopes:       []string{domain.ScopeOpenID, domain.ScopeEmail, domain.ScopeOfflineAccess, "menu:read"},
+	}
+	adminSPA = domain.OAuthClient{
+		ID:           "admin",
+		Name:         "Admin",
+		RedirectURIs: []string{"https://admin.micro-pizzas.com/callback"},
+		GrantTypes:   []string{domain.GrantAuthorizationCode},
+		Scopes:       []string{domain.ScopeOpenID, domain.ScopeRoles},
+		Public:       true,
+		Trusted:      true,
+	}
+)
+
+// expectOAuthStorage keeps clients and consents in memory the way repository would
+func (d testDeps) expectOAuthStorage(users []domain.User, clients ...domain.OAuthClient) {
+	consents := map[string]domain.Consent{}
+	d.repo.EXPECT().ReadOAuthClient(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string) (domain.OAuthClient, error) {
+			for _, c := range clients {
+				if c.ID == id {
+					return c, nil
+				}
+			}
+			return domain.OAuthClient{}, domain.ErrClientNotFound
+		}).AnyTimes()
+	d.repo.EXPECT().Read(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id domain.ID) (domain.User, error) {
+			for _, u := range users {
+				if u.ID == id {
+					return u, nil
+				}
+			}
+			return domain.User{}, domain.ErrNoUsers
+		}).AnyTimes()
+	d.repo.EXPECT().SaveConsent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, c domain.Consent) error {
+			consents[c.ClientID] = c
+			return nil
+		}).AnyTimes()
+	d.repo.EXPECT().ReadConsent(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ domain.ID, clientID string) (domain.Consent, error) {
+			c, ok := consents[clientID]
+			if !ok {
+				return c, domain.ErrConsentNotFound
+			}
+			return c, nil
+		}).AnyTimes()
+	d.repo.EXPECT().DeleteConsent(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ domain.ID, clientID string) error {
+			if _, ok := consents[clientID]; !ok {
+				return domain.ErrConsentNotFound
+			}
+			delete(consents, clientID)
+			return nil
+		}).AnyTimes()
+}
+
+func authorizeInput(client domain.OAuthClient, scope string) domain.AuthorizeInput {
+	return domain.AuthorizeInput{
+		ResponseType:        domain.ResponseTypeCode,
+		ClientID:            client.ID,
+		RedirectURI:         client.RedirectURIs[0],
+		Scope:               scope,
+		State:               "xyz",
+		Nonce:               "n-0S6_WzA2Mj",
+		CodeChallenge:       testChallenge(testVerifier),
+		CodeChallengeMethod: domain.PKCEMethodS256,
+	}
+}
+
+// signedIn is context of a request with token of the user
+func signedIn(userID domain.ID) context.Context {
+	return domain.WithCaller(context.Background(), domain.AccessClaims{UserID: userID, Roles: []domain.Role{domain.RoleUser}})
+}
+
+// codeFrom returns code from the redirect url and checks that state came back
+func codeFrom(t *testing.T, redirectURL string) string {
+	t.Helper()
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("state") != "xyz" {
+		t.Errorf("state did not come back in %s", redirectURL)
+	}
+	return u.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	user := domain.User{ID: 1, Email: "jane@gmail.com", Roles: []domain.Role{domain.RoleAdmin}}
+	s, deps := newTestService(t)
+	deps.expectOAuthStorage([]domain.User{user}, aggregator, adminSPA)
+	ctx := signedIn(user.ID)
+
+	// user has to allow the aggregator everything first
+	_, err := s.Authorize(ctx, user.ID, authorizeInput(aggregator, "openid email offline_access"))
+	var required *domain.ConsentRequiredError
+	if !errors.As(err, &required) || len(required.Scopes) != 3 || required.Client.Name != aggregator.Name {
+		t.Fatalf("expected consent for 3 scopes, got %v", err)
+	}
+	if err := s.GrantConsent(ctx, user.ID, aggregator.ID, []string{"openid", "email", "offline_access"}); err != nil {
+		t.Fatal(err)
+	}
+	redirectURL, err := s.Authorize(ctx, user.ID, authorizeInput(aggregator, "openid email offline_access"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := codeFrom(t, redirectURL)
+
+	deps.jwt.EXPECT().GenerateGrant(domain.TokenGrant{
+		ClientID: aggregator.ID,
+		UserID:   user.ID,
+		Scopes:   []string{"openid", "email", "offline_access"},
+	}, gomock.Not("")).Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "refresh", RefreshID: "r"}, nil)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	deps.jwt.EXPECT().GenerateIDToken(aggregator.ID, "n-0S6_WzA2Mj", domain.UserInfo{
+		Subject:       "1",
+		Email:         user.Email,
+		EmailVerified: true,
+	}).Return("id", nil)
+
+	inp := domain.TokenInput{
+		GrantType:    domain.GrantAuthorizationCode,
+		ClientID:     aggregator.ID,
+		ClientSecret: "secret",
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testVerifier,
+	}
+	out, err := s.Token(ctx, inp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.TokenOutput{
+		AccessToken:  "access",
+		TokenType:    "Bearer",
+		ExpiresIn:    3600,
+		RefreshToken: "refresh",
+		IDToken:      "id",
+		Scope:        "openid email offline_access",
+	}
+	if out != want {
+		t.Errorf("got %+v, want %+v", out, want)
+	}
+	if _, err := s.Token(ctx, inp); !errors.Is(err, domain.ErrInvalidGrant) {
+		t.Errorf("code worked twice: %v", err)
+	}
+
+	// trusted clients are ours, nobody asks for consent
+	redirectURL, err = s.Authorize(ctx, user.ID, authorizeInput(adminSPA, "openid roles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.jwt.EXPECT().GenerateGrant(domain.TokenGrant{
+		ClientID: adminSPA.ID,
+		UserID:   user.ID,
+		Roles:    user.Roles,
+		Scopes:   []string{"openid", "roles"},
+	}, "").Return(domain.SignInOutput{AccessKey: "access"}, nil)
+	deps.jwt.EXPECT().GenerateIDToken(adminSPA.ID, gomock.Any(), domain.UserInfo{Subject: "1"}).Return("id", nil)
+	if _, err := s.Token(ctx, domain.TokenInput{
+		GrantType:    domain.GrantAuthorizationCode,
+		ClientID:     adminSPA.ID,
+		Code:         codeFrom(t, redirectURL),
+		RedirectURI:  adminSPA.RedirectURIs[0],
+		CodeVerifier: testVerifier,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthorizeErrors(t *testing.T) {
+	user := domain.User{ID: 1}
+
+	testCases := []struct {
+		name   string
+		change func(inp *domain.AuthorizeInput)
+		err    error
+	}{
+		{
+			name:   "unknown client",
+			change: func(inp *domain.AuthorizeInput) { inp.ClientID = "unknown" },
+			err:    domain.ErrInvalidClient,
+		},
+		{
+			name:   "redirect uri that is not registered",
+			change: func(inp *domain.AuthorizeInput) { inp.RedirectURI = "https://attacker.com/callback" },
+			err:    domain.ErrInvalidRedirectURI,
+		},
+		{
+			name:   "implicit flow",
+			change: func(inp *domain.AuthorizeInput) { inp.ResponseType = "token" },
+			err:    domain.ErrUnsupportedResponseType,
+		},
+		{
+			name: "plain code challenge",
+			change: func(inp *domain.AuthorizeInput) {
+				inp.CodeChallenge, inp.CodeChallengeMethod = testVerifier, "plain"
+			},
+			err: domain.ErrPKCERequired,
+		},
+		{
+			name:   "no code challenge",
+			change: func(inp *domain.AuthorizeInput) { inp.CodeChallenge = "" },
+			err:    domain.ErrPKCERequired,
+		},
+		{
+			name:   "scope client may not ask for",
+			change: func(inp *domain.AuthorizeInput) { inp.Scope = "openid roles" },
+			err:    domain.ErrInvalidScope,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage([]domain.User{user}, aggregator)
+			inp := authorizeInput(aggregator, "openid")
+			tc.change(&inp)
+
+			if _, err := s.Authorize(signedIn(user.ID), user.ID, inp); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestOnlyUsersAuthorizeClients(t *testing.T) {
+	user := domain.User{ID: 1}
+
+	testCases := []struct {
+		name   string
+		caller *domain.AccessClaims
+	}{
+		{name: "anonymous"},
+		{name: "somebody else", caller: &domain.AccessClaims{UserID: 2, Roles: []domain.Role{domain.RoleUser}}},
+		{name: "admin", caller: &domain.AccessClaims{UserID: 2, Roles: []domain.Role{domain.RoleOwner}}},
+		{name: "client with token of user", caller: &domain.AccessClaims{UserID: 1, ClientID: aggregator.ID, Scopes: []string{domain.ScopeOpenID}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage([]domain.User{user}, aggregator)
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = domain.WithCaller(ctx, *tc.caller)
+			}
+
+			if err := s.GrantConsent(ctx, user.ID, aggregator.ID, []string{"openid"}); !errors.Is(err, domain.ErrNotAllowed) {
+				t.Errorf("grant consent: expected %v, got %v", domain.ErrNotAllowed, err)
+			}
+			if _, err := s.Authorize(ctx, user.ID, authorizeInput(aggregator, "openid")); !errors.Is(err, domain.ErrNotAllowed) {
+				t.Errorf("authorize: expected %v, got %v", domain.ErrNotAllowed, err)
+			}
+		})
+	}
+}
+
+func TestCodeIsBoundToRedirectURI(t *testing.T) {
+	user := domain.User{ID: 1}
+	s, deps := newTestService(t)
+	deps.expectOAuthStorage([]domain.User{user}, aggregator)
+	ctx := signedIn(user.ID)
+	if err := s.GrantConsent(ctx, user.ID, aggregator.ID, []string{"openid"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the only registered uri is used when client leaves it out
+	inp := authorizeInput(aggregator, "openid")
+	inp.RedirectURI = ""
+	redirectURL, err := s.Authorize(ctx, user.ID, inp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(redirectURL, testRedirectURI+"?") {
+		t.Fatalf("unexpected redirect %s", redirectURL)
+	}
+
+	deps.jwt.EXPECT().GenerateGrant(gomock.Any(), gomock.Any()).Return(domain.SignInOutput{AccessKey: "access"}, nil)
+	deps.jwt.EXPECT().GenerateIDToken(gomock.Any(), gomock.Any(), gomock.Any()).Return("id", nil)
+	if _, err := s.Token(ctx, domain.TokenInput{
+		GrantType:    domain.GrantAuthorizationCode,
+		ClientID:     aggregator.ID,
+		ClientSecret: "secret",
+		Code:         codeFrom(t, redirectURL),
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testVerifier,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExchangeCodeErrors(t *testing.T) {
+	user := domain.User{ID: 1}
+	other := aggregator
+	other.ID = "other"
+
+	testCases := []struct {
+		name   string
+		change func(inp *domain.TokenInput)
+		err    error
+	}{
+		{
+			name:   "wrong verifier",
+			change: func(inp *domain.TokenInput) { inp.CodeVerifier = "wrong" },
+			err:    domain.ErrInvalidGrant,
+		},
+		{
+			name:   "another redirect uri",
+			change: func(inp *domain.TokenInput) { inp.RedirectURI = "https://aggregator.com/other" },
+			err:    domain.ErrInvalidGrant,
+		},
+		{
+			name:   "code of another client",
+			change: func(inp *domain.TokenInput) { inp.ClientID = other.ID },
+			err:    domain.ErrInvalidGrant,
+		},
+		{
+			name:   "wrong secret",
+			change: func(inp *domain.TokenInput) { inp.ClientSecret = "wrong" },
+			err:    domain.ErrInvalidClient,
+		},
+		{
+			name:   "unknown grant type",
+			change: func(inp *domain.TokenInput) { inp.GrantType = "password" },
+			err:    domain.ErrUnsupportedGrantType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage([]domain.User{user}, aggregator, other)
+			if err := s.GrantConsent(signedIn(user.ID), user.ID, aggregator.ID, []string{"openid"}); err != nil {
+				t.Fatal(err)
+			}
+			redirectURL, err := s.Authorize(signedIn(user.ID), user.ID, authorizeInput(aggregator, "openid"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			inp := domain.TokenInput{
+				GrantType:    domain.GrantAuthorizationCode,
+				ClientID:     aggregator.ID,
+				ClientSecret: "secret",
+				Code:         codeFrom(t, redirectURL),
+				RedirectURI:  testRedirectURI,
+				CodeVerifier: testVerifier,
+			}
+			tc.change(&inp)
+
+			if _, err := s.Token(context.Background(), inp); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestOAuthRefresh(t *testing.T) {
+	user := domain.User{ID: 1}
+	claims := domain.RefreshClaims{
+		ID:       "a",
+		FamilyID: "family",
+		UserID:   1,
+		ClientID: aggregator.ID,
+		Scopes:   []string{"openid", "offline_access"},
+	}
+	refresh := domain.TokenInput{
+		GrantType:    domain.GrantRefreshToken,
+		ClientID:     aggregator.ID,
+		ClientSecret: "secret",
+		RefreshToken: "refresh",
+	}
+
+	testCases := []struct {
+		name    string
+		consent []string
+		claims  domain.RefreshClaims
+		scope   string
+		prepare func(deps testDeps)
+		err     error
+	}{
+		{
+			name:    "rotate refresh token",
+			consent: claims.Scopes,
+			claims:  claims,
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(domain.Session{ID: "family", UserID: 1}, nil)
+				deps.jwt.EXPECT().GenerateGrant(gomock.Any(), "family").Return(domain.SignInOutput{RefreshID: "b"}, nil)
+				deps.repo.EXPECT().RotateRefreshToken(gomock.Any(), "a", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "narrow scope of access token",
+			consent: claims.Scopes,
+			claims:  claims,
+			scope:   "openid",
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(domain.Session{ID: "family", UserID: 1}, nil)
+				deps.jwt.EXPECT().GenerateGrant(domain.TokenGrant{ClientID: aggregator.ID, UserID: 1, Scopes: claims.Scopes}, "family").
+					Return(domain.SignInOutput{AccessKey: "wide", RefreshID: "b"}, nil)
+				deps.jwt.EXPECT().GenerateGrant(domain.TokenGrant{ClientID: aggregator.ID, UserID: 1, Scopes: []string{"openid"}}, "").
+					Return(domain.SignInOutput{AccessKey: "narrow"}, nil)
+				deps.repo.EXPECT().RotateRefreshToken(gomock.Any(), "a", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "fail with wider scope",
+			consent: claims.Scopes,
+			claims:  claims,
+			scope:   "openid email",
+			err:     domain.ErrInvalidScope,
+		},
+		{
+			name:    "fail after consent was revoked",
+			consent: nil,
+			claims:  claims,
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(domain.Session{ID: "family", UserID: 1}, nil)
+			},
+			err: domain.ErrInvalidGrant,
+		},
+		{
+			name:    "fail with token of another client",
+			consent: claims.Scopes,
+			claims: domain.RefreshClaims{
+				ID: "a", FamilyID: "family", UserID: 1, ClientID: "other", Scopes: claims.Scopes,
+			},
+			err: domain.ErrInvalidGrant,
+		},
+		{
+			name:    "fail with our own refresh token",
+			consent: claims.Scopes,
+			claims:  domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1},
+			err:     domain.ErrInvalidGrant,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage([]domain.User{user}, aggregator)
+			if tc.consent != nil {
+				if err := s.GrantConsent(signedIn(user.ID), user.ID, aggregator.ID, tc.consent); err != nil {
+					t.Fatal(err)
+				}
+			}
+			deps.jwt.EXPECT().DecodeRefresh("refresh").Return(tc.claims, nil)
+			if tc.prepare != nil {
+				tc.prepare(deps)
+			}
+			inp := refresh
+			inp.Scope = tc.scope
+
+			out, err := s.Token(context.Background(), inp)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if tc.scope == "openid" && out.AccessToken != "narrow" {
+				t.Errorf("access token was not narrowed")
+			}
+		})
+	}
+
+	t.Run("our refresh does not take tokens of clients", func(t *testing.T) {
+		s, deps := newTestService(t)
+		deps.jwt.EXPECT().DecodeRefresh("refresh").Return(claims, nil)
+		if _, err := s.Refresh(context.Background(), "refresh"); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("expected %v, got %v", domain.ErrInvalidToken, err)
+		}
+	})
+}
+
+func TestClientCredentials(t *testing.T) {
+	testCases := []struct {
+		name   string
+		client domain.OAuthClient
+		secret string
+		scope  string
+		want   []string
+		err    error
+	}{
+		{
+			name:   "default scopes leave out user ones",
+			client: aggregator,
+			secret: "secret",
+			want:   []string{"menu:read"},
+		},
+		{
+			name:   "fail with user scope",
+			client: aggregator,
+			secret: "secret",
+			scope:  "menu:read email",
+			err:    domain.ErrInvalidScope,
+		},
+		{
+			name:   "fail for client without the grant",
+			client: adminSPA,
+			err:    domain.ErrUnauthorizedClient,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage(nil, tc.client)
+			if tc.err == nil {
+				deps.jwt.EXPECT().GenerateGrant(domain.TokenGrant{ClientID: tc.client.ID, Scopes: tc.want}, "").
+					Return(domain.SignInOutput{AccessKey: "access"}, nil)
+			}
+
+			out, err := s.Token(context.Background(), domain.TokenInput{
+				GrantType:    domain.GrantClientCredentials,
+				ClientID:     tc.client.ID,
+				ClientSecret: tc.secret,
+				Scope:        tc.scope,
+			})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && (out.RefreshToken != "" || out.AccessToken != "access") {
+				t.Errorf("got %+v", out)
+			}
+		})
+	}
+}
+
+func TestUserInfo(t *testing.T) {
+	user := domain.User{ID: 1, FullName: "Jane Doe", Email: "jane@gmail.com", PhoneNumber: "+996555000000"}
+
+	testCases := []struct {
+		name   string
+		claims domain.AccessClaims
+		want   domain.UserInfo
+		err    error
+	}{
+		{
+			name:   "only claims of granted scopes",
+			claims: domain.AccessClaims{UserID: 1, ClientID: "aggregator", Scopes: []string{"openid", "email"}},
+			want:   domain.UserInfo{Subject: "1", Email: user.Email, EmailVerified: true},
+		},
+		{
+			name:   "our own token sees everything",
+			claims: domain.AccessClaims{UserID: 1},
+			want:   domain.UserInfo{Subject: "1", Name: user.FullName, Email: user.Email, EmailVerified: true, PhoneNumber: user.PhoneNumber},
+		},
+		{
+			name:   "fail without openid scope",
+			claims: domain.AccessClaims{UserID: 1, ClientID: "aggregator", Scopes: []string{"menu:read"}},
+			err:    domain.ErrNotAllowed,
+		},
+		{
+			name:   "fail with token of client itself",
+			claims: domain.AccessClaims{ClientID: "aggregator", Scopes: []string{"openid"}},
+			err:    domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectOAuthStorage([]domain.User{user})
+			deps.jwt.EXPECT().DecodeAccess("access").Return(tc.claims, nil)
+
+			info, err := s.UserInfo(context.Background(), "access")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if info != tc.want {
+				t.Errorf("got %+v, want %+v", info, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegisterOAuthClient(t *testing.T) {
+	valid := domain.RegisterClientInput{
+		Name:         "Food Aggregator",
+		RedirectURIs: []string{testRedirectURI, "http://localhost:3000/callback", "com.aggregator.app:/callback"},
+		GrantTypes:   []string{domain.GrantAuthorizationCode, domain.GrantClientCredentials},
+		Scopes:       []string{"openid", "menu:read"},
+	}
+
+	testCases := []struct {
+		name   string
+		change func(inp *domain.RegisterClientInput)
+		err    error
+	}{
+		{
+			name:   "confidential client",
+			change: func(inp *domain.RegisterClientInput) {},
+		},
+		{
+			name:   "plain http redirect uri",
+			change: func(inp *domain.RegisterClientInput) { inp.RedirectURIs = []string{"http://aggregator.com/callback"} },
+			err:    domain.ErrInvalidClientMetadata,
+		},
+		{
+			name:   "redirect uri with fragment",
+			change: func(inp *domain.RegisterClientInput) { inp.RedirectURIs = []string{testRedirectURI + "#token"} },
+			err:    domain.ErrInvalidClientMetadata,
+		},
+		{
+			name:   "public client with client credentials",
+			change: func(inp *domain.RegisterClientInput) { inp.Public = true },
+			err:    domain.ErrInvalidClientMetadata,
+		},
+		{
+			name:   "unknown grant type",
+			change: func(inp *domain.RegisterClientInput) { inp.GrantTypes = []string{"password"} },
+			err:    domain.ErrInvalidClientMetadata,
+		},
+		{
+			name:   "code flow without redirect uris",
+			change: func(inp *domain.RegisterClientInput) { inp.RedirectURIs = nil },
+			err:    domain.ErrInvalidClientMetadata,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			inp := valid
+			tc.change(&inp)
+			var stored domain.OAuthClient
+			if tc.err == nil {
+				deps.repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, c domain.OAuthClient) error {
+						stored = c
+						return nil
+					})
+			}
+
+			reg, err := s.RegisterOAuthClient(context.Background(), inp)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			// the secret has to work at the token endpoint
+			deps.expectOAuthStorage(nil, stored)
+			deps.jwt.EXPECT().GenerateGrant(gomock.Any(), "").Return(domain.SignInOutput{}, nil)
+			if _, err := s.Token(context.Background(), domain.TokenInput{
+				GrantType:    domain.GrantClientCredentials,
+				ClientID:     reg.Client.ID,
+				ClientSecret: reg.Secret,
+			}); err != nil {
+				t.Errorf("secret of registration does not work: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sign in with an OpenID Connect provider is the authorization code flow
+// with PKCE. State, nonce and PKCE verifier of every attempt are kept in
+// cache under a hash of state and are used only once. Nonce makes sure
+// the id token was issued for this very attempt.
+
+type oidcPurpose string
+
+const (
+	oidcSignIn oidcPurpose = "signin"
+	oidcLink   oidcPurpose = "link"
+
+	pkceVerifierLength = 32
+)
+
+// oidcState is what we remember about an attempt between Begin and Finish
+type oidcState struct {
+	Provider string `json:"provider"`
+	UserID   ID     `json:"userID,omitempty"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+func oidcStateKey(purpose oidcPurpose, state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return "oidc:" + string(purpose) + ":" + hex.EncodeToString(sum[:])
+}
+
+func (s *service) oidcProvider(name string) (OIDCProvider, error) {
+	p, ok := s.oidcProviders[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+func (s *service) beginOIDC(purpose oidcPurpose, provider OIDCProvider, userID ID) (OIDCAuthorization, error) {
+	state, err := newTokenID()
+	if err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("could not generate state: %w", err)
+	}
+	nonce, err := newTokenID()
+	if err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("could not generate nonce: %w", err)
+	}
+	b := make([]byte, pkceVerifierLength)
+	if _, err := rand.Read(b); err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("could not generate verifier: %w", err)
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+
+	value, err := json.Marshal(oidcState{
+		Provider: provider.Name(),
+		UserID:   userID,
+		Nonce:    nonce,
+		Verifier: verifier,
+	})
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+	if err := s.cache.Store(oidcStateKey(purpose, state), string(value), OIDCStateExp); err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("could not cache state: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return OIDCAuthorization{
+		URL:   provider.AuthCodeURL(state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:])),
+		State: state,
+	}, nil
+}
+
+// finishOIDC consumes the state and returns verified claims of the user
+func (s *service) finishOIDC(ctx context.Context, purpose oidcPurpose, inp FinishOIDCInput) (oidcState, IDTokenClaims, error) {
+	value, err := s.cache.Pop(oidcStateKey(purpose, inp.State))
+	if errors.Is(err, ErrCacheMiss) {
+		return oidcState{}, IDTokenClaims{}, ErrInvalidState
+	}
+	if err != nil {
+		return oidcState{}, IDTokenClaims{}, err
+	}
+	var st oidcState
+	if err := json.Unmarshal([]byte(value), &st); err != nil {
+		return oidcState{}, IDTokenClaims{}, ErrInvalidState
+	}
+	provider, err := s.oidcProvider(st.Provider)
+	if err != nil {
+		return oidcState{}, IDTokenClaims{}, err
+	}
+
+	claims, err := provider.Exchange(ctx, inp.Code, st.Verifier)
+	if err != nil {
+		return oidcState{}, IDTokenClaims{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(st.Nonce)) != 1 {
+		return oidcState{}, IDTokenClaims{}, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return oidcState{}, IDTokenClaims{}, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	return st, claims, nil
+}
+
+func (s *service) BeginOIDCSignIn(ctx context.Context, provider string) (OIDCAuthorization, error) {
+	p, err := s.oidcProvider(provider)
+	if err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCSignIn(): %w", err)
+	}
+	auth, err := s.beginOIDC(oidcSignIn, p, 0)
+	if err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCSignIn(): %w", err)
+	}
+	return auth, nil
+}
+
+func (s *service) FinishOIDCSignIn(ctx context.Context, inp FinishOIDCInput) (SignInOutput, error) {
+	st, claims, err := s.finishOIDC(ctx, oidcSignIn, inp)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("finishOIDCSignIn(): %w", err)
+	}
+
+	var u User
+	identity, err := s.repo.ReadIdentity(ctx, st.Provider, claims.Subject)
+	switch {
+	case err == nil:
+		if u, err = s.repo.Read(ctx, identity.UserID); err != nil {
+			return SignInOutput{}, fmt.Errorf("finishOIDCSignIn(): could not read from db %w", err)
+		}
+	case errors.Is(err, ErrIdentityNotFound):
+		if u, err = s.createFederatedUser(ctx, st.Provider, claims); err != nil {
+			return SignInOutput{}, fmt.Errorf("finishOIDCSignIn(): %w", err)
+		}
+	default:
+		return SignInOutput{}, fmt.Errorf("finishOIDCSignIn(): %w", err)
+	}
+
+	out, err := s.completeSignIn(ctx, u)
+	if err != nil {
+		return out, fmt.Errorf("finishOIDCSignIn(): %w", err)
+	}
+	return out, nil
+}
+
+// createFederatedUser signs up the owner of identity we have never seen.
+// Email is taken only if provider verified it, and if somebody already
+// uses it we do not link identity on our own: a provider that is sloppy
+// with verification would give away the account.
+func (s *service) createFederatedUser(ctx context.Context, provider string, claims IDTokenClaims) (User, error) {
+	u := User{
+		FullName:  claims.Name,
+		Roles:     []Role{RoleUser},
+		CreatedAt: time.Now().UTC(),
+	}
+	if claims.EmailVerified && claims.Email != "" {
+		_, err := s.repo.ReadByEmail(ctx, claims.Email)
+		if err == nil {
+			return User{}, ErrIdentityNotLinked
+		}
+		if !errors.Is(err, ErrNoUsers) {
+			return User{}, err
+		}
+		u.Email = claims.Email
+	}
+
+	// when two callbacks race the loser gets ErrIdentityExists
+	// and no user is left behind
+	var err error
+	if u.ID, err = s.repo.CreateWithIdentity(ctx, u, Identity{
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: u.CreatedAt,
+	}); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func (s *service) BeginOIDCLink(ctx context.Context, userID ID, provider string) (OIDCAuthorization, error) {
+	p, err := s.oidcProvider(provider)
+	if err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCLink(): %w", err)
+	}
+	if _, err := s.repo.Read(ctx, userID); err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCLink(): %w", err)
+	}
+	auth, err := s.beginOIDC(oidcLink, p, userID)
+	if err != nil {
+		return OIDCAuthorization{}, fmt.Errorf("beginOIDCLink(): %w", err)
+	}
+	return auth, nil
+}
+
+func (s *service) FinishOIDCLink(ctx context.Context, userID ID, inp FinishOIDCInput) (Identity, error) {
+	st, claims, err := s.finishOIDC(ctx, oidcLink, inp)
+	if err != nil {
+		return Identity{}, fmt.Errorf("finishOIDCLink(): %w", err)
+	}
+	// state of one user must not link identity to another one
+	if st.UserID != userID {
+		return Identity{}, fmt.Errorf("finishOIDCLink(): %w", ErrInvalidState)
+	}
+	identity := Identity{
+		Provider:  st.Provider,
+		Subject:   claims.Subject,
+		UserID:    userID,
+		Email:     claims.Email,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreateIdentity(ctx, identity); err != nil {
+		return Identity{}, fmt.Errorf("finishOIDCLink(): %w", err)
+	}
+	return identity, nil
+}
+
+func (s *service) Identities(ctx context.Context, userID ID) ([]Identity, error) {
+	identities, err := s.repo.ReadIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("identities(): %w", err)
+	}
+	return identities, nil
+}
+
+func (s *service) UnlinkIdentity(ctx context.Context, userID ID, provider string) error {
+	if err := s.repo.DeleteIdentity(ctx, userID, provider); err != nil {
+		return fmt.Errorf("unlinkIdentity(): %w", err)
+	}
+	return nil
+}
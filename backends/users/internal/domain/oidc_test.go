@@ -0,0 +1,262 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/oidc"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/oidc/oidctest"
+)
+
+func newOIDCService(t *testing.T) (domain.Service, testDeps, *oidctest.Provider) {
+	t.Helper()
+	mock := oidctest.NewProvider("micro-pizzas", "secret")
+	t.Cleanup(mock.Close)
+	p, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Name:         "mock",
+		Issuer:       mock.Issuer(),
+		ClientID:     "micro-pizzas",
+		ClientSecret: "secret",
+		RedirectURL:  "https://micro-pizzas.com/oidc/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, deps := newTestService(t, domain.WithOIDCProviders(p))
+	return s, deps, mock
+}
+
+// expectIdentityStorage keeps users and identities in memory the way repository would
+func (d testDeps) expectIdentityStorage(users ...domain.User) {
+	var (
+		byID       = map[domain.ID]domain.User{}
+		identities = map[string]domain.Identity{}
+	)
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	d.repo.EXPECT().Read(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id domain.ID) (domain.User, error) {
+			u, ok := byID[id]
+			if !ok {
+				return u, domain.ErrNoUsers
+			}
+			return u, nil
+		}).AnyTimes()
+	d.repo.EXPECT().ReadByEmail(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, email string) (domain.User, error) {
+			for _, u := range byID {
+				if u.Email == email {
+					return u, nil
+				}
+			}
+			return domain.User{}, domain.ErrNoUsers
+		}).AnyTimes()
+	createIdentity := func(i domain.Identity) error {
+		if _, ok := identities[i.Provider+":"+i.Subject]; ok {
+			return domain.ErrIdentityExists
+		}
+		identities[i.Provider+":"+i.Subject] = i
+		return nil
+	}
+	d.repo.EXPECT().CreateWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, u domain.User, i domain.Identity) (domain.ID, error) {
+			u.ID = domain.ID(len(byID) + 100)
+			i.UserID = u.ID
+			if err := createIdentity(i); err != nil {
+				return 0, err
+			}
+			byID[u.ID] = u
+			return u.ID, nil
+		}).AnyTimes()
+	d.repo.EXPECT().CreateIdentity(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, i domain.Identity) error {
+			return createIdentity(i)
+		}).AnyTimes()
+	d.repo.EXPECT().ReadIdentity(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, provider, subject string) (domain.Identity, error) {
+			i, ok := identities[provider+":"+subject]
+			if !ok {
+				return i, domain.ErrIdentityNotFound
+			}
+			return i, nil
+		}).AnyTimes()
+	d.expectNoTOTP()
+	d.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+}
+
+// signInWithOIDC goes through the provider and returns what Finish returned
+func signInWithOIDC(t *testing.T, s domain.Service, mock *oidctest.Provider) (domain.SignInOutput, error) {
+	t.Helper()
+	auth, err := s.BeginOIDCSignIn(context.Background(), "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := mock.Authorize(auth.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != auth.State {
+		t.Fatalf("provider sent back state %q, want %q", state, auth.State)
+	}
+	return s.FinishOIDCSignIn(context.Background(), domain.FinishOIDCInput{State: state, Code: code})
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	existing := domain.User{ID: 1, FullName: "Jane Doe", Email: "jane@gmail.com", Roles: []domain.Role{domain.RoleUser}}
+
+	testCases := []struct {
+		name   string
+		user   oidctest.User
+		claims func(jwt.MapClaims)
+		// id of the user who is expected to get tokens
+		want    domain.ID
+		wantErr error
+	}{
+		{
+			name: "first sign in creates user",
+			user: oidctest.User{Subject: "john", Email: "john@gmail.com", EmailVerified: true, Name: "John Doe"},
+			want: 101,
+		},
+		{
+			name:    "verified email of existing user",
+			user:    oidctest.User{Subject: "jane", Email: existing.Email, EmailVerified: true},
+			wantErr: domain.ErrIdentityNotLinked,
+		},
+		{
+			// anyone could claim the email, so it is not used at all
+			name: "unverified email of existing user",
+			user: oidctest.User{Subject: "jane", Email: existing.Email},
+			want: 101,
+		},
+		{
+			name:    "id token for another sign in",
+			user:    oidctest.User{Subject: "john"},
+			claims:  func(c jwt.MapClaims) { c["nonce"] = "stolen" },
+			wantErr: domain.ErrInvalidIDToken,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps, mock := newOIDCService(t)
+			deps.expectIdentityStorage(existing)
+			mock.SignInAs(tc.user)
+			mock.Claims = tc.claims
+
+			if tc.wantErr == nil {
+				deps.jwt.EXPECT().Generate(tc.want, []domain.Role{domain.RoleUser}, nil, nil, gomock.Any()).
+					Return(domain.SignInOutput{AccessKey: "access"}, nil).Times(2)
+			}
+			out, err := signInWithOIDC(t, s, mock)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if out.AccessKey != "access" {
+				t.Fatalf("expected tokens, got %+v", out)
+			}
+			// the second time the same user signs in, no new user is made
+			if _, err := signInWithOIDC(t, s, mock); err != nil {
+				t.Fatal(err)
+			}
+			u, err := s.Read(context.Background(), tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Email == existing.Email {
+				t.Errorf("new user took email of existing one")
+			}
+		})
+	}
+}
+
+func TestOIDCSignInRace(t *testing.T) {
+	s, deps, mock := newOIDCService(t)
+	mock.SignInAs(oidctest.User{Subject: "john", Name: "John Doe"})
+
+	// another callback links the identity after we have looked it up,
+	// nothing may be created by this one then
+	deps.repo.EXPECT().ReadIdentity(gomock.Any(), "mock", "john").
+		Return(domain.Identity{}, domain.ErrIdentityNotFound)
+	deps.repo.EXPECT().CreateWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(domain.ID(0), domain.ErrIdentityExists)
+
+	if _, err := signInWithOIDC(t, s, mock); !errors.Is(err, domain.ErrIdentityExists) {
+		t.Fatalf("expected %v, got %v", domain.ErrIdentityExists, err)
+	}
+}
+
+func TestOIDCLink(t *testing.T) {
+	existing := domain.User{ID: 1, FullName: "Jane Doe", Email: "jane@gmail.com", Roles: []domain.Role{domain.RoleUser}}
+	ctx := context.Background()
+	s, deps, mock := newOIDCService(t)
+	deps.expectIdentityStorage(existing)
+	mock.SignInAs(oidctest.User{Subject: "jane", Email: existing.Email, EmailVerified: true})
+
+	auth, err := s.BeginOIDCLink(ctx, existing.ID, "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := mock.Authorize(auth.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// state is bound to the user who started linking
+	if _, err := s.FinishOIDCLink(ctx, 2, domain.FinishOIDCInput{State: state, Code: code}); !errors.Is(err, domain.ErrInvalidState) {
+		t.Fatalf("expected %v, got %v", domain.ErrInvalidState, err)
+	}
+
+	auth, err = s.BeginOIDCLink(ctx, existing.ID, "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err = mock.Authorize(auth.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity, err := s.FinishOIDCLink(ctx, existing.ID, domain.FinishOIDCInput{State: state, Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.UserID != existing.ID || identity.Subject != "jane" {
+		t.Fatalf("got identity %+v", identity)
+	}
+
+	deps.jwt.EXPECT().Generate(existing.ID, existing.Roles, existing.Permissions, existing.Grants, gomock.Any()).Return(domain.SignInOutput{AccessKey: "access"}, nil)
+	if _, err := signInWithOIDC(t, s, mock); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOIDCErrors(t *testing.T) {
+	ctx := context.Background()
+	s, deps, mock := newOIDCService(t)
+	deps.expectIdentityStorage()
+
+	if _, err := s.BeginOIDCSignIn(ctx, "unknown"); !errors.Is(err, domain.ErrUnknownProvider) {
+		t.Fatalf("expected %v, got %v", domain.ErrUnknownProvider, err)
+	}
+
+	mock.SignInAs(oidctest.User{Subject: "john"})
+	auth, err := s.BeginOIDCSignIn(ctx, "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := mock.Authorize(auth.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FinishOIDCSignIn(ctx, domain.FinishOIDCInput{State: state, Code: "wrong"}); !errors.Is(err, domain.ErrInvalidCode) {
+		t.Fatalf("expected %v, got %v", domain.ErrInvalidCode, err)
+	}
+	// state is spent even by a failed attempt
+	if _, err := s.FinishOIDCSignIn(ctx, domain.FinishOIDCInput{State: state, Code: code}); !errors.Is(err, domain.ErrInvalidState) {
+		t.Fatalf("expected %v, got %v", domain.ErrInvalidState, err)
+	}
+}
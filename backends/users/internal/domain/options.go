@@ -0,0 +1,66 @@
+package domain
+
+// Option configures optional parts of the service.
+type Option func(*service)
+
+// WithRateLimiter makes service check rl before sending any code.
+// Without it codes are sent as often as they are requested.
+func WithRateLimiter(rl *RateLimiter) Option {
+	return func(s *service) {
+		s.rateLimiter = rl
+	}
+}
+
+// WithPasswordParams changes argon2id parameters for new hashes,
+// hashes made with other parameters are replaced on sign in.
+func WithPasswordParams(p PasswordParams) Option {
+	return func(s *service) {
+		s.passwords = passwordHasher{params: p}
+	}
+}
+
+// WithPasswordPolicy replaces DefaultPasswordRules,
+// use it to add BreachedRule or custom rules.
+func WithPasswordPolicy(p *PasswordPolicy) Option {
+	return func(s *service) {
+		s.passwordPolicy = p
+	}
+}
+
+// WithLockout replaces DefaultLockoutConfig, zero
+// thresholds turn off lockouts of accounts or ips.
+func WithLockout(cfg LockoutConfig) Option {
+	return func(s *service) {
+		s.lockout = cfg
+	}
+}
+
+// WithRequiredSecondFactor makes users with any of roles set up TOTP
+// before they can sign in, usually RoleOwner and RoleAdmin.
+func WithRequiredSecondFactor(roles ...Role) Option {
+	return func(s *service) {
+		s.secondFactorRoles = map[Role]bool{}
+		for _, r := range roles {
+			s.secondFactorRoles[r] = true
+		}
+	}
+}
+
+// WithOIDCProviders lets users sign in with accounts at providers,
+// providers are picked by their names.
+func WithOIDCProviders(providers ...OIDCProvider) Option {
+	return func(s *service) {
+		s.oidcProviders = map[string]OIDCProvider{}
+		for _, p := range providers {
+			s.oidcProviders[p.Name()] = p
+		}
+	}
+}
+
+// WithWebAuthn turns on passkeys, without it passkey
+// methods return ErrPasskeysDisabled.
+func WithWebAuthn(w WebAuthn) Option {
+	return func(s *service) {
+		s.webauthn = w
+	}
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// One time codes we send through sms and email.
+// Only hashes of codes are kept in cache, codes expire after CodeExp
+// and a code can be used only once. Wrong guesses are counted
+// per destination, see CodeMaxAttempts.
+
+type otpPurpose string
+
+const (
+	otpSignUp otpPurpose = "signup"
+	otpSignIn otpPurpose = "signin"
+)
+
+// generateCode returns a numeric code of given length.
+// Every code from 000000 to 999999 has the same chance.
+func generateCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+func otpKey(purpose otpPurpose, destination string) string {
+	return "otp:" + string(purpose) + ":" + destination
+}
+
+func otpAttemptsKey(destination string) string {
+	return "otp:attempts:" + destination
+}
+
+func otpLockKey(destination string) string {
+	return "otp:lock:" + destination
+}
+
+func otpLockoutsKey(destination string) string {
+	return "otp:lockouts:" + destination
+}
+
+// lockoutDuration doubles with every lockout
+func lockoutDuration(lockouts int64) time.Duration {
+	d := CodeLockoutBase
+	for i := int64(1); i < lockouts && d < CodeLockoutMax; i++ {
+		d *= 2
+	}
+	if d > CodeLockoutMax {
+		return CodeLockoutMax
+	}
+	return d
+}
+
+func hashCode(destination, code string) string {
+	sum := sha256.Sum256([]byte(destination + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueCode creates a new code for destination, the previous one
+// if there was any stops working.
+func (s *service) issueCode(purpose otpPurpose, destination string) (string, error) {
+	if err := s.checkCodeLock(destination); err != nil {
+		return "", err
+	}
+	code, err := generateCode(CodeLength)
+	if err != nil {
+		return "", fmt.Errorf("could not generate code: %w", err)
+	}
+	if err := s.cache.Store(otpKey(purpose, destination), hashCode(destination, code), CodeExp); err != nil {
+		return "", fmt.Errorf("could not cache code: %w", err)
+	}
+	return code, nil
+}
+
+// consumeCode checks the code and deletes it if it is correct.
+// Wrong codes are left in place so user can try again
+// until CodeMaxAttempts is reached.
+func (s *service) consumeCode(purpose otpPurpose, destination, code string) error {
+	if err := s.checkCodeLock(destination); err != nil {
+		return err
+	}
+
+	key := otpKey(purpose, destination)
+	stored, err := s.cache.Get(key)
+	if errors.Is(err, ErrCacheMiss) {
+		return s.failCodeAttempt(purpose, destination)
+	}
+	if err != nil {
+		return fmt.Errorf("could not read code from cache: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashCode(destination, code))) != 1 {
+		return s.failCodeAttempt(purpose, destination)
+	}
+
+	// Pop is atomic so if the same code is sent twice at
+	// the same time only one of requests gets it
+	popped, err := s.cache.Pop(key)
+	if errors.Is(err, ErrCacheMiss) {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return fmt.Errorf("could not consume code: %w", err)
+	}
+	if popped != stored {
+		return ErrInvalidCode
+	}
+	if err := s.cache.Delete(otpAttemptsKey(destination)); err != nil {
+		return fmt.Errorf("could not reset attempts: %w", err)
+	}
+	return nil
+}
+
+func (s *service) checkCodeLock(destination string) error {
+	_, err := s.cache.Get(otpLockKey(destination))
+	if errors.Is(err, ErrCacheMiss) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not check lock: %w", err)
+	}
+	return ErrTooManyAttempts
+}
+
+// failCodeAttempt counts a wrong guess. When there are too many of them
+// the code is thrown away and destination gets locked for a while.
+func (s *service) failCodeAttempt(purpose otpPurpose, destination string) error {
+	attempts, err := s.cache.Incr(otpAttemptsKey(destination), CodeExp)
+	if err != nil {
+		return fmt.Errorf("could not count attempt: %w", err)
+	}
+	if attempts < CodeMaxAttempts {
+		return ErrInvalidCode
+	}
+
+	if err := s.cache.Delete(otpKey(purpose, destination)); err != nil {
+		return fmt.Errorf("could not invalidate code: %w", err)
+	}
+	if err := s.cache.Delete(otpAttemptsKey(destination)); err != nil {
+		return fmt.Errorf("could not reset attempts: %w", err)
+	}
+	lockouts, err := s.cache.Incr(otpLockoutsKey(destination), CodeLockoutMemory)
+	if err != nil {
+		return fmt.Errorf("could not count lockout: %w", err)
+	}
+	if err := s.cache.Store(otpLockKey(destination), "1", lockoutDuration(lockouts)); err != nil {
+		return fmt.Errorf("could not lock: %w", err)
+	}
+	return ErrTooManyAttempts
+}
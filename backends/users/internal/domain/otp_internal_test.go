@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGenerateCode(t *testing.T) {
+	format := regexp.MustCompile(`^\d{6}$`)
+	for i := 0; i < 1000; i++ {
+		code, err := generateCode(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("got %q, want 6 digits", code)
+		}
+	}
+
+	// every digit should show up about the same number of times
+	const samples = 100000
+	counts := make(map[string]int)
+	for i := 0; i < samples; i++ {
+		code, err := generateCode(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[code]++
+	}
+	if len(counts) != 10 {
+		t.Fatalf("got %d distinct digits, want 10", len(counts))
+	}
+	for digit, n := range counts {
+		if n < samples/10*9/10 || n > samples/10*11/10 {
+			t.Errorf("digit %s appeared %d times out of %d", digit, n, samples)
+		}
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	testCases := []struct {
+		lockouts int64
+		want     time.Duration
+	}{
+		{lockouts: 1, want: CodeLockoutBase},
+		{lockouts: 2, want: CodeLockoutBase * 2},
+		{lockouts: 3, want: CodeLockoutBase * 4},
+		{lockouts: 100, want: CodeLockoutMax},
+	}
+	for _, tc := range testCases {
+		if got := lockoutDuration(tc.lockouts); got != tc.want {
+			t.Errorf("lockout %d: got %v, want %v", tc.lockouts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestRequestSignInSendsNumericCode(t *testing.T) {
+	s, deps := newTestService(t)
+	phone := "+996702569123"
+
+	var text string
+	deps.sms.EXPECT().Send(phone, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _, t string) error {
+			text = t
+			return nil
+		})
+	if err := s.RequestSignIn(context.Background(), domain.RequestSignInInput{PhoneNumber: phone}); err != nil {
+		t.Fatal(err)
+	}
+
+	code := regexp.MustCompile(`:(\d{6})$`).FindStringSubmatch(text)
+	if code == nil {
+		t.Fatalf("message does not end with a 6 digit code: %q", text)
+	}
+	stored, err := deps.cache.Get("otp:signin:" + phone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored, code[1]) {
+		t.Errorf("code is stored in plain text: %q", stored)
+	}
+}
+
+func TestSignInCodeIsSingleUse(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.expectNoTOTP()
+	ctx := context.Background()
+	phone := "+996702569123"
+
+	code := deps.expectCode(phone)
+	if err := s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone}); err != nil {
+		t.Fatal(err)
+	}
+	deps.repo.EXPECT().ReadByPhoneNumber(gomock.Any(), phone).
+		Return(domain.User{ID: 1, Roles: []domain.Role{domain.RoleUser}}, nil)
+	deps.jwt.EXPECT().Generate(domain.ID(1), []domain.Role{domain.RoleUser}, nil, nil, gomock.Any()).
+		Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "refresh"}, nil)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	wrong := "000000"
+	if *code == wrong {
+		wrong = "111111"
+	}
+
+	testCases := []struct {
+		name string
+		code string
+		err  error
+	}{
+		{name: "fail with wrong code", code: wrong, err: domain.ErrInvalidCode},
+		{name: "success after wrong code", code: *code, err: nil},
+		{name: "fail with used code", code: *code, err: domain.ErrInvalidCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.SignIn(ctx, domain.SignInInput{PhoneNumber: phone, Code: tc.code})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestSignInAfterPhoneNumberUpdate(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.expectNoTOTP()
+	ctx := context.Background()
+	phone := "+996702569123"
+
+	var stored domain.UpdateInput
+	deps.repo.EXPECT().Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, changeset domain.UpdateInput) error {
+			stored = changeset
+			return nil
+		})
+	if err := s.Update(ctx, domain.UpdateInput{
+		ID:          1,
+		FullName:    "Pizza Lover",
+		PhoneNumber: "00996 (702) 569 123",
+		Email:       "pizza@lover.com",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	code := deps.expectCode(phone)
+	if err := s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone}); err != nil {
+		t.Fatal(err)
+	}
+	deps.repo.EXPECT().ReadByPhoneNumber(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, phoneNumber string) (domain.User, error) {
+			if phoneNumber != stored.PhoneNumber {
+				return domain.User{}, domain.ErrNoUsers
+			}
+			return domain.User{ID: stored.ID, Roles: []domain.Role{domain.RoleUser}}, nil
+		})
+	deps.jwt.EXPECT().Generate(domain.ID(1), []domain.Role{domain.RoleUser}, nil, nil, gomock.Any()).
+		Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "refresh"}, nil)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	if _, err := s.SignIn(ctx, domain.SignInInput{PhoneNumber: phone, Code: *code}); err != nil {
+		t.Errorf("could not sign in with updated number %q: %v", stored.PhoneNumber, err)
+	}
+}
+
+func TestSignUpDoesNotAcceptSignInCode(t *testing.T) {
+	s, deps := newTestService(t)
+	ctx := context.Background()
+	phone := "+996702569123"
+
+	code := deps.expectCode(phone)
+	if err := s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := s.SignUp(ctx, domain.SignUpInput{PhoneNumber: phone, Code: *code, FullName: "Pizza Lover"})
+	if !errors.Is(err, domain.ErrInvalidCode) {
+		t.Errorf("got %v, want %v", err, domain.ErrInvalidCode)
+	}
+}
+
+func TestCodeBruteForce(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.expectNoTOTP()
+	ctx := context.Background()
+	phone := "+996702569123"
+
+	code := deps.expectCode(phone)
+	if err := s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone}); err != nil {
+		t.Fatal(err)
+	}
+	wrong := "000000"
+	if *code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 1; i < domain.CodeMaxAttempts; i++ {
+		_, err := s.SignIn(ctx, domain.SignInInput{PhoneNumber: phone, Code: wrong})
+		if !errors.Is(err, domain.ErrInvalidCode) {
+			t.Fatalf("attempt %d: got %v, want %v", i, err, domain.ErrInvalidCode)
+		}
+	}
+	_, err := s.SignIn(ctx, domain.SignInInput{PhoneNumber: phone, Code: wrong})
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("got %v, want %v", err, domain.ErrTooManyAttempts)
+	}
+
+	// even the right code does not work now
+	_, err = s.SignIn(ctx, domain.SignInInput{PhoneNumber: phone, Code: *code})
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("got %v, want %v", err, domain.ErrTooManyAttempts)
+	}
+	if _, err := deps.cache.Get("otp:signin:" + phone); !errors.Is(err, domain.ErrCacheMiss) {
+		t.Errorf("code should be invalidated, got %v", err)
+	}
+	// and a new one can not be requested until lockout is over
+	err = s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone})
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("got %v, want %v", err, domain.ErrTooManyAttempts)
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestDecodeArgon2id(t *testing.T) {
+	h := passwordHasher{params: PasswordParams{Memory: 64, Iterations: 2, Parallelism: 1, SaltLength: 8, KeyLength: 16}}
+	encoded, err := h.hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != h.params || len(salt) != 8 || len(key) != 16 {
+		t.Errorf("got %+v with %d byte salt and %d byte key from %q", params, len(salt), len(key), encoded)
+	}
+
+	for _, broken := range []string{
+		"$argon2id$v=19$m=64,t=2,p=1$c2FsdA",
+		"$argon2id$v=16$m=64,t=2,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64;t=2;p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=2,p=1$!!!$a2V5",
+	} {
+		if _, _, _, err := decodeArgon2id(broken); err == nil {
+			t.Errorf("%q is decoded without errors", broken)
+		}
+	}
+}
+
+func TestVerifyUnknownHash(t *testing.T) {
+	h := passwordHasher{params: DefaultPasswordParams}
+	ok, _, err := h.verify("$1$md5$hash", "password")
+	if ok || err == nil {
+		t.Errorf("got ok=%v err=%v, want rejected", ok, err)
+	}
+}
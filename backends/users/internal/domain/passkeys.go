@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Every WebAuthn ceremony gets a random challenge the authenticator
+// has to sign. Challenges are kept in cache under a hash of ceremony id
+// together with the user the ceremony is for, and are used only once.
+// Passkeys verify the user on the device (pin, fingerprint), so
+// signing in with one does not ask for the second factor.
+
+type passkeyPurpose string
+
+const (
+	passkeyRegistration passkeyPurpose = "register"
+	passkeySignIn       passkeyPurpose = "signin"
+
+	passkeyChallengeLength = 32
+	defaultPasskeyName     = "Passkey"
+)
+
+func passkeyCeremonyKey(purpose passkeyPurpose, id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return "passkey:" + string(purpose) + ":" + hex.EncodeToString(sum[:])
+}
+
+// startCeremony returns ceremony id and challenge for it
+func (s *service) startCeremony(purpose passkeyPurpose, userID ID) (string, []byte, error) {
+	id, err := newTokenID()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not generate ceremony id: %w", err)
+	}
+	challenge := make([]byte, passkeyChallengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", nil, fmt.Errorf("could not generate challenge: %w", err)
+	}
+	value := strconv.FormatUint(uint64(userID), 10) + ":" + base64.RawURLEncoding.EncodeToString(challenge)
+	if err := s.cache.Store(passkeyCeremonyKey(purpose, id), value, PasskeyCeremonyExp); err != nil {
+		return "", nil, fmt.Errorf("could not cache challenge: %w", err)
+	}
+	return id, challenge, nil
+}
+
+// finishCeremony consumes the ceremony, whatever the response is
+// the challenge can not be signed twice
+func (s *service) finishCeremony(purpose passkeyPurpose, id string) (ID, []byte, error) {
+	value, err := s.cache.Pop(passkeyCeremonyKey(purpose, id))
+	if errors.Is(err, ErrCacheMiss) {
+		return 0, nil, ErrInvalidCeremony
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	user, encoded, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, nil, ErrInvalidCeremony
+	}
+	userID, err := strconv.ParseUint(user, 10, 64)
+	if err != nil {
+		return 0, nil, ErrInvalidCeremony
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, nil, ErrInvalidCeremony
+	}
+	return ID(userID), challenge, nil
+}
+
+func (s *service) BeginPasskeyRegistration(ctx context.Context, userID ID) (PasskeyCeremony, error) {
+	if s.webauthn == nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeyRegistration(): %w", ErrPasskeysDisabled)
+	}
+	u, err := s.repo.Read(ctx, userID)
+	if err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeyRegistration(): %w", err)
+	}
+	// authenticators refuse to make a second passkey for the same account
+	existing, err := s.repo.ReadPasskeys(ctx, userID)
+	if err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeyRegistration(): %w", err)
+	}
+	id, challenge, err := s.startCeremony(passkeyRegistration, userID)
+	if err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeyRegistration(): %w", err)
+	}
+	options, err := s.webauthn.RegistrationOptions(challenge, u, existing)
+	if err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeyRegistration(): %w", err)
+	}
+	return PasskeyCeremony{ID: id, Options: options}, nil
+}
+
+func (s *service) FinishPasskeyRegistration(ctx context.Context, inp FinishPasskeyInput) (Passkey, error) {
+	if s.webauthn == nil {
+		return Passkey{}, fmt.Errorf("finishPasskeyRegistration(): %w", ErrPasskeysDisabled)
+	}
+	userID, challenge, err := s.finishCeremony(passkeyRegistration, inp.CeremonyID)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("finishPasskeyRegistration(): %w", err)
+	}
+	if inp.UserID != 0 && inp.UserID != userID {
+		return Passkey{}, fmt.Errorf("finishPasskeyRegistration(): %w", ErrInvalidCeremony)
+	}
+	p, err := s.webauthn.VerifyRegistration(challenge, inp.Response)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("finishPasskeyRegistration(): %w", err)
+	}
+	p.UserID = userID
+	p.Name = strings.TrimSpace(inp.Name)
+	if p.Name == "" {
+		p.Name = defaultPasskeyName
+	}
+	p.CreatedAt = time.Now().UTC()
+	if err := s.repo.CreatePasskey(ctx, p); err != nil {
+		return Passkey{}, fmt.Errorf("finishPasskeyRegistration(): %w", err)
+	}
+	return p, nil
+}
+
+func (s *service) BeginPasskeySignIn(ctx context.Context, email string) (PasskeyCeremony, error) {
+	if s.webauthn == nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeySignIn(): %w", ErrPasskeysDisabled)
+	}
+	var allow []Passkey
+	if email != "" {
+		u, err := s.repo.ReadByEmail(ctx, email)
+		// unknown email gets the same options as no email at all,
+		// nobody should find out whose email is registered
+		if err != nil && !errors.Is(err, ErrNoUsers) {
+			return PasskeyCeremony{}, fmt.Errorf("beginPasskeySignIn(): %w", err)
+		}
+		if err == nil {
+			if allow, err = s.repo.ReadPasskeys(ctx, u.ID); err != nil {
+				return PasskeyCeremony{}, fmt.Errorf("beginPasskeySignIn(): %w", err)
+			}
+		}
+	}
+	id, challenge, err := s.startCeremony(passkeySignIn, 0)
+	if err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeySignIn(): %w", err)
+	}
+	options, err := s.webauthn.AssertionOptions(challenge, allow)
+	if err != nil {
+		return PasskeyCeremony{}, fmt.Errorf("beginPasskeySignIn(): %w", err)
+	}
+	return PasskeyCeremony{ID: id, Options: options}, nil
+}
+
+func (s *service) FinishPasskeySignIn(ctx context.Context, inp FinishPasskeyInput) (SignInOutput, error) {
+	if s.webauthn == nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", ErrPasskeysDisabled)
+	}
+	_, challenge, err := s.finishCeremony(passkeySignIn, inp.CeremonyID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+	id, err := s.webauthn.AssertedPasskey(inp.Response)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+	p, err := s.repo.ReadPasskey(ctx, id)
+	if errors.Is(err, ErrPasskeyNotFound) {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", ErrInvalidPasskey)
+	}
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+	signCount, err := s.webauthn.VerifyAssertion(challenge, p, inp.Response)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+	if err := s.repo.UpdatePasskeySignCount(ctx, p.ID, signCount, time.Now().UTC()); err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+
+	u, err := s.repo.Read(ctx, p.UserID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): could not read from db %w", err)
+	}
+	claims, err := s.issueTokens(ctx, u)
+	if err != nil {
+		return claims, fmt.Errorf("finishPasskeySignIn(): %w", err)
+	}
+	return claims, nil
+}
+
+func (s *service) Passkeys(ctx context.Context, userID ID) ([]Passkey, error) {
+	passkeys, err := s.repo.ReadPasskeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("passkeys(): %w", err)
+	}
+	return passkeys, nil
+}
+
+func (s *service) DeletePasskey(ctx context.Context, userID ID, passkeyID string) error {
+	if err := s.repo.DeletePasskey(ctx, userID, passkeyID); err != nil {
+		return fmt.Errorf("deletePasskey(): %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/webauthn"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/webauthn/softauthn"
+)
+
+const passkeyOrigin = "https://micro-pizzas.com"
+
+func newPasskeyService(t *testing.T) (domain.Service, testDeps) {
+	t.Helper()
+	rp, err := webauthn.NewRelyingParty(webauthn.Config{RPID: "micro-pizzas.com", Origins: []string{passkeyOrigin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestService(t, domain.WithWebAuthn(rp))
+}
+
+// expectPasskeyStorage keeps passkeys in memory the way repository would
+func (d testDeps) expectPasskeyStorage(u domain.User) {
+	stored := map[string]domain.Passkey{}
+	d.repo.EXPECT().Read(gomock.Any(), u.ID).Return(u, nil).AnyTimes()
+	d.repo.EXPECT().ReadByEmail(gomock.Any(), u.Email).Return(u, nil).AnyTimes()
+	d.repo.EXPECT().CreatePasskey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, p domain.Passkey) error {
+			if _, ok := stored[p.ID]; ok {
+				return domain.ErrPasskeyExists
+			}
+			stored[p.ID] = p
+			return nil
+		}).AnyTimes()
+	d.repo.EXPECT().ReadPasskey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string) (domain.Passkey, error) {
+			p, ok := stored[id]
+			if !ok {
+				return domain.Passkey{}, domain.ErrPasskeyNotFound
+			}
+			return p, nil
+		}).AnyTimes()
+	d.repo.EXPECT().ReadPasskeys(gomock.Any(), u.ID).
+		DoAndReturn(func(context.Context, domain.ID) ([]domain.Passkey, error) {
+			var passkeys []domain.Passkey
+			for _, p := range stored {
+				passkeys = append(passkeys, p)
+			}
+			return passkeys, nil
+		}).AnyTimes()
+	d.repo.EXPECT().UpdatePasskeySignCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string, signCount uint32, usedAt time.Time) error {
+			p := stored[id]
+			p.SignCount = signCount
+			p.LastUsedAt = &usedAt
+			stored[id] = p
+			return nil
+		}).AnyTimes()
+}
+
+func registerPasskey(t *testing.T, s domain.Service, auth *softauthn.Authenticator, userID domain.ID) domain.Passkey {
+	t.Helper()
+	ctx := context.Background()
+	ceremony, err := s.BeginPasskeyRegistration(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := auth.Register(ceremony.Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.FinishPasskeyRegistration(ctx, domain.FinishPasskeyInput{CeremonyID: ceremony.ID, Response: resp, Name: "laptop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPasskeySignIn(t *testing.T) {
+	user := domain.User{ID: 7, FullName: "John Doe", Email: "john@micro-pizzas.com", Roles: []domain.Role{domain.RoleUser}}
+
+	testCases := []struct {
+		name  string
+		email string
+	}{
+		{name: "discoverable", email: ""},
+		{name: "by email", email: user.Email},
+		{name: "unknown email", email: "nobody@micro-pizzas.com"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, deps := newPasskeyService(t)
+			deps.expectPasskeyStorage(user)
+			deps.repo.EXPECT().ReadByEmail(gomock.Any(), "nobody@micro-pizzas.com").Return(domain.User{}, domain.ErrNoUsers).AnyTimes()
+			auth := softauthn.New(passkeyOrigin)
+
+			p := registerPasskey(t, s, auth, user.ID)
+			if p.UserID != user.ID || p.Name != "laptop" {
+				t.Fatalf("got passkey %+v", p)
+			}
+
+			ceremony, err := s.BeginPasskeySignIn(ctx, tc.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := auth.Login(ceremony.Options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// passkeys skip the second factor, so no totp is read
+			deps.jwt.EXPECT().Generate(user.ID, user.Roles, user.Permissions, user.Grants, gomock.Any()).Return(domain.SignInOutput{AccessKey: "access"}, nil)
+			deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			out, err := s.FinishPasskeySignIn(ctx, domain.FinishPasskeyInput{CeremonyID: ceremony.ID, Response: resp})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.AccessKey != "access" {
+				t.Fatalf("expected tokens, got %+v", out)
+			}
+
+			// the same response can not be used twice
+			if _, err := s.FinishPasskeySignIn(ctx, domain.FinishPasskeyInput{CeremonyID: ceremony.ID, Response: resp}); !errors.Is(err, domain.ErrInvalidCeremony) {
+				t.Fatalf("expected %v, got %v", domain.ErrInvalidCeremony, err)
+			}
+		})
+	}
+}
+
+func TestPasskeyErrors(t *testing.T) {
+	user := domain.User{ID: 7, FullName: "John Doe", Email: "john@micro-pizzas.com", Roles: []domain.Role{domain.RoleUser}}
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		s, _ := newTestService(t)
+		if _, err := s.BeginPasskeySignIn(ctx, ""); !errors.Is(err, domain.ErrPasskeysDisabled) {
+			t.Fatalf("expected %v, got %v", domain.ErrPasskeysDisabled, err)
+		}
+	})
+
+	t.Run("unknown passkey", func(t *testing.T) {
+		s, deps := newPasskeyService(t)
+		deps.expectPasskeyStorage(user)
+		// registered on another server, so we have never seen it
+		other, otherDeps := newPasskeyService(t)
+		otherDeps.expectPasskeyStorage(user)
+		auth := softauthn.New(passkeyOrigin)
+		registerPasskey(t, other, auth, user.ID)
+
+		ceremony, err := s.BeginPasskeySignIn(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := auth.Login(ceremony.Options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.FinishPasskeySignIn(ctx, domain.FinishPasskeyInput{CeremonyID: ceremony.ID, Response: resp}); !errors.Is(err, domain.ErrInvalidPasskey) {
+			t.Fatalf("expected %v, got %v", domain.ErrInvalidPasskey, err)
+		}
+	})
+
+	t.Run("response to another ceremony", func(t *testing.T) {
+		s, deps := newPasskeyService(t)
+		deps.expectPasskeyStorage(user)
+		auth := softauthn.New(passkeyOrigin)
+		registerPasskey(t, s, auth, user.ID)
+
+		first, err := s.BeginPasskeySignIn(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := s.BeginPasskeySignIn(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := auth.Login(first.Options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.FinishPasskeySignIn(ctx, domain.FinishPasskeyInput{CeremonyID: second.ID, Response: resp}); !errors.Is(err, domain.ErrInvalidPasskey) {
+			t.Fatalf("expected %v, got %v", domain.ErrInvalidPasskey, err)
+		}
+	})
+}
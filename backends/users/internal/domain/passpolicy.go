@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Passwords are checked by a policy made of rules. Every rule that fails
+// adds a violation, so the client can show the user everything at once.
+
+const (
+	PasswordTooShort     = "too_short"
+	PasswordTooLong      = "too_long"
+	PasswordTooWeak      = "too_weak"
+	PasswordPersonalInfo = "personal_info"
+	PasswordBreached     = "breached"
+
+	// PasswordMinScore is the lowest strength score accepted by default,
+	// score 3 means about 10^8 guesses are needed.
+	PasswordMinScore = 3
+
+	breachedPrefixLength = 5
+)
+
+type (
+	PasswordViolation struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+
+	// PasswordPolicyError lists every rule the password broke.
+	// errors.Is(err, ErrPasswordIsNotSecure) works with it.
+	PasswordPolicyError struct {
+		Violations []PasswordViolation
+	}
+
+	// PasswordRule returns nil if password is fine. u is the owner of
+	// the password, some of its fields might be empty.
+	PasswordRule func(ctx context.Context, password string, u User) (*PasswordViolation, error)
+
+	PasswordPolicy struct {
+		rules []PasswordRule
+	}
+)
+
+func (e *PasswordPolicyError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		reasons[i] = v.Reason
+	}
+	return ErrPasswordIsNotSecure.Error() + ": " + strings.Join(reasons, ", ")
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordIsNotSecure
+}
+
+func NewPasswordPolicy(rules ...PasswordRule) *PasswordPolicy {
+	return &PasswordPolicy{rules: rules}
+}
+
+// DefaultPasswordRules are used when service gets no policy.
+// There is no breached passwords check among them since it needs a dataset.
+func DefaultPasswordRules() []PasswordRule {
+	return []PasswordRule{
+		LengthRule(PasswordMinLength, PasswordMaxLength),
+		PersonalInfoRule(),
+		StrengthRule(PasswordMinScore),
+	}
+}
+
+// Check runs all the rules, if any of them fails it
+// returns *PasswordPolicyError. Other errors mean a rule could not run.
+func (p *PasswordPolicy) Check(ctx context.Context, password string, u User) error {
+	var violations []PasswordViolation
+	for _, rule := range p.rules {
+		v, err := rule(ctx, password, u)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			continue
+		}
+		violations = append(violations, *v)
+		// there is no point in estimating a 10kb long password
+		if v.Reason == PasswordTooLong {
+			break
+		}
+	}
+	if len(violations) != 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func LengthRule(min, max int) PasswordRule {
+	return func(_ context.Context, password string, _ User) (*PasswordViolation, error) {
+		switch l := utf8.RuneCountInString(password); {
+		case l < min:
+			return &PasswordViolation{PasswordTooShort, fmt.Sprintf("password has to be at least %d characters long", min)}, nil
+		case l > max:
+			return &PasswordViolation{PasswordTooLong, fmt.Sprintf("password has to be at most %d characters long", max)}, nil
+		}
+		return nil, nil
+	}
+}
+
+// StrengthRule rejects passwords that are easy to guess, see estimateStrength.
+func StrengthRule(minScore int) PasswordRule {
+	return func(_ context.Context, password string, u User) (*PasswordViolation, error) {
+		if estimateStrength(password, personalInfo(u)).Score < minScore {
+			return &PasswordViolation{PasswordTooWeak, "password is too easy to guess, add a few more uncommon words"}, nil
+		}
+		return nil, nil
+	}
+}
+
+// PersonalInfoRule rejects passwords that contain name, email or phone number of the user.
+func PersonalInfoRule() PasswordRule {
+	return func(_ context.Context, password string, u User) (*PasswordViolation, error) {
+		lower := strings.ToLower(password)
+		for _, v := range personalInfo(u) {
+			if strings.Contains(lower, v) {
+				return &PasswordViolation{PasswordPersonalInfo, "password must not contain your name, email or phone number"}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// BreachedRule looks password up in a dataset of leaked passwords. Only
+// the first 5 characters of SHA-1 of the password are sent to the dataset.
+func BreachedRule(b BreachedPasswords) PasswordRule {
+	return func(ctx context.Context, password string, _ User) (*PasswordViolation, error) {
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		suffixes, err := b.Range(ctx, hash[:breachedPrefixLength])
+		if err != nil {
+			return nil, fmt.Errorf("could not check breached passwords: %w", err)
+		}
+		if suffixes[hash[breachedPrefixLength:]] > 0 {
+			return &PasswordViolation{PasswordBreached, "password was found in a data breach, please choose another one"}, nil
+		}
+		return nil, nil
+	}
+}
+
+// personalInfo returns lowercased parts of user's data that are
+// long enough to matter in a password
+func personalInfo(u User) []string {
+	var parts []string
+	add := func(v string) {
+		if utf8.RuneCountInString(v) >= 3 {
+			parts = append(parts, v)
+		}
+	}
+	for _, v := range strings.FieldsFunc(strings.ToLower(u.FullName), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		add(v)
+	}
+	if at := strings.LastIndex(u.Email, "@"); at > 0 {
+		local := strings.ToLower(u.Email[:at])
+		add(local)
+		for _, v := range strings.FieldsFunc(local, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if v != local {
+				add(v)
+			}
+		}
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, u.PhoneNumber)
+	// the part without country and operator codes is what people remember
+	if len(digits) > 7 {
+		add(digits[len(digits)-7:])
+	}
+	add(digits)
+	return parts
+}
+
+// checkPassword runs the policy and hashes password
+func (s *service) checkPassword(ctx context.Context, password string, u User) (string, error) {
+	if err := s.passwordPolicy.Check(ctx, password, u); err != nil {
+		return "", err
+	}
+	hash, err := s.passwords.hash(password)
+	if err != nil {
+		return "", fmt.Errorf("error while hashing password: %w", err)
+	}
+	return hash, nil
+}
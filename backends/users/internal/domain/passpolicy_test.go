@@ -0,0 +1,81 @@
+package domain_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// breachedList is a BreachedPasswords that knows a few passwords
+type breachedList []string
+
+func (b breachedList) Range(_ context.Context, prefix string) (map[string]int64, error) {
+	suffixes := map[string]int64{}
+	for _, p := range b {
+		sum := sha1.Sum([]byte(p))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		if strings.HasPrefix(hash, prefix) {
+			suffixes[hash[len(prefix):]] = 42
+		}
+	}
+	return suffixes, nil
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	user := domain.User{
+		FullName:    "Emirlan Rasulov",
+		Email:       "pizza.lover@gmail.com",
+		PhoneNumber: "+996 555 123 456",
+	}
+	policy := domain.NewPasswordPolicy(append(
+		domain.DefaultPasswordRules(),
+		domain.BreachedRule(breachedList{"Oregano-Basil-Crust-91"}),
+	)...)
+
+	testCases := []struct {
+		name     string
+		password string
+		reasons  []string
+	}{
+		{name: "pass with strong password", password: "Mozzarella-Tram-Violin-7", reasons: nil},
+		{name: "fail with short password", password: "x9!", reasons: []string{domain.PasswordTooShort, domain.PasswordTooWeak}},
+		{name: "fail with too long password", password: strings.Repeat("a", domain.PasswordMaxLength+1), reasons: []string{domain.PasswordTooLong}},
+		{name: "fail with common password", password: "password123", reasons: []string{domain.PasswordTooWeak}},
+		{name: "fail with keyboard walk", password: "qwertyuiop1234", reasons: []string{domain.PasswordTooWeak}},
+		{name: "fail with name", password: "Rasulov-Tram-Violin-7", reasons: []string{domain.PasswordPersonalInfo}},
+		{name: "fail with email", password: "PizzaLover-Tram-Violin", reasons: []string{domain.PasswordPersonalInfo}},
+		{name: "fail with phone number", password: "Tram-Violin-5123456", reasons: []string{domain.PasswordPersonalInfo}},
+		{name: "fail with breached password", password: "Oregano-Basil-Crust-91", reasons: []string{domain.PasswordBreached}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(context.Background(), tc.password, user)
+			if tc.reasons == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrPasswordIsNotSecure) {
+				t.Fatalf("expected %v, got %v", domain.ErrPasswordIsNotSecure, err)
+			}
+			var perr *domain.PasswordPolicyError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *PasswordPolicyError, got %T", err)
+			}
+			got := make([]string, len(perr.Violations))
+			for i, v := range perr.Violations {
+				got[i] = v.Reason
+			}
+			if strings.Join(got, ",") != strings.Join(tc.reasons, ",") {
+				t.Fatalf("expected violations %v, got %v", tc.reasons, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+)
+
+// Reset tokens are kept in cache the same way as codes, only hashes of them.
+// "pwreset:<hash>" points to the user and "pwreset:user:<id>" to the
+// latest token of the user, so asking for a new token kills the old one.
+
+func resetTokenKey(hash string) string {
+	return "pwreset:" + hash
+}
+
+func resetUserKey(userID ID) string {
+	return "pwreset:user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return fmt.Errorf("requestPasswordReset(): %w", ErrInvalidEmail)
+	}
+	if err := s.allowEmail(ctx, email); err != nil {
+		return fmt.Errorf("requestPasswordReset(): %w", err)
+	}
+	u, err := s.repo.ReadByEmail(ctx, email)
+	// nobody should be able to find out whose email is registered
+	if errors.Is(err, ErrNoUsers) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("requestPasswordReset(): could not read from db %w", err)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("requestPasswordReset(): could not generate token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	hash := hashResetToken(token)
+
+	if old, err := s.cache.Get(resetUserKey(u.ID)); err == nil {
+		if err := s.cache.Delete(resetTokenKey(old)); err != nil {
+			return fmt.Errorf("requestPasswordReset(): could not invalidate old token: %w", err)
+		}
+	} else if !errors.Is(err, ErrCacheMiss) {
+		return fmt.Errorf("requestPasswordReset(): %w", err)
+	}
+	if err := s.cache.Store(resetTokenKey(hash), strconv.FormatUint(uint64(u.ID), 10), PasswordResetExp); err != nil {
+		return fmt.Errorf("requestPasswordReset(): could not cache token: %w", err)
+	}
+	if err := s.cache.Store(resetUserKey(u.ID), hash, PasswordResetExp); err != nil {
+		return fmt.Errorf("requestPasswordReset(): could not cache token: %w", err)
+	}
+
+	if err := s.emailer.Send(
+		email,
+		PasswordResetEmailTitle,
+		PasswordResetEmailMessage+":"+token,
+	); err != nil {
+		return fmt.Errorf("requestPasswordReset(): could not send email %w", err)
+	}
+	return nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, inp ResetPasswordInput) error {
+	hash := hashResetToken(inp.Token)
+	value, err := s.cache.Get(resetTokenKey(hash))
+	if errors.Is(err, ErrCacheMiss) {
+		return fmt.Errorf("resetPassword(): %w", ErrInvalidResetToken)
+	}
+	if err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("resetPassword(): %w", ErrInvalidResetToken)
+	}
+	userID := ID(id)
+
+	// check the password before using the token so a weak one does not burn it
+	u, err := s.repo.Read(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+	passwordHash, err := s.checkPassword(ctx, inp.Password, u)
+	if err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+
+	// Pop is atomic so the token works only once
+	// even if it is sent twice at the same time
+	if _, err := s.cache.Pop(resetTokenKey(hash)); errors.Is(err, ErrCacheMiss) {
+		return fmt.Errorf("resetPassword(): %w", ErrInvalidResetToken)
+	} else if err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+	if err := s.cache.Delete(resetUserKey(userID)); err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+
+	if err := s.repo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return fmt.Errorf("resetPassword(): %w", err)
+	}
+	// whoever knew the old password must not stay signed in
+	if err := s.repo.RevokeAllSessions(ctx, userID); err != nil {
+		return fmt.Errorf("resetPassword(): could not revoke sessions: %w", err)
+	}
+	return nil
+}
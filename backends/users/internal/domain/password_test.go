@@ -0,0 +1,186 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSignInEmailPassword(t *testing.T) {
+	email := "pizzas@gmail.com"
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// hash made by a service with current parameters
+	s, deps := newTestService(t)
+	deps.expectNoTOTP()
+	current := new(string)
+	deps.repo.EXPECT().ReadByEmail(gomock.Any(), email).
+		Return(domain.User{ID: 1, Password: string(legacy)}, nil)
+	deps.repo.EXPECT().UpdatePassword(gomock.Any(), domain.ID(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ domain.ID, hash string) error {
+			*current = hash
+			return nil
+		})
+	deps.jwt.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.SignInOutput{}, nil)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	if _, err := s.SignInEmailPassword(context.Background(), email, "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	stronger := testPasswordParams
+	stronger.Iterations++
+
+	testCases := []struct {
+		name     string
+		opts     []domain.Option
+		hash     string
+		password string
+		rehash   bool
+		err      error
+	}{
+		{name: "rehash bcrypt", hash: string(legacy), password: "password", rehash: true, err: nil},
+		{name: "keep current hash", hash: *current, password: "password", rehash: false, err: nil},
+		{
+			name: "rehash with new parameters", opts: []domain.Option{domain.WithPasswordParams(stronger)},
+			hash: *current, password: "password", rehash: true, err: nil,
+		},
+		{name: "fail with wrong password", hash: *current, password: "pasword", err: domain.ErrInvalidCredentials},
+		{name: "fail with wrong bcrypt password", hash: string(legacy), password: "pasword", err: domain.ErrInvalidCredentials},
+		{name: "fail without password", hash: "", password: "", err: domain.ErrInvalidCredentials},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t, tc.opts...)
+			deps.expectNoTOTP()
+			deps.repo.EXPECT().ReadByEmail(gomock.Any(), email).
+				Return(domain.User{ID: 1, Password: tc.hash}, nil)
+			if tc.err == nil {
+				deps.jwt.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.SignInOutput{}, nil)
+				deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			}
+			if tc.rehash {
+				deps.repo.EXPECT().UpdatePassword(gomock.Any(), domain.ID(1), gomock.Any()).Return(nil)
+			}
+
+			_, err := s.SignInEmailPassword(context.Background(), email, tc.password)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestSignUpWithPassword(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		err      error
+	}{
+		{name: "store hash of the password", password: "Oregano-Basil-Crust-91", err: nil},
+		{name: "sign up without password", password: "", err: nil},
+		{name: "fail with short password", password: "short", err: domain.ErrPasswordIsNotSecure},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			ctx := context.Background()
+			email := "pizzas@gmail.com"
+
+			code := deps.expectEmail(email)
+			if err := s.RequestSignUp(ctx, domain.RequestSignUpInput{Email: email}); err != nil {
+				t.Fatal(err)
+			}
+			if tc.err == nil {
+				deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, u domain.User) (domain.ID, error) {
+						if tc.password == "" {
+							if u.Password != "" {
+								t.Errorf("got password hash %q, want none", u.Password)
+							}
+							return 1, nil
+						}
+						if !strings.HasPrefix(u.Password, "$argon2id$") || strings.Contains(u.Password, tc.password) {
+							t.Errorf("got %q, want argon2id hash", u.Password)
+						}
+						return 1, nil
+					})
+				deps.jwt.EXPECT().Generate(domain.ID(1), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.SignInOutput{}, nil)
+				deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			_, err := s.SignUp(ctx, domain.SignUpInput{
+				Email:    email,
+				Code:     *code,
+				FullName: "Pizza Lover",
+				Password: tc.password,
+			})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	s, deps := newTestService(t)
+	ctx := context.Background()
+	email := "pizzas@gmail.com"
+	user := domain.User{ID: 1, Email: email}
+
+	deps.repo.EXPECT().ReadByEmail(gomock.Any(), email).Return(user, nil).Times(2)
+	first := deps.expectEmail(email)
+	if err := s.RequestPasswordReset(ctx, email); err != nil {
+		t.Fatal(err)
+	}
+	second := deps.expectEmail(email)
+	if err := s.RequestPasswordReset(ctx, email); err != nil {
+		t.Fatal(err)
+	}
+
+	deps.repo.EXPECT().Read(gomock.Any(), domain.ID(1)).Return(user, nil).Times(2)
+	deps.repo.EXPECT().UpdatePassword(gomock.Any(), domain.ID(1), gomock.Any()).Return(nil)
+	deps.repo.EXPECT().RevokeAllSessions(gomock.Any(), domain.ID(1)).Return(nil)
+
+	testCases := []struct {
+		name     string
+		token    string
+		password string
+		err      error
+	}{
+		{name: "fail with replaced token", token: *first, password: "Oregano-Basil-Crust-91", err: domain.ErrInvalidResetToken},
+		{name: "fail with weak password", token: *second, password: "short", err: domain.ErrPasswordIsNotSecure},
+		{name: "reset with the latest token", token: *second, password: "Oregano-Basil-Crust-91", err: nil},
+		{name: "fail with used token", token: *second, password: "Oregano-Basil-Crust-91", err: domain.ErrInvalidResetToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.ResetPassword(ctx, domain.ResetPasswordInput{Token: tc.token, Password: tc.password})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRequestPasswordResetForUnknownEmail(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.repo.EXPECT().ReadByEmail(gomock.Any(), "nobody@gmail.com").
+		Return(domain.User{}, domain.ErrNoUsers)
+	deps.emailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := s.RequestPasswordReset(context.Background(), "nobody@gmail.com"); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
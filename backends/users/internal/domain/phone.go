@@ -0,0 +1,38 @@
+package domain
+
+import "strings"
+
+// normalizePhoneNumber brings numbers to +<digits>, so the same number
+// written differently gets the same codes, limits and lockouts. Numbers
+// with something else than digits and separators are left as they are.
+func normalizePhoneNumber(phoneNumber string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(phoneNumber) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && b.Len() == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return phoneNumber
+		}
+	}
+	n := b.String()
+	switch {
+	case n == "" || n == "+":
+		return phoneNumber
+	case strings.HasPrefix(n, "+"):
+		return n
+	case strings.HasPrefix(n, "00"):
+		return "+" + n[2:]
+	// 8 is a trunk prefix in Russia and Kazakhstan, 8 700 is +7 700
+	case len(n) == 11 && n[0] == '8':
+		return "+7" + n[1:]
+	// other trunk prefixes tell nothing about the country
+	case n[0] == '0':
+		return n
+	default:
+		return "+" + n
+	}
+}
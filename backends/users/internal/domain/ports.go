@@ -18,8 +18,105 @@ type (
 		SignInEmailPassword(ctx context.Context, email, password string) (SignInOutput, error)
 		Refresh(ctx context.Context, refreshKey string) (SignInOutput, error)
 
-		AddRole(ctx context.Context, userID ID, role Role) error
-		RemoveRole(ctx context.Context, userID ID, role Role) error
+		// RequestPasswordReset emails a reset token if there is a user with
+		// this email. ResetPassword sets new password and signs user out everywhere.
+		RequestPasswordReset(ctx context.Context, email string) error
+		ResetPassword(ctx context.Context, inp ResetPasswordInput) error
+
+		// SignOut revokes the session refreshKey belongs to.
+		SignOut(ctx context.Context, refreshKey string) error
+		Sessions(ctx context.Context, userID ID) ([]Session, error)
+		RevokeSession(ctx context.Context, userID ID, sessionID string) error
+		// RevokeOtherSessions signs out every device except
+		// the one refreshKey was issued to.
+		RevokeOtherSessions(ctx context.Context, refreshKey string) error
+
+		// EnrollTOTP makes a new TOTP secret, it starts working after
+		// ConfirmTOTP gets a valid code and returns recovery codes.
+		// If user has TOTP enabled sign in returns *SecondFactorRequiredError
+		// and VerifySecondFactor finishes it with a TOTP or recovery code.
+		EnrollTOTP(ctx context.Context, userID ID) (TOTPEnrollment, error)
+		ConfirmTOTP(ctx context.Context, userID ID, code string) (recoveryCodes []string, err error)
+		DisableTOTP(ctx context.Context, userID ID, code, password string) error
+		RegenerateRecoveryCodes(ctx context.Context, userID ID, code string) ([]string, error)
+		VerifySecondFactor(ctx context.Context, challengeToken, code string) (SignInOutput, error)
+
+		// Passkeys are registered and used in two steps, Begin returns
+		// options for navigator.credentials and Finish checks what the
+		// authenticator responded. Sign in works without email since
+		// passkeys know whose they are, email only narrows the choice.
+		BeginPasskeyRegistration(ctx context.Context, userID ID) (PasskeyCeremony, error)
+		FinishPasskeyRegistration(ctx context.Context, inp FinishPasskeyInput) (Passkey, error)
+		BeginPasskeySignIn(ctx context.Context, email string) (PasskeyCeremony, error)
+		FinishPasskeySignIn(ctx context.Context, inp FinishPasskeyInput) (SignInOutput, error)
+		Passkeys(ctx context.Context, userID ID) ([]Passkey, error)
+		DeletePasskey(ctx context.Context, userID ID, passkeyID string) error
+
+		// Users sign in with OpenID Connect providers in two steps, Begin
+		// returns where to send the user and Finish trades the code the
+		// provider sent back for our tokens. First sign in makes a new user,
+		// but an existing account has to link the provider on its own.
+		BeginOIDCSignIn(ctx context.Context, provider string) (OIDCAuthorization, error)
+		FinishOIDCSignIn(ctx context.Context, inp FinishOIDCInput) (SignInOutput, error)
+		BeginOIDCLink(ctx context.Context, userID ID, provider string) (OIDCAuthorization, error)
+		FinishOIDCLink(ctx context.Context, userID ID, inp FinishOIDCInput) (Identity, error)
+		Identities(ctx context.Context, userID ID) ([]Identity, error)
+		UnlinkIdentity(ctx context.Context, userID ID, provider string) error
+
+		// We are an OAuth 2.0 and OpenID Connect authorization server too.
+		// Authorize is called for a signed in user and returns where to send
+		// them back with the code, if user has not allowed the client to do
+		// that yet it returns *ConsentRequiredError. Token is the token
+		// endpoint and UserInfo is the userinfo one.
+		Authorize(ctx context.Context, userID ID, inp AuthorizeInput) (redirectURL string, err error)
+		Token(ctx context.Context, inp TokenInput) (TokenOutput, error)
+		UserInfo(ctx context.Context, accessKey string) (UserInfo, error)
+		GrantConsent(ctx context.Context, userID ID, clientID string, scopes []string) error
+		Consents(ctx context.Context, userID ID) ([]Consent, error)
+		// RevokeConsent stops refresh tokens of the client from working too.
+		RevokeConsent(ctx context.Context, userID ID, clientID string) error
+
+		// Authenticate checks an access token of a user or a client or an
+		// api key, tokens of deleted clients stop working right away.
+		Authenticate(ctx context.Context, accessKey string) (AccessClaims, error)
+
+		// API keys let partners call us with scopes of the key, they are
+		// accepted wherever access tokens are.
+		CreateAPIKey(ctx context.Context, inp CreateAPIKeyInput) (APIKeyCreation, error)
+		APIKeys(ctx context.Context, owner APIKeyOwner) ([]APIKey, error)
+		RevokeAPIKey(ctx context.Context, id string) error
+
+		RegisterOAuthClient(ctx context.Context, inp RegisterClientInput) (ClientRegistration, error)
+		OAuthClient(ctx context.Context, clientID string) (OAuthClient, error)
+		OAuthClients(ctx context.Context) ([]OAuthClient, error)
+		DeleteOAuthClient(ctx context.Context, clientID string) error
+
+		// UnlockAccount lifts the lock put on an account
+		// after too many wrong passwords, it is meant for admins.
+		UnlockAccount(ctx context.Context, userID ID) error
+
+		// AddRole grants the role again if user has it in the same scope,
+		// so expiry and reason are replaced. RemoveRole takes it only
+		// in the given scope.
+		AddRole(ctx context.Context, inp AddRoleInput) error
+		RemoveRole(ctx context.Context, userID ID, role Role, scope string) error
+		// PurgeExpiredGrants is meant for a background job,
+		// expired grants are not used even before they are purged.
+		PurgeExpiredGrants(ctx context.Context) (int64, error)
+
+		// Users ask for roles with RequestRole and admins review requests,
+		// approved ones are granted and requester is told either way.
+		RequestRole(ctx context.Context, inp RequestRoleInput) (RoleRequest, error)
+		RoleRequest(ctx context.Context, id string) (RoleRequest, error)
+		RoleRequests(ctx context.Context, inp ReadRoleRequestsInput) ([]RoleRequest, error)
+		ReviewRoleRequest(ctx context.Context, inp ReviewRoleRequestInput) (RoleRequest, error)
+
+		// Roles live in the database with permissions they give, admins
+		// may add their own roles, but built in ones can not be deleted.
+		Roles(ctx context.Context) ([]RoleDefinition, error)
+		CreateRole(ctx context.Context, inp CreateRoleInput) (RoleDefinition, error)
+		SetRolePermissions(ctx context.Context, role Role, permissions []string) error
+		DeleteRole(ctx context.Context, role Role) error
 
 		Read(ctx context.Context, id ID) (User, error)
 		ReadByEmail(ctx context.Context, email string) (User, error)
@@ -42,10 +139,115 @@ type (
 		ReadAll(ctx context.Context, cfg ReadAllInput) ([]User, error)
 
 		Update(ctx context.Context, changeset UpdateInput) error
-		AddRole(context.Context, ID, Role) error
-
-		RemoveRole(context.Context, ID, Role) error
+		UpdatePassword(ctx context.Context, userID ID, passwordHash string) error
+		// AddRole replaces the grant with the same role and scope
+		AddRole(ctx context.Context, userID ID, grant RoleGrant) error
+		RemoveRole(ctx context.Context, userID ID, role Role, scope string) error
+		DeleteExpiredGrants(ctx context.Context, now time.Time) (int64, error)
 		Delete(context.Context, ID) error
+
+		ReadRoles(ctx context.Context) ([]RoleDefinition, error)
+		// ReadRole returns ErrRoleNotFound if there is no such role.
+		ReadRole(ctx context.Context, role Role) (RoleDefinition, error)
+		// CreateRole returns ErrRoleExists if the name is taken.
+		CreateRole(ctx context.Context, r RoleDefinition) (Role, error)
+		// SetRolePermissions replaces all permissions of the role,
+		// it returns ErrRoleNotFound if there is no such role.
+		SetRolePermissions(ctx context.Context, role Role, permissions []string) error
+		// DeleteRole takes the role from everybody who has it, it
+		// returns ErrRoleNotFound if there is no such role.
+		DeleteRole(ctx context.Context, role Role) error
+
+		// CreateRoleRequest returns ErrRoleRequestPending if user
+		// already waits for the same role in the same scope.
+		CreateRoleRequest(ctx context.Context, r RoleRequest) error
+		// ReadRoleRequest returns ErrRoleRequestNotFound if there is no such request.
+		ReadRoleRequest(ctx context.Context, id string) (RoleRequest, error)
+		// ReadRoleRequests returns the oldest requests first.
+		ReadRoleRequests(ctx context.Context, inp ReadRoleRequestsInput) ([]RoleRequest, error)
+		// ReviewRoleRequest writes the decision down and adds grant of approved
+		// requests in the same transaction. It returns ErrRoleRequestNotPending
+		// and grants nothing if somebody has already reviewed the request.
+		ReviewRoleRequest(ctx context.Context, r RoleRequest, grant *RoleGrant) error
+
+		// CreateSession stores session together with its first refresh token.
+		CreateSession(ctx context.Context, session Session, first RefreshToken) error
+		// ReadSession returns ErrSessionNotFound if there is no such session.
+		ReadSession(ctx context.Context, id string) (Session, error)
+		// ReadSessions returns only sessions that were not revoked.
+		ReadSessions(ctx context.Context, userID ID) ([]Session, error)
+		// RevokeSession returns ErrSessionNotFound if user has
+		// no active session with this id.
+		RevokeSession(ctx context.Context, userID ID, id string) error
+		RevokeOtherSessions(ctx context.Context, userID ID, keepID string) error
+		RevokeAllSessions(ctx context.Context, userID ID) error
+
+		// CreateTOTP replaces unconfirmed secret of the user, if user already
+		// has a confirmed one it returns ErrTOTPAlreadyEnabled.
+		CreateTOTP(ctx context.Context, t TOTP) error
+		// ReadTOTP returns ErrTOTPNotFound if user has no secret at all.
+		ReadTOTP(ctx context.Context, userID ID) (TOTP, error)
+		// ConfirmTOTP enables the secret and stores hashes of recovery codes.
+		ConfirmTOTP(ctx context.Context, userID ID, recoveryCodes []string) error
+		ReplaceRecoveryCodes(ctx context.Context, userID ID, recoveryCodes []string) error
+		// UseRecoveryCode marks the code as used, it returns
+		// ErrInvalidCode if there is no such unused code.
+		UseRecoveryCode(ctx context.Context, userID ID, recoveryCode string) error
+		DeleteTOTP(ctx context.Context, userID ID) error
+
+		// CreatePasskey returns ErrPasskeyExists if
+		// credential with the same id is already stored.
+		CreatePasskey(ctx context.Context, p Passkey) error
+		// ReadPasskey returns ErrPasskeyNotFound if there is no such passkey.
+		ReadPasskey(ctx context.Context, id string) (Passkey, error)
+		ReadPasskeys(ctx context.Context, userID ID) ([]Passkey, error)
+		UpdatePasskeySignCount(ctx context.Context, id string, signCount uint32, usedAt time.Time) error
+		// DeletePasskey returns ErrPasskeyNotFound if user has no such passkey.
+		DeletePasskey(ctx context.Context, userID ID, id string) error
+
+		// CreateIdentity returns ErrIdentityExists if the identity is linked
+		// to anyone already or user has another identity at the same provider.
+		CreateIdentity(ctx context.Context, i Identity) error
+		// CreateWithIdentity creates user and links the identity to them in one
+		// transaction, if the identity is taken it creates nothing and returns
+		// ErrIdentityExists.
+		CreateWithIdentity(ctx context.Context, u User, i Identity) (ID, error)
+		// ReadIdentity returns ErrIdentityNotFound if nobody has linked it.
+		ReadIdentity(ctx context.Context, provider, subject string) (Identity, error)
+		ReadIdentities(ctx context.Context, userID ID) ([]Identity, error)
+		// DeleteIdentity returns ErrIdentityNotFound if user has no identity at provider.
+		DeleteIdentity(ctx context.Context, userID ID, provider string) error
+
+		CreateOAuthClient(ctx context.Context, c OAuthClient) error
+		// ReadOAuthClient returns ErrClientNotFound if there is no such client.
+		ReadOAuthClient(ctx context.Context, id string) (OAuthClient, error)
+		ReadOAuthClients(ctx context.Context) ([]OAuthClient, error)
+		// DeleteOAuthClient returns ErrClientNotFound if there is no such client,
+		// consents given to the client are deleted with it.
+		DeleteOAuthClient(ctx context.Context, id string) error
+
+		CreateAPIKey(ctx context.Context, k APIKey) error
+		// ReadAPIKey returns ErrAPIKeyNotFound if there is no such key, revoked keys are returned too.
+		ReadAPIKey(ctx context.Context, id string) (APIKey, error)
+		ReadAPIKeys(ctx context.Context, owner APIKeyOwner) ([]APIKey, error)
+		// RevokeAPIKey returns ErrAPIKeyNotFound if there is no such key that is not revoked yet.
+		RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+		TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+
+		// SaveConsent adds scopes to the consent user gave to the client before.
+		SaveConsent(ctx context.Context, c Consent) error
+		// ReadConsent returns ErrConsentNotFound if user has not allowed anything to the client.
+		ReadConsent(ctx context.Context, userID ID, clientID string) (Consent, error)
+		ReadConsents(ctx context.Context, userID ID) ([]Consent, error)
+		// DeleteConsent returns ErrConsentNotFound if there is no such consent.
+		DeleteConsent(ctx context.Context, userID ID, clientID string) error
+
+		// RotateRefreshToken marks token with oldID as used and stores next in
+		// one transaction. If old token was already used it returns ErrRefreshTokenReused.
+		RotateRefreshToken(ctx context.Context, oldID string, next RefreshToken) error
+		// RevokeRefreshFamily revokes every token of the family
+		// and the session they belong to.
+		RevokeRefreshFamily(ctx context.Context, familyID string) error
 	}
 
 	SMSsender interface {
@@ -56,9 +258,58 @@ type (
 		Send(email, title, text string) error
 	}
 
+	// Every value in Cache has to have a lifetime, codes we send
+	// to users must not stay valid forever.
 	Cache interface {
-		Store(key, value string) error
+		Store(key, value string, ttl time.Duration) error
 		Get(key string) (value string, err error)
+		// Pop returns the value and deletes it in one go,
+		// so a value can be consumed only once.
+		Pop(key string) (value string, err error)
+		// Incr adds one to a counter under the key. ttl is applied
+		// only when the counter is created and is not prolonged after.
+		Incr(key string, ttl time.Duration) (int64, error)
+		Delete(key string) error
+	}
+
+	// RateLimitStore counts hits under a key,
+	// the counter is dropped after window passes.
+	RateLimitStore interface {
+		Hit(key string, window time.Duration) (int64, error)
+	}
+
+	// BreachedPasswords is a k-anonymity range lookup like the one of
+	// haveibeenpwned. Range gets first 5 hex characters of SHA-1 of a password
+	// and returns uppercase remaining 35 characters of every leaked
+	// password with this prefix and how many times it was seen.
+	BreachedPasswords interface {
+		Range(ctx context.Context, prefix string) (map[string]int64, error)
+	}
+
+	// WebAuthn does the cryptographic part of WebAuthn ceremonies.
+	// Options and responses are JSON of navigator.credentials
+	// with binary fields encoded as base64url.
+	WebAuthn interface {
+		RegistrationOptions(challenge []byte, u User, exclude []Passkey) ([]byte, error)
+		// VerifyRegistration returns the new passkey without user id and name
+		VerifyRegistration(challenge, response []byte) (Passkey, error)
+		AssertionOptions(challenge []byte, allow []Passkey) ([]byte, error)
+		// AssertedPasskey returns id of the passkey response claims to be made with
+		AssertedPasskey(response []byte) (string, error)
+		// VerifyAssertion returns the new sign count of the passkey
+		VerifyAssertion(challenge []byte, p Passkey, response []byte) (uint32, error)
+	}
+
+	// OIDCProvider is an OpenID Connect provider we are a client of.
+	OIDCProvider interface {
+		// Name is how clients pick the provider, like "google"
+		Name() string
+		// AuthCodeURL is where the user signs in, codeChallenge is S256 of PKCE verifier
+		AuthCodeURL(state, nonce, codeChallenge string) string
+		// Exchange trades the code for tokens and returns claims of
+		// the id token after checking its signature, issuer, audience and
+		// expiration. Nonce is left for the caller to check.
+		Exchange(ctx context.Context, code, codeVerifier string) (IDTokenClaims, error)
 	}
 
 	Logger interface {
@@ -67,11 +318,19 @@ type (
 	}
 
 	JWTmanager interface {
-		SetKey(key []byte)
 		SetExp(access, refresh time.Duration)
 
-		Generate(userID ID, roles []Role) (SignInOutput, error)
+		Generate(userID ID, roles []Role, permissions []string, grants []RoleGrant, familyID string) (SignInOutput, error)
+		// GenerateGrant makes tokens for an OAuth client, refresh
+		// token is made only when familyID is not empty.
+		GenerateGrant(grant TokenGrant, familyID string) (SignInOutput, error)
+		// GenerateIDToken makes an OpenID Connect id token for the client
+		GenerateIDToken(clientID, nonce string, info UserInfo) (string, error)
 		DecodeAccess(accessKey string) (AccessClaims, error)
 		DecodeRefresh(refreshKey string) (RefreshClaims, error)
+		// DecodeClientAssertion checks signature of the assertion with keys
+		// of the client, its audience and expiration. Replays are left for
+		// the caller to catch.
+		DecodeClientAssertion(assertion string, jwks []byte) (ClientAssertion, error)
 	}
 )
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Every sms we send costs us money, so requests for codes are limited
+// per destination, per country of the phone number, per client ip
+// and globally per day. Limits use fixed windows, a counter for
+// every window lives in RateLimitStore.
+
+type (
+	RateLimit struct {
+		Max    int64
+		Window time.Duration
+	}
+
+	// Zero limits are not checked
+	RateLimitConfig struct {
+		PerDestination RateLimit
+		PerCountry     RateLimit
+		PerIP          RateLimit
+		GlobalDaily    int64
+	}
+
+	RateLimiter struct {
+		store RateLimitStore
+		cfg   RateLimitConfig
+		now   func() time.Time
+	}
+
+	// RateLimitError tells when it makes sense to try again.
+	// errors.Is(err, ErrRateLimited) works with it.
+	RateLimitError struct {
+		Scope      string
+		RetryAfter time.Duration
+	}
+)
+
+const (
+	RateLimitScopeDestination = "destination"
+	RateLimitScopeCountry     = "country"
+	RateLimitScopeIP          = "ip"
+	RateLimitScopeGlobal      = "global"
+)
+
+var DefaultRateLimitConfig = RateLimitConfig{
+	PerDestination: RateLimit{Max: 5, Window: time.Hour},
+	PerCountry:     RateLimit{Max: 1000, Window: time.Hour},
+	PerIP:          RateLimit{Max: 20, Window: time.Hour},
+	GlobalDaily:    50000,
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %s limit, retry after %s", ErrRateLimited, e.Scope, e.RetryAfter)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+func NewRateLimiter(store RateLimitStore, cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+// NewCacheRateLimitStore keeps counters in Cache,
+// so limits are shared by all replicas that share the Cache.
+func NewCacheRateLimitStore(c Cache) RateLimitStore {
+	return cacheRateLimitStore{c}
+}
+
+type cacheRateLimitStore struct {
+	cache Cache
+}
+
+func (s cacheRateLimitStore) Hit(key string, window time.Duration) (int64, error) {
+	return s.cache.Incr(key, window)
+}
+
+// AllowSMS counts a request to send sms to phoneNumber.
+func (rl *RateLimiter) AllowSMS(ctx context.Context, phoneNumber string) error {
+	// otherwise every way to write the number would get its own limit
+	phoneNumber = normalizePhoneNumber(phoneNumber)
+	rules := []rateLimitRule{
+		{RateLimitScopeDestination, phoneNumber, rl.cfg.PerDestination},
+		{RateLimitScopeIP, ClientInfoFromContext(ctx).IP, rl.cfg.PerIP},
+		{RateLimitScopeGlobal, "sms", RateLimit{Max: rl.cfg.GlobalDaily, Window: time.Hour * 24}},
+	}
+	if code := callingCode(phoneNumber); code != "" {
+		rules = append(rules, rateLimitRule{RateLimitScopeCountry, code, rl.cfg.PerCountry})
+	}
+	return rl.allow(rules)
+}
+
+// AllowEmail counts a request to send email. Emails are cheap
+// so only destination and ip are limited.
+func (rl *RateLimiter) AllowEmail(ctx context.Context, email string) error {
+	return rl.allow([]rateLimitRule{
+		{RateLimitScopeDestination, strings.ToLower(email), rl.cfg.PerDestination},
+		{RateLimitScopeIP, ClientInfoFromContext(ctx).IP, rl.cfg.PerIP},
+	})
+}
+
+type rateLimitRule struct {
+	scope string
+	value string
+	limit RateLimit
+}
+
+// allow counts a hit for every rule and returns the error with
+// the longest wait if any of them is exceeded.
+func (rl *RateLimiter) allow(rules []rateLimitRule) error {
+	now := rl.now()
+	var exceeded *RateLimitError
+	for _, r := range rules {
+		if r.value == "" || r.limit.Max <= 0 || r.limit.Window <= 0 {
+			continue
+		}
+		window := now.UnixNano() / int64(r.limit.Window)
+		key := "ratelimit:" + r.scope + ":" + r.value + ":" + strconv.FormatInt(window, 10)
+		hits, err := rl.store.Hit(key, r.limit.Window)
+		if err != nil {
+			return fmt.Errorf("rate limit: %w", err)
+		}
+		if hits <= r.limit.Max {
+			continue
+		}
+		retryAfter := time.Unix(0, (window+1)*int64(r.limit.Window)).Sub(now)
+		if exceeded == nil || retryAfter > exceeded.RetryAfter {
+			exceeded = &RateLimitError{Scope: r.scope, RetryAfter: retryAfter}
+		}
+	}
+	if exceeded != nil {
+		return exceeded
+	}
+	return nil
+}
+
+// callingCode returns country calling code of a phone number in
+// international format, like 996 for +996702569123. Codes are 1 to 3
+// digits long and no code is a prefix of another one, so we only
+// have to know which ones are short.
+func callingCode(phoneNumber string) string {
+	digits := strings.TrimPrefix(strings.TrimPrefix(phoneNumber, "+"), "00")
+	for i := 0; i < len(digits) && i < 3; i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return ""
+		}
+	}
+	switch {
+	case len(digits) < 4:
+		return ""
+	case digits[0] == '1' || digits[0] == '7':
+		return digits[:1]
+	case twoDigitCallingCodes[digits[:2]]:
+		return digits[:2]
+	default:
+		return digits[:3]
+	}
+}
+
+var twoDigitCallingCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true,
+	"34": true, "36": true, "39": true, "40": true, "41": true, "43": true,
+	"44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true,
+	"64": true, "65": true, "66": true, "81": true, "82": true, "84": true,
+	"86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+}
+
+func (s *service) allowSMS(ctx context.Context, phoneNumber string) error {
+	if s.rateLimiter == nil {
+		return nil
+	}
+	return s.rateLimiter.AllowSMS(ctx, phoneNumber)
+}
+
+func (s *service) allowEmail(ctx context.Context, email string) error {
+	if s.rateLimiter == nil {
+		return nil
+	}
+	return s.rateLimiter.AllowEmail(ctx, email)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "testing"
+
+func TestCallingCode(t *testing.T) {
+	testCases := []struct {
+		phoneNumber string
+		want        string
+	}{
+		{phoneNumber: "+996702569123", want: "996"},
+		{phoneNumber: "+79161234567", want: "7"},
+		{phoneNumber: "+14155552671", want: "1"},
+		{phoneNumber: "+447911123456", want: "44"},
+		{phoneNumber: "00996702569123", want: "996"},
+		{phoneNumber: "+12", want: ""},
+		{phoneNumber: "pizza", want: ""},
+	}
+	for _, tc := range testCases {
+		if got := callingCode(tc.phoneNumber); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.phoneNumber, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	testCases := []struct {
+		phoneNumber string
+		want        string
+	}{
+		{phoneNumber: "+7 700 123-45-67", want: "+77001234567"},
+		{phoneNumber: "87001234567", want: "+77001234567"},
+		{phoneNumber: "+77001234567", want: "+77001234567"},
+		{phoneNumber: "77001234567", want: "+77001234567"},
+		{phoneNumber: "00996 (702) 569 123", want: "+996702569123"},
+		{phoneNumber: "0702569123", want: "0702569123"},
+		{phoneNumber: "pizza", want: "pizza"},
+	}
+	for _, tc := range testCases {
+		if got := normalizePhoneNumber(tc.phoneNumber); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.phoneNumber, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/memory"
+)
+
+func TestRateLimiter(t *testing.T) {
+	cfg := domain.RateLimitConfig{
+		PerDestination: domain.RateLimit{Max: 2, Window: time.Hour},
+		PerCountry:     domain.RateLimit{Max: 5, Window: time.Hour},
+		PerIP:          domain.RateLimit{Max: 3, Window: time.Hour},
+		GlobalDaily:    8,
+	}
+
+	testCases := []struct {
+		name  string
+		scope string
+
+		// send is called until it fails
+		send func(rl *domain.RateLimiter, i int) error
+	}{
+		{
+			name:  "same phone number",
+			scope: domain.RateLimitScopeDestination,
+			send: func(rl *domain.RateLimiter, i int) error {
+				return rl.AllowSMS(context.Background(), "+996702569123")
+			},
+		},
+		{
+			name:  "same phone number written differently",
+			scope: domain.RateLimitScopeDestination,
+			send: func(rl *domain.RateLimiter, i int) error {
+				formats := []string{"+7 700 123 45 67", "87001234567", "+7(700)123-45-67"}
+				return rl.AllowSMS(context.Background(), formats[i%len(formats)])
+			},
+		},
+		{
+			name:  "same ip",
+			scope: domain.RateLimitScopeIP,
+			send: func(rl *domain.RateLimiter, i int) error {
+				ctx := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "10.0.0.1"})
+				return rl.AllowEmail(ctx, fmt.Sprintf("pizzas%d@gmail.com", i))
+			},
+		},
+		{
+			name:  "same country",
+			scope: domain.RateLimitScopeCountry,
+			send: func(rl *domain.RateLimiter, i int) error {
+				return rl.AllowSMS(context.Background(), fmt.Sprintf("+99670256910%d", i))
+			},
+		},
+		{
+			name:  "everybody",
+			scope: domain.RateLimitScopeGlobal,
+			send: func(rl *domain.RateLimiter, i int) error {
+				countries := []int{44, 49, 33, 34, 39, 81, 82, 86, 90, 91, 92, 93}
+				return rl.AllowSMS(context.Background(), fmt.Sprintf("+%d702569123", countries[i%len(countries)]))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := memory.NewRateLimitStore(0, time.Minute)
+			defer store.Close()
+			rl := domain.NewRateLimiter(store, cfg)
+
+			var err error
+			for i := 0; i < 12 && err == nil; i++ {
+				err = tc.send(rl, i)
+			}
+			if !errors.Is(err, domain.ErrRateLimited) {
+				t.Fatalf("got %v, want %v", err, domain.ErrRateLimited)
+			}
+			var rlErr *domain.RateLimitError
+			if !errors.As(err, &rlErr) {
+				t.Fatalf("got %T, want *domain.RateLimitError", err)
+			}
+			if rlErr.Scope != tc.scope {
+				t.Errorf("got scope %q, want %q", rlErr.Scope, tc.scope)
+			}
+			if rlErr.RetryAfter <= 0 || rlErr.RetryAfter > time.Hour*24 {
+				t.Errorf("unexpected retry after %v", rlErr.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestRequestSignInIsRateLimited(t *testing.T) {
+	s, deps := newTestService(t, domain.WithRateLimiter(domain.NewRateLimiter(
+		domain.NewCacheRateLimitStore(memory.NewCache(0, time.Minute)),
+		domain.RateLimitConfig{PerDestination: domain.RateLimit{Max: 1, Window: time.Hour}},
+	)))
+	ctx := context.Background()
+	phone := "+996702569123"
+
+	deps.expectCode(phone)
+	if err := s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone}); err != nil {
+		t.Fatal(err)
+	}
+	// sms sender expects only one call, the second one must not reach it
+	err := s.RequestSignIn(ctx, domain.RequestSignInInput{PhoneNumber: phone})
+	if !errors.Is(err, domain.ErrRateLimited) {
+		t.Errorf("got %v, want %v", err, domain.ErrRateLimited)
+	}
+}
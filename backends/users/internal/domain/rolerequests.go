@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Role requests replace asking admins in chat. Requester says why they
+// need the role, an admin approves or rejects it with a comment and the
+// requester gets an sms or an email about it.
+
+const roleRequestsMaxLimit = 100
+
+func (s *service) RequestRole(ctx context.Context, inp RequestRoleInput) (RoleRequest, error) {
+	if inp.Role == RoleOwner {
+		return RoleRequest{}, fmt.Errorf("requestRole(): owners can be asigned only manualy %w", ErrInvalidRoleRequest)
+	}
+	justification := strings.TrimSpace(inp.Justification)
+	if l := utf8.RuneCountInString(justification); l == 0 || l > 1000 {
+		return RoleRequest{}, fmt.Errorf("requestRole(): %w: justification has to be from 1 to 1000 characters", ErrInvalidRoleRequest)
+	}
+	scope, err := normalizeScope(inp.Scope)
+	if err != nil {
+		return RoleRequest{}, fmt.Errorf("requestRole(): %w", err)
+	}
+	if _, err := s.repo.ReadRole(ctx, inp.Role); err != nil {
+		return RoleRequest{}, fmt.Errorf("requestRole(): %w", err)
+	}
+
+	id, err := newTokenID()
+	if err != nil {
+		return RoleRequest{}, fmt.Errorf("requestRole(): could not generate id: %w", err)
+	}
+	req := RoleRequest{
+		ID:            id,
+		UserID:        inp.UserID,
+		Role:          inp.Role,
+		Scope:         scope,
+		Justification: justification,
+		Status:        RoleRequestPending,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := s.repo.CreateRoleRequest(ctx, req); err != nil {
+		return RoleRequest{}, fmt.Errorf("requestRole(): %w", err)
+	}
+	return req, nil
+}
+
+func (s *service) RoleRequest(ctx context.Context, id string) (RoleRequest, error) {
+	req, err := s.repo.ReadRoleRequest(ctx, id)
+	if err != nil {
+		return RoleRequest{}, fmt.Errorf("roleRequest(): %w", err)
+	}
+	return req, nil
+}
+
+func (s *service) RoleRequests(ctx context.Context, inp ReadRoleRequestsInput) ([]RoleRequest, error) {
+	switch inp.Status {
+	case "", RoleRequestPending, RoleRequestApproved, RoleRequestRejected:
+	default:
+		return nil, fmt.Errorf("roleRequests(): %w: unknown status %q", ErrInvalidRoleRequest, inp.Status)
+	}
+	if inp.Limit == 0 || inp.Limit > roleRequestsMaxLimit {
+		inp.Limit = roleRequestsMaxLimit
+	}
+	requests, err := s.repo.ReadRoleRequests(ctx, inp)
+	if err != nil {
+		return nil, fmt.Errorf("roleRequests(): %w", err)
+	}
+	return requests, nil
+}
+
+// ReviewRoleRequest writes the decision and grants the role in one
+// transaction that first claims the request while it is still pending.
+// If two admins review at once the losing one gets ErrRoleRequestNotPending
+// and nothing is granted.
+func (s *service) ReviewRoleRequest(ctx context.Context, inp ReviewRoleRequestInput) (RoleRequest, error) {
+	comment := strings.TrimSpace(inp.Comment)
+	if utf8.RuneCountInString(comment) > 1000 {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w: comment can not be longer than 1000 characters", ErrInvalidRoleRequest)
+	}
+	req, err := s.repo.ReadRoleRequest(ctx, inp.ID)
+	if err != nil {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", err)
+	}
+	if req.Status != RoleRequestPending {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", ErrRoleRequestNotPending)
+	}
+
+	req.Status = RoleRequestRejected
+	// the role is granted by repository together with the review,
+	// so a request somebody else has just rejected grants nothing
+	var grant *RoleGrant
+	if inp.Approve {
+		req.Status = RoleRequestApproved
+		g, err := s.roleGrant(ctx, AddRoleInput{
+			UserID: req.UserID,
+			Role:   req.Role,
+			Scope:  req.Scope,
+			Reason: req.Justification,
+		})
+		if err != nil {
+			return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", err)
+		}
+		grant = &g
+	}
+	if caller, ok := CallerFromContext(ctx); ok && !caller.Scoped() {
+		req.ReviewerID = caller.UserID
+	}
+	now := time.Now().UTC()
+	req.Comment, req.ReviewedAt = comment, &now
+	if err := s.repo.ReviewRoleRequest(ctx, req, grant); err != nil {
+		return RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", err)
+	}
+
+	s.notifyRoleRequestReviewed(ctx, req)
+	return req, nil
+}
+
+// notifyRoleRequestReviewed only logs failures, the decision is already made
+func (s *service) notifyRoleRequestReviewed(ctx context.Context, req RoleRequest) {
+	message := RoleRequestRejectedMessage
+	if req.Status == RoleRequestApproved {
+		message = RoleRequestApprovedMessage
+	}
+	if req.Comment != "" {
+		message += ":" + req.Comment
+	}
+
+	u, err := s.repo.Read(ctx, req.UserID)
+	switch {
+	case err != nil:
+	case u.PhoneNumber != "":
+		err = s.sms.Send(u.PhoneNumber, RoleRequestReviewedTitle, message)
+	case u.Email != "":
+		err = s.emailer.Send(u.Email, RoleRequestReviewedTitle, message)
+	}
+	if err != nil {
+		s.logger.Errorf("could not notify user %s about role request %s: %s",
+			strconv.FormatUint(uint64(req.UserID), 10), req.ID, err.Error())
+	}
+}
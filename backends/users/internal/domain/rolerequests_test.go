@@ -0,0 +1,149 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestRequestRole(t *testing.T) {
+	testCases := []struct {
+		name string
+		inp  domain.RequestRoleInput
+		err  error
+	}{
+		{
+			name: "request role in a store",
+			inp:  domain.RequestRoleInput{UserID: 3, Role: domain.RoleDeliveryMan, Scope: "store:3", Justification: "I have a bike"},
+		},
+		{
+			name: "fail without justification",
+			inp:  domain.RequestRoleInput{UserID: 3, Role: domain.RoleDeliveryMan, Justification: "  "},
+			err:  domain.ErrInvalidRoleRequest,
+		},
+		{
+			name: "fail with owner role",
+			inp:  domain.RequestRoleInput{UserID: 3, Role: domain.RoleOwner, Justification: "please"},
+			err:  domain.ErrInvalidRoleRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			if tc.err == nil {
+				deps.repo.EXPECT().ReadRole(gomock.Any(), tc.inp.Role).Return(domain.RoleDefinition{ID: tc.inp.Role}, nil)
+				deps.repo.EXPECT().CreateRoleRequest(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			req, err := s.RequestRole(context.Background(), tc.inp)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && (req.ID == "" || req.Status != domain.RoleRequestPending || req.Scope != tc.inp.Scope) {
+				t.Errorf("got %+v", req)
+			}
+		})
+	}
+}
+
+func TestReviewRoleRequest(t *testing.T) {
+	pending := domain.RoleRequest{ID: "a", UserID: 3, Role: domain.RoleDeliveryMan, Scope: "store:3", Justification: "I have a bike", Status: domain.RoleRequestPending}
+	reviewed := pending
+	reviewed.Status = domain.RoleRequestRejected
+
+	testCases := []struct {
+		name    string
+		inp     domain.ReviewRoleRequestInput
+		prepare func(deps testDeps)
+		status  domain.RoleRequestStatus
+		err     error
+	}{
+		{
+			name: "approve grants role and sends sms",
+			inp:  domain.ReviewRoleRequestInput{ID: "a", Approve: true, Comment: "welcome"},
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadRoleRequest(gomock.Any(), "a").Return(pending, nil)
+				deps.repo.EXPECT().ReadRole(gomock.Any(), domain.RoleDeliveryMan).Return(domain.RoleDefinition{ID: domain.RoleDeliveryMan}, nil)
+				deps.repo.EXPECT().ReviewRoleRequest(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).
+					DoAndReturn(func(_ context.Context, _ domain.RoleRequest, g *domain.RoleGrant) error {
+						if g.Role != domain.RoleDeliveryMan || g.Scope != "store:3" || g.GrantedBy != 2 || g.Reason != pending.Justification {
+							t.Errorf("unexpected grant %+v", g)
+						}
+						return nil
+					})
+				deps.repo.EXPECT().Read(gomock.Any(), domain.ID(3)).Return(domain.User{ID: 3, PhoneNumber: "+996700000000"}, nil)
+				deps.sms.EXPECT().Send("+996700000000", domain.RoleRequestReviewedTitle, gomock.Any()).
+					DoAndReturn(func(_, _, text string) error {
+						if !strings.Contains(text, "welcome") {
+							t.Errorf("comment is not in %q", text)
+						}
+						return nil
+					})
+			},
+			status: domain.RoleRequestApproved,
+		},
+		{
+			name: "reject only emails",
+			inp:  domain.ReviewRoleRequestInput{ID: "a"},
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadRoleRequest(gomock.Any(), "a").Return(pending, nil)
+				deps.repo.EXPECT().ReviewRoleRequest(gomock.Any(), gomock.Any(), gomock.Nil()).Return(nil)
+				deps.repo.EXPECT().Read(gomock.Any(), domain.ID(3)).Return(domain.User{ID: 3, Email: "jane@gmail.com"}, nil)
+				deps.emailer.EXPECT().Send("jane@gmail.com", domain.RoleRequestReviewedTitle, domain.RoleRequestRejectedMessage).Return(nil)
+			},
+			status: domain.RoleRequestRejected,
+		},
+		{
+			name: "failed notification does not fail review",
+			inp:  domain.ReviewRoleRequestInput{ID: "a"},
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadRoleRequest(gomock.Any(), "a").Return(pending, nil)
+				deps.repo.EXPECT().ReviewRoleRequest(gomock.Any(), gomock.Any(), gomock.Nil()).Return(nil)
+				deps.repo.EXPECT().Read(gomock.Any(), domain.ID(3)).Return(domain.User{ID: 3, Email: "jane@gmail.com"}, nil)
+				deps.emailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("smtp is down"))
+				deps.logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+			},
+			status: domain.RoleRequestRejected,
+		},
+		{
+			// somebody rejected it after we read it, repository grants nothing then
+			name: "fail when approving request that was just rejected",
+			inp:  domain.ReviewRoleRequestInput{ID: "a", Approve: true},
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadRoleRequest(gomock.Any(), "a").Return(pending, nil)
+				deps.repo.EXPECT().ReadRole(gomock.Any(), domain.RoleDeliveryMan).Return(domain.RoleDefinition{ID: domain.RoleDeliveryMan}, nil)
+				deps.repo.EXPECT().ReviewRoleRequest(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).Return(domain.ErrRoleRequestNotPending)
+			},
+			err: domain.ErrRoleRequestNotPending,
+		},
+		{
+			name: "fail when already reviewed",
+			inp:  domain.ReviewRoleRequestInput{ID: "a", Approve: true},
+			prepare: func(deps testDeps) {
+				deps.repo.EXPECT().ReadRoleRequest(gomock.Any(), "a").Return(reviewed, nil)
+			},
+			err: domain.ErrRoleRequestNotPending,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			tc.prepare(deps)
+			ctx := domain.WithCaller(context.Background(), domain.AccessClaims{UserID: 2, Roles: []domain.Role{domain.RoleAdmin}})
+
+			req, err := s.ReviewRoleRequest(ctx, tc.inp)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && (req.Status != tc.status || req.ReviewerID != 2 || req.ReviewedAt == nil) {
+				t.Errorf("got %+v", req)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Permissions look like resource:action, other services check their own
+// ones in access tokens, so we do not keep a list of every permission.
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersWrite  = "users:write"
+	PermissionRolesAssign = "roles:assign"
+	PermissionRolesManage = "roles:manage"
+)
+
+var permissionFormat = regexp.MustCompile(`^[a-z][a-z_]*:[a-z][a-z_]*$`)
+
+// BuiltIn roles are the ones we have constants for,
+// the rest were made by admins.
+func (r Role) BuiltIn() bool {
+	return r <= RoleUser
+}
+
+// HasPermission is true for owners whatever their roles give
+func (c AccessClaims) HasPermission(permission string) bool {
+	return hasRole(c.Roles, RoleOwner) || contains(c.Permissions, permission)
+}
+
+func (s *service) Roles(ctx context.Context) ([]RoleDefinition, error) {
+	roles, err := s.repo.ReadRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("roles(): %w", err)
+	}
+	return roles, nil
+}
+
+func (s *service) CreateRole(ctx context.Context, inp CreateRoleInput) (RoleDefinition, error) {
+	role := RoleDefinition{
+		Name:        strings.ToLower(strings.TrimSpace(inp.Name)),
+		Description: strings.TrimSpace(inp.Description),
+	}
+	if l := utf8.RuneCountInString(role.Name); l == 0 || l > 50 {
+		return RoleDefinition{}, fmt.Errorf("createRole(): %w: name has to be from 1 to 50 characters", ErrInvalidRole)
+	}
+	permissions, err := normalizePermissions(inp.Permissions)
+	if err != nil {
+		return RoleDefinition{}, fmt.Errorf("createRole(): %w", err)
+	}
+	role.Permissions = permissions
+
+	role.ID, err = s.repo.CreateRole(ctx, role)
+	if err != nil {
+		return RoleDefinition{}, fmt.Errorf("createRole(): %w", err)
+	}
+	return role, nil
+}
+
+// SetRolePermissions can not change owners, they may do anything anyway
+func (s *service) SetRolePermissions(ctx context.Context, role Role, permissions []string) error {
+	if role == RoleOwner {
+		return fmt.Errorf("setRolePermissions(): %w", ErrNotAllowed)
+	}
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return fmt.Errorf("setRolePermissions(): %w", err)
+	}
+	if err := s.repo.SetRolePermissions(ctx, role, permissions); err != nil {
+		return fmt.Errorf("setRolePermissions(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) DeleteRole(ctx context.Context, role Role) error {
+	if role.BuiltIn() {
+		return fmt.Errorf("deleteRole(): built in roles stay: %w", ErrNotAllowed)
+	}
+	if err := s.repo.DeleteRole(ctx, role); err != nil {
+		return fmt.Errorf("deleteRole(): %w", err)
+	}
+	return nil
+}
+
+// normalizePermissions checks format and drops duplicates
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, v := range permissions {
+		v = strings.TrimSpace(v)
+		if !permissionFormat.MatchString(v) {
+			return nil, fmt.Errorf("%w: permission %q is not like resource:action", ErrInvalidRole, v)
+		}
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
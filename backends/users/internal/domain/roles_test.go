@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestCreateRole(t *testing.T) {
+	testCases := []struct {
+		name string
+		inp  domain.CreateRoleInput
+		want []string
+		err  error
+	}{
+		{
+			name: "permissions are sorted without duplicates",
+			inp:  domain.CreateRoleInput{Name: "Support", Permissions: []string{"users:read", "orders:read", "users:read"}},
+			want: []string{"orders:read", "users:read"},
+		},
+		{
+			name: "fail without name",
+			inp:  domain.CreateRoleInput{Name: " ", Permissions: []string{"users:read"}},
+			err:  domain.ErrInvalidRole,
+		},
+		{
+			name: "fail with permission that is not resource:action",
+			inp:  domain.CreateRoleInput{Name: "support", Permissions: []string{"everything"}},
+			err:  domain.ErrInvalidRole,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			if tc.err == nil {
+				deps.repo.EXPECT().CreateRole(gomock.Any(), domain.RoleDefinition{Name: "support", Permissions: tc.want}).Return(domain.Role(5), nil)
+			}
+
+			role, err := s.CreateRole(context.Background(), tc.inp)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && (role.ID != 5 || !reflect.DeepEqual(role.Permissions, tc.want)) {
+				t.Errorf("got %+v", role)
+			}
+		})
+	}
+}
+
+func TestBuiltInRoles(t *testing.T) {
+	s, _ := newTestService(t)
+
+	if err := s.DeleteRole(context.Background(), domain.RoleModerator); !errors.Is(err, domain.ErrNotAllowed) {
+		t.Errorf("expected %v, got %v", domain.ErrNotAllowed, err)
+	}
+	if err := s.SetRolePermissions(context.Background(), domain.RoleOwner, nil); !errors.Is(err, domain.ErrNotAllowed) {
+		t.Errorf("expected %v, got %v", domain.ErrNotAllowed, err)
+	}
+}
@@ -2,14 +2,13 @@ package domain
 
 import (
 	"context"
-	"crypto/rand"
+	"errors"
 	"fmt"
 	"net/mail"
 	"reflect"
+	"strconv"
 	"time"
 	"unicode/utf8"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type service struct {
@@ -19,6 +18,15 @@ type service struct {
 	emailer    Emailer
 	logger     Logger
 	jwtManager JWTmanager
+
+	rateLimiter    *RateLimiter
+	passwords      passwordHasher
+	passwordPolicy *PasswordPolicy
+	lockout        LockoutConfig
+	// users with any of these roles can not sign in without TOTP
+	secondFactorRoles map[Role]bool
+	webauthn          WebAuthn
+	oidcProviders     map[string]OIDCProvider
 }
 
 func NewService(
@@ -28,7 +36,7 @@ func NewService(
 	c Cache,
 	l Logger,
 	j JWTmanager,
-	jwtKey []byte,
+	opts ...Option,
 ) (Service, error) {
 	if v := reflect.ValueOf(r); v.Kind() == reflect.Pointer &&
 		reflect.ValueOf(r).IsNil() {
@@ -56,15 +64,23 @@ func NewService(
 	}
 
 	j.SetExp(AuthAccessExp, AuthRefreshExp)
-	j.SetKey(jwtKey)
 
-	return &service{
+	srv := &service{
 		repo:       r,
+		cache:      c,
 		sms:        s,
 		emailer:    m,
 		logger:     l,
 		jwtManager: j,
-	}, nil
+		passwords:  passwordHasher{params: DefaultPasswordParams},
+
+		passwordPolicy: NewPasswordPolicy(DefaultPasswordRules()...),
+		lockout:        DefaultLockoutConfig,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv, nil
 }
 
 func (s *service) Read(ctx context.Context, id ID) (User, error) {
@@ -100,34 +116,38 @@ func (s *service) ReadAll(ctx context.Context, cfg ReadAllInput) ([]User, error)
 }
 
 func (s *service) RequestSignUp(ctx context.Context, inp RequestSignUpInput) error {
-	code := make([]byte, CodeLength)
-	if _, err := rand.Read(code); err != nil {
-		return fmt.Errorf("requestSignUp(): %w", err)
-	}
-
+	inp.PhoneNumber = normalizePhoneNumber(inp.PhoneNumber)
 	switch {
 	case utf8.RuneCountInString(inp.PhoneNumber) != 0:
+		if err := s.allowSMS(ctx, inp.PhoneNumber); err != nil {
+			return fmt.Errorf("requestSignUp(): %w", err)
+		}
+		code, err := s.issueCode(otpSignUp, inp.PhoneNumber)
+		if err != nil {
+			return fmt.Errorf("requestSignUp(): %w", err)
+		}
 		if err := s.sms.Send(
 			inp.PhoneNumber,
 			RequestSignUpSMSTitle,
-			RequestSignUpSMSMessage+":"+string(code),
+			RequestSignUpSMSMessage+":"+code,
 		); err != nil {
 			return fmt.Errorf("requestSignUp(): could not send sms %w", err)
 		}
-		if err := s.cache.Store(inp.PhoneNumber, string(code)); err != nil {
-			return fmt.Errorf("requestSignUp(): could not cache %w", err)
-		}
 	case utf8.RuneCountInString(inp.Email) != 0:
+		if err := s.allowEmail(ctx, inp.Email); err != nil {
+			return fmt.Errorf("requestSignUp(): %w", err)
+		}
+		code, err := s.issueCode(otpSignUp, inp.Email)
+		if err != nil {
+			return fmt.Errorf("requestSignUp(): %w", err)
+		}
 		if err := s.emailer.Send(
 			inp.Email,
 			RequestSignUpEmailTitle,
-			RequestSignUpEmailMessage+":"+string(code),
+			RequestSignUpEmailMessage+":"+code,
 		); err != nil {
 			return fmt.Errorf("requestSignUp(): could not send email %w", err)
 		}
-		if err := s.cache.Store(inp.Email, string(code)); err != nil {
-			return fmt.Errorf("requestSignUp(): could not cache %w", err)
-		}
 	default:
 		return ErrInvalidRequestSignUpInput
 	}
@@ -135,32 +155,41 @@ func (s *service) RequestSignUp(ctx context.Context, inp RequestSignUpInput) err
 }
 
 func (s *service) SignUp(ctx context.Context, inp SignUpInput) (SignInOutput, error) {
+	inp.PhoneNumber = normalizePhoneNumber(inp.PhoneNumber)
 	switch {
 	case utf8.RuneCountInString(inp.PhoneNumber) != 0:
-		code, err := s.cache.Get(inp.PhoneNumber)
-		if err != nil {
+		if err := s.consumeCode(otpSignUp, inp.PhoneNumber, inp.Code); err != nil {
 			return SignInOutput{}, fmt.Errorf("signUp(): %w", err)
 		}
-		if code != inp.Code {
-			return SignInOutput{}, fmt.Errorf("signUp(): %w", ErrInvalidCode)
-		}
 	case utf8.RuneCountInString(inp.Email) != 0:
-		code, err := s.cache.Get(inp.Email)
-		if err != nil {
+		if err := s.consumeCode(otpSignUp, inp.Email, inp.Code); err != nil {
 			return SignInOutput{}, fmt.Errorf("signUp(): %w", err)
 		}
-		if code != inp.Code {
-			return SignInOutput{}, fmt.Errorf("signUp(): %w", ErrInvalidCode)
-		}
 	default:
 		return SignInOutput{}, ErrInvalidSignUpInput
 	}
 
+	// password is checked after the code, so the code is spent
+	// even if user has to come up with a better password
+	var passwordHash string
+	if inp.Password != "" {
+		hash, err := s.checkPassword(ctx, inp.Password, User{
+			FullName:    inp.FullName,
+			Email:       inp.Email,
+			PhoneNumber: inp.PhoneNumber,
+		})
+		if err != nil {
+			return SignInOutput{}, fmt.Errorf("signUp(): %w", err)
+		}
+		passwordHash = hash
+	}
+
 	var (
 		u = User{
 			FullName:    inp.FullName,
 			Email:       inp.Email,
 			PhoneNumber: inp.PhoneNumber,
+			Password:    passwordHash,
 			Addresses:   []Address{inp.Address},
 			Roles:       []Role{RoleUser},
 			BirthDate:   inp.BirthDate,
@@ -173,42 +202,46 @@ func (s *service) SignUp(ctx context.Context, inp SignUpInput) (SignInOutput, er
 		return SignInOutput{}, fmt.Errorf("signUp(): %w", err)
 	}
 
-	claims, err := s.jwtManager.Generate(u.ID, u.Roles)
+	claims, err := s.issueTokens(ctx, u)
 	if err != nil {
-		return claims, fmt.Errorf("signUp(): could not generate jwt due to: %w", err)
+		return claims, fmt.Errorf("signUp(): %w", err)
 	}
 	return claims, nil
 }
 
 func (s *service) RequestSignIn(ctx context.Context, inp RequestSignInInput) error {
-	code := make([]byte, CodeLength)
-	if _, err := rand.Read(code); err != nil {
-		return fmt.Errorf("requestSignIn(): %w", err)
-	}
-
+	inp.PhoneNumber = normalizePhoneNumber(inp.PhoneNumber)
 	switch {
 	case utf8.RuneCountInString(inp.PhoneNumber) != 0:
+		if err := s.allowSMS(ctx, inp.PhoneNumber); err != nil {
+			return fmt.Errorf("requestSignIn(): %w", err)
+		}
+		code, err := s.issueCode(otpSignIn, inp.PhoneNumber)
+		if err != nil {
+			return fmt.Errorf("requestSignIn(): %w", err)
+		}
 		if err := s.sms.Send(
 			inp.PhoneNumber,
 			RequestSignInSMSTitle,
-			RequestSignInSMSMessage+":"+string(code),
+			RequestSignInSMSMessage+":"+code,
 		); err != nil {
 			return fmt.Errorf("requestSignIn(): could not send sms %w", err)
 		}
-		if err := s.cache.Store(inp.PhoneNumber, string(code)); err != nil {
+	case utf8.RuneCountInString(inp.Email) != 0:
+		if err := s.allowEmail(ctx, inp.Email); err != nil {
+			return fmt.Errorf("requestSignIn(): %w", err)
+		}
+		code, err := s.issueCode(otpSignIn, inp.Email)
+		if err != nil {
 			return fmt.Errorf("requestSignIn(): %w", err)
 		}
-	case utf8.RuneCountInString(inp.Email) != 0:
 		if err := s.emailer.Send(
 			inp.Email,
 			RequestSignInEmailTitle,
-			RequestSignInEmailMessage+":"+string(code),
+			RequestSignInEmailMessage+":"+code,
 		); err != nil {
 			return fmt.Errorf("requestSignIn(): could not send email %w", err)
 		}
-		if err := s.cache.Store(inp.Email, string(code)); err != nil {
-			return fmt.Errorf("requestSignIn(): %w", err)
-		}
 	default:
 		return ErrInvalidSignInInput
 	}
@@ -216,28 +249,20 @@ func (s *service) RequestSignIn(ctx context.Context, inp RequestSignInInput) err
 }
 
 func (s *service) SignIn(ctx context.Context, inp SignInInput) (SignInOutput, error) {
+	inp.PhoneNumber = normalizePhoneNumber(inp.PhoneNumber)
 	var (
 		u   User
-		code string
 		err error
 	)
 	switch {
 	case utf8.RuneCountInString(inp.PhoneNumber) != 0:
-		code, err = s.cache.Get(inp.PhoneNumber)
-		if err != nil {
-			return SignInOutput{}, fmt.Errorf("signIn(): could read from cache %w", err)
-		}
-		if code != inp.Code {
-			return SignInOutput{}, fmt.Errorf("signIn(): %w", ErrInvalidCode)
+		if err := s.consumeCode(otpSignIn, inp.PhoneNumber, inp.Code); err != nil {
+			return SignInOutput{}, fmt.Errorf("signIn(): %w", err)
 		}
 		u, err = s.repo.ReadByPhoneNumber(ctx, inp.PhoneNumber)
 	case utf8.RuneCountInString(inp.Email) != 0:
-		code, err = s.cache.Get(inp.Email)
-		if err != nil {
-			return SignInOutput{}, fmt.Errorf("signIn(): could read from cache %w", err)
-		}
-		if code != inp.Code {
-			return SignInOutput{}, fmt.Errorf("signIn(): %w", ErrInvalidCode)
+		if err := s.consumeCode(otpSignIn, inp.Email, inp.Code); err != nil {
+			return SignInOutput{}, fmt.Errorf("signIn(): %w", err)
 		}
 		u, err = s.repo.ReadByEmail(ctx, inp.Email)
 	default:
@@ -248,56 +273,102 @@ func (s *service) SignIn(ctx context.Context, inp SignInInput) (SignInOutput, er
 		return SignInOutput{}, fmt.Errorf("signIn(): could not read from db %w", err)
 	}
 
-	claims, err := s.jwtManager.Generate(u.ID, u.Roles)
+	claims, err := s.completeSignIn(ctx, u)
 	if err != nil {
-		return claims, fmt.Errorf("signIn(): could not generate jwt due to: %w", err)
+		return claims, fmt.Errorf("signIn(): %w", err)
 	}
 	return claims, nil
 }
 
 func (s *service) SignInEmailPassword(ctx context.Context, email, password string) (SignInOutput, error) {
+	if err := s.checkIPLock(ctx); err != nil {
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", err)
+	}
 	u, err := s.repo.ReadByEmail(ctx, email)
+	if errors.Is(err, ErrNoUsers) {
+		if err := s.failIPAttempt(ctx); err != nil {
+			return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", err)
+		}
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", ErrInvalidCredentials)
+	}
 	if err != nil {
 		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): could not read from db %w", err)
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
-		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): password is incorrect %w", err)
+	// locked account does not even check the password,
+	// otherwise guessing could go on during the lock
+	if err := s.checkAccountLock(u.ID); err != nil {
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", err)
+	}
+	// users who signed up with codes only have no password at all
+	if u.Password == "" {
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", s.failPasswordAttempt(ctx, u))
 	}
-	claims, err := s.jwtManager.Generate(u.ID, u.Roles)
+	ok, rehash, err := s.passwords.verify(u.Password, password)
+	if err != nil {
+		s.logger.Errorf("could not verify password of user %s: %s",
+			strconv.FormatUint(uint64(u.ID), 10), err.Error())
+	}
+	if !ok {
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", s.failPasswordAttempt(ctx, u))
+	}
+	if err := s.resetPasswordFailures(u.ID); err != nil {
+		return SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", err)
+	}
+	if rehash {
+		s.rehashPassword(ctx, u.ID, password)
+	}
+	claims, err := s.completeSignIn(ctx, u)
 	if err != nil {
 		return claims, fmt.Errorf("signInEmailPassword(): %w", err)
 	}
 	return claims, nil
 }
 
-func (s *service) Refresh(ctx context.Context, refreshKey string) (SignInOutput, error) {
-	refClaims, err := s.jwtManager.DecodeRefresh(refreshKey)
-	if err != nil {
-		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+// checkCurrentPassword is for signed in users confirming sensitive
+// changes, failures count towards the lockout like in sign in
+func (s *service) checkCurrentPassword(ctx context.Context, u User, password string) error {
+	if err := s.checkAccountLock(u.ID); err != nil {
+		return err
 	}
-	u, err := s.repo.Read(ctx, refClaims.UserID)
-	if err != nil {
-		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	if u.Password == "" {
+		return s.failPasswordAttempt(ctx, u)
 	}
-	claims, err := s.jwtManager.Generate(u.ID, u.Roles)
+	ok, _, err := s.passwords.verify(u.Password, password)
 	if err != nil {
-		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+		s.logger.Errorf("could not verify password of user %s: %s",
+			strconv.FormatUint(uint64(u.ID), 10), err.Error())
 	}
-	return claims, nil
+	if !ok {
+		return s.failPasswordAttempt(ctx, u)
+	}
+	return s.resetPasswordFailures(u.ID)
 }
 
-// TODO: not sure if we need role validation here
-// could be easier to do it in our transport layer
-// since every request will have jwt with roles
+// rehashPassword replaces outdated hash, sign in
+// should not fail because of it so errors are only logged
+func (s *service) rehashPassword(ctx context.Context, userID ID, password string) {
+	hash, err := s.passwords.hash(password)
+	if err == nil {
+		err = s.repo.UpdatePassword(ctx, userID, hash)
+	}
+	if err != nil {
+		s.logger.Errorf("could not rehash password of user %s: %s",
+			strconv.FormatUint(uint64(userID), 10), err.Error())
+	}
+}
 
-func (s *service) AddRole(ctx context.Context, userID ID, role Role) error {
-	if role == RoleOwner {
-		return fmt.Errorf("addRole(): owners can be asigned only manualy %w", ErrInvalidRole)
+func (s *service) AddRole(ctx context.Context, inp AddRoleInput) error {
+	grant, err := s.roleGrant(ctx, inp)
+	if err != nil {
+		return fmt.Errorf("addRole(): %w", err)
 	}
-	return fmt.Errorf("addRole(): %w", s.repo.AddRole(ctx, userID, role))
+	if err := s.repo.AddRole(ctx, inp.UserID, grant); err != nil {
+		return fmt.Errorf("addRole(): %w", err)
+	}
+	return nil
 }
 
-func (s *service) RemoveRole(ctx context.Context, userID ID, role Role) error {
+func (s *service) RemoveRole(ctx context.Context, userID ID, role Role, scope string) error {
 	u, err := s.repo.Read(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("removeRole(): %w", err)
@@ -307,25 +378,37 @@ func (s *service) RemoveRole(ctx context.Context, userID ID, role Role) error {
 			return fmt.Errorf("removeRole(): %w", ErrNotAllowed)
 		}
 	}
-	return fmt.Errorf("removeRole(): %w", s.repo.RemoveRole(ctx, userID, role))
+	scope, err = normalizeScope(scope)
+	if err != nil {
+		return fmt.Errorf("removeRole(): %w", err)
+	}
+	if err := s.repo.RemoveRole(ctx, userID, role, scope); err != nil {
+		return fmt.Errorf("removeRole(): %w", err)
+	}
+	return nil
 }
 
-// Make sure that the one calling this function is the user itself
-// If not then he has to be at least an admin. And keep in mind that nobody exept
-// the owner can change owners fields
+// Who may update whom is checked by authorizer
 func (s *service) Update(ctx context.Context, changeset UpdateInput) error {
+	// services and partners keep profiles in sync, passwords are up to users
+	if caller, ok := CallerFromContext(ctx); ok && caller.Scoped() && changeset.Password != "" {
+		return fmt.Errorf("update(): %w", ErrNotAllowed)
+	}
+	changeset.PhoneNumber = normalizePhoneNumber(changeset.PhoneNumber)
 	// some users might not even have a password
 	// so we do not force them to update it
 	if changeset.Password != "" {
 		// but if they have a password then force them to make a good one
-		if l := utf8.RuneCountInString(changeset.Password); l > PasswordMaxLength || l < PasswordMinLength {
-			return ErrPasswordIsNotSecure
-		}
-		hash, err := bcrypt.GenerateFromPassword([]byte(changeset.Password), bcrypt.DefaultCost)
+		hash, err := s.checkPassword(ctx, changeset.Password, User{
+			ID:          changeset.ID,
+			FullName:    changeset.FullName,
+			Email:       changeset.Email,
+			PhoneNumber: changeset.PhoneNumber,
+		})
 		if err != nil {
-			return fmt.Errorf("update(): error while hashing password: %w", err)
+			return fmt.Errorf("update(): %w", err)
 		}
-		changeset.Password = string(hash)
+		changeset.Password = hash
 	}
 
 	if _, err := mail.ParseAddress(changeset.Email); err != nil {
@@ -338,7 +421,10 @@ func (s *service) Update(ctx context.Context, changeset UpdateInput) error {
 	if l := utf8.RuneCountInString(changeset.FullName); l == 0 || l > 250 {
 		return fmt.Errorf("update(): %w", ErrInvalidFullName)
 	}
-	return fmt.Errorf("update(): repo error: %w", s.repo.Update(ctx, changeset))
+	if err := s.repo.Update(ctx, changeset); err != nil {
+		return fmt.Errorf("update(): repo error: %w", err)
+	}
+	return nil
 }
 
 func (s *service) Delete(ctx context.Context, whomToDelete ID) error {
@@ -351,5 +437,8 @@ func (s *service) Delete(ctx context.Context, whomToDelete ID) error {
 			return fmt.Errorf("delete(): %w", ErrNotAllowed)
 		}
 	}
-	return fmt.Errorf("delete(): repo error: %w", s.repo.Delete(ctx, whomToDelete))
+	if err := s.repo.Delete(ctx, whomToDelete); err != nil {
+		return fmt.Errorf("delete(): repo error: %w", err)
+	}
+	return nil
 }
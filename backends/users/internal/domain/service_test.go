@@ -19,7 +19,6 @@ func TestRequestSignUp(t *testing.T) {
 	mockEmailer := mocks.NewMockEmailer(ctrl)
 	mockJWTmanager := mocks.NewMockJWTmanager(ctrl)
 	mockJWTmanager.EXPECT().SetExp(gomock.Any(), gomock.Any()).Times(1)
-	mockJWTmanager.EXPECT().SetKey(gomock.Any()).Times(1)
 	mockLogger := mocks.NewMockLogger(ctrl)
 	s, err := domain.NewService(
 		mockRepo,
@@ -28,7 +27,6 @@ func TestRequestSignUp(t *testing.T) {
 		mockCache,
 		mockLogger,
 		mockJWTmanager,
-		[]byte("secret"),
 	)
 	if err != nil {
 		t.Error(err)
@@ -49,7 +47,8 @@ func TestRequestSignUp(t *testing.T) {
 			},
 			err: nil,
 			mockup: func() {
-				mockCache.EXPECT().Store("pizzas@gmail.com", gomock.AssignableToTypeOf(""))
+				mockCache.EXPECT().Get("otp:lock:pizzas@gmail.com").Return("", domain.ErrCacheMiss)
+				mockCache.EXPECT().Store("otp:signup:pizzas@gmail.com", gomock.AssignableToTypeOf(""), domain.CodeExp)
 				mockEmailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
 			},
 		},
@@ -61,7 +60,8 @@ func TestRequestSignUp(t *testing.T) {
 			},
 			err: nil,
 			mockup: func() {
-				mockCache.EXPECT().Store(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				mockCache.EXPECT().Get("otp:lock:+996702569123").Return("", domain.ErrCacheMiss)
+				mockCache.EXPECT().Store(gomock.Any(), gomock.Any(), domain.CodeExp).Times(1).Return(nil)
 				mockSMSsender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
 			},
 		},
@@ -73,9 +73,9 @@ func TestRequestSignUp(t *testing.T) {
 			},
 			err: domain.ErrInvalidRequestSignUpInput,
 			mockup: func() {
-				mockCache.EXPECT().Store(gomock.Any(), gomock.Any()).Times(1).Return(nil)
-				mockEmailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
-				mockSMSsender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				mockCache.EXPECT().Store(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mockEmailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mockSMSsender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 		},
 	}
@@ -90,3 +90,62 @@ func TestRequestSignUp(t *testing.T) {
 		})
 	}
 }
+
+// writes used to wrap whatever repository returned, so they failed even on success
+func TestWritesSucceed(t *testing.T) {
+	testCases := []struct {
+		name    string
+		prepare func(repo *mocks.MockRepository)
+		call    func(ctx context.Context, s domain.Service) error
+	}{
+		{
+			name: "update",
+			prepare: func(repo *mocks.MockRepository) {
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.Update(ctx, domain.UpdateInput{ID: 7, FullName: "Pizza Lover", PhoneNumber: "+996700000000", Email: "pizza@lover.com"})
+			},
+		},
+		{
+			name: "delete",
+			prepare: func(repo *mocks.MockRepository) {
+				repo.EXPECT().Read(gomock.Any(), domain.ID(7)).Return(domain.User{ID: 7}, nil)
+				repo.EXPECT().Delete(gomock.Any(), domain.ID(7)).Return(nil)
+			},
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.Delete(ctx, 7)
+			},
+		},
+		{
+			name: "add role",
+			prepare: func(repo *mocks.MockRepository) {
+				repo.EXPECT().ReadRole(gomock.Any(), domain.RoleModerator).Return(domain.RoleDefinition{ID: domain.RoleModerator}, nil)
+				repo.EXPECT().AddRole(gomock.Any(), domain.ID(7), gomock.Any()).Return(nil)
+			},
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.AddRole(ctx, domain.AddRoleInput{UserID: 7, Role: domain.RoleModerator})
+			},
+		},
+		{
+			name: "remove role",
+			prepare: func(repo *mocks.MockRepository) {
+				repo.EXPECT().Read(gomock.Any(), domain.ID(7)).Return(domain.User{ID: 7, Roles: []domain.Role{domain.RoleModerator}}, nil)
+				repo.EXPECT().RemoveRole(gomock.Any(), domain.ID(7), domain.RoleModerator, "").Return(nil)
+			},
+			call: func(ctx context.Context, s domain.Service) error {
+				return s.RemoveRole(ctx, 7, domain.RoleModerator, "")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			tc.prepare(deps.repo)
+			if err := tc.call(context.Background(), s); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
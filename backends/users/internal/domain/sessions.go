@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+func (s *service) SignOut(ctx context.Context, refreshKey string) error {
+	claims, err := s.jwtManager.DecodeRefresh(refreshKey)
+	if err != nil {
+		return fmt.Errorf("signOut(): %w", err)
+	}
+	if claims.FamilyID == "" {
+		return fmt.Errorf("signOut(): %w", ErrInvalidToken)
+	}
+	// signing out twice is not an error
+	err = s.repo.RevokeSession(ctx, claims.UserID, claims.FamilyID)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return fmt.Errorf("signOut(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) Sessions(ctx context.Context, userID ID) ([]Session, error) {
+	sessions, err := s.repo.ReadSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("sessions(): could not read from db %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *service) RevokeSession(ctx context.Context, userID ID, sessionID string) error {
+	if err := s.repo.RevokeSession(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("revokeSession(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) RevokeOtherSessions(ctx context.Context, refreshKey string) error {
+	claims, err := s.jwtManager.DecodeRefresh(refreshKey)
+	if err != nil {
+		return fmt.Errorf("revokeOtherSessions(): %w", err)
+	}
+	if claims.FamilyID == "" {
+		return fmt.Errorf("revokeOtherSessions(): %w", ErrInvalidToken)
+	}
+	// a revoked session should not be able to kick out the others
+	if err := s.checkSession(ctx, claims); err != nil {
+		return fmt.Errorf("revokeOtherSessions(): %w", err)
+	}
+	if err := s.repo.RevokeOtherSessions(ctx, claims.UserID, claims.FamilyID); err != nil {
+		return fmt.Errorf("revokeOtherSessions(): %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestSignInRecordsSession(t *testing.T) {
+	s, deps := newTestService(t)
+	deps.expectNoTOTP()
+	ctx := domain.WithClientInfo(context.Background(), domain.ClientInfo{
+		IP:        "10.0.0.1",
+		UserAgent: "pizzas-ios/1.0",
+	})
+
+	deps.repo.EXPECT().ReadByEmail(gomock.Any(), "pizzas@gmail.com").
+		Return(domain.User{ID: 1, Password: "$2a$04$NJIvbqDn9WWwxGjm6PAxhOYtgaiY.YgJxpqnwrXiZs2yBs/3pu4ji"}, nil)
+	// the bcrypt hash gets replaced
+	deps.repo.EXPECT().UpdatePassword(gomock.Any(), domain.ID(1), gomock.Any()).Return(nil)
+	deps.jwt.EXPECT().Generate(domain.ID(1), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(domain.SignInOutput{RefreshID: "r1", RefreshExpiresAt: time.Now().Add(time.Hour)}, nil)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session domain.Session, first domain.RefreshToken) error {
+			if session.IP != "10.0.0.1" || session.UserAgent != "pizzas-ios/1.0" {
+				t.Errorf("device is not recorded: %+v", session)
+			}
+			if session.CreatedAt.IsZero() || !session.LastUsedAt.Equal(session.CreatedAt) {
+				t.Errorf("unexpected timestamps: %+v", session)
+			}
+			if first.ID != "r1" || first.FamilyID != session.ID || session.UserID != 1 {
+				t.Errorf("refresh token %+v does not belong to session %+v", first, session)
+			}
+			return nil
+		})
+
+	if _, err := s.SignInEmailPassword(ctx, "pizzas@gmail.com", "password"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignOut(t *testing.T) {
+	testCases := []struct {
+		name    string
+		prepare func(deps testDeps)
+		err     error
+	}{
+		{
+			name: "revoke session of the token",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().RevokeSession(gomock.Any(), domain.ID(1), "family").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "sign out twice",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().RevokeSession(gomock.Any(), domain.ID(1), "family").
+					Return(domain.ErrSessionNotFound)
+			},
+			err: nil,
+		},
+		{
+			name: "fail with invalid token",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{}, domain.ErrInvalidToken)
+			},
+			err: domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			tc.prepare(deps)
+			err := s.SignOut(context.Background(), "refresh")
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	revokedAt := time.Now()
+
+	testCases := []struct {
+		name    string
+		prepare func(deps testDeps)
+		err     error
+	}{
+		{
+			name: "keep current session",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").
+					Return(domain.Session{ID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().RevokeOtherSessions(gomock.Any(), domain.ID(1), "family").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "fail from revoked session",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").
+					Return(domain.Session{ID: "family", UserID: 1, RevokedAt: &revokedAt}, nil)
+			},
+			err: domain.ErrSessionRevoked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			tc.prepare(deps)
+			err := s.RevokeOtherSessions(context.Background(), "refresh")
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
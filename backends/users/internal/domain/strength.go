@@ -0,0 +1,377 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength estimation in the style of zxcvbn. Password is split into
+// patterns people use: common words, repeats, sequences like abc or 987,
+// keyboard walks like qwerty and years. Every pattern costs some number
+// of guesses, characters that are not part of any pattern are brute forced.
+// The cheapest way to split the password tells how many guesses an attacker
+// who knows all these tricks would need.
+
+type (
+	strengthEstimate struct {
+		Guesses float64
+		// Entropy in bits, log2 of Guesses
+		Entropy float64
+		// Score from 0 to 4 the same as zxcvbn has
+		Score int
+	}
+
+	patternMatch struct {
+		i, j    int // runes i..j inclusive
+		guesses float64
+	}
+)
+
+const (
+	bruteforceCardinality = 10
+	minGuessesSingleChar  = 10
+	minGuessesMultiChar   = 50
+	// every additional pattern makes the attack a bit longer
+	minGuessesBeforeGrowingSequence = 10000
+
+	yearSpace  = 119
+	minYear    = 1900
+	maxYear    = 2039
+	minPattern = 3
+)
+
+// scoreThresholds are the same as in zxcvbn, a password that needs
+// less than scoreThresholds[i] guesses gets score i
+var scoreThresholds = []float64{1e3 + 5, 1e6 + 5, 1e8 + 5, 1e10 + 5}
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+var l33t = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i',
+	'!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z',
+}
+
+// commonPasswords are ordered by how often they show up in leaks,
+// position in the list is the number of guesses.
+var commonPasswords = rankWords(`
+password 123456 12345678 qwerty abc123 monkey 1234567 letmein trustno1 dragon
+baseball 111111 iloveyou master sunshine ashley bailey passw0rd shadow 123123
+654321 superman qazwsx michael football welcome jesus ninja mustang password1
+admin login princess solo starwars whatever freedom hello charlie aa123456
+donald batman zaq1zaq1 access flower hottie loveme 696969 lovely 666666
+qwertyuiop 1qaz2wsx secret pizza pepperoni cheese margherita delivery
+summer winter spring autumn love god money computer internet google
+jordan harley ranger hunter buster soccer hockey killer george sexy andrew
+thomas jessica pepper daniel joshua maggie tigger robert matthew cookie
+chocolate orange banana apple purple yellow silver golden diamond
+mother father family friend forever angel baby sweet happy lucky
+test guest user root default changeme qwerty123 12345 1234 123
+`)
+
+// commonWords make passphrases of a few popular words easy to guess
+var commonWords = rankWords(`
+the you and for new not are all one can my your with this have from
+time day life home house car dog cat red blue green black white big
+little good best first last love new old man woman boy girl world
+house city country food water fire sun moon star king queen heart
+micro pizzas pizza
+`)
+
+func rankWords(list string) map[string]int {
+	ranked := map[string]int{}
+	for i, w := range strings.Fields(list) {
+		if _, ok := ranked[w]; !ok {
+			ranked[w] = i + 1
+		}
+	}
+	return ranked
+}
+
+// estimateStrength tells how hard password is to guess.
+// userInputs are words an attacker would try first, like user's name.
+func estimateStrength(password string, userInputs []string) strengthEstimate {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return strengthEstimate{Guesses: 1}
+	}
+
+	dictionaries := []map[string]int{commonPasswords, commonWords, rankWords(strings.Join(userInputs, " "))}
+	matches := dictionaryMatches(runes, dictionaries)
+	matches = append(matches, repeatMatches(runes, userInputs)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	guesses := mostGuessableSplit(len(runes), matches)
+	est := strengthEstimate{Guesses: guesses, Entropy: math.Log2(guesses)}
+	for est.Score < len(scoreThresholds) && guesses >= scoreThresholds[est.Score] {
+		est.Score++
+	}
+	return est
+}
+
+// mostGuessableSplit finds the split of password into patterns and
+// brute forced pieces that needs the least guesses. opt[k][l] is the
+// cheapest product of guesses for the first k runes split into l pieces.
+func mostGuessableSplit(n int, matches []patternMatch) float64 {
+	byEnd := make([][]patternMatch, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	opt := make([][]float64, n+1)
+	for k := range opt {
+		opt[k] = make([]float64, n+1)
+		for l := range opt[k] {
+			opt[k][l] = math.Inf(1)
+		}
+	}
+	opt[0][0] = 1
+
+	for k := 1; k <= n; k++ {
+		// brute force of runes i..k-1
+		for i := 0; i < k; i++ {
+			g := math.Max(math.Pow(bruteforceCardinality, float64(k-i)), minGuesses(k-i))
+			for l := 0; l < k; l++ {
+				if v := opt[i][l] * g; v < opt[k][l+1] {
+					opt[k][l+1] = v
+				}
+			}
+		}
+		for _, m := range byEnd[k-1] {
+			g := math.Max(m.guesses, minGuesses(m.j-m.i+1))
+			for l := 0; l < k; l++ {
+				if v := opt[m.i][l] * g; v < opt[k][l+1] {
+					opt[k][l+1] = v
+				}
+			}
+		}
+	}
+
+	best := math.Inf(1)
+	for l := 1; l <= n; l++ {
+		if math.IsInf(opt[n][l], 1) {
+			continue
+		}
+		g := factorial(l)*opt[n][l] + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		if g < best {
+			best = g
+		}
+	}
+	return best
+}
+
+func minGuesses(length int) float64 {
+	if length == 1 {
+		return minGuessesSingleChar
+	}
+	return minGuessesMultiChar
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+func dictionaryMatches(runes []rune, dictionaries []map[string]int) []patternMatch {
+	var matches []patternMatch
+	lower := []rune(strings.ToLower(string(runes)))
+	for i := range lower {
+		for j := i + minPattern - 1; j < len(lower); j++ {
+			word := string(lower[i : j+1])
+			unleeted, leetCount := unleet(lower[i : j+1])
+			reversed := reverse(word)
+			for _, dict := range dictionaries {
+				if rank, ok := dict[word]; ok {
+					matches = append(matches, patternMatch{i, j, float64(rank) * uppercaseVariations(runes[i:j+1])})
+				}
+				if rank, ok := dict[unleeted]; ok && leetCount > 0 {
+					matches = append(matches, patternMatch{i, j, float64(rank) * uppercaseVariations(runes[i:j+1]) * math.Pow(2, float64(leetCount))})
+				}
+				if rank, ok := dict[reversed]; ok && reversed != word {
+					matches = append(matches, patternMatch{i, j, float64(rank) * uppercaseVariations(runes[i:j+1]) * 2})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func unleet(runes []rune) (string, int) {
+	out := make([]rune, len(runes))
+	count := 0
+	for i, r := range runes {
+		if v, ok := l33t[r]; ok {
+			out[i] = v
+			count++
+			continue
+		}
+		out[i] = r
+	}
+	return string(out), count
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// uppercaseVariations: Password and PASSWORD are the first things
+// to try after password, random capitals are worth more.
+func uppercaseVariations(runes []rune) float64 {
+	upper := 0
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case upper == len(runes), upper == 1 && unicode.IsUpper(runes[0]), upper == 1 && unicode.IsUpper(runes[len(runes)-1]):
+		return 2
+	}
+	variations := 0.0
+	for k := 1; k <= upper; k++ {
+		variations += binomial(len(runes), k)
+	}
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r *= float64(n - k + i)
+		r /= float64(i)
+	}
+	return r
+}
+
+// repeatMatches finds things like aaaa and abcabcabc. Guesses for a repeat
+// are guesses for the repeated part times number of repeats.
+func repeatMatches(runes []rune, userInputs []string) []patternMatch {
+	var matches []patternMatch
+	n := len(runes)
+	for i := 0; i < n; i++ {
+		for size := 1; i+size*2 <= n; size++ {
+			base := runes[i : i+size]
+			count := 1
+			for j := i + size; j+size <= n && string(runes[j:j+size]) == string(base); j += size {
+				count++
+			}
+			if count < 2 || size*count < minPattern {
+				continue
+			}
+			baseGuesses := estimateStrength(string(base), userInputs).Guesses
+			matches = append(matches, patternMatch{i, i + size*count - 1, baseGuesses * float64(count)})
+		}
+	}
+	return matches
+}
+
+// sequenceMatches finds runs like abcd, 1357 or zyx
+func sequenceMatches(runes []rune) []patternMatch {
+	var matches []patternMatch
+	n := len(runes)
+	for i := 0; i+minPattern <= n; i++ {
+		delta := runes[i+1] - runes[i]
+		if delta == 0 || delta > 5 || delta < -5 {
+			continue
+		}
+		j := i + 1
+		for j+1 < n && runes[j+1]-runes[j] == delta {
+			j++
+		}
+		if j-i+1 < minPattern {
+			continue
+		}
+		var base float64
+		switch first := unicode.ToLower(runes[i]); {
+		case strings.ContainsRune("aAzZ019", first):
+			base = 4
+		case unicode.IsDigit(first):
+			base = 10
+		default:
+			base = 26
+		}
+		if delta < 0 {
+			base *= 2
+		}
+		matches = append(matches, patternMatch{i, j, base * float64(j-i+1)})
+	}
+	return matches
+}
+
+// keyboardMatches finds walks along a row of qwerty keyboard like qwert or 7890
+func keyboardMatches(runes []rune) []patternMatch {
+	var matches []patternMatch
+	lower := []rune(strings.ToLower(string(runes)))
+	n := len(lower)
+	for i := 0; i < n; i++ {
+		j, turns, lastDir := i, 1, 0
+		for j+1 < n {
+			dir := keyboardDirection(lower[j], lower[j+1])
+			if dir == 0 {
+				break
+			}
+			if lastDir != 0 && dir != lastDir {
+				turns++
+			}
+			lastDir = dir
+			j++
+		}
+		if j-i+1 >= minPattern {
+			// 47 starting keys and about 4 neighbours for every key
+			matches = append(matches, patternMatch{i, j, 47 * float64(j-i+1) * math.Pow(4, float64(turns))})
+		}
+	}
+	return matches
+}
+
+// keyboardDirection returns 1 or -1 if b is right or left of a in the same row
+func keyboardDirection(a, b rune) int {
+	for _, row := range keyboardRows {
+		ia, ib := strings.IndexRune(row, a), strings.IndexRune(row, b)
+		if ia < 0 || ib < 0 {
+			continue
+		}
+		switch ib - ia {
+		case 1:
+			return 1
+		case -1:
+			return -1
+		}
+	}
+	return 0
+}
+
+func yearMatches(runes []rune) []patternMatch {
+	var matches []patternMatch
+	for i := 0; i+4 <= len(runes); i++ {
+		year := 0
+		ok := true
+		for _, r := range runes[i : i+4] {
+			if r < '0' || r > '9' {
+				ok = false
+				break
+			}
+			year = year*10 + int(r-'0')
+		}
+		if ok && year >= minYear && year <= maxYear {
+			matches = append(matches, patternMatch{i, i + 3, yearSpace})
+		}
+	}
+	return matches
+}
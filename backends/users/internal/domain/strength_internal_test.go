@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestEstimateStrength(t *testing.T) {
+	testCases := []struct {
+		password string
+		// score has to be within [min, max]
+		min, max int
+	}{
+		{password: "password", min: 0, max: 0},
+		{password: "P@ssw0rd", min: 0, max: 1},
+		{password: "qwertyuiop", min: 0, max: 1},
+		{password: "abcdefgh", min: 0, max: 0},
+		{password: "aaaaaaaaaaaa", min: 0, max: 0},
+		{password: "pizzapizzapizza", min: 0, max: 1},
+		{password: "iloveyou1990", min: 0, max: 2},
+		{password: "Oregano-Basil-Crust-91", min: 4, max: 4},
+		{password: "x7#Kp2!vQm9", min: 4, max: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.password, func(t *testing.T) {
+			est := estimateStrength(tc.password, nil)
+			if est.Score < tc.min || est.Score > tc.max {
+				t.Errorf("got score %d (%.0f guesses), want from %d to %d", est.Score, est.Guesses, tc.min, tc.max)
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthUsesUserInputs(t *testing.T) {
+	without := estimateStrength("emirlanrasulov", nil)
+	with := estimateStrength("emirlanrasulov", []string{"emirlan", "rasulov"})
+	if with.Guesses >= without.Guesses {
+		t.Errorf("name of the user does not make password weaker: %.0f >= %.0f", with.Guesses, without.Guesses)
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// newTokenID returns random 128 bit identifier in hex
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// issueTokens starts a new session for user on the calling device.
+func (s *service) issueTokens(ctx context.Context, u User) (SignInOutput, error) {
+	familyID, err := newTokenID()
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("could not generate family id: %w", err)
+	}
+	out, err := s.jwtManager.Generate(u.ID, u.Roles, u.Permissions, u.Grants, familyID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("could not generate jwt due to: %w", err)
+	}
+	if err := s.startSession(ctx, u.ID, familyID, out); err != nil {
+		return SignInOutput{}, err
+	}
+	return out, nil
+}
+
+// startSession stores session with the first refresh token of out
+func (s *service) startSession(ctx context.Context, userID ID, familyID string, out SignInOutput) error {
+	now := time.Now().UTC()
+	client := ClientInfoFromContext(ctx)
+	if err := s.repo.CreateSession(ctx, Session{
+		ID:         familyID,
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}, RefreshToken{
+		ID:        out.RefreshID,
+		FamilyID:  familyID,
+		UserID:    userID,
+		IssuedAt:  now,
+		ExpiresAt: out.RefreshExpiresAt,
+	}); err != nil {
+		return fmt.Errorf("could not store session: %w", err)
+	}
+	return nil
+}
+
+// Refresh exchanges refresh token for a new pair. Every refresh token
+// works only once, if it is used again the whole family is revoked
+// and both the thief and the owner have to sign in again.
+func (s *service) Refresh(ctx context.Context, refreshKey string) (SignInOutput, error) {
+	refClaims, err := s.jwtManager.DecodeRefresh(refreshKey)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	}
+	// tokens issued before rotation was introduced can not be tracked,
+	// tokens of OAuth clients are refreshed only at the token endpoint
+	if refClaims.ID == "" || refClaims.FamilyID == "" || refClaims.ClientID != "" {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", ErrInvalidToken)
+	}
+	if err := s.checkSession(ctx, refClaims); err != nil {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	}
+	u, err := s.repo.Read(ctx, refClaims.UserID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	}
+	out, err := s.jwtManager.Generate(u.ID, u.Roles, u.Permissions, u.Grants, refClaims.FamilyID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	}
+	if err := s.rotateRefreshToken(ctx, refClaims, out); err != nil {
+		return SignInOutput{}, fmt.Errorf("refresh(): %w", err)
+	}
+	return out, nil
+}
+
+// rotateRefreshToken replaces the used token with the one from out,
+// reuse of a token revokes its family.
+func (s *service) rotateRefreshToken(ctx context.Context, used RefreshClaims, out SignInOutput) error {
+	err := s.repo.RotateRefreshToken(ctx, used.ID, RefreshToken{
+		ID:        out.RefreshID,
+		FamilyID:  used.FamilyID,
+		UserID:    used.UserID,
+		IssuedAt:  time.Now().UTC(),
+		ExpiresAt: out.RefreshExpiresAt,
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if err := s.repo.RevokeRefreshFamily(ctx, used.FamilyID); err != nil {
+			return fmt.Errorf("could not revoke family: %w", err)
+		}
+		s.logger.Infof("refresh token reuse detected, revoked family %s", used.FamilyID)
+		return ErrRefreshTokenReused
+	}
+	return err
+}
+
+// checkSession makes sure that session of the refresh token is still active
+func (s *service) checkSession(ctx context.Context, claims RefreshClaims) error {
+	session, err := s.repo.ReadSession(ctx, claims.FamilyID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID != claims.UserID {
+		return ErrInvalidToken
+	}
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+	return nil
+}
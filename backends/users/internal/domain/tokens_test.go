@@ -0,0 +1,111 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func TestRefresh(t *testing.T) {
+	user := domain.User{ID: 1, Roles: []domain.Role{domain.RoleDeliveryMan}, Permissions: []string{"orders:deliver"}}
+	active := domain.Session{ID: "family", UserID: 1}
+	revokedAt := time.Now()
+	revoked := domain.Session{ID: "family", UserID: 1, RevokedAt: &revokedAt}
+
+	testCases := []struct {
+		name    string
+		prepare func(deps testDeps)
+		err     error
+	}{
+		{
+			name: "rotate unused token",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(active, nil)
+				deps.repo.EXPECT().Read(gomock.Any(), domain.ID(1)).Return(user, nil)
+				deps.jwt.EXPECT().Generate(domain.ID(1), user.Roles, user.Permissions, user.Grants, "family").
+					Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "next", RefreshID: "b"}, nil)
+				deps.repo.EXPECT().RotateRefreshToken(gomock.Any(), "a", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, next domain.RefreshToken) error {
+						if next.ID != "b" || next.FamilyID != "family" || next.UserID != 1 {
+							t.Errorf("unexpected next token %+v", next)
+						}
+						return nil
+					})
+			},
+			err: nil,
+		},
+		{
+			name: "revoke family on reuse",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(active, nil)
+				deps.repo.EXPECT().Read(gomock.Any(), domain.ID(1)).Return(user, nil)
+				deps.jwt.EXPECT().Generate(domain.ID(1), user.Roles, user.Permissions, user.Grants, "family").
+					Return(domain.SignInOutput{RefreshID: "c"}, nil)
+				deps.repo.EXPECT().RotateRefreshToken(gomock.Any(), "a", gomock.Any()).
+					Return(domain.ErrRefreshTokenReused)
+				deps.repo.EXPECT().RevokeRefreshFamily(gomock.Any(), "family").Return(nil)
+				deps.logger.EXPECT().Infof(gomock.Any(), gomock.Any())
+			},
+			err: domain.ErrRefreshTokenReused,
+		},
+		{
+			name: "fail with token issued before rotation",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{UserID: 1}, nil)
+			},
+			err: domain.ErrInvalidToken,
+		},
+		{
+			name: "fail with expired token",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(active, nil)
+				deps.repo.EXPECT().Read(gomock.Any(), domain.ID(1)).Return(user, nil)
+				deps.jwt.EXPECT().Generate(domain.ID(1), user.Roles, user.Permissions, user.Grants, "family").
+					Return(domain.SignInOutput{RefreshID: "b"}, nil)
+				deps.repo.EXPECT().RotateRefreshToken(gomock.Any(), "a", gomock.Any()).
+					Return(domain.ErrInvalidToken)
+			},
+			err: domain.ErrInvalidToken,
+		},
+		{
+			name: "fail with revoked session",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 1}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(revoked, nil)
+			},
+			err: domain.ErrSessionRevoked,
+		},
+		{
+			name: "fail with session of another user",
+			prepare: func(deps testDeps) {
+				deps.jwt.EXPECT().DecodeRefresh("refresh").
+					Return(domain.RefreshClaims{ID: "a", FamilyID: "family", UserID: 2}, nil)
+				deps.repo.EXPECT().ReadSession(gomock.Any(), "family").Return(active, nil)
+			},
+			err: domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			tc.prepare(deps)
+			_, err := s.Refresh(context.Background(), "refresh")
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
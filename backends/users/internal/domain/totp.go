@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Time based one time passwords from RFC 6238 with the parameters
+// every authenticator app supports: SHA-1, 6 digits and 30 seconds.
+// Secrets are 160 bit long as RFC 4226 recommends.
+
+const (
+	totpSecretLength = 20
+	// recovery codes are 80 bit, shown as xxxx-xxxx-xxxx-xxxx
+	recoveryCodeLength = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// totpCode is HOTP from RFC 4226 for the given counter
+func totpCode(secret string, counter uint64, digits int) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %w", err)
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod), nil
+}
+
+// verifyTOTP checks code against the current time step and TOTPSkew steps
+// around it, so a code typed at the end of its period still works.
+// It returns the step the code belongs to.
+func verifyTOTP(secret, code string, now time.Time) (uint64, bool) {
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+	current := uint64(now.Unix()) / uint64(TOTPPeriod/time.Second)
+	for i := -TOTPSkew; i <= TOTPSkew; i++ {
+		step := current + uint64(i)
+		expected, err := totpCode(secret, step, TOTPDigits)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpURI is the otpauth uri authenticator apps read from qr codes
+func totpURI(secret, account string) string {
+	label := url.PathEscape(TOTPIssuer) + ":" + url.PathEscape(account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", TOTPIssuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(TOTPDigits))
+	q.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// generateRecoveryCodes returns codes to show to the user
+// and their hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes[i] = raw[:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode lets users type codes without dashes or in upper case
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func isRecoveryCode(code string) bool {
+	return len(normalizeRecoveryCode(code)) == totpEncoding.EncodedLen(recoveryCodeLength)
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// test vectors for SHA-1 from RFC 6238 appendix B
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	testCases := []struct {
+		time int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tc := range testCases {
+		code, err := totpCode(secret, uint64(tc.time/30), 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tc.code {
+			t.Errorf("time %d: expected %s, got %s", tc.time, tc.code, code)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1655750000, 0)
+	step := uint64(now.Unix() / 30)
+
+	testCases := []struct {
+		name string
+		step uint64
+		ok   bool
+	}{
+		{name: "current step", step: step, ok: true},
+		{name: "previous step", step: step - 1, ok: true},
+		{name: "next step", step: step + 1, ok: true},
+		{name: "too old", step: step - 2, ok: false},
+		{name: "too new", step: step + 2, ok: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, err := totpCode(secret, tc.step, TOTPDigits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := verifyTOTP(secret, code, now)
+			if ok != tc.ok {
+				t.Fatalf("expected %v, got %v", tc.ok, ok)
+			}
+			if ok && got != tc.step {
+				t.Fatalf("expected step %d, got %d", tc.step, got)
+			}
+		})
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("expected %d codes, got %d", RecoveryCodeCount, len(codes))
+	}
+	for i, code := range codes {
+		if !isRecoveryCode(code) {
+			t.Fatalf("%s is not recognised as a recovery code", code)
+		}
+		typed := strings.ToUpper(strings.ReplaceAll(code, "-", ""))
+		if hashRecoveryCode(typed) != hashes[i] {
+			t.Fatalf("%s typed as %s does not match its hash", code, typed)
+		}
+	}
+	if isRecoveryCode("123456") {
+		t.Fatal("totp code is recognised as a recovery code")
+	}
+}
@@ -0,0 +1,319 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Users with TOTP enabled pass a second step after a code or a password:
+// sign in returns *SecondFactorRequiredError with a challenge token and
+// VerifySecondFactor exchanges the token and a TOTP or recovery code for
+// tokens. Users with a role from WithRequiredSecondFactor who have no TOTP
+// yet get a fresh secret with the challenge and enroll while signing in.
+// Wrong codes are counted the same way as for one time codes we send.
+
+type (
+	// SecondFactorRequiredError is returned instead of tokens,
+	// errors.Is(err, ErrSecondFactorRequired) works with it.
+	SecondFactorRequiredError struct {
+		ChallengeToken string
+		ExpiresAt      time.Time
+		// Enrollment is set if user has to set up TOTP first
+		Enrollment *TOTPEnrollment
+	}
+)
+
+func (e *SecondFactorRequiredError) Error() string {
+	if e.Enrollment != nil {
+		return ErrSecondFactorRequired.Error() + ", enroll totp first"
+	}
+	return ErrSecondFactorRequired.Error()
+}
+
+func (e *SecondFactorRequiredError) Is(target error) bool {
+	return target == ErrSecondFactorRequired
+}
+
+const otpTOTP otpPurpose = "totp"
+
+func totpDestination(userID ID) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func totpUsedKey(userID ID, step uint64) string {
+	return "totp:used:" + strconv.FormatUint(uint64(userID), 10) + ":" + strconv.FormatUint(step, 10)
+}
+
+func challengeKey(hash string) string {
+	return "2fa:challenge:" + hash
+}
+
+func hashChallenge(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpAccount is what authenticator apps show next to the issuer
+func totpAccount(u User) string {
+	switch {
+	case u.Email != "":
+		return u.Email
+	case u.PhoneNumber != "":
+		return u.PhoneNumber
+	}
+	return strconv.FormatUint(uint64(u.ID), 10)
+}
+
+func (s *service) EnrollTOTP(ctx context.Context, userID ID) (TOTPEnrollment, error) {
+	u, err := s.repo.Read(ctx, userID)
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("enrollTOTP(): %w", err)
+	}
+	enrollment, err := s.enrollTOTP(ctx, u)
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("enrollTOTP(): %w", err)
+	}
+	return enrollment, nil
+}
+
+// enrollTOTP stores a new secret that is not confirmed yet,
+// it replaces the previous unconfirmed one.
+func (s *service) enrollTOTP(ctx context.Context, u User) (TOTPEnrollment, error) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("could not generate totp secret: %w", err)
+	}
+	if err := s.repo.CreateTOTP(ctx, TOTP{
+		UserID:    u.ID,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return TOTPEnrollment{}, err
+	}
+	return TOTPEnrollment{Secret: secret, URI: totpURI(secret, totpAccount(u))}, nil
+}
+
+func (s *service) ConfirmTOTP(ctx context.Context, userID ID, code string) ([]string, error) {
+	t, err := s.repo.ReadTOTP(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("confirmTOTP(): %w", err)
+	}
+	if t.Enabled() {
+		return nil, fmt.Errorf("confirmTOTP(): %w", ErrTOTPAlreadyEnabled)
+	}
+	if err := s.checkSecondFactor(ctx, t, code); err != nil {
+		return nil, fmt.Errorf("confirmTOTP(): %w", err)
+	}
+	codes, err := s.confirmTOTP(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("confirmTOTP(): %w", err)
+	}
+	return codes, nil
+}
+
+func (s *service) confirmTOTP(ctx context.Context, userID ID) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate recovery codes: %w", err)
+	}
+	if err := s.repo.ConfirmTOTP(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP takes a totp or recovery code, users who lost
+// their authenticator can give their password instead
+func (s *service) DisableTOTP(ctx context.Context, userID ID, code, password string) error {
+	u, err := s.repo.Read(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("disableTOTP(): %w", err)
+	}
+	if s.secondFactorRequired(u) {
+		return fmt.Errorf("disableTOTP(): second factor is required for this role %w", ErrNotAllowed)
+	}
+	t, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("disableTOTP(): %w", err)
+	}
+	if code != "" {
+		err = s.checkSecondFactor(ctx, t, code)
+	} else {
+		err = s.checkCurrentPassword(ctx, u, password)
+	}
+	if err != nil {
+		return fmt.Errorf("disableTOTP(): %w", err)
+	}
+	if err := s.repo.DeleteTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("disableTOTP(): %w", err)
+	}
+	return nil
+}
+
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, userID ID, code string) ([]string, error) {
+	t, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("regenerateRecoveryCodes(): %w", err)
+	}
+	if err := s.checkSecondFactor(ctx, t, code); err != nil {
+		return nil, fmt.Errorf("regenerateRecoveryCodes(): %w", err)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("regenerateRecoveryCodes(): could not generate recovery codes: %w", err)
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("regenerateRecoveryCodes(): %w", err)
+	}
+	return codes, nil
+}
+
+func (s *service) VerifySecondFactor(ctx context.Context, challengeToken, code string) (SignInOutput, error) {
+	key := challengeKey(hashChallenge(challengeToken))
+	value, err := s.cache.Get(key)
+	if errors.Is(err, ErrCacheMiss) {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", ErrInvalidChallenge)
+	}
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", ErrInvalidChallenge)
+	}
+
+	u, err := s.repo.Read(ctx, ID(id))
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+	t, err := s.repo.ReadTOTP(ctx, u.ID)
+	// totp was disabled after the challenge was issued
+	if errors.Is(err, ErrTOTPNotFound) {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", ErrInvalidChallenge)
+	}
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+	if err := s.checkSecondFactor(ctx, t, code); err != nil {
+		if errors.Is(err, ErrTooManyAttempts) {
+			if err := s.cache.Delete(key); err != nil {
+				return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+			}
+		}
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+	// Pop is atomic so the challenge works only once
+	if _, err := s.cache.Pop(key); errors.Is(err, ErrCacheMiss) {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", ErrInvalidChallenge)
+	} else if err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+
+	var recoveryCodes []string
+	if !t.Enabled() {
+		if recoveryCodes, err = s.confirmTOTP(ctx, u.ID); err != nil {
+			return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+		}
+	}
+	out, err := s.issueTokens(ctx, u)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", err)
+	}
+	out.RecoveryCodes = recoveryCodes
+	return out, nil
+}
+
+// completeSignIn is the last step of every sign in, it issues tokens
+// or a challenge if user has to pass the second factor.
+func (s *service) completeSignIn(ctx context.Context, u User) (SignInOutput, error) {
+	t, err := s.repo.ReadTOTP(ctx, u.ID)
+	if err != nil && !errors.Is(err, ErrTOTPNotFound) {
+		return SignInOutput{}, err
+	}
+	enabled := err == nil && t.Enabled()
+	if !enabled && !s.secondFactorRequired(u) {
+		return s.issueTokens(ctx, u)
+	}
+
+	challenge := &SecondFactorRequiredError{ExpiresAt: time.Now().Add(SecondFactorChallengeExp)}
+	if !enabled {
+		enrollment, err := s.enrollTOTP(ctx, u)
+		if err != nil {
+			return SignInOutput{}, err
+		}
+		challenge.Enrollment = &enrollment
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return SignInOutput{}, fmt.Errorf("could not generate challenge: %w", err)
+	}
+	challenge.ChallengeToken = hex.EncodeToString(b)
+	if err := s.cache.Store(
+		challengeKey(hashChallenge(challenge.ChallengeToken)),
+		strconv.FormatUint(uint64(u.ID), 10),
+		SecondFactorChallengeExp,
+	); err != nil {
+		return SignInOutput{}, fmt.Errorf("could not cache challenge: %w", err)
+	}
+	return SignInOutput{}, challenge
+}
+
+func (s *service) enabledTOTP(ctx context.Context, userID ID) (TOTP, error) {
+	t, err := s.repo.ReadTOTP(ctx, userID)
+	if err != nil {
+		return TOTP{}, err
+	}
+	if !t.Enabled() {
+		return TOTP{}, ErrTOTPNotFound
+	}
+	return t, nil
+}
+
+// checkSecondFactor accepts a TOTP code or, once TOTP is
+// confirmed, one of recovery codes. Every code works only once.
+func (s *service) checkSecondFactor(ctx context.Context, t TOTP, code string) error {
+	destination := totpDestination(t.UserID)
+	if err := s.checkCodeLock(destination); err != nil {
+		return err
+	}
+	if step, ok := verifyTOTP(t.Secret, code, time.Now()); ok {
+		used, err := s.cache.Incr(totpUsedKey(t.UserID, step), TOTPPeriod*(2*TOTPSkew+1))
+		if err != nil {
+			return fmt.Errorf("could not check totp code: %w", err)
+		}
+		if used == 1 {
+			return s.resetSecondFactorAttempts(destination)
+		}
+	} else if t.Enabled() && isRecoveryCode(code) {
+		err := s.repo.UseRecoveryCode(ctx, t.UserID, hashRecoveryCode(code))
+		if err == nil {
+			return s.resetSecondFactorAttempts(destination)
+		}
+		if !errors.Is(err, ErrInvalidCode) {
+			return fmt.Errorf("could not check recovery code: %w", err)
+		}
+	}
+	return s.failCodeAttempt(otpTOTP, destination)
+}
+
+func (s *service) resetSecondFactorAttempts(destination string) error {
+	if err := s.cache.Delete(otpAttemptsKey(destination)); err != nil {
+		return fmt.Errorf("could not reset attempts: %w", err)
+	}
+	return nil
+}
+
+func (s *service) secondFactorRequired(u User) bool {
+	for _, r := range u.Roles {
+		if s.secondFactorRoles[r] {
+			return true
+		}
+	}
+	return false
+}
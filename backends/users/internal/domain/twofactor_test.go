@@ -0,0 +1,271 @@
+package domain_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// totpNow does what an authenticator app does
+func totpNow(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:])&0x7fffffff)%1000000)
+}
+
+// expectTOTPStorage keeps totp of user in memory the way repository would
+func (d testDeps) expectTOTPStorage(u domain.User) {
+	var (
+		stored   *domain.TOTP
+		recovery = map[string]bool{}
+	)
+	d.repo.EXPECT().Read(gomock.Any(), u.ID).Return(u, nil).AnyTimes()
+	d.repo.EXPECT().CreateTOTP(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t domain.TOTP) error {
+			if stored != nil && stored.Enabled() {
+				return domain.ErrTOTPAlreadyEnabled
+			}
+			stored = &t
+			return nil
+		}).AnyTimes()
+	d.repo.EXPECT().ReadTOTP(gomock.Any(), u.ID).
+		DoAndReturn(func(context.Context, domain.ID) (domain.TOTP, error) {
+			if stored == nil {
+				return domain.TOTP{}, domain.ErrTOTPNotFound
+			}
+			return *stored, nil
+		}).AnyTimes()
+	d.repo.EXPECT().ConfirmTOTP(gomock.Any(), u.ID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ domain.ID, codes []string) error {
+			now := time.Now()
+			stored.ConfirmedAt = &now
+			for _, c := range codes {
+				recovery[c] = true
+			}
+			return nil
+		}).AnyTimes()
+	d.repo.EXPECT().UseRecoveryCode(gomock.Any(), u.ID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ domain.ID, code string) error {
+			if !recovery[code] {
+				return domain.ErrInvalidCode
+			}
+			delete(recovery, code)
+			return nil
+		}).AnyTimes()
+	d.repo.EXPECT().UpdatePassword(gomock.Any(), u.ID, gomock.Any()).Return(nil).AnyTimes()
+	d.repo.EXPECT().ReadByEmail(gomock.Any(), u.Email).Return(u, nil).AnyTimes()
+}
+
+func testUserWithPassword(t *testing.T, roles ...domain.Role) domain.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain.User{ID: 1, Email: "pizzas@gmail.com", Password: string(hash), Roles: roles}
+}
+
+func TestTOTP(t *testing.T) {
+	user := testUserWithPassword(t, domain.RoleUser)
+	ctx := context.Background()
+	s, deps := newTestService(t)
+	deps.expectTOTPStorage(user)
+
+	enrollment, err := s.EnrollTOTP(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/Micro-Pizzas:pizzas@gmail.com?") ||
+		!strings.Contains(enrollment.URI, "secret="+enrollment.Secret) {
+		t.Fatalf("unexpected provisioning uri %s", enrollment.URI)
+	}
+
+	// totp is not used for sign in till it is confirmed
+	deps.jwt.EXPECT().Generate(user.ID, user.Roles, user.Permissions, user.Grants, gomock.Any()).Return(domain.SignInOutput{AccessKey: "access"}, nil).Times(2)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	if _, err := s.SignInEmailPassword(ctx, user.Email, "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ConfirmTOTP(ctx, user.ID, "000000"); !errors.Is(err, domain.ErrInvalidCode) {
+		t.Fatalf("expected %v, got %v", domain.ErrInvalidCode, err)
+	}
+	code := totpNow(t, enrollment.Secret)
+	recoveryCodes, err := s.ConfirmTOTP(ctx, user.ID, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recoveryCodes) != domain.RecoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d", domain.RecoveryCodeCount, len(recoveryCodes))
+	}
+	if _, err := s.EnrollTOTP(ctx, user.ID); !errors.Is(err, domain.ErrTOTPAlreadyEnabled) {
+		t.Fatalf("expected %v, got %v", domain.ErrTOTPAlreadyEnabled, err)
+	}
+
+	challenge := func() string {
+		t.Helper()
+		_, err := s.SignInEmailPassword(ctx, user.Email, "password")
+		var required *domain.SecondFactorRequiredError
+		if !errors.As(err, &required) {
+			t.Fatalf("expected *SecondFactorRequiredError, got %v", err)
+		}
+		if required.Enrollment != nil {
+			t.Fatal("user with totp should not enroll again")
+		}
+		return required.ChallengeToken
+	}
+
+	token := challenge()
+	testCases := []struct {
+		name  string
+		token string
+		code  string
+		err   error
+	}{
+		{name: "fail with unknown challenge", token: "token", code: code, err: domain.ErrInvalidChallenge},
+		{name: "fail with code that was already used", token: token, code: code, err: domain.ErrInvalidCode},
+		{name: "fail with unknown recovery code", token: token, code: "aaaa-bbbb-cccc-dddd", err: domain.ErrInvalidCode},
+		{name: "success with recovery code", token: token, code: strings.ToUpper(recoveryCodes[0]), err: nil},
+		{name: "fail with used challenge", token: token, code: recoveryCodes[1], err: domain.ErrInvalidChallenge},
+		{name: "fail with used recovery code", token: challenge(), code: recoveryCodes[0], err: domain.ErrInvalidCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := s.VerifySecondFactor(ctx, tc.token, tc.code)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && out.AccessKey != "access" {
+				t.Fatalf("expected tokens, got %+v", out)
+			}
+		})
+	}
+}
+
+func TestRequiredSecondFactor(t *testing.T) {
+	admin := testUserWithPassword(t, domain.RoleAdmin)
+	ctx := context.Background()
+	s, deps := newTestService(t, domain.WithRequiredSecondFactor(domain.RoleOwner, domain.RoleAdmin))
+	deps.expectTOTPStorage(admin)
+
+	_, err := s.SignInEmailPassword(ctx, admin.Email, "password")
+	var required *domain.SecondFactorRequiredError
+	if !errors.As(err, &required) {
+		t.Fatalf("expected *SecondFactorRequiredError, got %v", err)
+	}
+	if required.Enrollment == nil {
+		t.Fatal("expected admin to enroll totp during sign in")
+	}
+
+	deps.jwt.EXPECT().Generate(admin.ID, admin.Roles, admin.Permissions, admin.Grants, gomock.Any()).Return(domain.SignInOutput{AccessKey: "access"}, nil)
+	deps.repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	out, err := s.VerifySecondFactor(ctx, required.ChallengeToken, totpNow(t, required.Enrollment.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.RecoveryCodes) != domain.RecoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d", domain.RecoveryCodeCount, len(out.RecoveryCodes))
+	}
+
+	if err := s.DisableTOTP(ctx, admin.ID, out.RecoveryCodes[0], ""); !errors.Is(err, domain.ErrNotAllowed) {
+		t.Fatalf("expected %v, got %v", domain.ErrNotAllowed, err)
+	}
+}
+
+func TestDisableTOTP(t *testing.T) {
+	user := testUserWithPassword(t, domain.RoleUser)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		code     bool
+		password string
+		err      error
+	}{
+		{name: "disable with recovery code", code: true},
+		{name: "disable with password", password: "password"},
+		{name: "fail with wrong password", password: "drowssap", err: domain.ErrInvalidCredentials},
+		{name: "fail without code and password", err: domain.ErrInvalidCredentials},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, deps := newTestService(t)
+			deps.expectTOTPStorage(user)
+			enrollment, err := s.EnrollTOTP(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recoveryCodes, err := s.ConfirmTOTP(ctx, user.ID, totpNow(t, enrollment.Secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.err == nil {
+				deps.repo.EXPECT().DeleteTOTP(gomock.Any(), user.ID).Return(nil)
+			}
+
+			code := ""
+			// totp code was used for confirmation already
+			if tc.code {
+				code = recoveryCodes[0]
+			}
+			if err := s.DisableTOTP(ctx, user.ID, code, tc.password); !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSecondFactorBruteForce(t *testing.T) {
+	user := testUserWithPassword(t, domain.RoleUser)
+	ctx := context.Background()
+	s, deps := newTestService(t)
+	deps.expectTOTPStorage(user)
+
+	enrollment, err := s.EnrollTOTP(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ConfirmTOTP(ctx, user.ID, totpNow(t, enrollment.Secret)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.SignInEmailPassword(ctx, user.Email, "password")
+	var required *domain.SecondFactorRequiredError
+	if !errors.As(err, &required) {
+		t.Fatalf("expected *SecondFactorRequiredError, got %v", err)
+	}
+
+	for i := 1; i < domain.CodeMaxAttempts; i++ {
+		if _, err := s.VerifySecondFactor(ctx, required.ChallengeToken, "000000"); !errors.Is(err, domain.ErrInvalidCode) {
+			t.Fatalf("attempt %d: expected %v, got %v", i, domain.ErrInvalidCode, err)
+		}
+	}
+	if _, err := s.VerifySecondFactor(ctx, required.ChallengeToken, "000000"); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("expected %v, got %v", domain.ErrTooManyAttempts, err)
+	}
+	// the challenge is gone, password has to be typed again
+	if _, err := s.VerifySecondFactor(ctx, required.ChallengeToken, "000000"); !errors.Is(err, domain.ErrInvalidChallenge) {
+		t.Fatalf("expected %v, got %v", domain.ErrInvalidChallenge, err)
+	}
+}
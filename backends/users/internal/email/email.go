@@ -0,0 +1,10 @@
+package email
+
+// Same story as with sms.SMSsender, this one does not send anything
+// until we get a real mail provider.
+type Emailer struct {
+}
+
+func (e *Emailer) Send(email, title, text string) error {
+	return nil
+}
@@ -0,0 +1,113 @@
+package jwtlib
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// Clients sign assertions with their own keys, we know only public
+// parts of them from the JWKS they were registered with. Assertions
+// have no typ of ours, iss and sub are both the client id.
+
+type clientKey struct {
+	id  string
+	alg Algorithm
+	key interface{}
+}
+
+// publicKey returns the key and the only algorithm it may be used with
+func (k JWK) publicKey() (clientKey, error) {
+	key := clientKey{id: k.KeyID}
+	switch {
+	case k.Use != "" && k.Use != "sig":
+		return clientKey{}, ErrUnknownKey
+	case k.KeyType == "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return clientKey{}, ErrMalformed
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		// clients get no weaker keys than the ones we sign with
+		if pub.N.BitLen() < rsaKeyBits {
+			return clientKey{}, ErrWeakKey
+		}
+		key.alg, key.key = AlgRS256, pub
+	case k.KeyType == "OKP" && k.Curve == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return clientKey{}, ErrMalformed
+		}
+		key.alg = AlgEdDSA
+		key.key = ed25519.PublicKey(x)
+	default:
+		return clientKey{}, ErrUnknownAlgorithm
+	}
+	if k.Algorithm != "" && k.Algorithm != string(key.alg) {
+		return clientKey{}, ErrAlgMismatch
+	}
+	return key, nil
+}
+
+// clientKeyfunc finds the key by kid, kid may be left out
+// only if client has a single key
+func clientKeyfunc(set JWKS) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, v := range set.Keys {
+			if v.KeyID != kid && (kid != "" || len(set.Keys) != 1) {
+				continue
+			}
+			key, err := v.publicKey()
+			if err != nil {
+				return nil, err
+			}
+			if token.Method.Alg() != string(key.alg) {
+				return nil, ErrAlgMismatch
+			}
+			return key.key, nil
+		}
+		return nil, ErrUnknownKey
+	}
+}
+
+func (j *jwtmanager) DecodeClientAssertion(assertion string, jwks []byte) (domain.ClientAssertion, error) {
+	var set JWKS
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return domain.ClientAssertion{}, invalid(ErrUnknownKey, "keys of the client are malformed")
+	}
+	claims := RegisteredClaims{}
+	if err := parse(assertion, &claims, clientKeyfunc(set)); err != nil {
+		return domain.ClientAssertion{}, err
+	}
+
+	switch {
+	case claims.Issuer == "" || claims.ID == "" || claims.ExpiresAt == 0 || len(claims.Audience) == 0:
+		return domain.ClientAssertion{}, invalid(ErrMissingClaim, "")
+	case claims.Subject != claims.Issuer:
+		return domain.ClientAssertion{}, invalid(ErrInvalidIssuer, "sub has to be the same as iss")
+	}
+	audience := false
+	for _, v := range j.assertionAudiences {
+		audience = audience || claims.Audience.Contains(v)
+	}
+	if !audience {
+		return domain.ClientAssertion{}, invalid(ErrInvalidAudience, "")
+	}
+	if err := claims.validateTime(time.Now(), j.skew); err != nil {
+		return domain.ClientAssertion{}, err
+	}
+
+	return domain.ClientAssertion{
+		ClientID:  claims.Issuer,
+		ID:        claims.ID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,170 @@
+package jwtlib_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/jwtlib"
+)
+
+func TestClientAssertion(t *testing.T) {
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: jwtlib.AlgEdDSA, RetiredKeyTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jwtlib.NewJwtManager(keys, jwtlib.WithIssuer("https://users.micro-pizzas.com"),
+		jwtlib.WithAssertionAudiences("https://users.micro-pizzas.com", "https://users.micro-pizzas.com/oauth2/token"))
+
+	pub, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, stranger, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(jwtlib.JWKS{Keys: []jwtlib.JWK{{
+		KeyType: "OKP",
+		KeyID:   "orders-1",
+		Curve:   "Ed25519",
+		X:       base64.RawURLEncoding.EncodeToString(pub),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	valid := jwtlib.RegisteredClaims{
+		Issuer:    "orders",
+		Subject:   "orders",
+		Audience:  jwtlib.Audience{"https://users.micro-pizzas.com/oauth2/token"},
+		ExpiresAt: now.Add(time.Minute).Unix(),
+		IssuedAt:  now.Unix(),
+		ID:        "assertion",
+	}
+	sign := func(key ed25519.PrivateKey, kid string, claims jwtlib.RegisteredClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	change := func(f func(c *jwtlib.RegisteredClaims)) jwtlib.RegisteredClaims {
+		c := valid
+		f(&c)
+		return c
+	}
+
+	testCases := []struct {
+		name      string
+		assertion string
+		err       error
+	}{
+		{
+			name:      "accept assertion",
+			assertion: sign(private, "orders-1", valid),
+		},
+		{
+			name:      "accept assertion without kid when there is one key",
+			assertion: sign(private, "", valid),
+		},
+		{
+			name:      "accept issuer as audience",
+			assertion: sign(private, "orders-1", change(func(c *jwtlib.RegisteredClaims) { c.Audience = jwtlib.Audience{"https://users.micro-pizzas.com"} })),
+		},
+		{
+			name:      "fail with key of somebody else",
+			assertion: sign(stranger, "orders-1", valid),
+			err:       jwtlib.ErrSignature,
+		},
+		{
+			name:      "fail with unknown kid",
+			assertion: sign(private, "orders-2", valid),
+			err:       jwtlib.ErrUnknownKey,
+		},
+		{
+			name:      "fail with another audience",
+			assertion: sign(private, "orders-1", change(func(c *jwtlib.RegisteredClaims) { c.Audience = jwtlib.Audience{"products"} })),
+			err:       jwtlib.ErrInvalidAudience,
+		},
+		{
+			name:      "fail when subject is not issuer",
+			assertion: sign(private, "orders-1", change(func(c *jwtlib.RegisteredClaims) { c.Subject = "admin" })),
+			err:       jwtlib.ErrInvalidIssuer,
+		},
+		{
+			name:      "fail without id",
+			assertion: sign(private, "orders-1", change(func(c *jwtlib.RegisteredClaims) { c.ID = "" })),
+			err:       jwtlib.ErrMissingClaim,
+		},
+		{
+			name:      "fail when expired",
+			assertion: sign(private, "orders-1", change(func(c *jwtlib.RegisteredClaims) { c.ExpiresAt = now.Add(-time.Hour).Unix() })),
+			err:       jwtlib.ErrExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := m.DecodeClientAssertion(tc.assertion, jwks)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && (claims.ClientID != "orders" || claims.ID != "assertion") {
+				t.Errorf("got %+v", claims)
+			}
+		})
+	}
+}
+
+func TestClientAssertionRejectsShortRSAKeys(t *testing.T) {
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: jwtlib.AlgEdDSA, RetiredKeyTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jwtlib.NewJwtManager(keys, jwtlib.WithAssertionAudiences("https://users.micro-pizzas.com"))
+
+	private, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(jwtlib.JWKS{Keys: []jwtlib.JWK{{
+		KeyType: "RSA",
+		KeyID:   "orders-1",
+		N:       base64.RawURLEncoding.EncodeToString(private.N.Bytes()),
+		E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(private.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwtlib.RegisteredClaims{
+		Issuer:    "orders",
+		Subject:   "orders",
+		Audience:  jwtlib.Audience{"https://users.micro-pizzas.com"},
+		ExpiresAt: now.Add(time.Minute).Unix(),
+		IssuedAt:  now.Unix(),
+		ID:        "assertion",
+	})
+	token.Header["kid"] = "orders-1"
+	assertion, err := token.SignedString(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.DecodeClientAssertion(assertion, jwks); !errors.Is(err, jwtlib.ErrWeakKey) {
+		t.Errorf("expected %v, got %v", jwtlib.ErrWeakKey, err)
+	}
+}
@@ -0,0 +1,89 @@
+package jwtlib
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+	TypeID      = "id"
+)
+
+type (
+	// Audience is a list but a single string is accepted as well,
+	// both forms are allowed by RFC 7519.
+	Audience []string
+
+	// RegisteredClaims are claims from RFC 7519 plus typ, so a refresh
+	// token can never pass for an access token and the other way round.
+	RegisteredClaims struct {
+		Issuer    string   `json:"iss,omitempty"`
+		Subject   string   `json:"sub,omitempty"`
+		Audience  Audience `json:"aud,omitempty"`
+		ExpiresAt int64    `json:"exp,omitempty"`
+		NotBefore int64    `json:"nbf,omitempty"`
+		IssuedAt  int64    `json:"iat,omitempty"`
+		ID        string   `json:"jti,omitempty"`
+		Type      string   `json:"typ,omitempty"`
+	}
+)
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a Audience) Contains(audience string) bool {
+	for _, v := range a {
+		if v == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid is called by jwt parser, real checks are in validate
+// since they depend on configuration of the manager.
+func (c RegisteredClaims) Valid() error {
+	return nil
+}
+
+func (c RegisteredClaims) validate(typ, issuer, audience string, now time.Time, skew time.Duration) error {
+	switch {
+	case c.Type == "" || c.ID == "" || c.Issuer == "" || len(c.Audience) == 0 ||
+		c.ExpiresAt == 0 || c.IssuedAt == 0:
+		return invalid(ErrMissingClaim, "")
+	case c.Type != typ:
+		return invalid(ErrWrongTokenType, "got "+c.Type+", want "+typ)
+	case c.Issuer != issuer:
+		return invalid(ErrInvalidIssuer, c.Issuer)
+	case !c.Audience.Contains(audience):
+		return invalid(ErrInvalidAudience, "")
+	}
+	return c.validateTime(now, skew)
+}
+
+func (c RegisteredClaims) validateTime(now time.Time, skew time.Duration) error {
+	leeway := int64(skew / time.Second)
+	unix := now.Unix()
+	switch {
+	case unix-leeway >= c.ExpiresAt:
+		return invalid(ErrExpired, "")
+	case c.NotBefore != 0 && unix+leeway < c.NotBefore:
+		return invalid(ErrNotYetValid, "")
+	case c.IssuedAt != 0 && unix+leeway < c.IssuedAt:
+		return invalid(ErrIssuedInFuture, "")
+	}
+	return nil
+}
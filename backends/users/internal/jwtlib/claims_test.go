@@ -0,0 +1,241 @@
+package jwtlib_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/jwtlib"
+)
+
+func TestClaimValidation(t *testing.T) {
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: jwtlib.AlgEdDSA, RetiredKeyTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newManager := func(accessExp time.Duration, opts ...jwtlib.Option) domain.JWTmanager {
+		m := jwtlib.NewJwtManager(keys, opts...)
+		m.SetExp(accessExp, time.Hour)
+		return m
+	}
+	issue := func(m domain.JWTmanager) domain.SignInOutput {
+		out, err := m.Generate(1, []domain.Role{domain.RoleUser}, nil, nil, "family")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	users := newManager(time.Minute, jwtlib.WithAudiences("users", "orders"))
+	orders := newManager(time.Minute, jwtlib.WithAudience("orders"))
+	products := newManager(time.Minute, jwtlib.WithAudience("products"))
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwtlib.Claims{UserID: 1}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		decode func() error
+		err    error
+	}{
+		{
+			name: "accept access token",
+			decode: func() error {
+				_, err := users.DecodeAccess(issue(users).AccessKey)
+				return err
+			},
+			err: nil,
+		},
+		{
+			name: "accept access token in another audience",
+			decode: func() error {
+				_, err := orders.DecodeAccess(issue(users).AccessKey)
+				return err
+			},
+			err: nil,
+		},
+		{
+			name: "reject access token for another service",
+			decode: func() error {
+				_, err := products.DecodeAccess(issue(users).AccessKey)
+				return err
+			},
+			err: jwtlib.ErrInvalidAudience,
+		},
+		{
+			name: "reject refresh token as access token",
+			decode: func() error {
+				_, err := users.DecodeAccess(issue(users).RefreshKey)
+				return err
+			},
+			err: jwtlib.ErrWrongTokenType,
+		},
+		{
+			name: "reject access token as refresh token",
+			decode: func() error {
+				_, err := users.DecodeRefresh(issue(users).AccessKey)
+				return err
+			},
+			err: jwtlib.ErrWrongTokenType,
+		},
+		{
+			name: "reject token of another issuer",
+			decode: func() error {
+				_, err := users.DecodeAccess(issue(newManager(time.Minute, jwtlib.WithIssuer("evil"))).AccessKey)
+				return err
+			},
+			err: jwtlib.ErrInvalidIssuer,
+		},
+		{
+			name: "reject expired token",
+			decode: func() error {
+				_, err := users.DecodeAccess(issue(newManager(-time.Minute)).AccessKey)
+				return err
+			},
+			err: jwtlib.ErrExpired,
+		},
+		{
+			name: "accept recently expired token within clock skew",
+			decode: func() error {
+				skewed := newManager(time.Minute, jwtlib.WithClockSkew(time.Minute*2))
+				_, err := skewed.DecodeAccess(issue(newManager(-time.Minute)).AccessKey)
+				return err
+			},
+			err: nil,
+		},
+		{
+			name: "reject unsigned token",
+			decode: func() error {
+				_, err := users.DecodeAccess(none)
+				return err
+			},
+			err: jwtlib.ErrAlgMismatch,
+		},
+		{
+			name: "reject garbage",
+			decode: func() error {
+				_, err := users.DecodeAccess("not.a.token")
+				return err
+			},
+			err: jwtlib.ErrMalformed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.decode()
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+			if tc.err != nil && !errors.Is(err, domain.ErrInvalidToken) {
+				t.Errorf("%v is not domain.ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestGrantTokens(t *testing.T) {
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: jwtlib.AlgEdDSA, RetiredKeyTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jwtlib.NewJwtManager(keys)
+	m.SetExp(time.Minute, time.Hour)
+
+	t.Run("client acting on its own", func(t *testing.T) {
+		out, err := m.GenerateGrant(domain.TokenGrant{ClientID: "aggregator", Scopes: []string{"menu:read", "orders:read"}}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.RefreshKey != "" {
+			t.Error("refresh token was made without a family")
+		}
+		claims, err := m.DecodeAccess(out.AccessKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.UserID != 0 || claims.ClientID != "aggregator" || len(claims.Scopes) != 2 || claims.Scopes[1] != "orders:read" {
+			t.Errorf("got claims %+v", claims)
+		}
+	})
+
+	t.Run("user with permissions", func(t *testing.T) {
+		out, err := m.Generate(7, []domain.Role{domain.RoleDeliveryMan}, []string{"orders:deliver"}, nil, "family")
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := m.DecodeAccess(out.AccessKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !claims.HasPermission("orders:deliver") || claims.HasPermission("users:read") {
+			t.Errorf("got claims %+v", claims)
+		}
+	})
+
+	t.Run("scoped and expiring grants", func(t *testing.T) {
+		soon, past := time.Now().Add(time.Minute), time.Now().Add(-time.Minute)
+		grants := []domain.RoleGrant{
+			{Role: domain.RoleModerator, Scope: "branch:12", ExpiresAt: &soon},
+			{Role: domain.RoleAdmin, Scope: "store:3", ExpiresAt: &past},
+			{Role: domain.RoleDeliveryMan},
+		}
+		out, err := m.Generate(7, []domain.Role{domain.RoleDeliveryMan}, nil, grants, "family")
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := m.DecodeAccess(out.AccessKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// expired one is dropped and unscoped one is already in roles
+		if len(claims.Grants) != 1 || claims.Grants[0].Scope != "branch:12" || claims.Grants[0].ExpiresAt.Unix() != soon.Unix() {
+			t.Errorf("got grants %+v", claims.Grants)
+		}
+		var raw jwtlib.Claims
+		if _, _, err := new(jwt.Parser).ParseUnverified(out.AccessKey, &raw); err != nil {
+			t.Fatal(err)
+		}
+		if raw.ExpiresAt != soon.Unix() {
+			t.Errorf("access token expires at %d, want %d", raw.ExpiresAt, soon.Unix())
+		}
+	})
+
+	t.Run("client acting for user", func(t *testing.T) {
+		out, err := m.GenerateGrant(domain.TokenGrant{ClientID: "spa", UserID: 7, Scopes: []string{"openid", "offline_access"}}, "family")
+		if err != nil {
+			t.Fatal(err)
+		}
+		refresh, err := m.DecodeRefresh(out.RefreshKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if refresh.UserID != 7 || refresh.ClientID != "spa" || len(refresh.Scopes) != 2 {
+			t.Errorf("got refresh claims %+v", refresh)
+		}
+	})
+
+	t.Run("id token", func(t *testing.T) {
+		idToken, err := m.GenerateIDToken("spa", "nonce", domain.UserInfo{Subject: "7", Email: "jane@gmail.com", EmailVerified: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var claims jwtlib.IDClaims
+		if _, _, err := new(jwt.Parser).ParseUnverified(idToken, &claims); err != nil {
+			t.Fatal(err)
+		}
+		if claims.Subject != "7" || !claims.Audience.Contains("spa") || claims.Nonce != "nonce" ||
+			claims.Email != "jane@gmail.com" || !claims.EmailVerified || claims.Type != jwtlib.TypeID {
+			t.Errorf("got id token claims %+v", claims)
+		}
+		// id token is for the client, our services must not take it as access token
+		if _, err := m.DecodeAccess(idToken); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("id token was accepted as access token: %v", err)
+		}
+	})
+}
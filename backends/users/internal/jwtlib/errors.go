@@ -0,0 +1,52 @@
+package jwtlib
+
+import (
+	"errors"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("jwtlib: unknown signing algorithm")
+	ErrNoKeys           = errors.New("jwtlib: keyring is empty")
+
+	// reasons why a token was rejected
+	ErrMalformed       = errors.New("jwtlib: token is malformed")
+	ErrUnknownKey      = errors.New("jwtlib: token is signed with unknown key")
+	ErrAlgMismatch     = errors.New("jwtlib: token algorithm does not match the key")
+	ErrWeakKey         = errors.New("jwtlib: rsa key is shorter than 2048 bits")
+	ErrSignature       = errors.New("jwtlib: signature is invalid")
+	ErrWrongTokenType  = errors.New("jwtlib: wrong token type")
+	ErrInvalidIssuer   = errors.New("jwtlib: token is issued by someone else")
+	ErrInvalidAudience = errors.New("jwtlib: token is not meant for this service")
+	ErrExpired         = errors.New("jwtlib: token is expired")
+	ErrNotYetValid     = errors.New("jwtlib: token is not valid yet")
+	ErrIssuedInFuture  = errors.New("jwtlib: token is issued in the future")
+	ErrMissingClaim    = errors.New("jwtlib: required claim is missing")
+)
+
+// ValidationError tells why token was rejected. It is domain.ErrInvalidToken
+// for the service and one of the reasons above for whoever needs details.
+type ValidationError struct {
+	Reason error
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Detail == "" {
+		return e.Reason.Error()
+	}
+	return e.Reason.Error() + ": " + e.Detail
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Reason
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == domain.ErrInvalidToken
+}
+
+func invalid(reason error, detail string) error {
+	return &ValidationError{Reason: reason, Detail: detail}
+}
@@ -0,0 +1,83 @@
+package jwtlib
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// JWKSPath is where other services expect to find our public keys
+	JWKSPath = "/.well-known/jwks.json"
+	// JWKSMaxAge is how long clients may cache JWKS
+	JWKSMaxAge = time.Minute * 5
+)
+
+type (
+	// JWK is a public key in RFC 7517 format, only fields
+	// for RSA and Ed25519 keys are here
+	JWK struct {
+		KeyType   string `json:"kty"`
+		KeyID     string `json:"kid"`
+		Use       string `json:"use"`
+		Algorithm string `json:"alg"`
+
+		// RSA
+		N string `json:"n,omitempty"`
+		E string `json:"e,omitempty"`
+
+		// Ed25519
+		Curve string `json:"crv,omitempty"`
+		X     string `json:"x,omitempty"`
+	}
+
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// JWKS returns public parts of all the keys that can still verify tokens
+func (k *Keyring) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	set := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwk := JWK{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: string(key.alg),
+		}
+		switch pub := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// ServeHTTP publishes JWKS. Clients may cache it for JWKSMaxAge
+// but have to fetch it again when they see an unknown kid.
+func (k *Keyring) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(JWKSMaxAge.Seconds())))
+	_ = json.NewEncoder(w).Encode(k.JWKS())
+}
@@ -1,6 +1,11 @@
 package jwtlib
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -8,17 +13,39 @@ import (
 )
 
 type jwtmanager struct {
-	key        []byte
+	keys       *Keyring
 	accessExp  time.Duration
 	refreshExp time.Duration
-}
 
-func NewJwtManager(key []byte) *jwtmanager {
-	return &jwtmanager{key: key}
+	issuer    string
+	audiences []string
+	audience  string
+	skew      time.Duration
+
+	assertionAudiences []string
 }
 
-func (j *jwtmanager) SetKey(key []byte) {
-	j.key = key
+// NewJwtManager signs tokens with the newest key of the keyring,
+// other services verify them with keys from JWKS.
+func NewJwtManager(keys *Keyring, opts ...Option) *jwtmanager {
+	j := &jwtmanager{
+		keys:   keys,
+		issuer: DefaultIssuer,
+		skew:   DefaultClockSkew,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.audience == "" {
+		j.audience = j.issuer
+	}
+	if len(j.audiences) == 0 {
+		j.audiences = []string{j.audience}
+	}
+	if len(j.assertionAudiences) == 0 {
+		j.assertionAudiences = []string{j.issuer}
+	}
+	return j
 }
 
 func (j *jwtmanager) SetExp(accessExp, refreshExp time.Duration) {
@@ -27,81 +54,256 @@ func (j *jwtmanager) SetExp(accessExp, refreshExp time.Duration) {
 }
 
 type Claims struct {
-	jwt.StandardClaims
-	UserID domain.ID
-	Roles  []domain.Role
+	RegisteredClaims
+	UserID    domain.ID
+	SessionID string
+	Roles     []domain.Role
+	// other services check permissions, not roles
+	Permissions []string `json:"permissions,omitempty"`
+	// roles that work only in some branch or store
+	Grants []GrantClaim `json:"grants,omitempty"`
+
+	// tokens of OAuth clients have them like RFC 9068 says
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 }
 
+type GrantClaim struct {
+	Role      domain.Role `json:"role"`
+	Scope     string      `json:"scope"`
+	ExpiresAt int64       `json:"exp,omitempty"`
+}
+
+// RefreshClaims are meant only for us, so their
+// audience is always the issuer itself.
 type RefreshClaims struct {
-	jwt.StandardClaims
-	UserID domain.ID
+	RegisteredClaims
+	UserID   domain.ID
+	FamilyID string
+
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+}
+
+// IDClaims are claims of OpenID Connect id token,
+// its audience is the client it was issued to.
+type IDClaims struct {
+	RegisteredClaims
+	Nonce         string `json:"nonce,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	PhoneNumber   string `json:"phone_number,omitempty"`
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (j *jwtmanager) Generate(userID domain.ID, roles []domain.Role, permissions []string, grants []domain.RoleGrant, familyID string) (domain.SignInOutput, error) {
+	return j.GenerateGrant(domain.TokenGrant{UserID: userID, Roles: roles, Permissions: permissions, Grants: grants}, familyID)
 }
 
-func (j *jwtmanager) Generate(userID domain.ID, roles []domain.Role) (domain.SignInOutput, error) {
-	claims := Claims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(j.accessExp).Unix(),
-		},
-		UserID: userID,
-		Roles:  roles,
+// grantClaims keeps only active scoped grants, unscoped ones are already
+// in roles. Access token should not outlive any grant it carries.
+func grantClaims(grants []domain.RoleGrant, now, exp time.Time) ([]GrantClaim, time.Time) {
+	var claims []GrantClaim
+	for _, g := range grants {
+		if !g.Active(now) {
+			continue
+		}
+		if g.ExpiresAt != nil && g.ExpiresAt.Before(exp) {
+			exp = *g.ExpiresAt
+		}
+		if g.Scope == "" {
+			continue
+		}
+		claim := GrantClaim{Role: g.Role, Scope: g.Scope}
+		if g.ExpiresAt != nil {
+			claim.ExpiresAt = g.ExpiresAt.Unix()
+		}
+		claims = append(claims, claim)
 	}
+	return claims, exp
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString(j.key)
+// GenerateGrant makes refresh token only for a family, tokens of a client
+// acting on its own behalf have client id as subject.
+func (j *jwtmanager) GenerateGrant(grant domain.TokenGrant, familyID string) (domain.SignInOutput, error) {
+	key, err := j.keys.signer()
 	if err != nil {
 		return domain.SignInOutput{}, err
 	}
+	now := time.Now()
+	subject := grant.ClientID
+	if grant.UserID != 0 {
+		subject = strconv.FormatUint(uint64(grant.UserID), 10)
+	}
+	scope := strings.Join(grant.Scopes, " ")
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, RefreshClaims{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(j.refreshExp).Unix(),
-		},
+	accessID, err := newID()
+	if err != nil {
+		return domain.SignInOutput{}, err
+	}
+	grants, accessExpiresAt := grantClaims(grant.Grants, now, now.Add(j.accessExp))
+	accessToken, err := sign(key, Claims{
+		RegisteredClaims: j.registered(TypeAccess, accessID, subject, j.audiences, now, accessExpiresAt),
+		UserID:           grant.UserID,
+		SessionID:        familyID,
+		Roles:            grant.Roles,
+		Permissions:      grant.Permissions,
+		Grants:           grants,
+		ClientID:         grant.ClientID,
+		Scope:            scope,
+	})
+	if err != nil {
+		return domain.SignInOutput{}, err
+	}
+	if familyID == "" {
+		return domain.SignInOutput{AccessKey: accessToken}, nil
+	}
+
+	refreshID, err := newID()
+	if err != nil {
+		return domain.SignInOutput{}, err
+	}
+	refreshExpiresAt := now.Add(j.refreshExp)
+	refreshKey, err := sign(key, RefreshClaims{
+		RegisteredClaims: j.registered(TypeRefresh, refreshID, subject, []string{j.issuer}, now, refreshExpiresAt),
+		UserID:           grant.UserID,
+		FamilyID:         familyID,
+		ClientID:         grant.ClientID,
+		Scope:            scope,
 	})
-	refreshKey, err := refreshToken.SignedString(j.key)
 	if err != nil {
 		return domain.SignInOutput{}, err
 	}
 
 	return domain.SignInOutput{
-		AccessKey:  accessToken,
-		RefreshKey: refreshKey,
+		AccessKey:        accessToken,
+		RefreshKey:       refreshKey,
+		RefreshID:        refreshID,
+		RefreshExpiresAt: time.Unix(refreshExpiresAt.Unix(), 0).UTC(),
 	}, nil
 }
 
-func (j *jwtmanager) DecodeAccess(accessKey string) (domain.AccessClaims, error) {
-	token, err := jwt.ParseWithClaims(accessKey, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.key, nil
-	})
+// GenerateIDToken lives as long as access tokens do
+func (j *jwtmanager) GenerateIDToken(clientID, nonce string, info domain.UserInfo) (string, error) {
+	key, err := j.keys.signer()
 	if err != nil {
-		return domain.AccessClaims{}, err
+		return "", err
+	}
+	id, err := newID()
+	if err != nil {
+		return "", err
 	}
+	now := time.Now()
+	return sign(key, IDClaims{
+		RegisteredClaims: j.registered(TypeID, id, info.Subject, []string{clientID}, now, now.Add(j.accessExp)),
+		Nonce:            nonce,
+		Name:             info.Name,
+		Email:            info.Email,
+		EmailVerified:    info.EmailVerified,
+		PhoneNumber:      info.PhoneNumber,
+	})
+}
+
+func (j *jwtmanager) registered(typ, id, subject string, aud []string, now, exp time.Time) RegisteredClaims {
+	return RegisteredClaims{
+		Issuer:    j.issuer,
+		Subject:   subject,
+		Audience:  aud,
+		ExpiresAt: exp.Unix(),
+		NotBefore: now.Unix(),
+		IssuedAt:  now.Unix(),
+		ID:        id,
+		Type:      typ,
+	}
+}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok {
+func sign(key signingKey, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+func (j *jwtmanager) DecodeAccess(accessKey string) (domain.AccessClaims, error) {
+	claims := Claims{}
+	if err := j.parse(accessKey, &claims); err != nil {
+		return domain.AccessClaims{}, err
+	}
+	if err := claims.validate(TypeAccess, j.issuer, j.audience, time.Now(), j.skew); err != nil {
 		return domain.AccessClaims{}, err
 	}
 
+	var grants []domain.RoleGrant
+	for _, g := range claims.Grants {
+		grant := domain.RoleGrant{Role: g.Role, Scope: g.Scope}
+		if g.ExpiresAt != 0 {
+			expiresAt := time.Unix(g.ExpiresAt, 0).UTC()
+			grant.ExpiresAt = &expiresAt
+		}
+		grants = append(grants, grant)
+	}
 	return domain.AccessClaims{
-		UserID: claims.UserID,
-		Roles:  claims.Roles,
+		UserID:      claims.UserID,
+		SessionID:   claims.SessionID,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		Grants:      grants,
+		ClientID:    claims.ClientID,
+		Scopes:      strings.Fields(claims.Scope),
 	}, nil
 }
 
 func (j *jwtmanager) DecodeRefresh(refreshKey string) (domain.RefreshClaims, error) {
-	token, err := jwt.ParseWithClaims(refreshKey, &RefreshClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.key, nil
-	})
-	if err != nil {
+	claims := RefreshClaims{}
+	if err := j.parse(refreshKey, &claims); err != nil {
 		return domain.RefreshClaims{}, err
 	}
-
-	claims, ok := token.Claims.(*RefreshClaims)
-	if !ok {
+	if err := claims.validate(TypeRefresh, j.issuer, j.issuer, time.Now(), j.skew); err != nil {
 		return domain.RefreshClaims{}, err
 	}
 
 	return domain.RefreshClaims{
-		UserID: claims.UserID,
+		ID:       claims.ID,
+		FamilyID: claims.FamilyID,
+		UserID:   claims.UserID,
+		ClientID: claims.ClientID,
+		Scopes:   strings.Fields(claims.Scope),
 	}, nil
 }
+
+// parse checks only the signature, claims are validated by callers
+func (j *jwtmanager) parse(tokenString string, claims jwt.Claims) error {
+	return parse(tokenString, claims, j.keys.verificationKey)
+}
+
+func parse(tokenString string, claims jwt.Claims, keyfunc jwt.Keyfunc) error {
+	parser := jwt.Parser{ValidMethods: []string{string(AlgRS256), string(AlgEdDSA)}}
+	_, err := parser.ParseWithClaims(tokenString, claims, keyfunc)
+	if err == nil {
+		return nil
+	}
+
+	var ve *jwt.ValidationError
+	if !errors.As(err, &ve) {
+		return invalid(ErrMalformed, err.Error())
+	}
+	switch {
+	case ve.Errors&jwt.ValidationErrorMalformed != 0:
+		return invalid(ErrMalformed, ve.Error())
+	case ve.Errors&jwt.ValidationErrorUnverifiable != 0 && ve.Inner != nil:
+		// our keyfunc already knows the reason
+		return invalid(ve.Inner, "")
+	case ve.Errors&jwt.ValidationErrorSignatureInvalid != 0 && ve.Inner == nil:
+		// algorithm is not in ValidMethods
+		return invalid(ErrAlgMismatch, ve.Error())
+	default:
+		return invalid(ErrSignature, ve.Error())
+	}
+}
@@ -0,0 +1,318 @@
+package jwtlib
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+type Algorithm string
+
+const (
+	AlgRS256 Algorithm = "RS256"
+	AlgEdDSA Algorithm = "EdDSA"
+
+	rsaKeyBits = 2048
+	keyFileExt = ".pem"
+)
+
+type (
+	KeyringConfig struct {
+		Algorithm Algorithm
+		// Dir keeps private keys as PEM files, so tokens survive restarts
+		// and replicas can share keys. If empty keys live only in memory.
+		Dir string
+		// RetiredKeyTTL is how long a key is still published and
+		// used for verification after it was rotated out.
+		// It should not be shorter than lifetime of the longest token.
+		RetiredKeyTTL time.Duration
+		// PublishAhead is how long a new key is only published before
+		// it starts signing, so clients with a cached JWKS know it by then.
+		// It should not be shorter than JWKSMaxAge.
+		PublishAhead time.Duration
+	}
+
+	signingKey struct {
+		id        string
+		alg       Algorithm
+		private   crypto.Signer
+		createdAt time.Time
+	}
+
+	// Keyring signs tokens with the newest key that was published for
+	// PublishAhead and verifies them with any key that was not retired
+	// for longer than RetiredKeyTTL.
+	Keyring struct {
+		cfg KeyringConfig
+
+		mu sync.RWMutex
+		// sorted from the oldest to the newest
+		keys []signingKey
+	}
+)
+
+func NewKeyring(cfg KeyringConfig) (*Keyring, error) {
+	if cfg.Algorithm != AlgRS256 && cfg.Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, cfg.Algorithm)
+	}
+	k := &Keyring{cfg: cfg}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	empty := len(k.keys) == 0
+	k.mu.RUnlock()
+	if empty {
+		if err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// Rotate publishes a fresh key, it starts signing after PublishAhead.
+// Previous keys keep verifying tokens until RetiredKeyTTL passes.
+func (k *Keyring) Rotate() error {
+	key, err := generateKey(k.cfg.Algorithm)
+	if err != nil {
+		return fmt.Errorf("rotate(): %w", err)
+	}
+	if k.cfg.Dir != "" {
+		if err := writeKey(k.cfg.Dir, key); err != nil {
+			return fmt.Errorf("rotate(): %w", err)
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = append(k.keys, key)
+	k.prune(time.Now())
+	return nil
+}
+
+// Reload reads keys from Dir, so keys rotated by
+// other replicas are picked up. Without Dir it does nothing.
+func (k *Keyring) Reload() error {
+	if k.cfg.Dir == "" {
+		return nil
+	}
+	keys, err := readKeys(k.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("reload(): %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	for _, id := range k.prune(time.Now()) {
+		// another replica might have removed it already
+		if err := os.Remove(filepath.Join(k.cfg.Dir, id+keyFileExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reload(): %w", err)
+		}
+	}
+	return nil
+}
+
+// StartRotation rotates keys every interval. Keys from Dir are reloaded
+// every minute, so with several replicas sharing Dir only one fresh key
+// is made per interval in most cases. Call stop to end the rotation.
+func (k *Keyring) StartRotation(interval time.Duration, onError func(error)) (stop func()) {
+	check := time.Minute
+	if interval < check {
+		check = interval
+	}
+	ticker := time.NewTicker(check)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := k.rotateIfOld(interval); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (k *Keyring) rotateIfOld(interval time.Duration) error {
+	if err := k.Reload(); err != nil {
+		return err
+	}
+	k.mu.RLock()
+	old := len(k.keys) == 0 || time.Since(k.keys[len(k.keys)-1].createdAt) >= interval
+	k.mu.RUnlock()
+	if !old {
+		return nil
+	}
+	return k.Rotate()
+}
+
+// prune drops keys that were retired for too long and returns their ids,
+// a key is retired the moment the next one starts signing.
+// Callers must hold the lock.
+func (k *Keyring) prune(now time.Time) []string {
+	var (
+		kept   []signingKey
+		pruned []string
+	)
+	for i, key := range k.keys {
+		if i+1 < len(k.keys) && now.Sub(k.keys[i+1].createdAt.Add(k.cfg.PublishAhead)) > k.cfg.RetiredKeyTTL {
+			pruned = append(pruned, key.id)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	k.keys = kept
+	return pruned
+}
+
+// signer returns the newest key published for at least PublishAhead,
+// a fresh keyring has no such key so its oldest one signs
+func (k *Keyring) signer() (signingKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if len(k.keys) == 0 {
+		return signingKey{}, ErrNoKeys
+	}
+	now := time.Now()
+	for i := len(k.keys) - 1; i > 0; i-- {
+		if now.Sub(k.keys[i].createdAt) >= k.cfg.PublishAhead {
+			return k.keys[i], nil
+		}
+	}
+	return k.keys[0], nil
+}
+
+// verificationKey is a jwt.Keyfunc, it finds the key by kid
+// and makes sure the token was signed with the algorithm of that key
+func (k *Keyring) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.id != kid {
+			continue
+		}
+		if token.Method.Alg() != string(key.alg) {
+			return nil, ErrAlgMismatch
+		}
+		return key.private.Public(), nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (key signingKey) method() jwt.SigningMethod {
+	if key.alg == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+func generateKey(alg Algorithm) (signingKey, error) {
+	id, err := newID()
+	if err != nil {
+		return signingKey{}, err
+	}
+	key := signingKey{id: id, alg: alg, createdAt: time.Now()}
+	switch alg {
+	case AlgRS256:
+		key.private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, key.private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = ErrUnknownAlgorithm
+	}
+	return key, err
+}
+
+// writeKey stores key as <kid>.pem, file's modification time
+// tells when the key was created
+func writeKey(dir string, key signingKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	// write to a temp file first so other replicas never read half a key
+	tmp := filepath.Join(dir, "."+key.id)
+	if err := os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp, key.createdAt, key.createdAt); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, key.id+keyFileExt))
+}
+
+func readKeys(dir string) ([]signingKey, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []signingKey{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), keyFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s is not a pem file", e.Name())
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", e.Name(), err)
+		}
+		key := signingKey{
+			id:        strings.TrimSuffix(e.Name(), keyFileExt),
+			createdAt: info.ModTime(),
+		}
+		switch v := parsed.(type) {
+		case *rsa.PrivateKey:
+			key.alg, key.private = AlgRS256, v
+		case ed25519.PrivateKey:
+			key.alg, key.private = AlgEdDSA, v
+		default:
+			return nil, fmt.Errorf("%s: %w", e.Name(), ErrUnknownAlgorithm)
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.Before(keys[j].createdAt)
+	})
+	return keys, nil
+}
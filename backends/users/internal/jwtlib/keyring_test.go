@@ -0,0 +1,197 @@
+package jwtlib_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/jwtlib"
+)
+
+// publicKey builds a key from jwks the way other services would
+func publicKey(t *testing.T, set jwtlib.JWKS, kid string) interface{} {
+	t.Helper()
+	for _, k := range set.Keys {
+		if k.KeyID != kid {
+			continue
+		}
+		switch k.KeyType {
+		case "OKP":
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ed25519.PublicKey(x)
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		}
+	}
+	t.Fatalf("kid %q is not published", kid)
+	return nil
+}
+
+func TestTokensAreVerifiedWithJWKS(t *testing.T) {
+	for _, alg := range []jwtlib.Algorithm{jwtlib.AlgEdDSA, jwtlib.AlgRS256} {
+		t.Run(string(alg), func(t *testing.T) {
+			keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: alg, RetiredKeyTTL: time.Hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := jwtlib.NewJwtManager(keys)
+			m.SetExp(time.Minute, time.Hour)
+
+			out, err := m.Generate(1, []domain.Role{domain.RoleUser}, nil, nil, "family")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			keys.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jwtlib.JWKSPath, nil))
+			var set jwtlib.JWKS
+			if err := json.NewDecoder(rec.Body).Decode(&set); err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := jwt.Parse(out.AccessKey, func(token *jwt.Token) (interface{}, error) {
+				if token.Method.Alg() != string(alg) {
+					t.Errorf("got alg %s, want %s", token.Method.Alg(), alg)
+				}
+				kid, _ := token.Header["kid"].(string)
+				return publicKey(t, set, kid), nil
+			})
+			if err != nil || !token.Valid {
+				t.Fatalf("token is not valid with published key: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{
+		Algorithm:     jwtlib.AlgEdDSA,
+		Dir:           dir,
+		RetiredKeyTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jwtlib.NewJwtManager(keys)
+	m.SetExp(time.Minute, time.Hour)
+
+	old, err := m.Generate(1, nil, nil, nil, "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(keys.JWKS().Keys); n != 2 {
+		t.Errorf("got %d published keys, want 2", n)
+	}
+	if _, err := m.DecodeAccess(old.AccessKey); err != nil {
+		t.Errorf("token signed before rotation is rejected: %v", err)
+	}
+
+	// another replica sharing the same dir
+	replica, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{
+		Algorithm:     jwtlib.AlgEdDSA,
+		Dir:           dir,
+		RetiredKeyTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := m.Generate(1, nil, nil, nil, "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtlib.NewJwtManager(replica).DecodeAccess(fresh.AccessKey); err != nil {
+		t.Errorf("replica rejects token signed with rotated key: %v", err)
+	}
+}
+
+func TestRejectForeignKeys(t *testing.T) {
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: jwtlib.AlgEdDSA, RetiredKeyTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{Algorithm: jwtlib.AlgEdDSA, RetiredKeyTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := jwtlib.NewJwtManager(other)
+	foreign.SetExp(time.Minute, time.Hour)
+	out, err := foreign.Generate(1, []domain.Role{domain.RoleOwner}, nil, nil, "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtlib.Claims{UserID: 1}).
+		SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := jwtlib.NewJwtManager(keys)
+	for name, token := range map[string]string{"unknown kid": out.AccessKey, "hs256": hmac} {
+		if _, err := m.DecodeAccess(token); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("%s: got %v, want %v", name, err, domain.ErrInvalidToken)
+		}
+	}
+}
+
+func TestRotatedKeyIsPublishedBeforeSigning(t *testing.T) {
+	keys, err := jwtlib.NewKeyring(jwtlib.KeyringConfig{
+		Algorithm:     jwtlib.AlgEdDSA,
+		RetiredKeyTTL: time.Hour,
+		PublishAhead:  time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jwtlib.NewJwtManager(keys)
+	m.SetExp(time.Minute, time.Hour)
+
+	kid := func() string {
+		out, err := m.Generate(1, nil, nil, nil, "family")
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, _, err := new(jwt.Parser).ParseUnverified(out.AccessKey, jwt.MapClaims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		kid, _ := token.Header["kid"].(string)
+		return kid
+	}
+
+	// the only key signs right away
+	first := kid()
+	if err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(keys.JWKS().Keys); n != 2 {
+		t.Errorf("got %d published keys, want 2", n)
+	}
+	if got := kid(); got != first {
+		t.Errorf("token is signed with %q before it was published for long enough, want %q", got, first)
+	}
+}
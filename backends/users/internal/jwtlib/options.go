@@ -0,0 +1,50 @@
+package jwtlib
+
+import "time"
+
+const (
+	DefaultIssuer    = "users"
+	DefaultClockSkew = time.Second * 30
+)
+
+type Option func(*jwtmanager)
+
+// WithIssuer sets iss of issued tokens, only tokens
+// with the same iss are accepted.
+func WithIssuer(issuer string) Option {
+	return func(j *jwtmanager) {
+		j.issuer = issuer
+	}
+}
+
+// WithAudiences lists microservices access tokens are meant for,
+// each of them has to check that its own name is in aud.
+func WithAudiences(audiences ...string) Option {
+	return func(j *jwtmanager) {
+		j.audiences = audiences
+	}
+}
+
+// WithAudience is the name of this service, DecodeAccess
+// rejects tokens that were not issued for it.
+func WithAudience(audience string) Option {
+	return func(j *jwtmanager) {
+		j.audience = audience
+	}
+}
+
+// WithClockSkew is how far clocks of our servers may drift apart,
+// exp, nbf and iat are checked with this leeway.
+func WithClockSkew(skew time.Duration) Option {
+	return func(j *jwtmanager) {
+		j.skew = skew
+	}
+}
+
+// WithAssertionAudiences are values of aud client assertions may have,
+// RFC 7523 allows both the issuer and url of the token endpoint.
+func WithAssertionAudiences(audiences ...string) Option {
+	return func(j *jwtmanager) {
+		j.assertionAudiences = audiences
+	}
+}
@@ -19,3 +19,21 @@ func NewLogger(level zapcore.Level) (*Logger, error) {
 	}
 	return &Logger{l}, nil
 }
+
+// Infof and Errorf are here so Logger can be passed to domain.NewService
+
+func (l *Logger) Infof(format string, args ...string) {
+	l.Sugar().Infof(format, toInterfaces(args)...)
+}
+
+func (l *Logger) Errorf(format string, args ...string) {
+	l.Sugar().Errorf(format, toInterfaces(args)...)
+}
+
+func toInterfaces(args []string) []interface{} {
+	res := make([]interface{}, len(args))
+	for i, v := range args {
+		res[i] = v
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package oidc
+
+import (
+	"errors"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var (
+	ErrInvalidConfig = errors.New("oidc: name, issuer, client id and redirect url are required")
+	ErrDiscovery     = errors.New("oidc: could not discover provider")
+
+	// reasons why an id token was rejected
+	ErrMalformed       = errors.New("oidc: id token is malformed")
+	ErrUnknownKey      = errors.New("oidc: id token is signed with unknown key")
+	ErrSignature       = errors.New("oidc: signature is invalid")
+	ErrInvalidIssuer   = errors.New("oidc: id token is issued by someone else")
+	ErrInvalidAudience = errors.New("oidc: id token is not meant for us")
+	ErrExpired         = errors.New("oidc: id token is expired")
+	ErrIssuedInFuture  = errors.New("oidc: id token is issued in the future")
+	ErrMissingClaim    = errors.New("oidc: required claim is missing")
+)
+
+// ValidationError tells why id token was rejected. It is domain.ErrInvalidIDToken
+// for the service and one of the reasons above for whoever needs details.
+type ValidationError struct {
+	Reason error
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Detail == "" {
+		return e.Reason.Error()
+	}
+	return e.Reason.Error() + ": " + e.Detail
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Reason
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == domain.ErrInvalidIDToken
+}
+
+func invalid(reason error, detail string) error {
+	return &ValidationError{Reason: reason, Detail: detail}
+}
+
+// ExchangeError is an error response of the token endpoint, RFC 6749 section 5.2.
+// invalid_grant means the code is wrong, expired or was made for another
+// PKCE verifier, so it is domain.ErrInvalidCode.
+type ExchangeError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *ExchangeError) Error() string {
+	if e.Description == "" {
+		return "oidc: token endpoint responded with " + e.Code
+	}
+	return "oidc: token endpoint responded with " + e.Code + ": " + e.Description
+}
+
+func (e *ExchangeError) Is(target error) bool {
+	return target == domain.ErrInvalidCode && e.Code == "invalid_grant"
+}
@@ -0,0 +1,254 @@
+// Package oidctest is an OpenID Connect provider for tests. It runs on
+// httptest.Server, signs in whoever SignInAs tells without asking and
+// checks PKCE, redirect url and client credentials the way real ones do.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+type (
+	// User is who the provider signs in
+	User struct {
+		Subject       string
+		Email         string
+		EmailVerified bool
+		Name          string
+	}
+
+	authRequest struct {
+		user          User
+		redirectURL   string
+		nonce         string
+		codeChallenge string
+	}
+
+	Provider struct {
+		server       *httptest.Server
+		ClientID     string
+		ClientSecret string
+
+		// Claims can change id token claims right before signing
+		Claims func(claims jwt.MapClaims)
+
+		mu    sync.Mutex
+		user  User
+		key   *rsa.PrivateKey
+		kid   string
+		codes map[string]authRequest
+	}
+)
+
+// NewProvider starts the provider, call Close when done
+func NewProvider(clientID, clientSecret string) *Provider {
+	p := &Provider{ClientID: clientID, ClientSecret: clientSecret, codes: map[string]authRequest{}}
+	p.RotateKey()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+func (p *Provider) Close() {
+	p.server.Close()
+}
+
+func (p *Provider) Issuer() string {
+	return p.server.URL
+}
+
+// SignInAs sets who signs in at the next authorization
+func (p *Provider) SignInAs(u User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = u
+}
+
+// RotateKey makes a new signing key, the old one is not published anymore
+func (p *Provider) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.key, p.kid = key, randomString()
+}
+
+// Authorize does what a browser does with authorization url: opens it
+// and follows redirect back to us. It returns code and state we got back.
+func (p *Provider) Authorize(authURL string) (code, state string, err error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorize responded with %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	q := location.Query()
+	if q.Get("error") != "" {
+		return "", "", fmt.Errorf("authorize failed: %s", q.Get("error"))
+	}
+	return q.Get("code"), q.Get("state"), nil
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	respond(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("client_id") != p.ClientID {
+		http.Error(w, "unknown client or redirect_uri", http.StatusBadRequest)
+		return
+	}
+	back := redirect.Query()
+	back.Set("state", q.Get("state"))
+	switch {
+	case q.Get("response_type") != "code":
+		back.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		back.Set("error", "invalid_request")
+	default:
+		code := randomString()
+		p.mu.Lock()
+		p.codes[code] = authRequest{
+			user:          p.user,
+			redirectURL:   q.Get("redirect_uri"),
+			nonce:         q.Get("nonce"),
+			codeChallenge: q.Get("code_challenge"),
+		}
+		p.mu.Unlock()
+		back.Set("code", code)
+	}
+	redirect.RawQuery = back.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		respond(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+	}
+	if clientID != p.ClientID || secret != p.ClientSecret {
+		respond(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	req, found := p.codes[r.PostForm.Get("code")]
+	// codes work only once
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !found || r.PostForm.Get("grant_type") != "authorization_code" ||
+		req.redirectURL != r.PostForm.Get("redirect_uri") ||
+		req.codeChallenge != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+		respond(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer(),
+		"sub":            req.user.Subject,
+		"aud":            p.ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          req.nonce,
+		"email":          req.user.Email,
+		"email_verified": req.user.EmailVerified,
+		"name":           req.user.Name,
+	}
+	if p.Claims != nil {
+		p.Claims(claims)
+	}
+	p.mu.Lock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.kid
+	idToken, err := token.SignedString(p.key)
+	p.mu.Unlock()
+	if err != nil {
+		respond(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	respond(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	pub := p.key.PublicKey
+	kid := p.kid
+	p.mu.Unlock()
+	respond(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func respond(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,281 @@
+// Package oidc is a client of OpenID Connect providers like Google or Apple.
+// It implements the authorization code flow with PKCE and verifies id tokens
+// with keys the provider publishes, https://openid.net/specs/openid-connect-core-1_0.html
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/jwtlib"
+)
+
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+
+	DefaultClockSkew = time.Second * 30
+	requestTimeout   = time.Second * 10
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+type (
+	Config struct {
+		// Name is how clients pick the provider, like "google"
+		Name         string
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+		// RedirectURL is where provider sends the user back with the code,
+		// it has to be registered at the provider.
+		RedirectURL string
+		// Scopes default to openid, email and profile
+		Scopes    []string
+		ClockSkew time.Duration
+		// HTTPClient is used for every request to the provider
+		HTTPClient *http.Client
+	}
+
+	// metadata is the part of discovery document we need
+	metadata struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+
+	tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		IDToken     string `json:"id_token"`
+	}
+
+	idTokenClaims struct {
+		Issuer          string          `json:"iss"`
+		Subject         string          `json:"sub"`
+		Audience        jwtlib.Audience `json:"aud"`
+		AuthorizedParty string          `json:"azp"`
+		ExpiresAt       int64           `json:"exp"`
+		IssuedAt        int64           `json:"iat"`
+		Nonce           string          `json:"nonce"`
+
+		Email         string       `json:"email"`
+		EmailVerified flexibleBool `json:"email_verified"`
+		Name          string       `json:"name"`
+	}
+
+	// flexibleBool is true or "true", some providers send booleans as strings
+	flexibleBool bool
+
+	Provider struct {
+		cfg      Config
+		client   *http.Client
+		metadata metadata
+
+		mu   sync.RWMutex
+		keys map[string]interface{}
+	}
+)
+
+var _ domain.OIDCProvider = (*Provider)(nil)
+
+// NewProvider reads discovery document of the issuer,
+// so the provider has to be reachable.
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, ErrInvalidConfig
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaultScopes
+	}
+	if cfg.ClockSkew == 0 {
+		cfg.ClockSkew = DefaultClockSkew
+	}
+	p := &Provider{cfg: cfg, client: cfg.HTTPClient, keys: map[string]interface{}{}}
+	if p.client == nil {
+		p.client = &http.Client{Timeout: requestTimeout}
+	}
+	if err := p.discover(ctx); err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrDiscovery, cfg.Issuer, err)
+	}
+	return p, nil
+}
+
+func (p *Provider) discover(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+DiscoveryPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&p.metadata); err != nil {
+		return err
+	}
+	// issuer in the document has to be exactly the one we asked,
+	// otherwise tokens of one provider could pass for another
+	if p.metadata.Issuer != p.cfg.Issuer {
+		return fmt.Errorf("document is for issuer %s", p.metadata.Issuer)
+	}
+	if p.metadata.AuthorizationEndpoint == "" || p.metadata.TokenEndpoint == "" || p.metadata.JWKSURI == "" {
+		return errors.New("document misses endpoints")
+	}
+	return nil
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + q.Encode()
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (domain.IDTokenClaims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return domain.IDTokenClaims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic wants both parts form encoded, RFC 6749 section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return domain.IDTokenClaims{}, fmt.Errorf("could not exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		exchangeErr := &ExchangeError{}
+		if err := json.NewDecoder(resp.Body).Decode(exchangeErr); err != nil || exchangeErr.Code == "" {
+			return domain.IDTokenClaims{}, fmt.Errorf("could not exchange code: status %d", resp.StatusCode)
+		}
+		return domain.IDTokenClaims{}, exchangeErr
+	}
+	var tokens tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return domain.IDTokenClaims{}, fmt.Errorf("could not decode tokens: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return domain.IDTokenClaims{}, invalid(ErrMalformed, "token response has no id token")
+	}
+	return p.verify(ctx, tokens.IDToken, time.Now())
+}
+
+// verify checks signature and claims of an id token, nonce is left to the caller
+func (p *Provider) verify(ctx context.Context, idToken string, now time.Time) (domain.IDTokenClaims, error) {
+	claims := &idTokenClaims{}
+	parser := jwt.Parser{ValidMethods: []string{"RS256", "ES256", "EdDSA"}}
+	if _, err := parser.ParseWithClaims(idToken, claims, p.verificationKey(ctx)); err != nil {
+		var ve *jwt.ValidationError
+		if !errors.As(err, &ve) {
+			return domain.IDTokenClaims{}, invalid(ErrMalformed, err.Error())
+		}
+		var our *ValidationError
+		switch {
+		case ve.Errors&jwt.ValidationErrorMalformed != 0:
+			return domain.IDTokenClaims{}, invalid(ErrMalformed, ve.Error())
+		case errors.As(ve.Inner, &our):
+			return domain.IDTokenClaims{}, our
+		case ve.Errors&jwt.ValidationErrorUnverifiable != 0 && ve.Inner != nil:
+			// keys could not be fetched, it is not the token's fault
+			return domain.IDTokenClaims{}, ve.Inner
+		default:
+			return domain.IDTokenClaims{}, invalid(ErrSignature, ve.Error())
+		}
+	}
+	if err := claims.validate(p.cfg.Issuer, p.cfg.ClientID, now, p.cfg.ClockSkew); err != nil {
+		return domain.IDTokenClaims{}, err
+	}
+	return domain.IDTokenClaims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Nonce:         claims.Nonce,
+	}, nil
+}
+
+// Valid is called by jwt parser, real checks are in validate
+func (c idTokenClaims) Valid() error {
+	return nil
+}
+
+// validate follows section 3.1.3.7 of OpenID Connect Core
+func (c idTokenClaims) validate(issuer, clientID string, now time.Time, skew time.Duration) error {
+	switch {
+	case c.Issuer == "" || c.Subject == "" || len(c.Audience) == 0 || c.ExpiresAt == 0 || c.IssuedAt == 0:
+		return invalid(ErrMissingClaim, "")
+	case c.Issuer != issuer:
+		return invalid(ErrInvalidIssuer, c.Issuer)
+	case !c.Audience.Contains(clientID):
+		return invalid(ErrInvalidAudience, "")
+	// with several audiences azp tells who the token was issued to
+	case len(c.Audience) > 1 && c.AuthorizedParty != clientID:
+		return invalid(ErrInvalidAudience, "azp is "+c.AuthorizedParty)
+	}
+
+	leeway := int64(skew / time.Second)
+	unix := now.Unix()
+	switch {
+	case unix-leeway >= c.ExpiresAt:
+		return invalid(ErrExpired, "")
+	case unix+leeway < c.IssuedAt:
+		return invalid(ErrIssuedInFuture, "")
+	}
+	return nil
+}
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v bool
+	if err := json.Unmarshal(data, &v); err == nil {
+		*b = flexibleBool(v)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*b = s == "true"
+	return nil
+}
@@ -0,0 +1,139 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/oidc"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/oidc/oidctest"
+)
+
+const (
+	clientID     = "micro-pizzas"
+	clientSecret = "secret"
+	redirectURL  = "https://micro-pizzas.com/oidc/callback"
+	verifier     = "dBjftJeZ4CK-mB0tbL2D3Bz5kZfyxDjpLdc9XW7yLuQ"
+)
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestExchange(t *testing.T) {
+	user := oidctest.User{Subject: "1234", Email: "john@gmail.com", EmailVerified: true, Name: "John Doe"}
+
+	testCases := []struct {
+		name     string
+		verifier string
+		claims   func(jwt.MapClaims)
+		// rotate key after provider was created, so keys have to be fetched again
+		rotate bool
+		want   error
+	}{
+		{name: "valid", verifier: verifier},
+		{name: "valid after key rotation", verifier: verifier, rotate: true},
+		{name: "wrong verifier", verifier: "another-verifier-of-43-characters-000000000", want: domain.ErrInvalidCode},
+		{
+			name:     "another audience",
+			verifier: verifier,
+			claims:   func(c jwt.MapClaims) { c["aud"] = "another-client" },
+			want:     oidc.ErrInvalidAudience,
+		},
+		{
+			name:     "several audiences without azp",
+			verifier: verifier,
+			claims:   func(c jwt.MapClaims) { c["aud"] = []string{clientID, "another-client"} },
+			want:     oidc.ErrInvalidAudience,
+		},
+		{
+			name:     "another issuer",
+			verifier: verifier,
+			claims:   func(c jwt.MapClaims) { c["iss"] = "https://evil.com" },
+			want:     oidc.ErrInvalidIssuer,
+		},
+		{
+			name:     "expired",
+			verifier: verifier,
+			claims:   func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			want:     oidc.ErrExpired,
+		},
+		{
+			name:     "no subject",
+			verifier: verifier,
+			claims:   func(c jwt.MapClaims) { delete(c, "sub") },
+			want:     oidc.ErrMissingClaim,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			mock := oidctest.NewProvider(clientID, clientSecret)
+			defer mock.Close()
+			mock.SignInAs(user)
+			mock.Claims = tc.claims
+
+			p, err := oidc.NewProvider(ctx, oidc.Config{
+				Name:         "mock",
+				Issuer:       mock.Issuer(),
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				RedirectURL:  redirectURL,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.rotate {
+				// the first sign in caches the keys
+				code, _, err := mock.Authorize(p.AuthCodeURL("state", "nonce", challenge(verifier)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := p.Exchange(ctx, code, verifier); err != nil {
+					t.Fatal(err)
+				}
+				mock.RotateKey()
+			}
+
+			code, state, err := mock.Authorize(p.AuthCodeURL("state", "nonce", challenge(verifier)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state != "state" {
+				t.Fatalf("got state %q back", state)
+			}
+			claims, err := p.Exchange(ctx, code, tc.verifier)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+			if tc.want != nil {
+				return
+			}
+			want := domain.IDTokenClaims{Subject: user.Subject, Email: user.Email, EmailVerified: true, Name: user.Name, Nonce: "nonce"}
+			if claims != want {
+				t.Errorf("got claims %+v, want %+v", claims, want)
+			}
+		})
+	}
+}
+
+func TestNewProviderChecksIssuer(t *testing.T) {
+	mock := oidctest.NewProvider(clientID, clientSecret)
+	defer mock.Close()
+
+	_, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Name:        "mock",
+		Issuer:      mock.Issuer() + "/",
+		ClientID:    clientID,
+		RedirectURL: redirectURL,
+	})
+	if !errors.Is(err, oidc.ErrDiscovery) {
+		t.Fatalf("got error %v, want %v", err, oidc.ErrDiscovery)
+	}
+}
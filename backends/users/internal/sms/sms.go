@@ -5,6 +5,6 @@ package sms
 type SMSsender struct {
 }
 
-func (s *SMSsender) Send(to, msg string) error {
+func (s *SMSsender) Send(phoneNumber, title, text string) error {
 	return nil
 }
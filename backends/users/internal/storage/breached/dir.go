@@ -0,0 +1,90 @@
+package breached
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPrefix = errors.New("breached: prefix has to be 5 hex characters")
+
+const prefixLength = 5
+
+// Dir reads a local copy of Pwned Passwords range files.
+// Every file is named after a prefix of SHA-1 like 21BD1.txt and
+// has one "SUFFIX:COUNT" line per leaked password, the same as
+// the range api returns, so the dataset never leaves our network.
+type Dir struct {
+	path string
+}
+
+func NewDir(path string) (*Dir, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("breached: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached: %s is not a directory", path)
+	}
+	return &Dir{path: path}, nil
+}
+
+// Range returns suffixes of hashes that start with prefix and how many
+// times every one of them was seen in breaches.
+func (d *Dir) Range(ctx context.Context, prefix string) (map[string]int64, error) {
+	prefix = strings.ToUpper(prefix)
+	if !validPrefix(prefix) {
+		return nil, ErrInvalidPrefix
+	}
+	f, err := os.Open(filepath.Join(d.path, prefix+".txt"))
+	// nothing leaked with this prefix
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]int64{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("breached: %w", err)
+	}
+	defer f.Close()
+
+	suffixes := map[string]int64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		suffix, count, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("breached: malformed line %q in %s", line, f.Name())
+		}
+		n, err := strconv.ParseInt(count, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("breached: malformed line %q in %s", line, f.Name())
+		}
+		suffixes[strings.ToUpper(suffix)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("breached: %w", err)
+	}
+	return suffixes, nil
+}
+
+func validPrefix(prefix string) bool {
+	if len(prefix) != prefixLength {
+		return false
+	}
+	for _, r := range prefix {
+		if !(r >= '0' && r <= '9' || r >= 'A' && r <= 'F') {
+			return false
+		}
+	}
+	return true
+}
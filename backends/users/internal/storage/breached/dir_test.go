@@ -0,0 +1,57 @@
+package breached_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/breached"
+)
+
+func TestDir(t *testing.T) {
+	path := t.TempDir()
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	data := "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n011053FD0102E94D6AE2F8B83D76FAF94F6:1\r\n"
+	if err := os.WriteFile(filepath.Join(path, "5BAA6.txt"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := breached.NewDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		prefix string
+		suffix string
+		count  int64
+		err    error
+	}{
+		{name: "success", prefix: "5BAA6", suffix: "1E4C9B93F3F0682250B6CF8331B7EE68FD8", count: 9545824},
+		{name: "success with lowercase prefix", prefix: "5baa6", suffix: "011053FD0102E94D6AE2F8B83D76FAF94F6", count: 1},
+		{name: "nothing for unknown prefix", prefix: "00000", suffix: "1E4C9B93F3F0682250B6CF8331B7EE68FD8", count: 0},
+		{name: "fail with short prefix", prefix: "5BA", err: breached.ErrInvalidPrefix},
+		{name: "fail with path in prefix", prefix: "../5B", err: breached.ErrInvalidPrefix},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			suffixes, err := d.Range(context.Background(), tc.prefix)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if suffixes[tc.suffix] != tc.count {
+				t.Fatalf("expected %d, got %d", tc.count, suffixes[tc.suffix])
+			}
+		})
+	}
+
+	if _, err := breached.NewDir(filepath.Join(path, "missing")); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"container/heap"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var (
+	ErrInvalidTTL = errors.New("memory: ttl has to be positive")
+	ErrNotInteger = errors.New("memory: value is not an integer")
+)
+
+// Cache keeps everything in a map, so it only works
+// when we run a single instance of the service.
+// Every entry has its own ttl. Expired entries are dropped
+// by a background janitor and when Cache is full the entry
+// that is closest to its expiration is evicted first.
+type Cache struct {
+	mu       sync.Mutex
+	items    map[string]*entry
+	queue    expiryQueue
+	maxItems int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type entry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+	index     int
+}
+
+// NewCache starts a janitor that removes expired entries every cleanupInterval.
+// Do not forget to Close the cache so the janitor stops.
+func NewCache(maxItems int, cleanupInterval time.Duration) *Cache {
+	c := &Cache{
+		items:    make(map[string]*entry),
+		maxItems: maxItems,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go c.janitor(cleanupInterval)
+	return c
+}
+
+func (c *Cache) Store(key, value string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.value = value
+		e.expiresAt = expiresAt
+		heap.Fix(&c.queue, e.index)
+		return nil
+	}
+
+	c.insert(key, value, expiresAt)
+	return nil
+}
+
+func (c *Cache) Get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return "", domain.ErrCacheMiss
+	}
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(e)
+		return "", domain.ErrCacheMiss
+	}
+	return e.value, nil
+}
+
+func (c *Cache) Pop(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return "", domain.ErrCacheMiss
+	}
+	c.remove(e)
+	if !time.Now().Before(e.expiresAt) {
+		return "", domain.ErrCacheMiss
+	}
+	return e.value, nil
+}
+
+func (c *Cache) Incr(key string, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if e, ok := c.items[key]; ok && now.Before(e.expiresAt) {
+		n, err := strconv.ParseInt(e.value, 10, 64)
+		if err != nil {
+			return 0, ErrNotInteger
+		}
+		n++
+		e.value = strconv.FormatInt(n, 10)
+		return n, nil
+	} else if ok {
+		c.remove(e)
+	}
+
+	c.insert(key, "1", now.Add(ttl))
+	return 1, nil
+}
+
+func (c *Cache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	return nil
+}
+
+// Len returns number of entries including the ones
+// that are expired but were not evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+func (c *Cache) Close() {
+	close(c.stop)
+	<-c.done
+}
+
+func (c *Cache) janitor(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			c.removeExpired(now)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// insert, removeExpired and remove expect c.mu to be locked
+
+// insert makes room for a new entry if cache is full
+func (c *Cache) insert(key, value string, expiresAt time.Time) {
+	c.removeExpired(time.Now())
+	for c.maxItems > 0 && len(c.items) >= c.maxItems {
+		c.remove(c.queue[0])
+	}
+	e := &entry{key: key, value: value, expiresAt: expiresAt}
+	heap.Push(&c.queue, e)
+	c.items[key] = e
+}
+
+func (c *Cache) removeExpired(now time.Time) {
+	for len(c.queue) > 0 && !now.Before(c.queue[0].expiresAt) {
+		c.remove(c.queue[0])
+	}
+}
+
+func (c *Cache) remove(e *entry) {
+	heap.Remove(&c.queue, e.index)
+	delete(c.items, e.key)
+}
+
+// expiryQueue is a min heap ordered by expiration time
+type expiryQueue []*entry
+
+func (q expiryQueue) Len() int { return len(q) }
+
+func (q expiryQueue) Less(i, j int) bool { return q[i].expiresAt.Before(q[j].expiresAt) }
+
+func (q expiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *expiryQueue) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
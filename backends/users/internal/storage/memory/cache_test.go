@@ -0,0 +1,182 @@
+package memory_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/memory"
+)
+
+func TestCache(t *testing.T) {
+	c := memory.NewCache(3, time.Hour)
+	defer c.Close()
+
+	testCases := []struct {
+		name  string
+		key   string
+		value string
+		err   error
+
+		setup func()
+	}{
+		{
+			name:  "success",
+			key:   "+996702569123",
+			value: "123456",
+			setup: func() {
+				c.Store("+996702569123", "123456", time.Minute)
+			},
+		},
+		{
+			name:  "overwrite",
+			key:   "pizzas@gmail.com",
+			value: "654321",
+			setup: func() {
+				c.Store("pizzas@gmail.com", "123456", time.Minute)
+				c.Store("pizzas@gmail.com", "654321", time.Minute)
+			},
+		},
+		{
+			name: "fail with expired",
+			key:  "expired",
+			err:  domain.ErrCacheMiss,
+			setup: func() {
+				c.Store("expired", "123456", time.Millisecond)
+				time.Sleep(time.Millisecond * 5)
+			},
+		},
+		{
+			name:  "fail with missing",
+			key:   "missing",
+			err:   domain.ErrCacheMiss,
+			setup: func() {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setup()
+			value, err := c.Get(tc.key)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			}
+			if value != tc.value {
+				t.Errorf("got %q, want %q", value, tc.value)
+			}
+		})
+	}
+
+	if err := c.Store("key", "value", 0); !errors.Is(err, memory.ErrInvalidTTL) {
+		t.Errorf("got %v, want %v", err, memory.ErrInvalidTTL)
+	}
+}
+
+func TestCachePop(t *testing.T) {
+	c := memory.NewCache(0, time.Hour)
+	defer c.Close()
+
+	c.Store("+996702569123", "123456", time.Minute)
+	value, err := c.Pop("+996702569123")
+	if err != nil || value != "123456" {
+		t.Fatalf("got %q, %v, want %q", value, err, "123456")
+	}
+	if _, err := c.Pop("+996702569123"); !errors.Is(err, domain.ErrCacheMiss) {
+		t.Errorf("value should be consumed, got %v", err)
+	}
+}
+
+func TestCacheIncr(t *testing.T) {
+	c := memory.NewCache(0, time.Hour)
+	defer c.Close()
+
+	for i := int64(1); i <= 3; i++ {
+		n, err := c.Incr("attempts", time.Millisecond*30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != i {
+			t.Errorf("got %d, want %d", n, i)
+		}
+	}
+	// ttl is not prolonged by later increments
+	time.Sleep(time.Millisecond * 40)
+	if n, err := c.Incr("attempts", time.Minute); err != nil || n != 1 {
+		t.Errorf("got %d, %v, want counter to start over", n, err)
+	}
+
+	c.Store("code", "123456", time.Minute)
+	if err := c.Delete("code"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("code"); !errors.Is(err, domain.ErrCacheMiss) {
+		t.Errorf("got %v, want %v", err, domain.ErrCacheMiss)
+	}
+	if err := c.Delete("code"); err != nil {
+		t.Errorf("deleting missing key should not fail, got %v", err)
+	}
+}
+
+func TestCacheSizeBound(t *testing.T) {
+	c := memory.NewCache(2, time.Hour)
+	defer c.Close()
+
+	c.Store("short", "1", time.Minute)
+	c.Store("long", "2", time.Hour)
+	c.Store("new", "3", time.Hour)
+
+	if l := c.Len(); l != 2 {
+		t.Fatalf("got %d entries, want 2", l)
+	}
+	if _, err := c.Get("short"); !errors.Is(err, domain.ErrCacheMiss) {
+		t.Errorf("entry closest to expiration should be evicted, got %v", err)
+	}
+	for _, key := range []string{"long", "new"} {
+		if _, err := c.Get(key); err != nil {
+			t.Errorf("%s: %v", key, err)
+		}
+	}
+}
+
+func TestCacheJanitor(t *testing.T) {
+	c := memory.NewCache(0, time.Millisecond)
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		c.Store(fmt.Sprint(i), "code", time.Millisecond)
+	}
+	c.Store("alive", "code", time.Hour)
+
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("janitor did not evict expired entries, %d left", c.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheConcurrency(t *testing.T) {
+	c := memory.NewCache(100, time.Millisecond)
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				key := fmt.Sprint(i, j%150)
+				c.Store(key, "code", time.Duration(j%3+1)*time.Millisecond)
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if l := c.Len(); l > 100 {
+		t.Errorf("cache grew over its bound: %d", l)
+	}
+}
@@ -0,0 +1,21 @@
+package memory
+
+import "time"
+
+// RateLimitStore keeps rate limit counters in memory, so every
+// replica of the service counts its own requests.
+type RateLimitStore struct {
+	cache *Cache
+}
+
+func NewRateLimitStore(maxKeys int, cleanupInterval time.Duration) *RateLimitStore {
+	return &RateLimitStore{cache: NewCache(maxKeys, cleanupInterval)}
+}
+
+func (s *RateLimitStore) Hit(key string, window time.Duration) (int64, error) {
+	return s.cache.Incr(key, window)
+}
+
+func (s *RateLimitStore) Close() {
+	s.cache.Close()
+}
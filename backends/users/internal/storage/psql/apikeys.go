@@ -0,0 +1,151 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func selectAPIKeys() sq.SelectBuilder {
+	return sq.Select(
+		"id", "name", "COALESCE(user_id, 0)", "COALESCE(organization, '')", "hash",
+		"scopes", "created_at", "expires_at", "last_used_at", "revoked_at",
+	).From("api_keys").PlaceholderFormat(sq.Dollar)
+}
+
+func scanAPIKey(row pgx.Row) (domain.APIKey, error) {
+	k := domain.APIKey{}
+	err := row.Scan(
+		&k.ID, &k.Name, &k.Owner.UserID, &k.Owner.Organization, &k.Hash,
+		&k.Scopes, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt,
+	)
+	return k, err
+}
+
+func (r *Repository) CreateAPIKey(ctx context.Context, k domain.APIKey) error {
+	var (
+		userID       *domain.ID
+		organization *string
+	)
+	if k.Owner.UserID != 0 {
+		userID = &k.Owner.UserID
+	} else {
+		organization = &k.Owner.Organization
+	}
+	sql, args, err := sq.Insert("api_keys").
+		Columns("id", "name", "user_id", "organization", "hash", "scopes", "created_at", "expires_at").
+		Values(k.ID, k.Name, userID, organization, k.Hash, k.Scopes, k.CreatedAt, k.ExpiresAt).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *Repository) ReadAPIKey(ctx context.Context, id string) (domain.APIKey, error) {
+	sql, args, err := selectAPIKeys().Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	defer conn.Release()
+
+	k, err := scanAPIKey(conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return k, domain.ErrAPIKeyNotFound
+	}
+	return k, err
+}
+
+func (r *Repository) ReadAPIKeys(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error) {
+	where := sq.Eq{"organization": owner.Organization}
+	if owner.UserID != 0 {
+		where = sq.Eq{"user_id": owner.UserID}
+	}
+	sql, args, err := selectAPIKeys().Where(where).OrderBy("created_at").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *Repository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	sql, args, err := sq.Update("api_keys").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"id": id, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *Repository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	sql, args, err := sq.Update("api_keys").
+		Set("last_used_at", usedAt).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sql, args...)
+	return err
+}
@@ -0,0 +1,114 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// grantRow is how grantsColumn builds every grant in json
+type grantRow struct {
+	RoleID    int        `json:"role_id"`
+	Scope     string     `json:"scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	GrantedBy domain.ID  `json:"granted_by"`
+	Reason    string     `json:"reason"`
+	GrantedAt time.Time  `json:"granted_at"`
+}
+
+func decodeGrants(raw []byte) ([]domain.RoleGrant, error) {
+	rows := []grantRow{}
+	if len(raw) != 0 {
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, err
+		}
+	}
+	grants := make([]domain.RoleGrant, 0, len(rows))
+	for _, v := range rows {
+		grants = append(grants, domain.RoleGrant{
+			Role:      roleFromID(v.RoleID),
+			Scope:     v.Scope,
+			ExpiresAt: v.ExpiresAt,
+			GrantedBy: v.GrantedBy,
+			Reason:    v.Reason,
+			GrantedAt: v.GrantedAt,
+		})
+	}
+	return grants, nil
+}
+
+// addRoleQuery replaces the grant with the same role and scope
+func addRoleQuery(userID domain.ID, grant domain.RoleGrant) (string, []interface{}, error) {
+	var grantedBy *domain.ID
+	if grant.GrantedBy != 0 {
+		grantedBy = &grant.GrantedBy
+	}
+	return sq.Insert("users_roles").
+		Columns("user_id", "role_id", "scope", "expires_at", "granted_by", "reason", "granted_at").
+		Values(userID, roleID(grant.Role), grant.Scope, grant.ExpiresAt, grantedBy, grant.Reason, grant.GrantedAt).
+		Suffix(`ON CONFLICT (user_id, role_id, scope) DO UPDATE SET
+			expires_at = EXCLUDED.expires_at, granted_by = EXCLUDED.granted_by,
+			reason = EXCLUDED.reason, granted_at = EXCLUDED.granted_at`).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+}
+
+func (r *Repository) AddRole(ctx context.Context, userID domain.ID, grant domain.RoleGrant) error {
+	sql, args, err := addRoleQuery(userID, grant)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *Repository) RemoveRole(ctx context.Context, userID domain.ID, role domain.Role, scope string) error {
+	sql, args, err := sq.Delete("users_roles").
+		Where(sq.Eq{"user_id": userID, "role_id": roleID(role), "scope": scope}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *Repository) DeleteExpiredGrants(ctx context.Context, now time.Time) (int64, error) {
+	sql, args, err := sq.Delete("users_roles").
+		Where(sq.LtOrEq{"expires_at": now}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,163 @@
+package psql
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func selectIdentities() sq.SelectBuilder {
+	return sq.Select(
+		"provider", "subject", "user_id", "COALESCE(email, '')", "created_at",
+	).From("identities").PlaceholderFormat(sq.Dollar)
+}
+
+// createIdentityQuery inserts nothing if the identity is taken,
+// both primary key and (user_id, provider) must be free
+func createIdentityQuery(i domain.Identity) (string, []interface{}, error) {
+	return sq.Insert("identities").
+		Columns("provider", "subject", "user_id", "email", "created_at").
+		Values(i.Provider, i.Subject, i.UserID, i.Email, i.CreatedAt).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar).ToSql()
+}
+
+func (r *Repository) CreateIdentity(ctx context.Context, i domain.Identity) error {
+	sql, args, err := createIdentityQuery(i)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrIdentityExists
+	}
+	return nil
+}
+
+func (r *Repository) CreateWithIdentity(ctx context.Context, u domain.User, i domain.Identity) (domain.ID, error) {
+	sql, args, err := createUserQuery(u)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&i.UserID); err != nil {
+		return 0, err
+	}
+	sql, args, err = createIdentityQuery(i)
+	if err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, domain.ErrIdentityExists
+	}
+	return i.UserID, tx.Commit(ctx)
+}
+
+func (r *Repository) ReadIdentity(ctx context.Context, provider, subject string) (domain.Identity, error) {
+	sql, args, err := selectIdentities().
+		Where(sq.Eq{"provider": provider, "subject": subject}).ToSql()
+	if err != nil {
+		return domain.Identity{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	defer conn.Release()
+
+	i := domain.Identity{}
+	if err := conn.QueryRow(ctx, sql, args...).Scan(
+		&i.Provider, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return i, domain.ErrIdentityNotFound
+		}
+		return i, err
+	}
+	return i, nil
+}
+
+func (r *Repository) ReadIdentities(ctx context.Context, userID domain.ID) ([]domain.Identity, error) {
+	sql, args, err := selectIdentities().
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []domain.Identity{}
+	for rows.Next() {
+		i := domain.Identity{}
+		if err := rows.Scan(&i.Provider, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+	return identities, rows.Err()
+}
+
+func (r *Repository) DeleteIdentity(ctx context.Context, userID domain.ID, provider string) error {
+	sql, args, err := sq.Delete("identities").
+		Where(sq.Eq{"user_id": userID, "provider": provider}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrIdentityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         text primary key,
+    family_id  text not null,
+    user_id    bigint not null,
+    issued_at  timestamptz not null default now(),
+    expires_at timestamptz not null,
+    rotated_at timestamptz,
+    revoked_at timestamptz,
+    CONSTRAINT fk_refresh_tokens_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id
+    ON refresh_tokens (family_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS refresh_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS sessions (
+    id           text primary key,
+    user_id      bigint not null,
+    user_agent   text not null default '',
+    ip           text not null default '',
+    created_at   timestamptz not null default now(),
+    last_used_at timestamptz not null default now(),
+    revoked_at   timestamptz,
+    CONSTRAINT fk_sessions_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id
+    ON sessions (user_id);
+
+-- every refresh token family issued so far becomes a session
+INSERT INTO sessions (id, user_id, created_at, last_used_at, revoked_at)
+SELECT family_id, user_id, min(issued_at), max(issued_at), max(revoked_at)
+FROM refresh_tokens
+GROUP BY family_id, user_id
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_family_id FOREIGN KEY (family_id)
+        REFERENCES sessions (id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens
+    DROP CONSTRAINT IF EXISTS fk_refresh_tokens_family_id;
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementEnd
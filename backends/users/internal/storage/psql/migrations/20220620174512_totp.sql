@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS totp (
+    user_id      bigint primary key,
+    secret       text not null,
+    created_at   timestamptz not null default now(),
+    confirmed_at timestamptz,
+    CONSTRAINT fk_totp_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- only sha256 of recovery codes are stored
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    user_id   bigint not null,
+    code_hash text not null,
+    used_at   timestamptz,
+    PRIMARY KEY (user_id, code_hash),
+    CONSTRAINT fk_recovery_codes_user_id FOREIGN KEY (user_id)
+        REFERENCES totp (user_id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS totp;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS passkeys (
+    -- base64url of credential id
+    id           text primary key,
+    user_id      bigint not null,
+    name         text not null,
+    -- COSE_Key as authenticator sent it
+    public_key   bytea not null,
+    sign_count   bigint not null default 0,
+    aaguid       bytea,
+    created_at   timestamptz not null default now(),
+    last_used_at timestamptz,
+    CONSTRAINT fk_passkeys_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS passkeys;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS identities (
+    provider   text not null,
+    -- id of the user at the provider
+    subject    text not null,
+    user_id    bigint not null,
+    email      text,
+    created_at timestamptz not null default now(),
+    PRIMARY KEY (provider, subject),
+    -- one identity per provider is enough for everyone
+    UNIQUE (user_id, provider),
+    CONSTRAINT fk_identities_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS identities;
+-- +goose StatementEnd
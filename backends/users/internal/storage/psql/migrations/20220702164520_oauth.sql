@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id            text primary key,
+    name          text not null,
+    -- sha256 of the secret, public clients have none
+    secret_hash   text,
+    redirect_uris text[] not null default '{}',
+    grant_types   text[] not null,
+    scopes        text[] not null,
+    public        boolean not null default false,
+    trusted       boolean not null default false,
+    created_at    timestamptz not null default now()
+);
+
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    user_id    bigint not null,
+    client_id  text not null,
+    scopes     text[] not null,
+    granted_at timestamptz not null default now(),
+    PRIMARY KEY (user_id, client_id),
+    CONSTRAINT fk_oauth_consents_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_oauth_consents_client_id FOREIGN KEY (client_id)
+        REFERENCES oauth_clients (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oauth_consents;
+DROP TABLE IF EXISTS oauth_clients;
+-- +goose StatementEnd
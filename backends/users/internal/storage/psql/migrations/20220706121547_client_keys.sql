@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- clients with keys sign assertions instead of sending a secret
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS jwks jsonb;
+ALTER TABLE oauth_clients ADD CONSTRAINT oauth_clients_secret_or_keys
+    CHECK (secret_hash IS NULL OR jwks IS NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_clients DROP CONSTRAINT IF EXISTS oauth_clients_secret_or_keys;
+ALTER TABLE oauth_clients DROP COLUMN IF EXISTS jwks;
+-- +goose StatementEnd
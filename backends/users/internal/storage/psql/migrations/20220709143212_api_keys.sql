@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           text primary key,
+    name         text not null,
+    -- a key belongs either to a user or to a partner organization
+    user_id      bigint,
+    organization text,
+    -- sha256 of the secret part of the key
+    hash         text not null,
+    scopes       text[] not null,
+    created_at   timestamptz not null default now(),
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz,
+    CONSTRAINT api_keys_one_owner CHECK ((user_id IS NULL) <> (organization IS NULL)),
+    CONSTRAINT fk_api_keys_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_organization ON api_keys (organization);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id    integer not null,
+    permission text not null,
+    PRIMARY KEY (role_id, permission),
+    CONSTRAINT fk_role_permissions_role_id FOREIGN KEY (role_id)
+        REFERENCES roles (id) ON DELETE CASCADE
+);
+
+-- owners may do anything whatever they have here
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, p.permission
+FROM roles JOIN (VALUES
+    ('owner', 'users:read'),
+    ('owner', 'users:write'),
+    ('owner', 'roles:assign'),
+    ('owner', 'roles:manage'),
+    ('admin', 'users:read'),
+    ('admin', 'users:write'),
+    ('admin', 'roles:assign'),
+    ('admin', 'roles:manage'),
+    ('moderator', 'users:read'),
+    ('moderator', 'products:write'),
+    ('deliveryman', 'orders:read'),
+    ('deliveryman', 'orders:deliver')
+) AS p (role, permission) ON p.role = roles.name
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_permissions;
+DROP INDEX IF EXISTS idx_roles_name;
+-- +goose StatementEnd
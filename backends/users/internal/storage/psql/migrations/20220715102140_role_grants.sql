@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users_roles
+    ADD COLUMN IF NOT EXISTS scope      text not null default '',
+    ADD COLUMN IF NOT EXISTS expires_at timestamptz,
+    ADD COLUMN IF NOT EXISTS granted_by bigint,
+    ADD COLUMN IF NOT EXISTS reason     text not null default '',
+    ADD COLUMN IF NOT EXISTS granted_at timestamptz not null default now(),
+    ADD CONSTRAINT fk_users_roles_granted_by FOREIGN KEY (granted_by)
+        REFERENCES users (id) ON DELETE SET NULL;
+
+-- nothing stopped the same role from being added twice before
+DELETE FROM users_roles a USING users_roles b
+WHERE a.ctid < b.ctid AND a.user_id = b.user_id AND a.role_id = b.role_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_roles_grant ON users_roles (user_id, role_id, scope);
+CREATE INDEX IF NOT EXISTS idx_users_roles_expires_at ON users_roles (expires_at)
+    WHERE expires_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_roles_expires_at;
+DROP INDEX IF EXISTS idx_users_roles_grant;
+
+-- scoped grants can not be told apart from global ones without scope
+DELETE FROM users_roles WHERE scope <> '';
+
+ALTER TABLE users_roles
+    DROP CONSTRAINT IF EXISTS fk_users_roles_granted_by,
+    DROP COLUMN IF EXISTS granted_at,
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS granted_by,
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS scope;
+-- +goose StatementEnd
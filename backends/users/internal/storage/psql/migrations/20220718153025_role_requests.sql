@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS role_requests (
+    id            text primary key,
+    user_id       bigint not null,
+    role_id       integer not null,
+    scope         text not null default '',
+    justification text not null,
+    status        text not null default 'pending',
+    reviewer_id   bigint,
+    comment       text not null default '',
+    created_at    timestamptz not null default now(),
+    reviewed_at   timestamptz,
+    CONSTRAINT fk_role_requests_user_id FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_requests_role_id FOREIGN KEY (role_id)
+        REFERENCES roles (id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_requests_reviewer_id FOREIGN KEY (reviewer_id)
+        REFERENCES users (id) ON DELETE SET NULL
+);
+
+-- users wait for the same role only once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_requests_pending ON role_requests (user_id, role_id, scope)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_role_requests_status ON role_requests (status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_requests;
+-- +goose StatementEnd
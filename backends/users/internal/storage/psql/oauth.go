@@ -0,0 +1,240 @@
+package psql
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func selectOAuthClients() sq.SelectBuilder {
+	return sq.Select(
+		"id", "name", "COALESCE(secret_hash, '')", "redirect_uris",
+		"grant_types", "scopes", "public", "trusted", "jwks", "created_at",
+	).From("oauth_clients").PlaceholderFormat(sq.Dollar)
+}
+
+func scanOAuthClient(row pgx.Row) (domain.OAuthClient, error) {
+	c := domain.OAuthClient{}
+	var jwks []byte
+	err := row.Scan(
+		&c.ID, &c.Name, &c.SecretHash, &c.RedirectURIs,
+		&c.GrantTypes, &c.Scopes, &c.Public, &c.Trusted, &jwks, &c.CreatedAt,
+	)
+	c.JWKS = jwks
+	return c, err
+}
+
+func (r *Repository) CreateOAuthClient(ctx context.Context, c domain.OAuthClient) error {
+	var secretHash *string
+	if c.SecretHash != "" {
+		secretHash = &c.SecretHash
+	}
+	var jwks *string
+	if len(c.JWKS) != 0 {
+		v := string(c.JWKS)
+		jwks = &v
+	}
+	redirectURIs := c.RedirectURIs
+	if redirectURIs == nil {
+		redirectURIs = []string{}
+	}
+	sql, args, err := sq.Insert("oauth_clients").
+		Columns("id", "name", "secret_hash", "redirect_uris", "grant_types", "scopes", "public", "trusted", "jwks", "created_at").
+		Values(c.ID, c.Name, secretHash, redirectURIs, c.GrantTypes, c.Scopes, c.Public, c.Trusted, jwks, c.CreatedAt).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *Repository) ReadOAuthClient(ctx context.Context, id string) (domain.OAuthClient, error) {
+	sql, args, err := selectOAuthClients().Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+	defer conn.Release()
+
+	c, err := scanOAuthClient(conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, domain.ErrClientNotFound
+	}
+	return c, err
+}
+
+func (r *Repository) ReadOAuthClients(ctx context.Context) ([]domain.OAuthClient, error) {
+	sql, args, err := selectOAuthClients().OrderBy("created_at").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []domain.OAuthClient{}
+	for rows.Next() {
+		c, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, rows.Err()
+}
+
+func (r *Repository) DeleteOAuthClient(ctx context.Context, id string) error {
+	sql, args, err := sq.Delete("oauth_clients").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrClientNotFound
+	}
+	return nil
+}
+
+func (r *Repository) SaveConsent(ctx context.Context, c domain.Consent) error {
+	// scopes allowed before stay allowed
+	sql, args, err := sq.Insert("oauth_consents").
+		Columns("user_id", "client_id", "scopes", "granted_at").
+		Values(c.UserID, c.ClientID, c.Scopes, c.GrantedAt).
+		Suffix(`ON CONFLICT (user_id, client_id) DO UPDATE SET
+			scopes = ARRAY(SELECT DISTINCT unnest(oauth_consents.scopes || EXCLUDED.scopes)),
+			granted_at = EXCLUDED.granted_at`).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sql, args...)
+	return err
+}
+
+func selectConsents() sq.SelectBuilder {
+	return sq.Select("user_id", "client_id", "scopes", "granted_at").
+		From("oauth_consents").PlaceholderFormat(sq.Dollar)
+}
+
+func (r *Repository) ReadConsent(ctx context.Context, userID domain.ID, clientID string) (domain.Consent, error) {
+	sql, args, err := selectConsents().
+		Where(sq.Eq{"user_id": userID, "client_id": clientID}).ToSql()
+	if err != nil {
+		return domain.Consent{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.Consent{}, err
+	}
+	defer conn.Release()
+
+	c := domain.Consent{}
+	if err := conn.QueryRow(ctx, sql, args...).Scan(
+		&c.UserID, &c.ClientID, &c.Scopes, &c.GrantedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c, domain.ErrConsentNotFound
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+func (r *Repository) ReadConsents(ctx context.Context, userID domain.ID) ([]domain.Consent, error) {
+	sql, args, err := selectConsents().
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("granted_at").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []domain.Consent{}
+	for rows.Next() {
+		c := domain.Consent{}
+		if err := rows.Scan(&c.UserID, &c.ClientID, &c.Scopes, &c.GrantedAt); err != nil {
+			return nil, err
+		}
+		consents = append(consents, c)
+	}
+	return consents, rows.Err()
+}
+
+func (r *Repository) DeleteConsent(ctx context.Context, userID domain.ID, clientID string) error {
+	sql, args, err := sq.Delete("oauth_consents").
+		Where(sq.Eq{"user_id": userID, "client_id": clientID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrConsentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func selectPasskeys() sq.SelectBuilder {
+	return sq.Select(
+		"id", "user_id", "name", "public_key", "sign_count",
+		"aaguid", "created_at", "last_used_at",
+	).From("passkeys").PlaceholderFormat(sq.Dollar)
+}
+
+func scanPasskey(row pgx.Row) (domain.Passkey, error) {
+	p := domain.Passkey{}
+	var signCount int64
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.Name, &p.PublicKey, &signCount,
+		&p.AAGUID, &p.CreatedAt, &p.LastUsedAt,
+	)
+	p.SignCount = uint32(signCount)
+	return p, err
+}
+
+func (r *Repository) CreatePasskey(ctx context.Context, p domain.Passkey) error {
+	sql, args, err := sq.Insert("passkeys").
+		Columns("id", "user_id", "name", "public_key", "sign_count", "aaguid", "created_at").
+		Values(p.ID, p.UserID, p.Name, p.PublicKey, int64(p.SignCount), p.AAGUID, p.CreatedAt).
+		Suffix("ON CONFLICT (id) DO NOTHING").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPasskeyExists
+	}
+	return nil
+}
+
+func (r *Repository) ReadPasskey(ctx context.Context, id string) (domain.Passkey, error) {
+	sql, args, err := selectPasskeys().Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return domain.Passkey{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.Passkey{}, err
+	}
+	defer conn.Release()
+
+	p, err := scanPasskey(conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, domain.ErrPasskeyNotFound
+	}
+	return p, err
+}
+
+func (r *Repository) ReadPasskeys(ctx context.Context, userID domain.ID) ([]domain.Passkey, error) {
+	sql, args, err := selectPasskeys().
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passkeys := []domain.Passkey{}
+	for rows.Next() {
+		p, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, p)
+	}
+	return passkeys, rows.Err()
+}
+
+func (r *Repository) UpdatePasskeySignCount(ctx context.Context, id string, signCount uint32, usedAt time.Time) error {
+	sql, args, err := sq.Update("passkeys").
+		Set("sign_count", int64(signCount)).
+		Set("last_used_at", usedAt).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (r *Repository) DeletePasskey(ctx context.Context, userID domain.ID, id string) error {
+	sql, args, err := sq.Delete("passkeys").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPasskeyNotFound
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package psql
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -10,12 +11,16 @@ import (
 	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
 )
 
-func (r *Repository) Create(ctx context.Context, u domain.User) (domain.ID, error) {
-	sql, args, err := sq.Insert("users").Columns(
+func createUserQuery(u domain.User) (string, []interface{}, error) {
+	return sq.Insert("users").Columns(
 		"full_name", "email", "phone_number", "password", "birth_date",
 		"created_at",
 	).Values(u.FullName, u.Email, u.PhoneNumber, u.Password, u.BirthDate, u.CreatedAt).
 		Suffix("RETURNING \"id\"").PlaceholderFormat(sq.Dollar).ToSql()
+}
+
+func (r *Repository) Create(ctx context.Context, u domain.User) (domain.ID, error) {
+	sql, args, err := createUserQuery(u)
 	if err != nil {
 		return 0, err
 	}
@@ -33,9 +38,13 @@ func (r *Repository) Create(ctx context.Context, u domain.User) (domain.ID, erro
 	defer tx.Rollback(ctx)
 
 	id := domain.ID(0)
-	if err := conn.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
 		return id, err
 	}
+	// users who sign in with identity providers have no addresses yet
+	if len(u.Addresses) == 0 {
+		return id, tx.Commit(ctx)
+	}
 
 	insertAddress := sq.Insert("addresses").
 		Columns("user_id", "country", "city", "street", "floor", "apartment", "instructions")
@@ -55,18 +64,31 @@ func (r *Repository) Create(ctx context.Context, u domain.User) (domain.ID, erro
 	return id, tx.Commit(ctx)
 }
 
+// roles and permissions of a user are read with subqueries,
+// so reading a user needs no joins and grouping. Roles and permissions
+// come only from grants that work everywhere, scoped ones are in grants.
+const (
+	activeGrant       = "(expires_at IS NULL OR expires_at > now())"
+	rolesColumn       = "ARRAY(SELECT role_id FROM users_roles WHERE users_roles.user_id = users.id AND scope = '' AND " + activeGrant + ")"
+	permissionsColumn = `ARRAY(SELECT DISTINCT rp.permission FROM role_permissions rp
+		JOIN users_roles ur ON ur.role_id = rp.role_id WHERE ur.user_id = users.id
+		AND ur.scope = '' AND (ur.expires_at IS NULL OR ur.expires_at > now()))`
+	grantsColumn = `COALESCE((SELECT json_agg(json_build_object(
+			'role_id', role_id, 'scope', scope, 'expires_at', expires_at,
+			'granted_by', COALESCE(granted_by, 0), 'reason', reason, 'granted_at', granted_at
+		) ORDER BY granted_at) FROM users_roles WHERE users_roles.user_id = users.id AND ` + activeGrant + `), '[]')`
+)
+
 func (r *Repository) Read(ctx context.Context, userID domain.ID) (domain.User, error) {
 
 	sql, args, err := sq.Select(
-		"id", "full_name", "email", "password",
-		"birth_date", "phone_number",
-		"ARRAY_AGGG(users_roles.role_id)",
+		"id", "full_name", "email", "phone_number",
+		"password", "birth_date",
+		rolesColumn, permissionsColumn, grantsColumn,
 		"created_at",
 		"updated_at",
 	).From("users").
-		LeftJoin("users_roles").
 		Where("id = ?", userID).
-		GroupBy("id").
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return domain.User{}, err
@@ -99,14 +121,21 @@ func (r *Repository) Read(ctx context.Context, userID domain.ID) (domain.User, e
 
 	u := domain.User{}
 	roles := []int{}
+	var grants []byte
 	if err := res.QueryRow().Scan(
 		&u.ID, &u.FullName, &u.Email, &u.PhoneNumber, &u.Password, &u.BirthDate,
-		&roles, &u.CreatedAt, &u.UpdatedAt,
+		&roles, &u.Permissions, &grants, &u.CreatedAt, &u.UpdatedAt,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return u, domain.ErrNoUsers
+		}
 		return u, err
 	}
 	for _, v := range roles {
-		u.Roles = append(u.Roles, parseRoles(v))
+		u.Roles = append(u.Roles, roleFromID(v))
+	}
+	if u.Grants, err = decodeGrants(grants); err != nil {
+		return u, err
 	}
 
 	// now we read all addresses
@@ -130,15 +159,13 @@ func (r *Repository) Read(ctx context.Context, userID domain.ID) (domain.User, e
 
 func (r *Repository) ReadByName(ctx context.Context, fullName string) (domain.User, error) {
 	sql, args, err := sq.Select(
-		"id", "full_name", "email", "password",
-		"birth_date", "phone_number",
-		"ARRAY_AGGG(users_roles.role_id)",
+		"id", "full_name", "email", "phone_number",
+		"password", "birth_date",
+		rolesColumn, permissionsColumn, grantsColumn,
 		"created_at",
 		"updated_at",
 	).From("users").
-		LeftJoin("users_roles").
 		Where("full_name = ?", fullName).
-		GroupBy("id").
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return domain.User{}, err
@@ -171,14 +198,21 @@ func (r *Repository) ReadByName(ctx context.Context, fullName string) (domain.Us
 
 	u := domain.User{}
 	roles := []int{}
+	var grants []byte
 	if err := res.QueryRow().Scan(
 		&u.ID, &u.FullName, &u.Email, &u.PhoneNumber, &u.Password, &u.BirthDate,
-		&roles, &u.CreatedAt, &u.UpdatedAt,
+		&roles, &u.Permissions, &grants, &u.CreatedAt, &u.UpdatedAt,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return u, domain.ErrNoUsers
+		}
 		return u, err
 	}
 	for _, v := range roles {
-		u.Roles = append(u.Roles, parseRoles(v))
+		u.Roles = append(u.Roles, roleFromID(v))
+	}
+	if u.Grants, err = decodeGrants(grants); err != nil {
+		return u, err
 	}
 
 	// now we read all addresses
@@ -202,15 +236,13 @@ func (r *Repository) ReadByName(ctx context.Context, fullName string) (domain.Us
 
 func (r *Repository) ReadByEmail(ctx context.Context, email string) (domain.User, error) {
 	sql, args, err := sq.Select(
-		"id", "full_name", "email", "password",
-		"birth_date", "phone_number",
-		"ARRAY_AGGG(users_roles.role_id)",
+		"id", "full_name", "email", "phone_number",
+		"password", "birth_date",
+		rolesColumn, permissionsColumn, grantsColumn,
 		"created_at",
 		"updated_at",
 	).From("users").
-		LeftJoin("users_roles").
 		Where("email = ?", email).
-		GroupBy("id").
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return domain.User{}, err
@@ -243,14 +275,21 @@ func (r *Repository) ReadByEmail(ctx context.Context, email string) (domain.User
 
 	u := domain.User{}
 	roles := []int{}
+	var grants []byte
 	if err := res.QueryRow().Scan(
 		&u.ID, &u.FullName, &u.Email, &u.PhoneNumber, &u.Password, &u.BirthDate,
-		&roles, &u.CreatedAt, &u.UpdatedAt,
+		&roles, &u.Permissions, &grants, &u.CreatedAt, &u.UpdatedAt,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return u, domain.ErrNoUsers
+		}
 		return u, err
 	}
 	for _, v := range roles {
-		u.Roles = append(u.Roles, parseRoles(v))
+		u.Roles = append(u.Roles, roleFromID(v))
+	}
+	if u.Grants, err = decodeGrants(grants); err != nil {
+		return u, err
 	}
 
 	// now we read all addresses
@@ -274,15 +313,13 @@ func (r *Repository) ReadByEmail(ctx context.Context, email string) (domain.User
 
 func (r *Repository) ReadByPhoneNumber(ctx context.Context, phoneNumber string) (domain.User, error) {
 	sql, args, err := sq.Select(
-		"id", "full_name", "email", "password",
-		"birth_date", "phone_number",
-		"ARRAY_AGGG(users_roles.role_id)",
+		"id", "full_name", "email", "phone_number",
+		"password", "birth_date",
+		rolesColumn, permissionsColumn, grantsColumn,
 		"created_at",
 		"updated_at",
 	).From("users").
-		LeftJoin("users_roles").
 		Where(sq.Eq{"phone_number": phoneNumber}).
-		GroupBy("id").
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return domain.User{}, err
@@ -296,23 +333,30 @@ func (r *Repository) ReadByPhoneNumber(ctx context.Context, phoneNumber string)
 
 	u := domain.User{}
 	roles := []int{}
+	var grants []byte
 	if err := conn.QueryRow(ctx, sql, args).Scan(
 		&u.ID, &u.FullName, &u.Email, &u.PhoneNumber, &u.Password, &u.BirthDate,
-		&roles, &u.CreatedAt, &u.UpdatedAt,
+		&roles, &u.Permissions, &grants, &u.CreatedAt, &u.UpdatedAt,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return u, domain.ErrNoUsers
+		}
 		return u, err
 	}
 	for _, v := range roles {
-		u.Roles = append(u.Roles, parseRoles(v))
+		u.Roles = append(u.Roles, roleFromID(v))
+	}
+	if u.Grants, err = decodeGrants(grants); err != nil {
+		return u, err
 	}
 	return u, nil
 }
 
 func (r *Repository) ReadAll(ctx context.Context, inp domain.ReadAllInput) ([]domain.User, error) {
 	query := sq.Select(
-		`id, full_name, email, phone_number, birth_date, created_at, updated_at, ARRAY_AGGG(users_roles.role_id) as all_roles`,
+		`id, full_name, email, phone_number, birth_date, created_at, updated_at, ARRAY_AGG(ur.role_id) as all_roles`,
 	).From("users u").
-		LeftJoin("users_roles ur ON ur.user_id = u.id").
+		LeftJoin("users_roles ur ON ur.user_id = u.id AND ur.scope = '' AND (ur.expires_at IS NULL OR ur.expires_at > now())").
 		LeftJoin("roles r ON r.id = ur.role_id").
 		GroupBy("id").Limit(inp.Limit).Offset(inp.Offset)
 
@@ -334,7 +378,7 @@ func (r *Repository) ReadAll(ctx context.Context, inp domain.ReadAllInput) ([]do
 	if len(inp.Roles) > 0 {
 		havingRole := sq.Eq{}
 		for _, v := range inp.Roles {
-			havingRole["all_roles"] = getRoleID(v)
+			havingRole["all_roles"] = roleID(v)
 		}
 		query.Having(havingRole)
 	}
@@ -373,7 +417,7 @@ func (r *Repository) ReadAll(ctx context.Context, inp domain.ReadAllInput) ([]do
 			user.UpdatedAt = updatedAt.Time
 		}
 		for _, v := range roles {
-			user.Roles = append(user.Roles, parseRoles(v))
+			user.Roles = append(user.Roles, roleFromID(v))
 		}
 		users = append(users, user)
 	}
@@ -381,15 +425,18 @@ func (r *Repository) ReadAll(ctx context.Context, inp domain.ReadAllInput) ([]do
 }
 
 func (r *Repository) Update(ctx context.Context, changeset domain.UpdateInput) error {
-	sql, args, err := sq.
+	q := sq.
 		Update("users").
 		Set("full_name", changeset.FullName).
 		Set("email", changeset.Email).
 		Set("phone_number", changeset.PhoneNumber).
-		Set("password", changeset.Password).
 		Set("updated_at", time.Now().UTC()).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		Where(sq.Eq{"id": changeset.ID})
+	// empty password means it is not changed
+	if changeset.Password != "" {
+		q = q.Set("password", changeset.Password)
+	}
+	sql, args, err := q.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return err
 	}
@@ -400,34 +447,22 @@ func (r *Repository) Update(ctx context.Context, changeset domain.UpdateInput) e
 	}
 	defer conn.Release()
 
-	_, err = conn.Exec(ctx, sql, args...)
-	return err
-}
-
-func (r *Repository) AddRole(ctx context.Context, userID domain.ID, inp domain.Role) error {
-	sql, args, err := sq.Insert("users_roles").
-		Columns("user_id", "role_id").
-		Values(userID, sq.Expr("")).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
-
+	tag, err := conn.Exec(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
-
-	conn, err := r.conn.Acquire(ctx)
-	if err != nil {
-		return err
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNoUsers
 	}
-	defer conn.Release()
-
-	_, err = conn.Exec(ctx, sql, args...)
-	return err
+	return nil
 }
 
-func (r *Repository) RemoveRole(ctx context.Context, userID domain.ID, role domain.Role) error {
-	sql, args, err := sq.Delete("users_roles").
-		Where(sq.Eq{"user_id": userID, "role_id": getRoleID(role)}).
+func (r *Repository) UpdatePassword(ctx context.Context, userID domain.ID, passwordHash string) error {
+	sql, args, err := sq.
+		Update("users").
+		Set("password", passwordHash).
+		Set("updated_at", time.Now().UTC()).
+		Where(sq.Eq{"id": userID}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
@@ -440,8 +475,14 @@ func (r *Repository) RemoveRole(ctx context.Context, userID domain.ID, role doma
 	}
 	defer conn.Release()
 
-	_, err = conn.Exec(ctx, sql, args...)
-	return err
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNoUsers
+	}
+	return nil
 }
 
 func (r *Repository) Delete(ctx context.Context, userID domain.ID) error {
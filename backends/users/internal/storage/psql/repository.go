@@ -33,34 +33,12 @@ func (r *Repository) Close() {
 	r.conn.Close()
 }
 
-func parseRoles(role int) domain.Role {
-	switch role {
-	case 0:
-		return domain.RoleOwner
-	case 1:
-		return domain.RoleAdmin
-	case 2:
-		return domain.RoleModerator
-	case 3:
-		return domain.RoleDeliveryMan
-	default:
-		return domain.RoleUser
-	}
+// Ids of the roles table start from 1 and Role constants from 0,
+// roles admins make get the next ids and so the next Role values.
+func roleFromID(id int) domain.Role {
+	return domain.Role(id - 1)
 }
 
-func getRoleID(role domain.Role) int {
-	// TODO: change this code for some
-	// actual db calls maybe?
-	switch role {
-	case domain.RoleOwner:
-		return 1
-	case domain.RoleAdmin:
-		return 2
-	case domain.RoleModerator:
-		return 3
-	case domain.RoleDeliveryMan:
-		return 4
-	default:
-		return 5
-	}
+func roleID(role domain.Role) int {
+	return int(role) + 1
 }
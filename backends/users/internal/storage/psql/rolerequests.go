@@ -0,0 +1,160 @@
+package psql
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func selectRoleRequests() sq.SelectBuilder {
+	return sq.Select(
+		"id", "user_id", "role_id", "scope", "justification", "status",
+		"COALESCE(reviewer_id, 0)", "comment", "created_at", "reviewed_at",
+	).From("role_requests").PlaceholderFormat(sq.Dollar)
+}
+
+func scanRoleRequest(row pgx.Row) (domain.RoleRequest, error) {
+	req := domain.RoleRequest{}
+	var roleID int
+	err := row.Scan(
+		&req.ID, &req.UserID, &roleID, &req.Scope, &req.Justification, &req.Status,
+		&req.ReviewerID, &req.Comment, &req.CreatedAt, &req.ReviewedAt,
+	)
+	req.Role = roleFromID(roleID)
+	return req, err
+}
+
+func (r *Repository) CreateRoleRequest(ctx context.Context, req domain.RoleRequest) error {
+	// only one request may be pending for the same role and scope,
+	// unique index on them lets us skip a select
+	sql, args, err := sq.Insert("role_requests").
+		Columns("id", "user_id", "role_id", "scope", "justification", "status", "created_at").
+		Values(req.ID, req.UserID, roleID(req.Role), req.Scope, req.Justification, req.Status, req.CreatedAt).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleRequestPending
+	}
+	return nil
+}
+
+func (r *Repository) ReadRoleRequest(ctx context.Context, id string) (domain.RoleRequest, error) {
+	sql, args, err := selectRoleRequests().Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return domain.RoleRequest{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.RoleRequest{}, err
+	}
+	defer conn.Release()
+
+	req, err := scanRoleRequest(conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return req, domain.ErrRoleRequestNotFound
+	}
+	return req, err
+}
+
+func (r *Repository) ReadRoleRequests(ctx context.Context, inp domain.ReadRoleRequestsInput) ([]domain.RoleRequest, error) {
+	query := selectRoleRequests().OrderBy("created_at", "id").Limit(inp.Limit).Offset(inp.Offset)
+	if inp.UserID != 0 {
+		query = query.Where(sq.Eq{"user_id": inp.UserID})
+	}
+	if inp.Status != "" {
+		query = query.Where(sq.Eq{"status": inp.Status})
+	}
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []domain.RoleRequest{}
+	for rows.Next() {
+		req, err := scanRoleRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+// ReviewRoleRequest claims the request first, so of concurrent
+// reviews only one is written down and grants the role
+func (r *Repository) ReviewRoleRequest(ctx context.Context, req domain.RoleRequest, grant *domain.RoleGrant) error {
+	var reviewerID *domain.ID
+	if req.ReviewerID != 0 {
+		reviewerID = &req.ReviewerID
+	}
+	sql, args, err := sq.Update("role_requests").
+		Set("status", req.Status).
+		Set("reviewer_id", reviewerID).
+		Set("comment", req.Comment).
+		Set("reviewed_at", req.ReviewedAt).
+		Where(sq.Eq{"id": req.ID, "status": domain.RoleRequestPending}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleRequestNotPending
+	}
+	if grant != nil {
+		sql, args, err := addRoleQuery(req.UserID, *grant)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,181 @@
+package psql
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func selectRoles() sq.SelectBuilder {
+	return sq.Select(
+		"id", "name", "description",
+		"ARRAY(SELECT permission FROM role_permissions WHERE role_id = roles.id ORDER BY permission)",
+	).From("roles").OrderBy("id").PlaceholderFormat(sq.Dollar)
+}
+
+func scanRole(row pgx.Row) (domain.RoleDefinition, error) {
+	role := domain.RoleDefinition{}
+	var id int
+	err := row.Scan(&id, &role.Name, &role.Description, &role.Permissions)
+	role.ID = roleFromID(id)
+	return role, err
+}
+
+func (r *Repository) ReadRoles(ctx context.Context) ([]domain.RoleDefinition, error) {
+	sql, args, err := selectRoles().ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []domain.RoleDefinition{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *Repository) ReadRole(ctx context.Context, role domain.Role) (domain.RoleDefinition, error) {
+	sql, args, err := selectRoles().Where(sq.Eq{"id": roleID(role)}).ToSql()
+	if err != nil {
+		return domain.RoleDefinition{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.RoleDefinition{}, err
+	}
+	defer conn.Release()
+
+	def, err := scanRole(conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return def, domain.ErrRoleNotFound
+	}
+	return def, err
+}
+
+func (r *Repository) CreateRole(ctx context.Context, role domain.RoleDefinition) (domain.Role, error) {
+	sql, args, err := sq.Insert("roles").
+		Columns("name", "description").
+		Values(role.Name, role.Description).
+		Suffix("ON CONFLICT (name) DO NOTHING RETURNING id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrRoleExists
+		}
+		return 0, err
+	}
+	if err := insertPermissions(ctx, tx, id, role.Permissions); err != nil {
+		return 0, err
+	}
+	return roleFromID(id), tx.Commit(ctx)
+}
+
+func (r *Repository) SetRolePermissions(ctx context.Context, role domain.Role, permissions []string) error {
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the role so concurrent changes do not mix
+	var id int
+	if err := tx.QueryRow(ctx, "SELECT id FROM roles WHERE id = $1 FOR UPDATE", roleID(role)).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrRoleNotFound
+		}
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM role_permissions WHERE role_id = $1", id); err != nil {
+		return err
+	}
+	if err := insertPermissions(ctx, tx, id, permissions); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertPermissions(ctx context.Context, tx pgx.Tx, id int, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	q := sq.Insert("role_permissions").Columns("role_id", "permission")
+	for _, v := range permissions {
+		q = q.Values(id, v)
+	}
+	sql, args, err := q.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *Repository) DeleteRole(ctx context.Context, role domain.Role) error {
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM users_roles WHERE role_id = $1", roleID(role)); err != nil {
+		return err
+	}
+	// permissions go with the role by cascade
+	tag, err := tx.Exec(ctx, "DELETE FROM roles WHERE id = $1", roleID(role))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleNotFound
+	}
+	return tx.Commit(ctx)
+}
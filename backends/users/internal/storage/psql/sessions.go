@@ -0,0 +1,192 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func (r *Repository) CreateSession(ctx context.Context, s domain.Session, first domain.RefreshToken) error {
+	sql, args, err := sq.Insert("sessions").
+		Columns("id", "user_id", "user_agent", "ip", "created_at", "last_used_at").
+		Values(s.ID, s.UserID, s.UserAgent, s.IP, s.CreatedAt, s.LastUsedAt).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	if err := insertRefreshToken(ctx, tx, first); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func selectSessions() sq.SelectBuilder {
+	return sq.Select(
+		"id", "user_id", "user_agent", "ip",
+		"created_at", "last_used_at", "revoked_at",
+	).From("sessions").PlaceholderFormat(sq.Dollar)
+}
+
+func (r *Repository) ReadSession(ctx context.Context, id string) (domain.Session, error) {
+	sql, args, err := selectSessions().Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.Session{}, err
+	}
+	defer conn.Release()
+
+	s := domain.Session{}
+	if err := conn.QueryRow(ctx, sql, args...).Scan(
+		&s.ID, &s.UserID, &s.UserAgent, &s.IP,
+		&s.CreatedAt, &s.LastUsedAt, &s.RevokedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return s, domain.ErrSessionNotFound
+		}
+		return s, err
+	}
+	return s, nil
+}
+
+func (r *Repository) ReadSessions(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
+	sql, args, err := selectSessions().
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		OrderBy("last_used_at DESC").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Session{}
+	for rows.Next() {
+		s := domain.Session{}
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &s.UserAgent, &s.IP,
+			&s.CreatedAt, &s.LastUsedAt, &s.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *Repository) RevokeSession(ctx context.Context, userID domain.ID, id string) error {
+	return r.revoke(ctx, sq.Eq{"id": id, "user_id": userID}, true)
+}
+
+func (r *Repository) RevokeOtherSessions(ctx context.Context, userID domain.ID, keepID string) error {
+	return r.revoke(ctx, sq.And{sq.Eq{"user_id": userID}, sq.NotEq{"id": keepID}}, false)
+}
+
+func (r *Repository) RevokeAllSessions(ctx context.Context, userID domain.ID) error {
+	return r.revoke(ctx, sq.Eq{"user_id": userID}, false)
+}
+
+// revoke revokes sessions that match where, if mustExist is set and
+// nothing was revoked it returns domain.ErrSessionNotFound
+func (r *Repository) revoke(ctx context.Context, where sq.Sqlizer, mustExist bool) error {
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	n, err := revokeSessions(ctx, tx, where)
+	if err != nil {
+		return err
+	}
+	if mustExist && n == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// revokeSessions marks active sessions and all of their
+// refresh tokens as revoked and returns how many sessions were revoked
+func revokeSessions(ctx context.Context, tx pgx.Tx, where sq.Sqlizer) (int64, error) {
+	now := time.Now().UTC()
+	sql, args, err := sq.Update("sessions").
+		Set("revoked_at", now).
+		Where(where).
+		Where(sq.Eq{"revoked_at": nil}).
+		Suffix("RETURNING \"id\"").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		id := ""
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	sql, args, err = sq.Update("refresh_tokens").
+		Set("revoked_at", now).
+		Where(sq.Eq{"family_id": ids, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
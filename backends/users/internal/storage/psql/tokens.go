@@ -0,0 +1,115 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func (r *Repository) RotateRefreshToken(ctx context.Context, oldID string, next domain.RefreshToken) error {
+	now := time.Now().UTC()
+	// only a token that was never used, is not revoked
+	// and is not expired can be rotated
+	sql, args, err := sq.Update("refresh_tokens").
+		Set("rotated_at", now).
+		Where(sq.Eq{"id": oldID, "family_id": next.FamilyID, "rotated_at": nil, "revoked_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.checkRefreshToken(ctx, tx, oldID)
+	}
+
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	sql, args, err = sq.Update("sessions").
+		Set("last_used_at", now).
+		Where(sq.Eq{"id": next.FamilyID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertRefreshToken(ctx context.Context, tx pgx.Tx, t domain.RefreshToken) error {
+	sql, args, err := sq.Insert("refresh_tokens").
+		Columns("id", "family_id", "user_id", "issued_at", "expires_at").
+		Values(t.ID, t.FamilyID, t.UserID, t.IssuedAt, t.ExpiresAt).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// checkRefreshToken tells why token could not be rotated
+func (r *Repository) checkRefreshToken(ctx context.Context, tx pgx.Tx, id string) error {
+	sql, args, err := sq.Select("rotated_at IS NOT NULL OR revoked_at IS NOT NULL").
+		From("refresh_tokens").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	used := false
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&used); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+	if used {
+		return domain.ErrRefreshTokenReused
+	}
+	// the token is simply expired
+	return domain.ErrInvalidToken
+}
+
+func (r *Repository) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := revokeSessions(ctx, tx, sq.Eq{"id": familyID}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
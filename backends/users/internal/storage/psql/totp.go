@@ -0,0 +1,195 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func (r *Repository) CreateTOTP(ctx context.Context, t domain.TOTP) error {
+	// confirmed secret is never replaced, it has to be deleted first
+	sql, args, err := sq.Insert("totp").
+		Columns("user_id", "secret", "created_at").
+		Values(t.UserID, t.Secret, t.CreatedAt).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE
+			SET secret = EXCLUDED.secret, created_at = EXCLUDED.created_at
+			WHERE totp.confirmed_at IS NULL`).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTOTPAlreadyEnabled
+	}
+	return nil
+}
+
+func (r *Repository) ReadTOTP(ctx context.Context, userID domain.ID) (domain.TOTP, error) {
+	sql, args, err := sq.Select("user_id", "secret", "created_at", "confirmed_at").
+		From("totp").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return domain.TOTP{}, err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return domain.TOTP{}, err
+	}
+	defer conn.Release()
+
+	t := domain.TOTP{}
+	if err := conn.QueryRow(ctx, sql, args...).Scan(
+		&t.UserID, &t.Secret, &t.CreatedAt, &t.ConfirmedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return t, domain.ErrTOTPNotFound
+		}
+		return t, err
+	}
+	return t, nil
+}
+
+func (r *Repository) ConfirmTOTP(ctx context.Context, userID domain.ID, recoveryCodes []string) error {
+	sql, args, err := sq.Update("totp").
+		Set("confirmed_at", time.Now().UTC()).
+		Where(sq.Eq{"user_id": userID, "confirmed_at": nil}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTOTPAlreadyEnabled
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userID domain.ID, recoveryCodes []string) error {
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID domain.ID, recoveryCodes []string) error {
+	sql, args, err := sq.Delete("recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	if len(recoveryCodes) == 0 {
+		return nil
+	}
+
+	insert := sq.Insert("recovery_codes").Columns("user_id", "code_hash")
+	for _, c := range recoveryCodes {
+		insert = insert.Values(userID, c)
+	}
+	sql, args, err = insert.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *Repository) UseRecoveryCode(ctx context.Context, userID domain.ID, recoveryCode string) error {
+	sql, args, err := sq.Update("recovery_codes").
+		Set("used_at", time.Now().UTC()).
+		Where(sq.Eq{"user_id": userID, "code_hash": recoveryCode, "used_at": nil}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidCode
+	}
+	return nil
+}
+
+func (r *Repository) DeleteTOTP(ctx context.Context, userID domain.ID) error {
+	sql, args, err := sq.Delete("totp").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTOTPNotFound
+	}
+	return nil
+}
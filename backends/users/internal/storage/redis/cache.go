@@ -0,0 +1,166 @@
+package redis
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var ErrInvalidTTL = errors.New("redis: ttl has to be positive")
+
+type Config struct {
+	Addr     string
+	Password string
+
+	// PoolSize is the maximum number of open connections
+	PoolSize int
+	// PoolTimeout is how long we wait for a free connection
+	// when all of them are busy
+	PoolTimeout time.Duration
+	// IdleTimeout closes connections that were not used for this long,
+	// zero keeps them forever
+	IdleTimeout time.Duration
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+const (
+	defaultPoolSize     = 10
+	defaultPoolTimeout  = time.Second * 4
+	defaultIdleTimeout  = time.Minute * 5
+	defaultDialTimeout  = time.Second * 5
+	defaultReadTimeout  = time.Second * 3
+	defaultWriteTimeout = time.Second * 3
+)
+
+// Cache implements domain.Cache on top of redis so codes
+// can be shared between replicas of the service.
+// GETDEL is used for Pop, so redis has to be at least 6.2
+type Cache struct {
+	pool *pool
+}
+
+// NewCache pings redis to make sure it is reachable.
+// Zero values in cfg are replaced with defaults.
+func NewCache(cfg Config) (*Cache, error) {
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = defaultPoolSize
+	}
+	if cfg.PoolTimeout <= 0 {
+		cfg.PoolTimeout = defaultPoolTimeout
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+	if cfg.ReadTimeout <= 0 {
+		cfg.ReadTimeout = defaultReadTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+
+	c := &Cache{pool: newPool(cfg)}
+	if err := c.Ping(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) Ping() error {
+	_, err := c.do("PING")
+	return err
+}
+
+func (c *Cache) Close() error {
+	return c.pool.close()
+}
+
+func (c *Cache) Store(key, value string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	// redis expects whole seconds with EX, so
+	// ttls like 1.5s are sent in milliseconds
+	var args []string
+	if ttl%time.Second == 0 {
+		args = []string{"SET", key, value, "EX", strconv.FormatInt(int64(ttl/time.Second), 10)}
+	} else {
+		args = []string{"SET", key, value, "PX", strconv.FormatInt(ttl.Milliseconds(), 10)}
+	}
+	_, err := c.do(args...)
+	return err
+}
+
+func (c *Cache) Get(key string) (string, error) {
+	return c.getString("GET", key)
+}
+
+func (c *Cache) Pop(key string) (string, error) {
+	return c.getString("GETDEL", key)
+}
+
+// incrScript sets expiration only for new counters, INCR alone would
+// create a key that never expires and EXPIRE after it is not atomic.
+const incrScript = `
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n`
+
+func (c *Cache) Incr(key string, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+	reply, err := c.do("EVAL", incrScript, "1", key, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, ErrUnexpectedReply
+	}
+	return n, nil
+}
+
+func (c *Cache) Delete(key string) error {
+	_, err := c.do("DEL", key)
+	return err
+}
+
+func (c *Cache) getString(args ...string) (string, error) {
+	reply, err := c.do(args...)
+	if err != nil {
+		return "", err
+	}
+	switch v := reply.(type) {
+	case nil:
+		return "", domain.ErrCacheMiss
+	case string:
+		return v, nil
+	default:
+		return "", ErrUnexpectedReply
+	}
+}
+
+func (c *Cache) do(args ...string) (interface{}, error) {
+	conn, err := c.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.pool.do(conn, args...)
+
+	// replies with an error from redis still leave
+	// the connection in a usable state
+	var redisErr Error
+	c.pool.put(conn, err != nil && !errors.As(err, &redisErr))
+	return reply, err
+}
@@ -0,0 +1,222 @@
+package redis_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/storage/redis"
+)
+
+func TestCache(t *testing.T) {
+	fake := newFakeRedis(t, "secret")
+	c, err := redis.NewCache(redis.Config{Addr: fake.addr(), Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	testCases := []struct {
+		name  string
+		key   string
+		value string
+		pop   bool
+		err   error
+
+		setup func()
+	}{
+		{
+			name:  "success",
+			key:   "+996702569123",
+			value: "123456",
+			setup: func() {
+				if err := c.Store("+996702569123", "123456", time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name:  "success with pop",
+			key:   "pizzas@gmail.com",
+			value: "654321",
+			pop:   true,
+			setup: func() {
+				if err := c.Store("pizzas@gmail.com", "654321", time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name:  "fail with consumed value",
+			key:   "pizzas@gmail.com",
+			pop:   true,
+			err:   domain.ErrCacheMiss,
+			setup: func() {},
+		},
+		{
+			name: "fail with expired",
+			key:  "expired",
+			err:  domain.ErrCacheMiss,
+			setup: func() {
+				if err := c.Store("expired", "123456", time.Millisecond*10); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(time.Millisecond * 20)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setup()
+			get := c.Get
+			if tc.pop {
+				get = c.Pop
+			}
+			value, err := get(tc.key)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			}
+			if value != tc.value {
+				t.Errorf("got %q, want %q", value, tc.value)
+			}
+		})
+	}
+
+	if err := c.Store("key", "value", 0); !errors.Is(err, redis.ErrInvalidTTL) {
+		t.Errorf("got %v, want %v", err, redis.ErrInvalidTTL)
+	}
+}
+
+func TestCacheIncr(t *testing.T) {
+	fake := newFakeRedis(t, "")
+	c, err := redis.NewCache(redis.Config{Addr: fake.addr()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := int64(1); i <= 3; i++ {
+		n, err := c.Incr("attempts", time.Millisecond*30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != i {
+			t.Errorf("got %d, want %d", n, i)
+		}
+	}
+	// ttl is not prolonged by later increments
+	time.Sleep(time.Millisecond * 40)
+	if n, err := c.Incr("attempts", time.Minute); err != nil || n != 1 {
+		t.Errorf("got %d, %v, want counter to start over", n, err)
+	}
+
+	c.Store("code", "123456", time.Minute)
+	if err := c.Delete("code"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("code"); !errors.Is(err, domain.ErrCacheMiss) {
+		t.Errorf("got %v, want %v", err, domain.ErrCacheMiss)
+	}
+	if err := c.Delete("code"); err != nil {
+		t.Errorf("deleting missing key should not fail, got %v", err)
+	}
+}
+
+func TestCacheSendsExpiration(t *testing.T) {
+	fake := newFakeRedis(t, "")
+	c, err := redis.NewCache(redis.Config{Addr: fake.addr()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Store("seconds", "1", time.Minute*5)
+	c.Store("millis", "2", time.Millisecond*1500)
+
+	want := []string{"PING", "SET seconds 1 EX 300", "SET millis 2 PX 1500"}
+	got := fake.commands()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got[i], want[i])
+		}
+	}
+}
+
+func TestCacheAuth(t *testing.T) {
+	fake := newFakeRedis(t, "secret")
+	_, err := redis.NewCache(redis.Config{Addr: fake.addr(), Password: "wrong"})
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		t.Errorf("got %v, want redis error", err)
+	}
+}
+
+func TestCachePool(t *testing.T) {
+	fake := newFakeRedis(t, "")
+	c, err := redis.NewCache(redis.Config{Addr: fake.addr(), PoolSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	fake.setDelay(time.Millisecond * 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Store("key", "value", time.Minute); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := fake.connections(); n > 3 {
+		t.Errorf("pool opened %d connections, want at most 3", n)
+	}
+}
+
+func TestCacheTimeouts(t *testing.T) {
+	fake := newFakeRedis(t, "")
+	c, err := redis.NewCache(redis.Config{
+		Addr:        fake.addr(),
+		PoolSize:    1,
+		PoolTimeout: time.Millisecond * 20,
+		ReadTimeout: time.Millisecond * 50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	fake.setDelay(time.Millisecond * 200)
+	done := make(chan error)
+	go func() {
+		_, err := c.Get("key")
+		done <- err
+	}()
+
+	// the only connection is busy so we can not get one from the pool
+	time.Sleep(time.Millisecond * 5)
+	if _, err := c.Get("key"); !errors.Is(err, redis.ErrPoolTimeout) {
+		t.Errorf("got %v, want %v", err, redis.ErrPoolTimeout)
+	}
+
+	var netErr interface{ Timeout() bool }
+	if err := <-done; !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("got %v, want read timeout", err)
+	}
+
+	// broken connection is thrown away and a new one is dialed
+	fake.setDelay(0)
+	if _, err := c.Get("key"); !errors.Is(err, domain.ErrCacheMiss) {
+		t.Errorf("got %v, want %v", err, domain.ErrCacheMiss)
+	}
+}
@@ -0,0 +1,233 @@
+package redis_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-process stand-in for redis that understands
+// just enough of RESP and commands for our Cache.
+type fakeRedis struct {
+	t        *testing.T
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	values  map[string]fakeValue
+	conns   int
+	delay   time.Duration
+	history []string
+}
+
+type fakeValue struct {
+	value     string
+	expiresAt time.Time
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{
+		t:        t,
+		listener: l,
+		password: password,
+		values:   make(map[string]fakeValue),
+	}
+	go f.serve()
+	t.Cleanup(func() { l.Close() })
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeRedis) connections() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.conns
+}
+
+func (f *fakeRedis) setDelay(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delay = d
+}
+
+func (f *fakeRedis) commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.history...)
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		c, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns++
+		f.mu.Unlock()
+		go f.handle(c)
+	}
+}
+
+func (f *fakeRedis) handle(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authed := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if err != io.EOF {
+				f.t.Logf("fake redis: %v", err)
+			}
+			return
+		}
+
+		f.mu.Lock()
+		delay := f.delay
+		f.history = append(f.history, strings.Join(args, " "))
+		f.mu.Unlock()
+		time.Sleep(delay)
+
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			io.WriteString(c, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		switch cmd {
+		case "AUTH":
+			if len(args) != 2 || args[1] != f.password {
+				io.WriteString(c, "-WRONGPASS invalid username-password pair\r\n")
+				continue
+			}
+			authed = true
+			io.WriteString(c, "+OK\r\n")
+		case "PING":
+			io.WriteString(c, "+PONG\r\n")
+		case "SET":
+			io.WriteString(c, f.set(args[1:]))
+		case "GET", "GETDEL":
+			io.WriteString(c, f.get(args[1], cmd == "GETDEL"))
+		case "DEL":
+			io.WriteString(c, f.del(args[1]))
+		case "EVAL":
+			// the only script we run is the one from Cache.Incr
+			if len(args) != 5 || !strings.Contains(args[1], "INCR") {
+				io.WriteString(c, "-ERR unknown script\r\n")
+				continue
+			}
+			io.WriteString(c, f.incr(args[3], args[4]))
+		default:
+			fmt.Fprintf(c, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+func (f *fakeRedis) set(args []string) string {
+	if len(args) != 4 {
+		return "-ERR syntax error\r\n"
+	}
+	n, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil || n <= 0 {
+		return "-ERR invalid expire time in 'set' command\r\n"
+	}
+	var ttl time.Duration
+	switch strings.ToUpper(args[2]) {
+	case "EX":
+		ttl = time.Duration(n) * time.Second
+	case "PX":
+		ttl = time.Duration(n) * time.Millisecond
+	default:
+		return "-ERR syntax error\r\n"
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values[args[0]] = fakeValue{value: args[1], expiresAt: time.Now().Add(ttl)}
+	return "+OK\r\n"
+}
+
+func (f *fakeRedis) get(key string, del bool) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.values[key]
+	if !ok || time.Now().After(v.expiresAt) {
+		delete(f.values, key)
+		return "$-1\r\n"
+	}
+	if del {
+		delete(f.values, key)
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(v.value), v.value)
+}
+
+func (f *fakeRedis) del(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.values[key]
+	delete(f.values, key)
+	if ok {
+		return ":1\r\n"
+	}
+	return ":0\r\n"
+}
+
+func (f *fakeRedis) incr(key, ttl string) string {
+	ms, err := strconv.ParseInt(ttl, 10, 64)
+	if err != nil || ms <= 0 {
+		return "-ERR invalid expire time\r\n"
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.values[key]
+	if !ok || time.Now().After(v.expiresAt) {
+		v = fakeValue{value: "0", expiresAt: time.Now().Add(time.Duration(ms) * time.Millisecond)}
+	}
+	n, err := strconv.ParseInt(v.value, 10, 64)
+	if err != nil {
+		return "-ERR value is not an integer or out of range\r\n"
+	}
+	v.value = strconv.FormatInt(n+1, 10)
+	f.values[key] = v
+	return fmt.Sprintf(":%d\r\n", n+1)
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPoolTimeout = errors.New("redis: timed out waiting for a free connection")
+	ErrPoolClosed  = errors.New("redis: pool is closed")
+)
+
+type conn struct {
+	netConn  net.Conn
+	r        *bufio.Reader
+	w        *bufio.Writer
+	lastUsed time.Time
+}
+
+// pool limits number of open connections to its size
+// and keeps the ones that are not in use for reuse.
+type pool struct {
+	cfg Config
+
+	// sem holds a token for every connection that is handed out or idle
+	sem  chan struct{}
+	mu   sync.Mutex
+	idle []*conn
+
+	closed bool
+}
+
+func newPool(cfg Config) *pool {
+	return &pool{
+		cfg: cfg,
+		sem: make(chan struct{}, cfg.PoolSize),
+	}
+}
+
+func (p *pool) get() (*conn, error) {
+	timer := time.NewTimer(p.cfg.PoolTimeout)
+	defer timer.Stop()
+	select {
+	case p.sem <- struct{}{}:
+	case <-timer.C:
+		return nil, ErrPoolTimeout
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.sem
+		return nil, ErrPoolClosed
+	}
+	for len(p.idle) > 0 {
+		c := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if p.cfg.IdleTimeout > 0 && time.Since(c.lastUsed) > p.cfg.IdleTimeout {
+			c.netConn.Close()
+			continue
+		}
+		p.mu.Unlock()
+		return c, nil
+	}
+	p.mu.Unlock()
+
+	c, err := p.dial()
+	if err != nil {
+		<-p.sem
+		return nil, err
+	}
+	return c, nil
+}
+
+// put returns connection to the pool. Connections that had
+// network or protocol errors must be put with broken set to true.
+func (p *pool) put(c *conn, broken bool) {
+	defer func() { <-p.sem }()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if broken || p.closed {
+		c.netConn.Close()
+		return
+	}
+	c.lastUsed = time.Now()
+	p.idle = append(p.idle, c)
+}
+
+func (p *pool) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var err error
+	for _, c := range p.idle {
+		if cerr := c.netConn.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	p.idle = nil
+	return err
+}
+
+func (p *pool) dial() (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", p.cfg.Addr, p.cfg.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{
+		netConn: netConn,
+		r:       bufio.NewReader(netConn),
+		w:       bufio.NewWriter(netConn),
+	}
+	if p.cfg.Password != "" {
+		if _, err := p.do(c, "AUTH", p.cfg.Password); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (p *pool) do(c *conn, args ...string) (interface{}, error) {
+	if err := c.netConn.SetWriteDeadline(time.Now().Add(p.cfg.WriteTimeout)); err != nil {
+		return nil, err
+	}
+	if err := writeCommand(c.w, args...); err != nil {
+		return nil, err
+	}
+	if err := c.netConn.SetReadDeadline(time.Now().Add(p.cfg.ReadTimeout)); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// We only need a handful of commands, so instead of pulling
+// a whole client library we speak RESP ourselves.
+// https://redis.io/docs/reference/protocol-spec/
+
+var (
+	ErrProtocol        = errors.New("redis: protocol error")
+	ErrUnexpectedReply = errors.New("redis: unexpected reply type")
+)
+
+// Error is an error reply sent by redis itself.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+func writeCommand(w *bufio.Writer, args ...string) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, v := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	}
+	return w.Flush()
+}
+
+// readReply returns string for simple and bulk strings, int64 for integers,
+// []interface{} for arrays and nil for nil replies. Error replies are
+// returned as Error.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, ErrProtocol
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, ErrProtocol
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, ErrProtocol
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if buf[n] != '\r' || buf[n+1] != '\n' {
+			return nil, ErrProtocol
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, ErrProtocol
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		res := make([]interface{}, n)
+		for i := range res {
+			if res[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	default:
+		return nil, ErrProtocol
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", ErrProtocol
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,108 @@
+package grpcserver
+
+import (
+	"errors"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorCodes maps domain errors to grpc codes.
+// Errors that are not listed here are reported as codes.Internal
+// and their message is not sent to the client.
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{domain.ErrInvalidRequestSignUpInput, codes.InvalidArgument},
+	{domain.ErrInvalidSignUpInput, codes.InvalidArgument},
+	{domain.ErrInvalidSignInInput, codes.InvalidArgument},
+	{domain.ErrInvalidPhoneNumber, codes.InvalidArgument},
+	{domain.ErrInvalidEmail, codes.InvalidArgument},
+	{domain.ErrInvalidFullName, codes.InvalidArgument},
+	{domain.ErrInvalidRole, codes.InvalidArgument},
+	{domain.ErrInvalidRoleRequest, codes.InvalidArgument},
+	{domain.ErrUnknownProvider, codes.InvalidArgument},
+	{domain.ErrPasswordIsNotSecure, codes.InvalidArgument},
+	{domain.ErrInvalidClientMetadata, codes.InvalidArgument},
+	{domain.ErrInvalidAPIKeyInput, codes.InvalidArgument},
+	{domain.ErrInvalidRedirectURI, codes.InvalidArgument},
+	{domain.ErrUnsupportedResponseType, codes.InvalidArgument},
+	{domain.ErrInvalidScope, codes.InvalidArgument},
+	{domain.ErrPKCERequired, codes.InvalidArgument},
+
+	{domain.ErrInvalidCode, codes.Unauthenticated},
+	{domain.ErrInvalidToken, codes.Unauthenticated},
+	{domain.ErrRefreshTokenReused, codes.Unauthenticated},
+	{domain.ErrSessionRevoked, codes.Unauthenticated},
+	{domain.ErrInvalidResetToken, codes.Unauthenticated},
+	{domain.ErrInvalidCredentials, codes.Unauthenticated},
+	{domain.ErrSecondFactorRequired, codes.Unauthenticated},
+	{domain.ErrInvalidChallenge, codes.Unauthenticated},
+	{domain.ErrInvalidCeremony, codes.Unauthenticated},
+	{domain.ErrInvalidPasskey, codes.Unauthenticated},
+	{domain.ErrInvalidState, codes.Unauthenticated},
+	{domain.ErrInvalidIDToken, codes.Unauthenticated},
+	{domain.ErrInvalidClient, codes.Unauthenticated},
+
+	{domain.ErrOwnerCantBeRemoved, codes.PermissionDenied},
+	{domain.ErrNotAllowed, codes.PermissionDenied},
+	{domain.ErrUnauthorizedClient, codes.PermissionDenied},
+	{domain.ErrConsentRequired, codes.PermissionDenied},
+
+	{domain.ErrNoUsers, codes.NotFound},
+	{domain.ErrSessionNotFound, codes.NotFound},
+	{domain.ErrPasskeyNotFound, codes.NotFound},
+	{domain.ErrIdentityNotFound, codes.NotFound},
+	{domain.ErrClientNotFound, codes.NotFound},
+	{domain.ErrConsentNotFound, codes.NotFound},
+	{domain.ErrAPIKeyNotFound, codes.NotFound},
+	{domain.ErrRoleNotFound, codes.NotFound},
+	{domain.ErrRoleRequestNotFound, codes.NotFound},
+
+	{domain.ErrTooManyAttempts, codes.ResourceExhausted},
+	{domain.ErrAccountLocked, codes.FailedPrecondition},
+	{domain.ErrTOTPNotFound, codes.FailedPrecondition},
+	{domain.ErrTOTPAlreadyEnabled, codes.AlreadyExists},
+	{domain.ErrPasskeyExists, codes.AlreadyExists},
+	{domain.ErrIdentityExists, codes.AlreadyExists},
+	{domain.ErrRoleExists, codes.AlreadyExists},
+	{domain.ErrRoleRequestPending, codes.AlreadyExists},
+	{domain.ErrIdentityNotLinked, codes.FailedPrecondition},
+	{domain.ErrRoleRequestNotPending, codes.FailedPrecondition},
+	{domain.ErrPasskeysDisabled, codes.Unimplemented},
+}
+
+func toStatus(err error) error {
+	var rateLimitErr *domain.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		st, detailsErr := status.New(codes.ResourceExhausted, rateLimitErr.Error()).
+			WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(rateLimitErr.RetryAfter)})
+		if detailsErr != nil {
+			return status.Error(codes.ResourceExhausted, rateLimitErr.Error())
+		}
+		return st.Err()
+	}
+	var passwordErr *domain.PasswordPolicyError
+	if errors.As(err, &passwordErr) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(passwordErr.Violations))
+		for i, v := range passwordErr.Violations {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: "password", Description: v.Reason + ": " + v.Message}
+		}
+		st, detailsErr := status.New(codes.InvalidArgument, domain.ErrPasswordIsNotSecure.Error()).
+			WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+		if detailsErr != nil {
+			return status.Error(codes.InvalidArgument, domain.ErrPasswordIsNotSecure.Error())
+		}
+		return st.Err()
+	}
+	for _, v := range errorCodes {
+		if errors.Is(err, v.err) {
+			return status.Error(v.code, v.err.Error())
+		}
+	}
+	return status.Error(codes.Internal, "internal error")
+}
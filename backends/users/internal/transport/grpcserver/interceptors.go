@@ -0,0 +1,67 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// withClientInfo puts caller's ip and user agent into context
+func withClientInfo(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var client domain.ClientInfo
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			client.UserAgent = ua[0]
+		}
+	}
+	return handler(domain.WithClientInfo(ctx, client), req)
+}
+
+const bearerPrefix = "Bearer "
+
+// scopedMethods are the only methods clients and api keys may call
+// and the scope each of them needs, roles mean nothing for them.
+var scopedMethods = map[string]string{
+	"/users.UserService/GetUser":              domain.ScopeUsersRead,
+	"/users.UserService/GetUserByEmail":       domain.ScopeUsersRead,
+	"/users.UserService/GetUserByPhoneNumber": domain.ScopeUsersRead,
+	"/users.UserService/UpdateUser":           domain.ScopeUsersWrite,
+}
+
+// withCaller puts claims of the bearer token or api key from authorization
+// metadata into context, requests may come without them too.
+func withCaller(s domain.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		header := md.Get("authorization")
+		if len(header) == 0 {
+			return handler(ctx, req)
+		}
+		if !strings.HasPrefix(header[0], bearerPrefix) {
+			return nil, toStatus(fmt.Errorf("withCaller(): %w", domain.ErrInvalidToken))
+		}
+		caller, err := s.Authenticate(ctx, strings.TrimPrefix(header[0], bearerPrefix))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		if caller.Scoped() {
+			scope, ok := scopedMethods[info.FullMethod]
+			if !ok || !caller.HasScope(scope) {
+				return nil, toStatus(fmt.Errorf("withCaller(): %s needs scope %q: %w", info.FullMethod, scope, domain.ErrNotAllowed))
+			}
+		}
+		return handler(domain.WithCaller(ctx, caller), req)
+	}
+}
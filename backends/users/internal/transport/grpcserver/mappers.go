@@ -0,0 +1,283 @@
+package grpcserver
+
+import (
+	"github.com/rasulov-emirlan/micro-pizzas/backends/protos/userspb"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+func roleFromProto(r userspb.User_Role) (domain.Role, error) {
+	switch r {
+	case userspb.User_OWNER:
+		return domain.RoleOwner, nil
+	case userspb.User_ADMIN:
+		return domain.RoleAdmin, nil
+	case userspb.User_MODERATOR:
+		return domain.RoleModerator, nil
+	case userspb.User_DELIVERYMAN:
+		return domain.RoleDeliveryMan, nil
+	case userspb.User_USER:
+		return domain.RoleUser, nil
+	default:
+		return 0, domain.ErrInvalidRole
+	}
+}
+
+// customRoleFromProto lets requests name roles admins made,
+// they have no values in our enum.
+func customRoleFromProto(r userspb.User_Role, custom uint32) (domain.Role, error) {
+	if custom != 0 {
+		return domain.Role(custom), nil
+	}
+	return roleFromProto(r)
+}
+
+func roleDefinitionToProto(r domain.RoleDefinition) *userspb.RoleDefinition {
+	return &userspb.RoleDefinition{
+		Id:          uint32(r.ID),
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.Permissions,
+	}
+}
+
+func roleToProto(r domain.Role) userspb.User_Role {
+	switch r {
+	case domain.RoleOwner:
+		return userspb.User_OWNER
+	case domain.RoleAdmin:
+		return userspb.User_ADMIN
+	case domain.RoleModerator:
+		return userspb.User_MODERATOR
+	case domain.RoleDeliveryMan:
+		return userspb.User_DELIVERYMAN
+	default:
+		return userspb.User_USER
+	}
+}
+
+// rolesToProto squashes users roles into the single role our
+// proto has. We pick the most privileged one, roles are ordered
+// from owner to user so it is the one with the lowest value.
+func rolesToProto(roles []domain.Role) userspb.User_Role {
+	if len(roles) == 0 {
+		return userspb.User_USER
+	}
+	top := roles[0]
+	for _, v := range roles[1:] {
+		if v < top {
+			top = v
+		}
+	}
+	return roleToProto(top)
+}
+
+func sortingFromProto(s userspb.GetUsersRequest_Sorting) domain.Sorting {
+	switch s {
+	case userspb.GetUsersRequest_BY_FULL_NAME_ASC:
+		return domain.ReadAllSortByFullNameASC
+	case userspb.GetUsersRequest_BY_FULL_NAME_DESC:
+		return domain.ReadAllSortByFullNameDESC
+	case userspb.GetUsersRequest_BY_EMAIL_ASC:
+		return domain.ReadAllSortByEmailASC
+	case userspb.GetUsersRequest_BY_EMAIL_DESC:
+		return domain.ReadAllSortByEmailDESC
+	default:
+		return domain.ReadAllSortByID
+	}
+}
+
+func addressFromProto(a *userspb.Address) domain.Address {
+	if a == nil {
+		return domain.Address{}
+	}
+	res := domain.Address{
+		CountryCode:  a.GetCountry(),
+		City:         a.GetCity(),
+		Street:       a.GetStreet(),
+		Instructions: a.GetInstructions(),
+	}
+	if a.GetFloor() != 0 {
+		floor := int(a.GetFloor())
+		res.Floor = &floor
+	}
+	if a.GetApartment() != 0 {
+		apartment := int(a.GetApartment())
+		res.Apartment = &apartment
+	}
+	return res
+}
+
+func addressToProto(a domain.Address) *userspb.Address {
+	res := &userspb.Address{
+		Country:      a.CountryCode,
+		City:         a.City,
+		Street:       a.Street,
+		Instructions: a.Instructions,
+	}
+	if a.Floor != nil {
+		res.Floor = int32(*a.Floor)
+	}
+	if a.Apartment != nil {
+		res.Apartment = int32(*a.Apartment)
+	}
+	return res
+}
+
+func userToProto(u domain.User) *userspb.User {
+	res := &userspb.User{
+		Id:          uint64(u.ID),
+		Email:       u.Email,
+		PhoneNumber: u.PhoneNumber,
+		FullName:    u.FullName,
+		Roles:       rolesToProto(u.Roles),
+		Permissions: u.Permissions,
+		Addresses:   make([]*userspb.Address, len(u.Addresses)),
+		Grants:      make([]*userspb.RoleGrant, len(u.Grants)),
+	}
+	for i, a := range u.Addresses {
+		res.Addresses[i] = addressToProto(a)
+	}
+	for i, g := range u.Grants {
+		res.Grants[i] = grantToProto(g)
+	}
+	if !u.BirthDate.IsZero() {
+		res.BirthDate = u.BirthDate.Unix()
+	}
+	if !u.CreatedAt.IsZero() {
+		res.CreatedAt = u.CreatedAt.Unix()
+	}
+	if !u.UpdatedAt.IsZero() {
+		res.UpdatedAt = u.UpdatedAt.Unix()
+	}
+	return res
+}
+
+func grantToProto(g domain.RoleGrant) *userspb.RoleGrant {
+	res := &userspb.RoleGrant{
+		Role:      uint32(g.Role),
+		Scope:     g.Scope,
+		GrantedBy: uint64(g.GrantedBy),
+		Reason:    g.Reason,
+		GrantedAt: g.GrantedAt.Unix(),
+	}
+	if g.ExpiresAt != nil {
+		res.ExpiresAt = g.ExpiresAt.Unix()
+	}
+	return res
+}
+
+func roleRequestToProto(r domain.RoleRequest) *userspb.RoleRequest {
+	res := &userspb.RoleRequest{
+		Id:            r.ID,
+		UserID:        uint64(r.UserID),
+		Role:          uint32(r.Role),
+		Scope:         r.Scope,
+		Justification: r.Justification,
+		Status:        string(r.Status),
+		ReviewerID:    uint64(r.ReviewerID),
+		Comment:       r.Comment,
+		CreatedAt:     r.CreatedAt.Unix(),
+	}
+	if r.ReviewedAt != nil {
+		res.ReviewedAt = r.ReviewedAt.Unix()
+	}
+	return res
+}
+
+func sessionToProto(s domain.Session) *userspb.Session {
+	return &userspb.Session{
+		Id:         s.ID,
+		UserAgent:  s.UserAgent,
+		Ip:         s.IP,
+		CreatedAt:  s.CreatedAt.Unix(),
+		LastUsedAt: s.LastUsedAt.Unix(),
+	}
+}
+
+func passkeyToProto(p domain.Passkey) *userspb.Passkey {
+	res := &userspb.Passkey{
+		Id:        p.ID,
+		Name:      p.Name,
+		CreatedAt: p.CreatedAt.Unix(),
+	}
+	if p.LastUsedAt != nil {
+		res.LastUsedAt = p.LastUsedAt.Unix()
+	}
+	return res
+}
+
+func identityToProto(i domain.Identity) *userspb.Identity {
+	return &userspb.Identity{
+		Provider:  i.Provider,
+		Subject:   i.Subject,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt.Unix(),
+	}
+}
+
+func consentToProto(c domain.Consent) *userspb.Consent {
+	return &userspb.Consent{
+		ClientId:  c.ClientID,
+		Scopes:    c.Scopes,
+		GrantedAt: c.GrantedAt.Unix(),
+	}
+}
+
+// oauthClientToProto never sends the secret hash
+func oauthClientToProto(c domain.OAuthClient) *userspb.OAuthClient {
+	return &userspb.OAuthClient{
+		Id:           c.ID,
+		Name:         c.Name,
+		RedirectUris: c.RedirectURIs,
+		GrantTypes:   c.GrantTypes,
+		Scopes:       c.Scopes,
+		Public:       c.Public,
+		Trusted:      c.Trusted,
+		CreatedAt:    c.CreatedAt.Unix(),
+		Jwks:         string(c.JWKS),
+	}
+}
+
+func apiKeyToProto(k domain.APIKey) *userspb.APIKey {
+	res := &userspb.APIKey{
+		Id:   k.ID,
+		Name: k.Name,
+		Owner: &userspb.APIKeyOwner{
+			UserId:       uint64(k.Owner.UserID),
+			Organization: k.Owner.Organization,
+		},
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt.Unix(),
+	}
+	if k.ExpiresAt != nil {
+		res.ExpiresAt = k.ExpiresAt.Unix()
+	}
+	if k.LastUsedAt != nil {
+		res.LastUsedAt = k.LastUsedAt.Unix()
+	}
+	if k.RevokedAt != nil {
+		res.RevokedAt = k.RevokedAt.Unix()
+	}
+	return res
+}
+
+func apiKeyOwnerFromProto(o *userspb.APIKeyOwner) domain.APIKeyOwner {
+	return domain.APIKeyOwner{
+		UserID:       domain.ID(o.GetUserId()),
+		Organization: o.GetOrganization(),
+	}
+}
+
+func challengeToProto(c *domain.SecondFactorRequiredError) *userspb.SecondFactorChallenge {
+	challenge := &userspb.SecondFactorChallenge{
+		ChallengeToken: c.ChallengeToken,
+		ExpiresAt:      c.ExpiresAt.Unix(),
+	}
+	if c.Enrollment != nil {
+		challenge.Enrollment = &userspb.TOTPEnrollment{
+			Secret: c.Enrollment.Secret,
+			Uri:    c.Enrollment.URI,
+		}
+	}
+	return challenge
+}
@@ -0,0 +1,660 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/protos/userspb"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"google.golang.org/grpc"
+)
+
+type server struct {
+	userspb.UnimplementedUserServiceServer
+	service domain.Service
+}
+
+// NewServer returns a grpc server with UserService registered on it.
+// All the work is done by domain.Service, here we only convert
+// protobuf messages to domain structs and back. Services and partners
+// call it with tokens of their own or api keys in authorization
+// metadata, those reach only methods their scopes allow.
+func NewServer(s domain.Service, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(withClientInfo, withCaller(s))}, opts...)
+	gs := grpc.NewServer(opts...)
+	userspb.RegisterUserServiceServer(gs, &server{service: s})
+	return gs
+}
+
+func (s *server) RequestSignUp(ctx context.Context, req *userspb.RequestSignUpRequest) (*userspb.Empty, error) {
+	if err := s.service.RequestSignUp(ctx, domain.RequestSignUpInput{
+		PhoneNumber: req.GetPhoneNumber(),
+		Email:       req.GetEmail(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) SignUp(ctx context.Context, req *userspb.SignUpRequest) (*userspb.SignUpResponse, error) {
+	inp := domain.SignUpInput{
+		Code:        req.GetCode(),
+		PhoneNumber: req.GetPhoneNumber(),
+		Email:       req.GetEmail(),
+		FullName:    req.GetFullName(),
+		Address:     addressFromProto(req.GetAddress()),
+		Password:    req.GetPassword(),
+	}
+	if req.GetBirthDate() != 0 {
+		inp.BirthDate = time.Unix(req.GetBirthDate(), 0).UTC()
+	}
+	out, err := s.service.SignUp(ctx, inp)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.SignUpResponse{
+		AccessKey:  out.AccessKey,
+		RefreshKey: out.RefreshKey,
+	}, nil
+}
+
+func (s *server) RequestSignIn(ctx context.Context, req *userspb.RequestSignInRequest) (*userspb.Empty, error) {
+	if err := s.service.RequestSignIn(ctx, domain.RequestSignInInput{
+		PhoneNumber: req.GetPhoneNumber(),
+		Email:       req.GetEmail(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) SignIn(ctx context.Context, req *userspb.SignInRequest) (*userspb.SignInResponse, error) {
+	out, err := s.service.SignIn(ctx, domain.SignInInput{
+		Code:        req.GetCode(),
+		PhoneNumber: req.GetPhoneNumber(),
+		Email:       req.GetEmail(),
+	})
+	var required *domain.SecondFactorRequiredError
+	if errors.As(err, &required) {
+		return &userspb.SignInResponse{Challenge: challengeToProto(required)}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.SignInResponse{
+		AccessKey:  out.AccessKey,
+		RefreshKey: out.RefreshKey,
+	}, nil
+}
+
+func (s *server) SignInEmailPassword(ctx context.Context, req *userspb.SignInEmailPasswordRequest) (*userspb.SignInEmailPasswordResponse, error) {
+	out, err := s.service.SignInEmailPassword(ctx, req.GetEmail(), req.GetPassword())
+	var required *domain.SecondFactorRequiredError
+	if errors.As(err, &required) {
+		return &userspb.SignInEmailPasswordResponse{Challenge: challengeToProto(required)}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.SignInEmailPasswordResponse{
+		AccessKey:  out.AccessKey,
+		RefreshKey: out.RefreshKey,
+	}, nil
+}
+
+func (s *server) Refresh(ctx context.Context, req *userspb.RefreshRequest) (*userspb.RefreshResponse, error) {
+	out, err := s.service.Refresh(ctx, req.GetRefreshKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.RefreshResponse{
+		AccessKey:  out.AccessKey,
+		RefreshKey: out.RefreshKey,
+	}, nil
+}
+
+func (s *server) VerifySecondFactor(ctx context.Context, req *userspb.VerifySecondFactorRequest) (*userspb.VerifySecondFactorResponse, error) {
+	out, err := s.service.VerifySecondFactor(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.VerifySecondFactorResponse{
+		AccessKey:     out.AccessKey,
+		RefreshKey:    out.RefreshKey,
+		RecoveryCodes: out.RecoveryCodes,
+	}, nil
+}
+
+func (s *server) EnrollTOTP(ctx context.Context, req *userspb.EnrollTOTPRequest) (*userspb.TOTPEnrollment, error) {
+	enrollment, err := s.service.EnrollTOTP(ctx, domain.ID(req.GetUserID()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.TOTPEnrollment{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
+}
+
+func (s *server) ConfirmTOTP(ctx context.Context, req *userspb.ConfirmTOTPRequest) (*userspb.RecoveryCodesResponse, error) {
+	codes, err := s.service.ConfirmTOTP(ctx, domain.ID(req.GetUserID()), req.GetCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *server) DisableTOTP(ctx context.Context, req *userspb.DisableTOTPRequest) (*userspb.Empty, error) {
+	if err := s.service.DisableTOTP(ctx, domain.ID(req.GetUserID()), req.GetCode(), req.GetPassword()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) RegenerateRecoveryCodes(ctx context.Context, req *userspb.RegenerateRecoveryCodesRequest) (*userspb.RecoveryCodesResponse, error) {
+	codes, err := s.service.RegenerateRecoveryCodes(ctx, domain.ID(req.GetUserID()), req.GetCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *server) BeginPasskeyRegistration(ctx context.Context, req *userspb.BeginPasskeyRegistrationRequest) (*userspb.PasskeyCeremony, error) {
+	ceremony, err := s.service.BeginPasskeyRegistration(ctx, domain.ID(req.GetUserID()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.PasskeyCeremony{CeremonyID: ceremony.ID, Options: ceremony.Options}, nil
+}
+
+func (s *server) FinishPasskeyRegistration(ctx context.Context, req *userspb.FinishPasskeyRequest) (*userspb.Passkey, error) {
+	p, err := s.service.FinishPasskeyRegistration(ctx, domain.FinishPasskeyInput{
+		CeremonyID: req.GetCeremonyID(),
+		Response:   req.GetResponse(),
+		UserID:     domain.ID(req.GetUserID()),
+		Name:       req.GetName(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return passkeyToProto(p), nil
+}
+
+func (s *server) BeginPasskeySignIn(ctx context.Context, req *userspb.BeginPasskeySignInRequest) (*userspb.PasskeyCeremony, error) {
+	ceremony, err := s.service.BeginPasskeySignIn(ctx, req.GetEmail())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.PasskeyCeremony{CeremonyID: ceremony.ID, Options: ceremony.Options}, nil
+}
+
+func (s *server) FinishPasskeySignIn(ctx context.Context, req *userspb.FinishPasskeyRequest) (*userspb.FinishPasskeySignInResponse, error) {
+	out, err := s.service.FinishPasskeySignIn(ctx, domain.FinishPasskeyInput{
+		CeremonyID: req.GetCeremonyID(),
+		Response:   req.GetResponse(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.FinishPasskeySignInResponse{
+		AccessKey:  out.AccessKey,
+		RefreshKey: out.RefreshKey,
+	}, nil
+}
+
+func (s *server) GetPasskeys(ctx context.Context, req *userspb.GetPasskeysRequest) (*userspb.GetPasskeysResponse, error) {
+	passkeys, err := s.service.Passkeys(ctx, domain.ID(req.GetUserID()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetPasskeysResponse{Passkeys: make([]*userspb.Passkey, len(passkeys))}
+	for i, v := range passkeys {
+		res.Passkeys[i] = passkeyToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) DeletePasskey(ctx context.Context, req *userspb.DeletePasskeyRequest) (*userspb.Empty, error) {
+	if err := s.service.DeletePasskey(ctx, domain.ID(req.GetUserID()), req.GetPasskeyID()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) BeginOIDCSignIn(ctx context.Context, req *userspb.BeginOIDCSignInRequest) (*userspb.OIDCAuthorization, error) {
+	auth, err := s.service.BeginOIDCSignIn(ctx, req.GetProvider())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.OIDCAuthorization{Url: auth.URL, State: auth.State}, nil
+}
+
+func (s *server) FinishOIDCSignIn(ctx context.Context, req *userspb.FinishOIDCRequest) (*userspb.FinishOIDCSignInResponse, error) {
+	out, err := s.service.FinishOIDCSignIn(ctx, domain.FinishOIDCInput{
+		State: req.GetState(),
+		Code:  req.GetCode(),
+	})
+	var required *domain.SecondFactorRequiredError
+	if errors.As(err, &required) {
+		return &userspb.FinishOIDCSignInResponse{Challenge: challengeToProto(required)}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.FinishOIDCSignInResponse{
+		AccessKey:  out.AccessKey,
+		RefreshKey: out.RefreshKey,
+	}, nil
+}
+
+func (s *server) BeginOIDCLink(ctx context.Context, req *userspb.BeginOIDCLinkRequest) (*userspb.OIDCAuthorization, error) {
+	auth, err := s.service.BeginOIDCLink(ctx, domain.ID(req.GetUserID()), req.GetProvider())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.OIDCAuthorization{Url: auth.URL, State: auth.State}, nil
+}
+
+func (s *server) FinishOIDCLink(ctx context.Context, req *userspb.FinishOIDCRequest) (*userspb.Identity, error) {
+	identity, err := s.service.FinishOIDCLink(ctx, domain.ID(req.GetUserID()), domain.FinishOIDCInput{
+		State: req.GetState(),
+		Code:  req.GetCode(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return identityToProto(identity), nil
+}
+
+func (s *server) GetIdentities(ctx context.Context, req *userspb.GetIdentitiesRequest) (*userspb.GetIdentitiesResponse, error) {
+	identities, err := s.service.Identities(ctx, domain.ID(req.GetUserID()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetIdentitiesResponse{Identities: make([]*userspb.Identity, len(identities))}
+	for i, v := range identities {
+		res.Identities[i] = identityToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) UnlinkIdentity(ctx context.Context, req *userspb.UnlinkIdentityRequest) (*userspb.Empty, error) {
+	if err := s.service.UnlinkIdentity(ctx, domain.ID(req.GetUserID()), req.GetProvider()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) Authorize(ctx context.Context, req *userspb.AuthorizeRequest) (*userspb.AuthorizeResponse, error) {
+	redirectURL, err := s.service.Authorize(ctx, domain.ID(req.GetUserID()), domain.AuthorizeInput{
+		ResponseType:        req.GetResponseType(),
+		ClientID:            req.GetClientId(),
+		RedirectURI:         req.GetRedirectUri(),
+		Scope:               req.GetScope(),
+		State:               req.GetState(),
+		Nonce:               req.GetNonce(),
+		CodeChallenge:       req.GetCodeChallenge(),
+		CodeChallengeMethod: req.GetCodeChallengeMethod(),
+	})
+	var required *domain.ConsentRequiredError
+	if errors.As(err, &required) {
+		return &userspb.AuthorizeResponse{Consent: &userspb.ConsentRequest{
+			ClientId:   required.Client.ID,
+			ClientName: required.Client.Name,
+			Scopes:     required.Scopes,
+		}}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.AuthorizeResponse{RedirectURL: redirectURL}, nil
+}
+
+func (s *server) GrantConsent(ctx context.Context, req *userspb.GrantConsentRequest) (*userspb.Empty, error) {
+	if err := s.service.GrantConsent(ctx, domain.ID(req.GetUserID()), req.GetClientId(), req.GetScopes()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) GetConsents(ctx context.Context, req *userspb.GetConsentsRequest) (*userspb.GetConsentsResponse, error) {
+	consents, err := s.service.Consents(ctx, domain.ID(req.GetUserID()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetConsentsResponse{Consents: make([]*userspb.Consent, len(consents))}
+	for i, v := range consents {
+		res.Consents[i] = consentToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) RevokeConsent(ctx context.Context, req *userspb.RevokeConsentRequest) (*userspb.Empty, error) {
+	if err := s.service.RevokeConsent(ctx, domain.ID(req.GetUserID()), req.GetClientId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) RegisterOAuthClient(ctx context.Context, req *userspb.RegisterOAuthClientRequest) (*userspb.ClientRegistration, error) {
+	inp := domain.RegisterClientInput{
+		Name:         req.GetName(),
+		RedirectURIs: req.GetRedirectUris(),
+		GrantTypes:   req.GetGrantTypes(),
+		Scopes:       req.GetScopes(),
+		Public:       req.GetPublic(),
+		Trusted:      req.GetTrusted(),
+	}
+	if req.GetJwks() != "" {
+		inp.JWKS = json.RawMessage(req.GetJwks())
+	}
+	reg, err := s.service.RegisterOAuthClient(ctx, inp)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.ClientRegistration{Client: oauthClientToProto(reg.Client), Secret: reg.Secret}, nil
+}
+
+func (s *server) GetOAuthClient(ctx context.Context, req *userspb.GetOAuthClientRequest) (*userspb.OAuthClient, error) {
+	client, err := s.service.OAuthClient(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return oauthClientToProto(client), nil
+}
+
+func (s *server) GetOAuthClients(ctx context.Context, _ *userspb.Empty) (*userspb.GetOAuthClientsResponse, error) {
+	clients, err := s.service.OAuthClients(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetOAuthClientsResponse{Clients: make([]*userspb.OAuthClient, len(clients))}
+	for i, v := range clients {
+		res.Clients[i] = oauthClientToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) DeleteOAuthClient(ctx context.Context, req *userspb.DeleteOAuthClientRequest) (*userspb.Empty, error) {
+	if err := s.service.DeleteOAuthClient(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) CreateAPIKey(ctx context.Context, req *userspb.CreateAPIKeyRequest) (*userspb.APIKeyCreation, error) {
+	inp := domain.CreateAPIKeyInput{
+		Name:   req.GetName(),
+		Owner:  apiKeyOwnerFromProto(req.GetOwner()),
+		Scopes: req.GetScopes(),
+	}
+	if req.GetExpiresAt() != 0 {
+		expiresAt := time.Unix(req.GetExpiresAt(), 0).UTC()
+		inp.ExpiresAt = &expiresAt
+	}
+	created, err := s.service.CreateAPIKey(ctx, inp)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.APIKeyCreation{ApiKey: apiKeyToProto(created.APIKey), Key: created.Key}, nil
+}
+
+func (s *server) GetAPIKeys(ctx context.Context, req *userspb.GetAPIKeysRequest) (*userspb.GetAPIKeysResponse, error) {
+	keys, err := s.service.APIKeys(ctx, apiKeyOwnerFromProto(req.GetOwner()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetAPIKeysResponse{Keys: make([]*userspb.APIKey, len(keys))}
+	for i, v := range keys {
+		res.Keys[i] = apiKeyToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) RevokeAPIKey(ctx context.Context, req *userspb.RevokeAPIKeyRequest) (*userspb.Empty, error) {
+	if err := s.service.RevokeAPIKey(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) RequestPasswordReset(ctx context.Context, req *userspb.RequestPasswordResetRequest) (*userspb.Empty, error) {
+	if err := s.service.RequestPasswordReset(ctx, req.GetEmail()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) ResetPassword(ctx context.Context, req *userspb.ResetPasswordRequest) (*userspb.Empty, error) {
+	if err := s.service.ResetPassword(ctx, domain.ResetPasswordInput{
+		Token:    req.GetToken(),
+		Password: req.GetPassword(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) SignOut(ctx context.Context, req *userspb.SignOutRequest) (*userspb.Empty, error) {
+	if err := s.service.SignOut(ctx, req.GetRefreshKey()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) GetSessions(ctx context.Context, req *userspb.GetSessionsRequest) (*userspb.GetSessionsResponse, error) {
+	sessions, err := s.service.Sessions(ctx, domain.ID(req.GetUserID()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetSessionsResponse{Sessions: make([]*userspb.Session, len(sessions))}
+	for i, v := range sessions {
+		res.Sessions[i] = sessionToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) RevokeSession(ctx context.Context, req *userspb.RevokeSessionRequest) (*userspb.Empty, error) {
+	if err := s.service.RevokeSession(ctx, domain.ID(req.GetUserID()), req.GetSessionID()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) RevokeOtherSessions(ctx context.Context, req *userspb.RevokeOtherSessionsRequest) (*userspb.Empty, error) {
+	if err := s.service.RevokeOtherSessions(ctx, req.GetRefreshKey()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) AddRole(ctx context.Context, req *userspb.AddRoleRequest) (*userspb.Empty, error) {
+	role, err := customRoleFromProto(req.GetRole(), req.GetCustomRole())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	inp := domain.AddRoleInput{
+		UserID: domain.ID(req.GetUserID()),
+		Role:   role,
+		Scope:  req.GetScope(),
+		Reason: req.GetReason(),
+	}
+	if req.GetExpiresAt() != 0 {
+		expiresAt := time.Unix(req.GetExpiresAt(), 0).UTC()
+		inp.ExpiresAt = &expiresAt
+	}
+	if err := s.service.AddRole(ctx, inp); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) RemoveRole(ctx context.Context, req *userspb.RemoveRoleRequest) (*userspb.Empty, error) {
+	role, err := customRoleFromProto(req.GetRole(), req.GetCustomRole())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := s.service.RemoveRole(ctx, domain.ID(req.GetUserID()), role, req.GetScope()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) RequestRole(ctx context.Context, req *userspb.RequestRoleRequest) (*userspb.RoleRequest, error) {
+	role, err := customRoleFromProto(req.GetRole(), req.GetCustomRole())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	request, err := s.service.RequestRole(ctx, domain.RequestRoleInput{
+		UserID:        domain.ID(req.GetUserID()),
+		Role:          role,
+		Scope:         req.GetScope(),
+		Justification: req.GetJustification(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return roleRequestToProto(request), nil
+}
+
+func (s *server) GetRoleRequests(ctx context.Context, req *userspb.GetRoleRequestsRequest) (*userspb.GetRoleRequestsResponse, error) {
+	requests, err := s.service.RoleRequests(ctx, domain.ReadRoleRequestsInput{
+		UserID: domain.ID(req.GetUserID()),
+		Status: domain.RoleRequestStatus(req.GetStatus()),
+		Limit:  req.GetLimit(),
+		Offset: req.GetOffset(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetRoleRequestsResponse{Requests: make([]*userspb.RoleRequest, len(requests))}
+	for i, v := range requests {
+		res.Requests[i] = roleRequestToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) ReviewRoleRequest(ctx context.Context, req *userspb.ReviewRoleRequestRequest) (*userspb.RoleRequest, error) {
+	request, err := s.service.ReviewRoleRequest(ctx, domain.ReviewRoleRequestInput{
+		ID:      req.GetId(),
+		Approve: req.GetApprove(),
+		Comment: req.GetComment(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return roleRequestToProto(request), nil
+}
+
+func (s *server) GetRoles(ctx context.Context, req *userspb.Empty) (*userspb.GetRolesResponse, error) {
+	roles, err := s.service.Roles(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetRolesResponse{Roles: make([]*userspb.RoleDefinition, len(roles))}
+	for i, v := range roles {
+		res.Roles[i] = roleDefinitionToProto(v)
+	}
+	return res, nil
+}
+
+func (s *server) CreateRole(ctx context.Context, req *userspb.CreateRoleRequest) (*userspb.RoleDefinition, error) {
+	role, err := s.service.CreateRole(ctx, domain.CreateRoleInput{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Permissions: req.GetPermissions(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return roleDefinitionToProto(role), nil
+}
+
+func (s *server) SetRolePermissions(ctx context.Context, req *userspb.SetRolePermissionsRequest) (*userspb.Empty, error) {
+	if err := s.service.SetRolePermissions(ctx, domain.Role(req.GetRole()), req.GetPermissions()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) DeleteRole(ctx context.Context, req *userspb.DeleteRoleRequest) (*userspb.Empty, error) {
+	if err := s.service.DeleteRole(ctx, domain.Role(req.GetRole())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) UnlockAccount(ctx context.Context, req *userspb.UnlockAccountRequest) (*userspb.Empty, error) {
+	if err := s.service.UnlockAccount(ctx, domain.ID(req.GetUserID())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) GetUser(ctx context.Context, req *userspb.GetUserRequest) (*userspb.GetUserResponse, error) {
+	u, err := s.service.Read(ctx, domain.ID(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.GetUserResponse{User: userToProto(u)}, nil
+}
+
+func (s *server) GetUserByEmail(ctx context.Context, req *userspb.GetUserByEmailRequest) (*userspb.GetUserByEmailResponse, error) {
+	u, err := s.service.ReadByEmail(ctx, req.GetEmail())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.GetUserByEmailResponse{User: userToProto(u)}, nil
+}
+
+func (s *server) GetUserByPhoneNumber(ctx context.Context, req *userspb.GetUserByPhoneNumberRequest) (*userspb.GetUserByPhoneNumberResponse, error) {
+	u, err := s.service.ReadByPhoneNumber(ctx, req.GetPhoneNumber())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.GetUserByPhoneNumberResponse{User: userToProto(u)}, nil
+}
+
+func (s *server) GetUsers(ctx context.Context, req *userspb.GetUsersRequest) (*userspb.GetUsersResponse, error) {
+	inp := domain.ReadAllInput{
+		Limit:       req.GetLimit(),
+		Offset:      req.GetOffset(),
+		SortBy:      sortingFromProto(req.GetSortBy()),
+		CountryCode: req.GetCountryCode(),
+	}
+	for _, v := range req.GetRoles() {
+		role, err := roleFromProto(v)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		inp.Roles = append(inp.Roles, role)
+	}
+	users, err := s.service.ReadAll(ctx, inp)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &userspb.GetUsersResponse{Users: make([]*userspb.User, len(users))}
+	for i, u := range users {
+		res.Users[i] = userToProto(u)
+	}
+	return res, nil
+}
+
+func (s *server) UpdateUser(ctx context.Context, req *userspb.UpdateUserRequest) (*userspb.Empty, error) {
+	if err := s.service.Update(ctx, domain.UpdateInput{
+		ID:          domain.ID(req.GetId()),
+		FullName:    req.GetFullName(),
+		PhoneNumber: req.GetPhoneNumber(),
+		Email:       req.GetEmail(),
+		Password:    req.GetPassword(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
+
+func (s *server) DeleteUser(ctx context.Context, req *userspb.DeleteUserRequest) (*userspb.Empty, error) {
+	if err := s.service.Delete(ctx, domain.ID(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &userspb.Empty{}, nil
+}
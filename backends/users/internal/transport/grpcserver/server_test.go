@@ -0,0 +1,401 @@
+package grpcserver_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/protos/userspb"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain/mocks"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/transport/grpcserver"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, s domain.Service) userspb.UserServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpcserver.NewServer(s)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return userspb.NewUserServiceClient(conn)
+}
+
+func TestSignIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	testCases := []struct {
+		name string
+		req  *userspb.SignInRequest
+		code codes.Code
+
+		mockup func()
+	}{
+		{
+			name: "success",
+			req:  &userspb.SignInRequest{PhoneNumber: "+996702569123", Code: "123456"},
+			code: codes.OK,
+			mockup: func() {
+				mockService.EXPECT().SignIn(gomock.Any(), domain.SignInInput{
+					PhoneNumber: "+996702569123",
+					Code:        "123456",
+				}).Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "refresh"}, nil)
+			},
+		},
+		{
+			name: "fail with invalid code",
+			req:  &userspb.SignInRequest{PhoneNumber: "+996702569123", Code: "000000"},
+			code: codes.Unauthenticated,
+			mockup: func() {
+				mockService.EXPECT().SignIn(gomock.Any(), gomock.Any()).
+					Return(domain.SignInOutput{}, fmt.Errorf("signIn(): %w", domain.ErrInvalidCode))
+			},
+		},
+		{
+			name: "fail with unknown error",
+			req:  &userspb.SignInRequest{Email: "pizzas@gmail.com", Code: "000000"},
+			code: codes.Internal,
+			mockup: func() {
+				mockService.EXPECT().SignIn(gomock.Any(), gomock.Any()).
+					Return(domain.SignInOutput{}, fmt.Errorf("connection refused"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockup()
+			res, err := client.SignIn(context.Background(), tc.req)
+			if got := status.Code(err); got != tc.code {
+				t.Fatalf("got %v, want %v", got, tc.code)
+			}
+			if tc.code == codes.OK && (res.GetAccessKey() != "access" || res.GetRefreshKey() != "refresh") {
+				t.Errorf("unexpected response %v", res)
+			}
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	testCases := []struct {
+		name string
+		id   uint64
+		code codes.Code
+		role userspb.User_Role
+
+		mockup func()
+	}{
+		{
+			name: "success with the most privileged role",
+			id:   1,
+			code: codes.OK,
+			role: userspb.User_ADMIN,
+			mockup: func() {
+				mockService.EXPECT().Read(gomock.Any(), domain.ID(1)).Return(domain.User{
+					ID:       1,
+					FullName: "Pizza Lover",
+					Roles:    []domain.Role{domain.RoleUser, domain.RoleAdmin, domain.RoleDeliveryMan},
+				}, nil)
+			},
+		},
+		{
+			name: "fail with not found",
+			id:   2,
+			code: codes.NotFound,
+			mockup: func() {
+				mockService.EXPECT().Read(gomock.Any(), domain.ID(2)).
+					Return(domain.User{}, fmt.Errorf("read(): %w", domain.ErrNoUsers))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockup()
+			res, err := client.GetUser(context.Background(), &userspb.GetUserRequest{Id: tc.id})
+			if got := status.Code(err); got != tc.code {
+				t.Fatalf("got %v, want %v", got, tc.code)
+			}
+			if tc.code == codes.OK && res.GetUser().GetRoles() != tc.role {
+				t.Errorf("got role %v, want %v", res.GetUser().GetRoles(), tc.role)
+			}
+		})
+	}
+}
+
+func TestAddRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	expiresAt := time.Unix(1700000000, 0).UTC()
+	mockService.EXPECT().AddRole(gomock.Any(), domain.AddRoleInput{
+		UserID:    7,
+		Role:      domain.RoleDeliveryMan,
+		Scope:     "branch:12",
+		ExpiresAt: &expiresAt,
+		Reason:    "covers a shift",
+	}).Return(nil)
+	if _, err := client.AddRole(context.Background(), &userspb.AddRoleRequest{
+		UserID:    7,
+		Role:      userspb.User_DELIVERYMAN,
+		Scope:     "branch:12",
+		ExpiresAt: expiresAt.Unix(),
+		Reason:    "covers a shift",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.AddRole(context.Background(), &userspb.AddRoleRequest{UserID: 7, Role: 42})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("got %v, want %v", got, codes.InvalidArgument)
+	}
+}
+
+func TestWritesSucceed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	mockService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+	if _, err := client.UpdateUser(context.Background(), &userspb.UpdateUserRequest{Id: 7, FullName: "Pizza Lover"}); err != nil {
+		t.Errorf("update: %v", err)
+	}
+	mockService.EXPECT().Delete(gomock.Any(), domain.ID(7)).Return(nil)
+	if _, err := client.DeleteUser(context.Background(), &userspb.DeleteUserRequest{Id: 7}); err != nil {
+		t.Errorf("delete: %v", err)
+	}
+	mockService.EXPECT().RemoveRole(gomock.Any(), domain.ID(7), domain.RoleModerator, "").Return(nil)
+	if _, err := client.RemoveRole(context.Background(), &userspb.RemoveRoleRequest{UserID: 7, Role: userspb.User_MODERATOR}); err != nil {
+		t.Errorf("remove role: %v", err)
+	}
+}
+
+func TestSessionsOfOthers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, domain.NewAuthorizer(mockService))
+
+	mockService.EXPECT().Authenticate(gomock.Any(), "user").
+		Return(domain.AccessClaims{UserID: 3, Roles: []domain.Role{domain.RoleUser}}, nil).Times(2)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer user")
+
+	_, err := client.GetSessions(ctx, &userspb.GetSessionsRequest{UserID: 7})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("get sessions: got %v, want %v", got, codes.PermissionDenied)
+	}
+	_, err = client.RevokeSession(ctx, &userspb.RevokeSessionRequest{UserID: 7, SessionID: "family"})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("revoke session: got %v, want %v", got, codes.PermissionDenied)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	mockService.EXPECT().RequestSignUp(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ domain.RequestSignUpInput) error {
+			if ip := domain.ClientInfoFromContext(ctx).IP; ip == "" {
+				t.Error("client ip is not in context")
+			}
+			return fmt.Errorf("requestSignUp(): %w", &domain.RateLimitError{
+				Scope:      domain.RateLimitScopeCountry,
+				RetryAfter: time.Minute,
+			})
+		})
+
+	_, err := client.RequestSignUp(context.Background(), &userspb.RequestSignUpRequest{PhoneNumber: "+996702569123"})
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("got %v, want %v", st.Code(), codes.ResourceExhausted)
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			if got := info.GetRetryDelay().AsDuration(); got != time.Minute {
+				t.Errorf("got retry delay %v, want %v", got, time.Minute)
+			}
+			return
+		}
+	}
+	t.Error("status has no retry info")
+}
+
+func TestSignInWithSecondFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	mockService.EXPECT().SignInEmailPassword(gomock.Any(), "pizzas@gmail.com", "password").
+		Return(domain.SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", &domain.SecondFactorRequiredError{
+			ChallengeToken: "challenge",
+			ExpiresAt:      time.Unix(1655750000, 0),
+		}))
+	res, err := client.SignInEmailPassword(context.Background(), &userspb.SignInEmailPasswordRequest{
+		Email:    "pizzas@gmail.com",
+		Password: "password",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetAccessKey() != "" || res.GetChallenge().GetChallengeToken() != "challenge" ||
+		res.GetChallenge().GetExpiresAt() != 1655750000 {
+		t.Fatalf("unexpected response %v", res)
+	}
+
+	mockService.EXPECT().VerifySecondFactor(gomock.Any(), "challenge", "123456").
+		Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "refresh"}, nil)
+	verified, err := client.VerifySecondFactor(context.Background(), &userspb.VerifySecondFactorRequest{
+		ChallengeToken: "challenge",
+		Code:           "123456",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.GetAccessKey() != "access" {
+		t.Fatalf("unexpected response %v", verified)
+	}
+}
+
+func TestClientScopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	client := newClient(t, mockService)
+
+	orders := domain.AccessClaims{ClientID: "orders", Scopes: []string{domain.ScopeUsersRead}}
+	testCases := []struct {
+		name  string
+		token string
+		call  func(ctx context.Context) error
+		code  codes.Code
+
+		mockup func()
+	}{
+		{
+			name:  "client reads user with its scope",
+			token: "Bearer orders",
+			call: func(ctx context.Context) error {
+				_, err := client.GetUserByEmail(ctx, &userspb.GetUserByEmailRequest{Email: "pizzas@gmail.com"})
+				return err
+			},
+			code: codes.OK,
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "orders").Return(orders, nil)
+				mockService.EXPECT().ReadByEmail(gomock.Any(), "pizzas@gmail.com").
+					DoAndReturn(func(ctx context.Context, _ string) (domain.User, error) {
+						if caller, ok := domain.CallerFromContext(ctx); !ok || caller.ClientID != "orders" {
+							t.Errorf("caller is not in context")
+						}
+						return domain.User{ID: 1}, nil
+					})
+			},
+		},
+		{
+			name:  "api key updates user with its scope",
+			token: "Bearer mpk_key.secret",
+			call: func(ctx context.Context) error {
+				_, err := client.UpdateUser(ctx, &userspb.UpdateUserRequest{Id: 1, FullName: "Pizza Lover"})
+				return err
+			},
+			code: codes.OK,
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "mpk_key.secret").
+					Return(domain.AccessClaims{APIKeyID: "key", Scopes: []string{domain.ScopeUsersWrite}}, nil)
+				mockService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "fail for client without the scope",
+			token: "Bearer products",
+			call: func(ctx context.Context) error {
+				_, err := client.GetUser(ctx, &userspb.GetUserRequest{Id: 1})
+				return err
+			},
+			code: codes.PermissionDenied,
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "products").
+					Return(domain.AccessClaims{ClientID: "products", Scopes: []string{"menu:read"}}, nil)
+			},
+		},
+		{
+			name:  "fail for client calling a method not meant for services",
+			token: "Bearer orders",
+			call: func(ctx context.Context) error {
+				_, err := client.AddRole(ctx, &userspb.AddRoleRequest{UserID: 1, Role: userspb.User_ADMIN})
+				return err
+			},
+			code: codes.PermissionDenied,
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "orders").Return(orders, nil)
+			},
+		},
+		{
+			name:  "fail with invalid token",
+			token: "Bearer expired",
+			call: func(ctx context.Context) error {
+				_, err := client.GetUser(ctx, &userspb.GetUserRequest{Id: 1})
+				return err
+			},
+			code: codes.Unauthenticated,
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "expired").
+					Return(domain.AccessClaims{}, fmt.Errorf("authenticate(): %w", domain.ErrInvalidToken))
+			},
+		},
+		{
+			name:  "fail with basic credentials",
+			token: "Basic b3JkZXJzOnNlY3JldA==",
+			call: func(ctx context.Context) error {
+				_, err := client.GetUser(ctx, &userspb.GetUserRequest{Id: 1})
+				return err
+			},
+			code:   codes.Unauthenticated,
+			mockup: func() {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockup()
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", tc.token)
+			if got := status.Code(tc.call(ctx)); got != tc.code {
+				t.Errorf("got %v, want %v", got, tc.code)
+			}
+		})
+	}
+}
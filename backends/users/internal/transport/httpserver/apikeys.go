@@ -0,0 +1,63 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// apiKeys routes /v1/api-keys and /v1/api-keys/{id}, keys are listed
+// by owner: ?userID=7 or ?organization=glovo
+func (s *server) apiKeys(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/api-keys"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		var owner domain.APIKeyOwner
+		if v := r.URL.Query().Get("userID"); v != "" {
+			userID, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				respondError(w, errInvalidQuery)
+				return
+			}
+			owner.UserID = domain.ID(userID)
+		}
+		owner.Organization = r.URL.Query().Get("organization")
+		if (owner.UserID == 0) == (owner.Organization == "") {
+			respondError(w, errInvalidQuery)
+			return
+		}
+		keys, err := s.service.APIKeys(r.Context(), owner)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		if keys == nil {
+			keys = []domain.APIKey{}
+		}
+		respond(w, http.StatusOK, keys)
+	case id == "" && r.Method == http.MethodPost:
+		var inp domain.CreateAPIKeyInput
+		if err := decode(r, &inp); err != nil {
+			respondError(w, err)
+			return
+		}
+		created, err := s.service.CreateAPIKey(r.Context(), inp)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusCreated, created)
+	case id != "" && !strings.Contains(id, "/") && r.Method == http.MethodDelete:
+		if err := s.service.RevokeAPIKey(r.Context(), id); err != nil {
+			respondError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case strings.Contains(id, "/"):
+		respondError(w, errNotFound)
+	default:
+		respondError(w, errMethodNotAllowed)
+	}
+}
@@ -0,0 +1,49 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// routeScope returns the scope clients and api keys need for the route,
+// routes that are not here are closed for them.
+func routeScope(r *http.Request) (string, bool) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodGet && (path == "/v1/users/by-email" || path == "/v1/users/by-phone"):
+		return domain.ScopeUsersRead, true
+	case !strings.HasPrefix(path, "/v1/users/") || strings.Contains(strings.TrimPrefix(path, "/v1/users/"), "/"):
+		return "", false
+	case r.Method == http.MethodGet:
+		return domain.ScopeUsersRead, true
+	case r.Method == http.MethodPatch:
+		return domain.ScopeUsersWrite, true
+	}
+	return "", false
+}
+
+// authenticate puts claims of the bearer token or api key into context.
+// Token and userinfo endpoints check credentials on their own.
+func (s *server) authenticate(r *http.Request) (*http.Request, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" || r.URL.Path == TokenPath || r.URL.Path == UserInfoPath {
+		return r, nil
+	}
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return nil, fmt.Errorf("authenticate(): %w", domain.ErrInvalidToken)
+	}
+	caller, err := s.service.Authenticate(r.Context(), strings.TrimPrefix(header, bearerPrefix))
+	if err != nil {
+		return nil, err
+	}
+	if caller.Scoped() {
+		scope, ok := routeScope(r)
+		if !ok || !caller.HasScope(scope) {
+			return nil, fmt.Errorf("authenticate(): %s %s needs scope %q: %w", r.Method, r.URL.Path, scope, domain.ErrNotAllowed)
+		}
+	}
+	return r.WithContext(domain.WithCaller(r.Context(), caller)), nil
+}
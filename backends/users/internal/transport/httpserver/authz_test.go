@@ -0,0 +1,291 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain/mocks"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/transport/httpserver"
+)
+
+// TestServerChecksCallers goes through the real authorizer,
+// routes about a user must not work with a token of somebody else.
+func TestServerChecksCallers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	handler := httpserver.NewHandler(domain.NewAuthorizer(mockService))
+
+	tokens := map[string]domain.AccessClaims{
+		"user":  {UserID: 3, Roles: []domain.Role{domain.RoleUser}},
+		"admin": {UserID: 2, Roles: []domain.Role{domain.RoleAdmin}},
+	}
+	mockService.EXPECT().Authenticate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, token string) (domain.AccessClaims, error) {
+			return tokens[token], nil
+		}).AnyTimes()
+	mockService.EXPECT().Read(gomock.Any(), gomock.Any()).
+		Return(domain.User{Roles: []domain.Role{domain.RoleUser}}, nil).AnyTimes()
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		// token is empty for anonymous requests
+		token  string
+		status int
+
+		mockup func()
+	}{
+		{
+			name:   "user lists own sessions",
+			method: http.MethodGet,
+			path:   "/v1/users/3/sessions",
+			token:  "user",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().Sessions(gomock.Any(), domain.ID(3)).Return(nil, nil)
+			},
+		},
+		{
+			name:   "admin lists sessions of user",
+			method: http.MethodGet,
+			path:   "/v1/users/3/sessions",
+			token:  "admin",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().Sessions(gomock.Any(), domain.ID(3)).Return(nil, nil)
+			},
+		},
+		{
+			name:   "fail to list sessions of somebody else",
+			method: http.MethodGet,
+			path:   "/v1/users/7/sessions",
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to revoke session of somebody else",
+			method: http.MethodDelete,
+			path:   "/v1/users/7/sessions/family",
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to list sessions without token",
+			method: http.MethodGet,
+			path:   "/v1/users/3/sessions",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "user enrolls own totp",
+			method: http.MethodPut,
+			path:   "/v1/users/3/totp",
+			token:  "user",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().EnrollTOTP(gomock.Any(), domain.ID(3)).Return(domain.TOTPEnrollment{}, nil)
+			},
+		},
+		{
+			name:   "user disables own totp with password",
+			method: http.MethodDelete,
+			path:   "/v1/users/3/totp",
+			body:   `{"password":"password"}`,
+			token:  "user",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().DisableTOTP(gomock.Any(), domain.ID(3), "", "password").Return(nil)
+			},
+		},
+		{
+			name:   "fail to enroll totp of somebody else",
+			method: http.MethodPut,
+			path:   "/v1/users/7/totp",
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to confirm totp of somebody else",
+			method: http.MethodPost,
+			path:   "/v1/users/7/totp/confirm",
+			body:   `{"code":"123456"}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to disable totp of somebody else",
+			method: http.MethodDelete,
+			path:   "/v1/users/7/totp",
+			body:   `{"code":"123456"}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to disable totp of somebody else as admin",
+			method: http.MethodDelete,
+			path:   "/v1/users/3/totp",
+			body:   `{"code":"123456"}`,
+			token:  "admin",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to enroll totp without token",
+			method: http.MethodPut,
+			path:   "/v1/users/3/totp",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "user begins own passkey registration",
+			method: http.MethodPost,
+			path:   "/v1/users/3/passkeys/begin",
+			token:  "user",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().BeginPasskeyRegistration(gomock.Any(), domain.ID(3)).Return(domain.PasskeyCeremony{}, nil)
+			},
+		},
+		{
+			name:   "fail to begin passkey registration for somebody else",
+			method: http.MethodPost,
+			path:   "/v1/users/7/passkeys/begin",
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to begin passkey registration for user as admin",
+			method: http.MethodPost,
+			path:   "/v1/users/3/passkeys/begin",
+			token:  "admin",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to finish passkey registration for somebody else",
+			method: http.MethodPost,
+			path:   "/v1/users/7/passkeys/finish",
+			body:   `{"ceremonyID":"ceremony","name":"phone"}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to begin passkey registration without token",
+			method: http.MethodPost,
+			path:   "/v1/users/3/passkeys/begin",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "user begins linking own account",
+			method: http.MethodPost,
+			path:   "/v1/users/3/identities/begin",
+			body:   `{"provider":"google"}`,
+			token:  "user",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().BeginOIDCLink(gomock.Any(), domain.ID(3), "google").Return(domain.OIDCAuthorization{}, nil)
+			},
+		},
+		{
+			name:   "fail to begin linking account of somebody else",
+			method: http.MethodPost,
+			path:   "/v1/users/7/identities/begin",
+			body:   `{"provider":"google"}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to finish linking account of somebody else",
+			method: http.MethodPost,
+			path:   "/v1/users/7/identities/finish",
+			body:   `{"state":"state","code":"code"}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to link account of user as admin",
+			method: http.MethodPost,
+			path:   "/v1/users/3/identities/finish",
+			body:   `{"state":"state","code":"code"}`,
+			token:  "admin",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to begin linking without token",
+			method: http.MethodPost,
+			path:   "/v1/users/3/identities/begin",
+			body:   `{"provider":"google"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to allow client for somebody else",
+			method: http.MethodPut,
+			path:   "/v1/users/7/consents/aggregator",
+			body:   `{"scopes":["openid"]}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to allow client for user as admin",
+			method: http.MethodPut,
+			path:   "/v1/users/3/consents/aggregator",
+			body:   `{"scopes":["openid"]}`,
+			token:  "admin",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to authorize client for somebody else",
+			method: http.MethodPost,
+			path:   "/v1/users/7/oauth/authorize",
+			body:   `{"responseType":"code","clientID":"aggregator"}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "admin registers oauth client",
+			method: http.MethodPost,
+			path:   "/v1/oauth/clients",
+			body:   `{"name":"Aggregator"}`,
+			token:  "admin",
+			status: http.StatusCreated,
+			mockup: func() {
+				mockService.EXPECT().RegisterOAuthClient(gomock.Any(), domain.RegisterClientInput{Name: "Aggregator"}).Return(domain.ClientRegistration{}, nil)
+			},
+		},
+		{
+			name:   "fail to register oauth client as user",
+			method: http.MethodPost,
+			path:   "/v1/oauth/clients",
+			body:   `{"name":"Aggregator","trusted":true}`,
+			token:  "user",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "fail to register oauth client without token",
+			method: http.MethodPost,
+			path:   "/v1/oauth/clients",
+			body:   `{"name":"Aggregator"}`,
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockup != nil {
+				tc.mockup()
+			}
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,568 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+type (
+	signInEmailPasswordRequest struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	refreshRequest struct {
+		RefreshKey string `json:"refreshKey"`
+	}
+
+	requestPasswordResetRequest struct {
+		Email string `json:"email"`
+	}
+
+	verifySecondFactorRequest struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+	}
+
+	// codeRequest carries a totp or recovery code
+	codeRequest struct {
+		Code string `json:"code"`
+	}
+
+	// disableTOTPRequest needs either a code or the password
+	disableTOTPRequest struct {
+		Code     string `json:"code"`
+		Password string `json:"password"`
+	}
+
+	recoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+
+	providerRequest struct {
+		Provider string `json:"provider"`
+	}
+
+	beginPasskeySignInRequest struct {
+		// optional, narrows passkeys the browser offers
+		Email string `json:"email"`
+	}
+)
+
+func (s *server) requestSignUp(w http.ResponseWriter, r *http.Request) {
+	var inp domain.RequestSignUpInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.RequestSignUp(r.Context(), inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) signUp(w http.ResponseWriter, r *http.Request) {
+	var inp domain.SignUpInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.SignUp(r.Context(), inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusCreated, out)
+}
+
+func (s *server) requestSignIn(w http.ResponseWriter, r *http.Request) {
+	var inp domain.RequestSignInInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.RequestSignIn(r.Context(), inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) signIn(w http.ResponseWriter, r *http.Request) {
+	var inp domain.SignInInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.SignIn(r.Context(), inp)
+	respondSignIn(w, http.StatusOK, out, err)
+}
+
+func (s *server) signInEmailPassword(w http.ResponseWriter, r *http.Request) {
+	var inp signInEmailPasswordRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.SignInEmailPassword(r.Context(), inp.Email, inp.Password)
+	respondSignIn(w, http.StatusOK, out, err)
+}
+
+func (s *server) refresh(w http.ResponseWriter, r *http.Request) {
+	var inp refreshRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.Refresh(r.Context(), inp.RefreshKey)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, out)
+}
+
+func (s *server) requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var inp requestPasswordResetRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.RequestPasswordReset(r.Context(), inp.Email); err != nil {
+		respondError(w, err)
+		return
+	}
+	// the same answer whether the email is registered or not
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *server) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var inp domain.ResetPasswordInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.ResetPassword(r.Context(), inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// signOut and revokeOtherSessions take the same body as refresh
+func (s *server) signOut(w http.ResponseWriter, r *http.Request) {
+	var inp refreshRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.SignOut(r.Context(), inp.RefreshKey); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) revokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	var inp refreshRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.RevokeOtherSessions(r.Context(), inp.RefreshKey); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) sessions(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	sessions, err := s.service.Sessions(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	if sessions == nil {
+		sessions = []domain.Session{}
+	}
+	respond(w, http.StatusOK, sessions)
+}
+
+func (s *server) revokeSession(w http.ResponseWriter, r *http.Request, id domain.ID, sessionID string) {
+	if err := s.service.RevokeSession(r.Context(), id, sessionID); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) unlockAccount(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	if err := s.service.UnlockAccount(r.Context(), id); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) verifySecondFactor(w http.ResponseWriter, r *http.Request) {
+	var inp verifySecondFactorRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.VerifySecondFactor(r.Context(), inp.ChallengeToken, inp.Code)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, out)
+}
+
+func (s *server) enrollTOTP(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	enrollment, err := s.service.EnrollTOTP(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, enrollment)
+}
+
+func (s *server) confirmTOTP(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp codeRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	codes, err := s.service.ConfirmTOTP(r.Context(), id, inp.Code)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, recoveryCodesResponse{codes})
+}
+
+func (s *server) disableTOTP(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp disableTOTPRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.DisableTOTP(r.Context(), id, inp.Code, inp.Password); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp codeRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	codes, err := s.service.RegenerateRecoveryCodes(r.Context(), id, inp.Code)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, recoveryCodesResponse{codes})
+}
+
+func (s *server) beginPasskeyRegistration(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	ceremony, err := s.service.BeginPasskeyRegistration(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, ceremony)
+}
+
+func (s *server) finishPasskeyRegistration(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp domain.FinishPasskeyInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	inp.UserID = id
+	p, err := s.service.FinishPasskeyRegistration(r.Context(), inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusCreated, p)
+}
+
+func (s *server) beginPasskeySignIn(w http.ResponseWriter, r *http.Request) {
+	var inp beginPasskeySignInRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	ceremony, err := s.service.BeginPasskeySignIn(r.Context(), inp.Email)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, ceremony)
+}
+
+func (s *server) finishPasskeySignIn(w http.ResponseWriter, r *http.Request) {
+	var inp domain.FinishPasskeyInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.FinishPasskeySignIn(r.Context(), inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, out)
+}
+
+func (s *server) passkeys(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	passkeys, err := s.service.Passkeys(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	if passkeys == nil {
+		passkeys = []domain.Passkey{}
+	}
+	respond(w, http.StatusOK, passkeys)
+}
+
+func (s *server) deletePasskey(w http.ResponseWriter, r *http.Request, id domain.ID, passkeyID string) {
+	if err := s.service.DeletePasskey(r.Context(), id, passkeyID); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) beginOIDCSignIn(w http.ResponseWriter, r *http.Request) {
+	var inp providerRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	auth, err := s.service.BeginOIDCSignIn(r.Context(), inp.Provider)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, auth)
+}
+
+func (s *server) finishOIDCSignIn(w http.ResponseWriter, r *http.Request) {
+	var inp domain.FinishOIDCInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	out, err := s.service.FinishOIDCSignIn(r.Context(), inp)
+	respondSignIn(w, http.StatusOK, out, err)
+}
+
+func (s *server) beginOIDCLink(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp providerRequest
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	auth, err := s.service.BeginOIDCLink(r.Context(), id, inp.Provider)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, auth)
+}
+
+func (s *server) finishOIDCLink(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp domain.FinishOIDCInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	identity, err := s.service.FinishOIDCLink(r.Context(), id, inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusCreated, identity)
+}
+
+func (s *server) identities(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	identities, err := s.service.Identities(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	if identities == nil {
+		identities = []domain.Identity{}
+	}
+	respond(w, http.StatusOK, identities)
+}
+
+func (s *server) unlinkIdentity(w http.ResponseWriter, r *http.Request, id domain.ID, provider string) {
+	if err := s.service.UnlinkIdentity(r.Context(), id, provider); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) read(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	u, err := s.service.Read(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, u)
+}
+
+func (s *server) readByEmail(w http.ResponseWriter, r *http.Request) {
+	u, err := s.service.ReadByEmail(r.Context(), r.URL.Query().Get("email"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, u)
+}
+
+func (s *server) readByPhoneNumber(w http.ResponseWriter, r *http.Request) {
+	u, err := s.service.ReadByPhoneNumber(r.Context(), r.URL.Query().Get("phoneNumber"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, u)
+}
+
+// readAll takes its config from query params:
+// ?limit=10&offset=0&sortBy=1&countryCode=KG&role=admin&role=moderator
+func (s *server) readAll(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	inp := domain.ReadAllInput{
+		Limit:       20,
+		CountryCode: q.Get("countryCode"),
+	}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if inp.Limit, err = strconv.ParseUint(v, 10, 64); err != nil {
+			respondError(w, errInvalidQuery)
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if inp.Offset, err = strconv.ParseUint(v, 10, 64); err != nil {
+			respondError(w, errInvalidQuery)
+			return
+		}
+	}
+	if v := q.Get("sortBy"); v != "" {
+		sortBy, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			respondError(w, errInvalidQuery)
+			return
+		}
+		switch inp.SortBy = domain.Sorting(sortBy); inp.SortBy {
+		case domain.ReadAllSortByID, domain.ReadAllSortByFullNameASC, domain.ReadAllSortByFullNameDESC,
+			domain.ReadAllSortByEmailASC, domain.ReadAllSortByEmailDESC:
+		default:
+			respondError(w, errInvalidQuery)
+			return
+		}
+	}
+	for _, v := range q["role"] {
+		role, err := parseRole(v)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		inp.Roles = append(inp.Roles, role)
+	}
+
+	users, err := s.service.ReadAll(r.Context(), inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	if users == nil {
+		users = []domain.User{}
+	}
+	respond(w, http.StatusOK, users)
+}
+
+func (s *server) update(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp domain.UpdateInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	inp.ID = id
+	if err := s.service.Update(r.Context(), inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) delete(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	if err := s.service.Delete(r.Context(), id); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addRole takes scope, expiry and reason in the body, without body the role
+// is given everywhere and forever
+func (s *server) addRole(w http.ResponseWriter, r *http.Request, id domain.ID, role domain.Role) {
+	var inp domain.AddRoleInput
+	if r.ContentLength != 0 {
+		if err := decode(r, &inp); err != nil {
+			respondError(w, err)
+			return
+		}
+	}
+	inp.UserID, inp.Role = id, role
+	if err := s.service.AddRole(r.Context(), inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) removeRole(w http.ResponseWriter, r *http.Request, id domain.ID, role domain.Role) {
+	if err := s.service.RemoveRole(r.Context(), id, role, r.URL.Query().Get("scope")); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// role names are the same as in our roles table,
+// roles admins made are addressed by their ids
+func parseRole(name string) (domain.Role, error) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return domain.Role(id), nil
+	}
+	switch name {
+	case "owner":
+		return domain.RoleOwner, nil
+	case "admin":
+		return domain.RoleAdmin, nil
+	case "moderator":
+		return domain.RoleModerator, nil
+	case "deliveryman":
+		return domain.RoleDeliveryMan, nil
+	case "user":
+		return domain.RoleUser, nil
+	default:
+		return 0, domain.ErrInvalidRole
+	}
+}
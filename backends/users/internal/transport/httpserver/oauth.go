@@ -0,0 +1,305 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// Endpoints of our authorization server follow RFC 6749 and OpenID Connect
+// instead of the rest of the api: token endpoint takes forms, and errors of
+// token and userinfo endpoints are in the format OAuth libraries understand.
+
+const (
+	DiscoveryPath     = "/.well-known/openid-configuration"
+	AuthorizePath     = "/oauth2/authorize"
+	TokenPath         = "/oauth2/token"
+	UserInfoPath      = "/oauth2/userinfo"
+	bearerPrefix      = "Bearer "
+	maxTokenFormBytes = 1 << 16
+)
+
+var errInvalidTokenRequest = errors.New("httpserver: token request is malformed")
+
+type (
+	// AuthorizationServer describes us to OAuth clients. Issuer has to be
+	// the url we are reachable at and iss of our tokens. LoginURL is the page
+	// of our frontend where users sign in and allow clients to act for them,
+	// it gets the authorization request as is.
+	AuthorizationServer struct {
+		Issuer     string
+		LoginURL   string
+		SigningAlg string
+	}
+
+	discovery struct {
+		Issuer                            string   `json:"issuer"`
+		AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+		TokenEndpoint                     string   `json:"token_endpoint"`
+		UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+		JWKSURI                           string   `json:"jwks_uri"`
+		ScopesSupported                   []string `json:"scopes_supported"`
+		ResponseTypesSupported            []string `json:"response_types_supported"`
+		GrantTypesSupported               []string `json:"grant_types_supported"`
+		SubjectTypesSupported             []string `json:"subject_types_supported"`
+		IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+		TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+		TokenEndpointAuthSigningAlgs      []string `json:"token_endpoint_auth_signing_alg_values_supported"`
+		CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+		ClaimsSupported                   []string `json:"claims_supported"`
+	}
+
+	// oauthErrorBody is the error response of RFC 6749
+	oauthErrorBody struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description,omitempty"`
+	}
+
+	authorizeResponse struct {
+		RedirectURL string `json:"redirectURL"`
+	}
+
+	consentRequest struct {
+		Scopes []string `json:"scopes"`
+	}
+)
+
+// tokenErrors are error codes of the token endpoint,
+// anything else is a server_error.
+var tokenErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{errInvalidTokenRequest, http.StatusBadRequest, "invalid_request"},
+	{domain.ErrPKCERequired, http.StatusBadRequest, "invalid_request"},
+	{domain.ErrInvalidClient, http.StatusUnauthorized, "invalid_client"},
+	{domain.ErrInvalidGrant, http.StatusBadRequest, "invalid_grant"},
+	// user was deleted after the grant was issued
+	{domain.ErrNoUsers, http.StatusBadRequest, "invalid_grant"},
+	{domain.ErrUnauthorizedClient, http.StatusBadRequest, "unauthorized_client"},
+	{domain.ErrUnsupportedGrantType, http.StatusBadRequest, "unsupported_grant_type"},
+	{domain.ErrInvalidScope, http.StatusBadRequest, "invalid_scope"},
+}
+
+// WithAuthorizationServer publishes the discovery document and sends
+// browsers that come to the authorization endpoint to the login page.
+func WithAuthorizationServer(as AuthorizationServer) Option {
+	return func(s *server) {
+		issuer := strings.TrimSuffix(as.Issuer, "/")
+		doc := discovery{
+			Issuer:                            issuer,
+			AuthorizationEndpoint:             issuer + AuthorizePath,
+			TokenEndpoint:                     issuer + TokenPath,
+			UserInfoEndpoint:                  issuer + UserInfoPath,
+			ScopesSupported:                   domain.DelegableScopes(),
+			ResponseTypesSupported:            []string{domain.ResponseTypeCode},
+			GrantTypesSupported:               []string{domain.GrantAuthorizationCode, domain.GrantClientCredentials, domain.GrantRefreshToken},
+			SubjectTypesSupported:             []string{"public"},
+			IDTokenSigningAlgValuesSupported:  []string{as.SigningAlg},
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "private_key_jwt", "none"},
+			TokenEndpointAuthSigningAlgs:      []string{"RS256", "EdDSA"},
+			CodeChallengeMethodsSupported:     []string{domain.PKCEMethodS256},
+			ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "email", "email_verified", "phone_number"},
+		}
+		s.mux.HandleFunc(DiscoveryPath, method(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			doc := doc
+			doc.JWKSURI = issuer + s.jwksPath
+			respond(w, http.StatusOK, doc)
+		}))
+		s.mux.HandleFunc(AuthorizePath, method(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			login, err := url.Parse(as.LoginURL)
+			if err != nil {
+				respondError(w, err)
+				return
+			}
+			login.RawQuery = r.URL.RawQuery
+			http.Redirect(w, r, login.String(), http.StatusFound)
+		}))
+	}
+}
+
+// token takes client credentials either from basic auth or from the form,
+// clients with keys send a signed assertion in the form instead.
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxTokenFormBytes)
+	if err := r.ParseForm(); err != nil {
+		respondTokenError(w, errInvalidTokenRequest)
+		return
+	}
+	inp := domain.TokenInput{
+		GrantType:           r.PostForm.Get("grant_type"),
+		ClientID:            r.PostForm.Get("client_id"),
+		ClientSecret:        r.PostForm.Get("client_secret"),
+		ClientAssertionType: r.PostForm.Get("client_assertion_type"),
+		ClientAssertion:     r.PostForm.Get("client_assertion"),
+		Code:                r.PostForm.Get("code"),
+		RedirectURI:         r.PostForm.Get("redirect_uri"),
+		CodeVerifier:        r.PostForm.Get("code_verifier"),
+		RefreshToken:        r.PostForm.Get("refresh_token"),
+		Scope:               r.PostForm.Get("scope"),
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		// only one way of authentication is allowed
+		if inp.ClientSecret != "" || inp.ClientAssertion != "" {
+			respondTokenError(w, errInvalidTokenRequest)
+			return
+		}
+		// credentials are form encoded before they are put into the header
+		var errID, errSecret error
+		inp.ClientID, errID = url.QueryUnescape(id)
+		inp.ClientSecret, errSecret = url.QueryUnescape(secret)
+		if errID != nil || errSecret != nil {
+			respondTokenError(w, errInvalidTokenRequest)
+			return
+		}
+	}
+
+	out, err := s.service.Token(r.Context(), inp)
+	if err != nil {
+		respondTokenError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	respond(w, http.StatusOK, out)
+}
+
+func respondTokenError(w http.ResponseWriter, err error) {
+	w.Header().Set("Cache-Control", "no-store")
+	for _, v := range tokenErrors {
+		if errors.Is(err, v.err) {
+			if v.status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Basic realm="oauth2"`)
+			}
+			respond(w, v.status, oauthErrorBody{Error: v.code, Description: v.err.Error()})
+			return
+		}
+	}
+	respond(w, http.StatusInternalServerError, oauthErrorBody{Error: "server_error"})
+}
+
+// userInfo accepts bearer token only from the header, RFC 6750 allows
+// forms and queries too but tokens leak from there into logs.
+func (s *server) userInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		respondError(w, errMethodNotAllowed)
+		return
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oauth2"`)
+		respond(w, http.StatusUnauthorized, oauthErrorBody{Error: "invalid_request"})
+		return
+	}
+
+	info, err := s.service.UserInfo(r.Context(), strings.TrimPrefix(header, bearerPrefix))
+	switch {
+	case errors.Is(err, domain.ErrInvalidToken):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oauth2", error="invalid_token"`)
+		respond(w, http.StatusUnauthorized, oauthErrorBody{Error: "invalid_token"})
+	case errors.Is(err, domain.ErrNotAllowed):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oauth2", error="insufficient_scope", scope="openid"`)
+		respond(w, http.StatusForbidden, oauthErrorBody{Error: "insufficient_scope"})
+	case err != nil:
+		respondError(w, err)
+	default:
+		respond(w, http.StatusOK, info)
+	}
+}
+
+func (s *server) authorize(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var inp domain.AuthorizeInput
+	if err := decode(r, &inp); err != nil {
+		respondError(w, err)
+		return
+	}
+	redirectURL, err := s.service.Authorize(r.Context(), id, inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, authorizeResponse{RedirectURL: redirectURL})
+}
+
+func (s *server) consents(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	consents, err := s.service.Consents(r.Context(), id)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	if consents == nil {
+		consents = []domain.Consent{}
+	}
+	respond(w, http.StatusOK, consents)
+}
+
+func (s *server) grantConsent(w http.ResponseWriter, r *http.Request, id domain.ID, clientID string) {
+	var req consentRequest
+	if err := decode(r, &req); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := s.service.GrantConsent(r.Context(), id, clientID, req.Scopes); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) revokeConsent(w http.ResponseWriter, r *http.Request, id domain.ID, clientID string) {
+	if err := s.service.RevokeConsent(r.Context(), id, clientID); err != nil {
+		respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// oauthClients routes /v1/oauth/clients and /v1/oauth/clients/{id}
+func (s *server) oauthClients(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/oauth/clients"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		clients, err := s.service.OAuthClients(r.Context())
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		if clients == nil {
+			clients = []domain.OAuthClient{}
+		}
+		respond(w, http.StatusOK, clients)
+	case id == "" && r.Method == http.MethodPost:
+		var inp domain.RegisterClientInput
+		if err := decode(r, &inp); err != nil {
+			respondError(w, err)
+			return
+		}
+		reg, err := s.service.RegisterOAuthClient(r.Context(), inp)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusCreated, reg)
+	case id != "" && !strings.Contains(id, "/") && r.Method == http.MethodGet:
+		client, err := s.service.OAuthClient(r.Context(), id)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusOK, client)
+	case id != "" && !strings.Contains(id, "/") && r.Method == http.MethodDelete:
+		if err := s.service.DeleteOAuthClient(r.Context(), id); err != nil {
+			respondError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case strings.Contains(id, "/"):
+		respondError(w, errNotFound)
+	default:
+		respondError(w, errMethodNotAllowed)
+	}
+}
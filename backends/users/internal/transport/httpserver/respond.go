@@ -0,0 +1,203 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var (
+	errInvalidBody      = errors.New("httpserver: request body is not valid json")
+	errInvalidQuery     = errors.New("httpserver: invalid query parameters")
+	errNotFound         = errors.New("httpserver: route not found")
+	errMethodNotAllowed = errors.New("httpserver: method not allowed")
+)
+
+type (
+	errorBody struct {
+		Error errorDetails `json:"error"`
+	}
+
+	errorDetails struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+
+		// RetryAfter is in seconds, same as Retry-After header
+		RetryAfter int64 `json:"retryAfter,omitempty"`
+		// Violations tell every rule of password policy the password broke
+		Violations []domain.PasswordViolation `json:"violations,omitempty"`
+		// Client and Scopes are what user is asked to consent to
+		Client *consentClient `json:"client,omitempty"`
+		Scopes []string       `json:"scopes,omitempty"`
+	}
+
+	consentClient struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	// challengeBody is sent instead of keys when user has to pass the second factor
+	challengeBody struct {
+		Challenge challenge `json:"challenge"`
+	}
+
+	challenge struct {
+		Token      string                 `json:"token"`
+		ExpiresAt  time.Time              `json:"expiresAt"`
+		Enrollment *domain.TOTPEnrollment `json:"enrollment,omitempty"`
+	}
+)
+
+// errorStatuses maps errors to http statuses and codes our clients can switch on.
+// Errors that are not listed here become 500 without any details.
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{errInvalidBody, http.StatusBadRequest, "invalid_body"},
+	{errInvalidQuery, http.StatusBadRequest, "invalid_query"},
+	{errNotFound, http.StatusNotFound, "not_found"},
+	{errMethodNotAllowed, http.StatusMethodNotAllowed, "method_not_allowed"},
+
+	{domain.ErrInvalidRequestSignUpInput, http.StatusBadRequest, "invalid_input"},
+	{domain.ErrInvalidSignUpInput, http.StatusBadRequest, "invalid_input"},
+	{domain.ErrInvalidSignInInput, http.StatusBadRequest, "invalid_input"},
+	{domain.ErrInvalidPhoneNumber, http.StatusBadRequest, "invalid_phone_number"},
+	{domain.ErrInvalidEmail, http.StatusBadRequest, "invalid_email"},
+	{domain.ErrInvalidFullName, http.StatusBadRequest, "invalid_full_name"},
+	{domain.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+	{domain.ErrUnknownProvider, http.StatusBadRequest, "unknown_provider"},
+	{domain.ErrPasswordIsNotSecure, http.StatusBadRequest, "insecure_password"},
+	{domain.ErrInvalidClientMetadata, http.StatusBadRequest, "invalid_client_metadata"},
+	{domain.ErrInvalidRedirectURI, http.StatusBadRequest, "invalid_redirect_uri"},
+	{domain.ErrUnsupportedResponseType, http.StatusBadRequest, "unsupported_response_type"},
+	{domain.ErrUnauthorizedClient, http.StatusBadRequest, "unauthorized_client"},
+	{domain.ErrInvalidScope, http.StatusBadRequest, "invalid_scope"},
+	{domain.ErrPKCERequired, http.StatusBadRequest, "invalid_request"},
+	{domain.ErrInvalidAPIKeyInput, http.StatusBadRequest, "invalid_api_key"},
+	{domain.ErrInvalidRoleRequest, http.StatusBadRequest, "invalid_role_request"},
+
+	{domain.ErrInvalidCode, http.StatusUnauthorized, "invalid_code"},
+	{domain.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{domain.ErrRefreshTokenReused, http.StatusUnauthorized, "token_reused"},
+	{domain.ErrSessionRevoked, http.StatusUnauthorized, "session_revoked"},
+	{domain.ErrInvalidResetToken, http.StatusUnauthorized, "invalid_reset_token"},
+	{domain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{domain.ErrSecondFactorRequired, http.StatusUnauthorized, "second_factor_required"},
+	{domain.ErrInvalidChallenge, http.StatusUnauthorized, "invalid_challenge"},
+	{domain.ErrInvalidCeremony, http.StatusUnauthorized, "invalid_ceremony"},
+	{domain.ErrInvalidPasskey, http.StatusUnauthorized, "invalid_passkey"},
+	{domain.ErrInvalidState, http.StatusUnauthorized, "invalid_state"},
+	{domain.ErrInvalidIDToken, http.StatusUnauthorized, "invalid_id_token"},
+	{domain.ErrInvalidClient, http.StatusUnauthorized, "invalid_client"},
+
+	{domain.ErrOwnerCantBeRemoved, http.StatusForbidden, "not_allowed"},
+	{domain.ErrNotAllowed, http.StatusForbidden, "not_allowed"},
+	{domain.ErrConsentRequired, http.StatusForbidden, "consent_required"},
+
+	{domain.ErrNoUsers, http.StatusNotFound, "not_found"},
+	{domain.ErrSessionNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrPasskeyNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrIdentityNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrClientNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrConsentNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrAPIKeyNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrRoleNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrRoleRequestNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrPasskeysDisabled, http.StatusNotFound, "passkeys_disabled"},
+
+	{domain.ErrTOTPNotFound, http.StatusConflict, "totp_not_enabled"},
+	{domain.ErrTOTPAlreadyEnabled, http.StatusConflict, "totp_already_enabled"},
+	{domain.ErrPasskeyExists, http.StatusConflict, "passkey_exists"},
+	{domain.ErrIdentityExists, http.StatusConflict, "identity_exists"},
+	{domain.ErrRoleExists, http.StatusConflict, "role_exists"},
+	{domain.ErrRoleRequestPending, http.StatusConflict, "role_request_pending"},
+	{domain.ErrRoleRequestNotPending, http.StatusConflict, "role_request_reviewed"},
+	{domain.ErrIdentityNotLinked, http.StatusConflict, "identity_not_linked"},
+
+	{domain.ErrTooManyAttempts, http.StatusTooManyRequests, "too_many_attempts"},
+	{domain.ErrAccountLocked, http.StatusLocked, "account_locked"},
+}
+
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errInvalidBody
+	}
+	return nil
+}
+
+func respond(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// respondSignIn responds with keys or with a challenge for the second factor
+func respondSignIn(w http.ResponseWriter, status int, out domain.SignInOutput, err error) {
+	var required *domain.SecondFactorRequiredError
+	if errors.As(err, &required) {
+		respond(w, http.StatusOK, challengeBody{challenge{
+			Token:      required.ChallengeToken,
+			ExpiresAt:  required.ExpiresAt,
+			Enrollment: required.Enrollment,
+		}})
+		return
+	}
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, status, out)
+}
+
+func respondError(w http.ResponseWriter, err error) {
+	var rateLimitErr *domain.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		seconds := int64(math.Ceil(rateLimitErr.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+		respond(w, http.StatusTooManyRequests, errorBody{errorDetails{
+			Code:       "rate_limited",
+			Message:    rateLimitErr.Error(),
+			RetryAfter: seconds,
+		}})
+		return
+	}
+	var passwordErr *domain.PasswordPolicyError
+	if errors.As(err, &passwordErr) {
+		respond(w, http.StatusBadRequest, errorBody{errorDetails{
+			Code:       "insecure_password",
+			Message:    domain.ErrPasswordIsNotSecure.Error(),
+			Violations: passwordErr.Violations,
+		}})
+		return
+	}
+	var consentErr *domain.ConsentRequiredError
+	if errors.As(err, &consentErr) {
+		respond(w, http.StatusForbidden, errorBody{errorDetails{
+			Code:    "consent_required",
+			Message: domain.ErrConsentRequired.Error(),
+			Client:  &consentClient{ID: consentErr.Client.ID, Name: consentErr.Client.Name},
+			Scopes:  consentErr.Scopes,
+		}})
+		return
+	}
+	for _, v := range errorStatuses {
+		if errors.Is(err, v.err) {
+			respond(w, v.status, errorBody{errorDetails{
+				Code:    v.code,
+				Message: v.err.Error(),
+			}})
+			return
+		}
+	}
+	respond(w, http.StatusInternalServerError, errorBody{errorDetails{
+		Code:    "internal",
+		Message: "internal error",
+	}})
+}
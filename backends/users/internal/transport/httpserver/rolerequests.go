@@ -0,0 +1,119 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// requestRole takes role by name like the roles routes do
+func (s *server) requestRole(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	var body struct {
+		Role          string `json:"role"`
+		Scope         string `json:"scope"`
+		Justification string `json:"justification"`
+	}
+	if err := decode(r, &body); err != nil {
+		respondError(w, err)
+		return
+	}
+	role, err := parseRole(body.Role)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	req, err := s.service.RequestRole(r.Context(), domain.RequestRoleInput{
+		UserID:        id,
+		Role:          role,
+		Scope:         body.Scope,
+		Justification: body.Justification,
+	})
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusCreated, req)
+}
+
+func (s *server) userRoleRequests(w http.ResponseWriter, r *http.Request, id domain.ID) {
+	inp, err := parseRoleRequestsQuery(r)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	inp.UserID = id
+	requests, err := s.service.RoleRequests(r.Context(), inp)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, requests)
+}
+
+// roleRequests routes /v1/role-requests, /v1/role-requests/{id}
+// and /v1/role-requests/{id}/approve or reject
+func (s *server) roleRequests(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/role-requests"), "/"), "/")
+	switch {
+	case parts[0] == "" && r.Method == http.MethodGet:
+		inp, err := parseRoleRequestsQuery(r)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		requests, err := s.service.RoleRequests(r.Context(), inp)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusOK, requests)
+	case len(parts) == 1 && parts[0] != "" && r.Method == http.MethodGet:
+		req, err := s.service.RoleRequest(r.Context(), parts[0])
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusOK, req)
+	case len(parts) == 2 && (parts[1] == "approve" || parts[1] == "reject") && r.Method == http.MethodPost:
+		inp := domain.ReviewRoleRequestInput{}
+		if r.ContentLength != 0 {
+			if err := decode(r, &inp); err != nil {
+				respondError(w, err)
+				return
+			}
+		}
+		inp.ID, inp.Approve = parts[0], parts[1] == "approve"
+		req, err := s.service.ReviewRoleRequest(r.Context(), inp)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusOK, req)
+	case len(parts) > 2 || (len(parts) == 2 && parts[1] != "approve" && parts[1] != "reject"):
+		respondError(w, errNotFound)
+	default:
+		respondError(w, errMethodNotAllowed)
+	}
+}
+
+func parseRoleRequestsQuery(r *http.Request) (domain.ReadRoleRequestsInput, error) {
+	q := r.URL.Query()
+	inp := domain.ReadRoleRequestsInput{
+		Status: domain.RoleRequestStatus(q.Get("status")),
+		Limit:  20,
+	}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if inp.Limit, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return inp, errInvalidQuery
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if inp.Offset, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return inp, errInvalidQuery
+		}
+	}
+	return inp, nil
+}
@@ -0,0 +1,67 @@
+package httpserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+// roles routes /v1/roles, /v1/roles/{role} and /v1/roles/{role}/permissions
+func (s *server) roles(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/roles"), "/"), "/")
+	switch {
+	case parts[0] == "" && r.Method == http.MethodGet:
+		roles, err := s.service.Roles(r.Context())
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusOK, roles)
+	case parts[0] == "" && r.Method == http.MethodPost:
+		var inp domain.CreateRoleInput
+		if err := decode(r, &inp); err != nil {
+			respondError(w, err)
+			return
+		}
+		role, err := s.service.CreateRole(r.Context(), inp)
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		respond(w, http.StatusCreated, role)
+	case len(parts) == 1 && parts[0] != "" && r.Method == http.MethodDelete:
+		role, err := parseRole(parts[0])
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		if err := s.service.DeleteRole(r.Context(), role); err != nil {
+			respondError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[1] == "permissions" && r.Method == http.MethodPut:
+		role, err := parseRole(parts[0])
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		var inp struct {
+			Permissions []string `json:"permissions"`
+		}
+		if err := decode(r, &inp); err != nil {
+			respondError(w, err)
+			return
+		}
+		if err := s.service.SetRolePermissions(r.Context(), role, inp.Permissions); err != nil {
+			respondError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) > 2 || (len(parts) == 2 && parts[1] != "permissions"):
+		respondError(w, errNotFound)
+	default:
+		respondError(w, errMethodNotAllowed)
+	}
+}
@@ -0,0 +1,274 @@
+package httpserver
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+type (
+	server struct {
+		service  domain.Service
+		mux      *http.ServeMux
+		jwksPath string
+	}
+
+	Option func(*server)
+)
+
+// WithJWKS publishes public keys of our tokens under path,
+// other services use them to verify tokens without asking us.
+func WithJWKS(path string, jwks http.Handler) Option {
+	return func(s *server) {
+		s.jwksPath = path
+		s.mux.Handle(path, jwks)
+	}
+}
+
+// NewHandler returns versioned REST api for our web and mobile clients.
+// Like the grpc one it only decodes requests and passes them to domain.Service.
+// Access tokens and api keys come as bearer tokens, clients and api keys
+// reach only routes their scopes allow.
+func NewHandler(s domain.Service, opts ...Option) http.Handler {
+	srv := &server{
+		service: s,
+		mux:     http.NewServeMux(),
+	}
+	srv.routes()
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, err := s.authenticate(r.WithContext(domain.WithClientInfo(r.Context(), clientInfo(r))))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="users"`)
+		}
+		respondError(w, err)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// clientInfo trusts only the address of the connection,
+// headers like X-Forwarded-For are too easy to fake.
+func clientInfo(r *http.Request) domain.ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return domain.ClientInfo{
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func (s *server) routes() {
+	s.mux.HandleFunc("/v1/auth/signup/request", method(http.MethodPost, s.requestSignUp))
+	s.mux.HandleFunc("/v1/auth/signup", method(http.MethodPost, s.signUp))
+	s.mux.HandleFunc("/v1/auth/signin/request", method(http.MethodPost, s.requestSignIn))
+	s.mux.HandleFunc("/v1/auth/signin", method(http.MethodPost, s.signIn))
+	s.mux.HandleFunc("/v1/auth/signin/password", method(http.MethodPost, s.signInEmailPassword))
+	s.mux.HandleFunc("/v1/auth/refresh", method(http.MethodPost, s.refresh))
+	s.mux.HandleFunc("/v1/auth/2fa/verify", method(http.MethodPost, s.verifySecondFactor))
+	s.mux.HandleFunc("/v1/auth/passkeys/signin/begin", method(http.MethodPost, s.beginPasskeySignIn))
+	s.mux.HandleFunc("/v1/auth/passkeys/signin/finish", method(http.MethodPost, s.finishPasskeySignIn))
+	s.mux.HandleFunc("/v1/auth/oidc/begin", method(http.MethodPost, s.beginOIDCSignIn))
+	s.mux.HandleFunc("/v1/auth/oidc/finish", method(http.MethodPost, s.finishOIDCSignIn))
+	s.mux.HandleFunc("/v1/auth/password/forgot", method(http.MethodPost, s.requestPasswordReset))
+	s.mux.HandleFunc("/v1/auth/password/reset", method(http.MethodPost, s.resetPassword))
+	s.mux.HandleFunc("/v1/auth/signout", method(http.MethodPost, s.signOut))
+	s.mux.HandleFunc("/v1/auth/signout/others", method(http.MethodPost, s.revokeOtherSessions))
+
+	s.mux.HandleFunc(TokenPath, method(http.MethodPost, s.token))
+	s.mux.HandleFunc(UserInfoPath, s.userInfo)
+	s.mux.HandleFunc("/v1/oauth/clients", s.oauthClients)
+	s.mux.HandleFunc("/v1/oauth/clients/", s.oauthClients)
+	s.mux.HandleFunc("/v1/api-keys", s.apiKeys)
+	s.mux.HandleFunc("/v1/api-keys/", s.apiKeys)
+	s.mux.HandleFunc("/v1/roles", s.roles)
+	s.mux.HandleFunc("/v1/roles/", s.roles)
+	s.mux.HandleFunc("/v1/role-requests", s.roleRequests)
+	s.mux.HandleFunc("/v1/role-requests/", s.roleRequests)
+
+	s.mux.HandleFunc("/v1/users", method(http.MethodGet, s.readAll))
+	s.mux.HandleFunc("/v1/users/by-email", method(http.MethodGet, s.readByEmail))
+	s.mux.HandleFunc("/v1/users/by-phone", method(http.MethodGet, s.readByPhoneNumber))
+	s.mux.HandleFunc("/v1/users/", s.users)
+
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, errNotFound)
+	})
+}
+
+// users routes everything that looks like /v1/users/{id},
+// /v1/users/{id}/roles/{role}, /v1/users/{id}/sessions/{sessionID}
+// /v1/users/{id}/totp/..., /v1/users/{id}/consents/{clientID} and /v1/users/{id}/lock
+func (s *server) users(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/users/"), "/"), "/")
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		respondError(w, errNotFound)
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			s.read(w, r, domain.ID(id))
+		case http.MethodPatch:
+			s.update(w, r, domain.ID(id))
+		case http.MethodDelete:
+			s.delete(w, r, domain.ID(id))
+		default:
+			respondError(w, errMethodNotAllowed)
+		}
+	case len(parts) == 3 && parts[1] == "roles":
+		role, err := parseRole(parts[2])
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			s.addRole(w, r, domain.ID(id), role)
+		case http.MethodDelete:
+			s.removeRole(w, r, domain.ID(id), role)
+		default:
+			respondError(w, errMethodNotAllowed)
+		}
+	case len(parts) == 2 && parts[1] == "role-requests":
+		switch r.Method {
+		case http.MethodPost:
+			s.requestRole(w, r, domain.ID(id))
+		case http.MethodGet:
+			s.userRoleRequests(w, r, domain.ID(id))
+		default:
+			respondError(w, errMethodNotAllowed)
+		}
+	case len(parts) == 2 && parts[1] == "sessions":
+		if r.Method != http.MethodGet {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.sessions(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "sessions":
+		if r.Method != http.MethodDelete {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.revokeSession(w, r, domain.ID(id), parts[2])
+	case len(parts) == 2 && parts[1] == "totp":
+		switch r.Method {
+		case http.MethodPut:
+			s.enrollTOTP(w, r, domain.ID(id))
+		case http.MethodDelete:
+			s.disableTOTP(w, r, domain.ID(id))
+		default:
+			respondError(w, errMethodNotAllowed)
+		}
+	case len(parts) == 3 && parts[1] == "totp" && parts[2] == "confirm":
+		if r.Method != http.MethodPost {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.confirmTOTP(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "totp" && parts[2] == "recovery-codes":
+		if r.Method != http.MethodPost {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.regenerateRecoveryCodes(w, r, domain.ID(id))
+	case len(parts) == 2 && parts[1] == "passkeys":
+		if r.Method != http.MethodGet {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.passkeys(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "passkeys" && (parts[2] == "begin" || parts[2] == "finish"):
+		if r.Method != http.MethodPost {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		if parts[2] == "begin" {
+			s.beginPasskeyRegistration(w, r, domain.ID(id))
+			return
+		}
+		s.finishPasskeyRegistration(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "passkeys":
+		if r.Method != http.MethodDelete {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.deletePasskey(w, r, domain.ID(id), parts[2])
+	case len(parts) == 2 && parts[1] == "identities":
+		if r.Method != http.MethodGet {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.identities(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "identities" && (parts[2] == "begin" || parts[2] == "finish"):
+		if r.Method != http.MethodPost {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		if parts[2] == "begin" {
+			s.beginOIDCLink(w, r, domain.ID(id))
+			return
+		}
+		s.finishOIDCLink(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "identities":
+		if r.Method != http.MethodDelete {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.unlinkIdentity(w, r, domain.ID(id), parts[2])
+	case len(parts) == 3 && parts[1] == "oauth" && parts[2] == "authorize":
+		if r.Method != http.MethodPost {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.authorize(w, r, domain.ID(id))
+	case len(parts) == 2 && parts[1] == "consents":
+		if r.Method != http.MethodGet {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.consents(w, r, domain.ID(id))
+	case len(parts) == 3 && parts[1] == "consents":
+		switch r.Method {
+		case http.MethodPut:
+			s.grantConsent(w, r, domain.ID(id), parts[2])
+		case http.MethodDelete:
+			s.revokeConsent(w, r, domain.ID(id), parts[2])
+		default:
+			respondError(w, errMethodNotAllowed)
+		}
+	case len(parts) == 2 && parts[1] == "lock":
+		if r.Method != http.MethodDelete {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		s.unlockAccount(w, r, domain.ID(id))
+	default:
+		respondError(w, errNotFound)
+	}
+}
+
+func method(m string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != m {
+			respondError(w, errMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,721 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain/mocks"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/transport/httpserver"
+)
+
+func TestServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	handler := httpserver.NewHandler(mockService)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+
+		mockup func()
+	}{
+		{
+			name:   "sign in",
+			method: http.MethodPost,
+			path:   "/v1/auth/signin",
+			body:   `{"phoneNumber":"+996702569123","code":"123456"}`,
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().SignIn(gomock.Any(), domain.SignInInput{
+					PhoneNumber: "+996702569123",
+					Code:        "123456",
+				}).Return(domain.SignInOutput{AccessKey: "access", RefreshKey: "refresh"}, nil)
+			},
+		},
+		{
+			name:   "sign in with invalid code",
+			method: http.MethodPost,
+			path:   "/v1/auth/signin",
+			body:   `{"phoneNumber":"+996702569123","code":"000000"}`,
+			status: http.StatusUnauthorized,
+			code:   "invalid_code",
+			mockup: func() {
+				mockService.EXPECT().SignIn(gomock.Any(), gomock.Any()).
+					Return(domain.SignInOutput{}, fmt.Errorf("signIn(): %w", domain.ErrInvalidCode))
+			},
+		},
+		{
+			name:   "sign in with totp enabled",
+			method: http.MethodPost,
+			path:   "/v1/auth/signin/password",
+			body:   `{"email":"pizzas@gmail.com","password":"password"}`,
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().SignInEmailPassword(gomock.Any(), "pizzas@gmail.com", "password").
+					Return(domain.SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w",
+						&domain.SecondFactorRequiredError{ChallengeToken: "challenge"}))
+			},
+		},
+		{
+			name:   "verify second factor with used challenge",
+			method: http.MethodPost,
+			path:   "/v1/auth/2fa/verify",
+			body:   `{"challengeToken":"challenge","code":"123456"}`,
+			status: http.StatusUnauthorized,
+			code:   "invalid_challenge",
+			mockup: func() {
+				mockService.EXPECT().VerifySecondFactor(gomock.Any(), "challenge", "123456").
+					Return(domain.SignInOutput{}, fmt.Errorf("verifySecondFactor(): %w", domain.ErrInvalidChallenge))
+			},
+		},
+		{
+			name:   "confirm totp",
+			method: http.MethodPost,
+			path:   "/v1/users/3/totp/confirm",
+			body:   `{"code":"123456"}`,
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().ConfirmTOTP(gomock.Any(), domain.ID(3), "123456").Return([]string{"aaaa-bbbb-cccc-dddd"}, nil)
+			},
+		},
+		{
+			name:   "finish passkey registration for user from path",
+			method: http.MethodPost,
+			path:   "/v1/users/3/passkeys/finish",
+			body:   `{"ceremonyID":"ceremony","response":{"id":"cred"},"name":"laptop"}`,
+			status: http.StatusCreated,
+			mockup: func() {
+				mockService.EXPECT().FinishPasskeyRegistration(gomock.Any(), domain.FinishPasskeyInput{
+					CeremonyID: "ceremony",
+					Response:   []byte(`{"id":"cred"}`),
+					UserID:     3,
+					Name:       "laptop",
+				}).Return(domain.Passkey{ID: "cred", UserID: 3, Name: "laptop"}, nil)
+			},
+		},
+		{
+			name:   "sign in with unknown passkey",
+			method: http.MethodPost,
+			path:   "/v1/auth/passkeys/signin/finish",
+			body:   `{"ceremonyID":"ceremony","response":{"id":"cred"}}`,
+			status: http.StatusUnauthorized,
+			code:   "invalid_passkey",
+			mockup: func() {
+				mockService.EXPECT().FinishPasskeySignIn(gomock.Any(), gomock.Any()).
+					Return(domain.SignInOutput{}, fmt.Errorf("finishPasskeySignIn(): %w", domain.ErrInvalidPasskey))
+			},
+		},
+		{
+			name:   "delete passkey",
+			method: http.MethodDelete,
+			path:   "/v1/users/3/passkeys/cred",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().DeletePasskey(gomock.Any(), domain.ID(3), "cred").Return(nil)
+			},
+		},
+		{
+			name:   "sign in with oidc",
+			method: http.MethodPost,
+			path:   "/v1/auth/oidc/finish",
+			body:   `{"state":"state","code":"code"}`,
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().FinishOIDCSignIn(gomock.Any(), domain.FinishOIDCInput{State: "state", Code: "code"}).
+					Return(domain.SignInOutput{AccessKey: "access"}, nil)
+			},
+		},
+		{
+			name:   "sign in with oidc to existing account",
+			method: http.MethodPost,
+			path:   "/v1/auth/oidc/finish",
+			body:   `{"state":"state","code":"code"}`,
+			status: http.StatusConflict,
+			code:   "identity_not_linked",
+			mockup: func() {
+				mockService.EXPECT().FinishOIDCSignIn(gomock.Any(), gomock.Any()).
+					Return(domain.SignInOutput{}, fmt.Errorf("finishOIDCSignIn(): %w", domain.ErrIdentityNotLinked))
+			},
+		},
+		{
+			name:   "link identity of user from path",
+			method: http.MethodPost,
+			path:   "/v1/users/3/identities/finish",
+			body:   `{"state":"state","code":"code"}`,
+			status: http.StatusCreated,
+			mockup: func() {
+				mockService.EXPECT().FinishOIDCLink(gomock.Any(), domain.ID(3), domain.FinishOIDCInput{State: "state", Code: "code"}).
+					Return(domain.Identity{Provider: "google", Subject: "1", UserID: 3}, nil)
+			},
+		},
+		{
+			name:   "sign out",
+			method: http.MethodPost,
+			path:   "/v1/auth/signout",
+			body:   `{"refreshKey":"refresh"}`,
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().SignOut(gomock.Any(), "refresh").Return(nil)
+			},
+		},
+		{
+			name:   "revoke unknown session",
+			method: http.MethodDelete,
+			path:   "/v1/users/1/sessions/family",
+			status: http.StatusNotFound,
+			code:   "not_found",
+			mockup: func() {
+				mockService.EXPECT().RevokeSession(gomock.Any(), domain.ID(1), "family").
+					Return(fmt.Errorf("revokeSession(): %w", domain.ErrSessionNotFound))
+			},
+		},
+		{
+			name:   "sign in with broken body",
+			method: http.MethodPost,
+			path:   "/v1/auth/signin",
+			body:   `{"phoneNumber":`,
+			status: http.StatusBadRequest,
+			code:   "invalid_body",
+			mockup: func() {},
+		},
+		{
+			name:   "sign in with wrong method",
+			method: http.MethodGet,
+			path:   "/v1/auth/signin",
+			status: http.StatusMethodNotAllowed,
+			code:   "method_not_allowed",
+			mockup: func() {},
+		},
+		{
+			name:   "request sign in too often",
+			method: http.MethodPost,
+			path:   "/v1/auth/signin/request",
+			body:   `{"phoneNumber":"+996702569123"}`,
+			status: http.StatusTooManyRequests,
+			code:   "rate_limited",
+			mockup: func() {
+				mockService.EXPECT().RequestSignIn(gomock.Any(), gomock.Any()).
+					Return(fmt.Errorf("requestSignIn(): %w", &domain.RateLimitError{
+						Scope:      domain.RateLimitScopeDestination,
+						RetryAfter: time.Millisecond * 1500,
+					}))
+			},
+		},
+		{
+			name:   "reset password to a weak one",
+			method: http.MethodPost,
+			path:   "/v1/auth/password/reset",
+			body:   `{"token":"token","password":"password"}`,
+			status: http.StatusBadRequest,
+			code:   "insecure_password",
+			mockup: func() {
+				mockService.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).
+					Return(fmt.Errorf("resetPassword(): %w", &domain.PasswordPolicyError{
+						Violations: []domain.PasswordViolation{{Reason: domain.PasswordTooWeak}},
+					}))
+			},
+		},
+		{
+			name:   "sign in to locked account",
+			method: http.MethodPost,
+			path:   "/v1/auth/signin/password",
+			body:   `{"email":"pizzas@gmail.com","password":"password"}`,
+			status: http.StatusLocked,
+			code:   "account_locked",
+			mockup: func() {
+				mockService.EXPECT().SignInEmailPassword(gomock.Any(), "pizzas@gmail.com", "password").
+					Return(domain.SignInOutput{}, fmt.Errorf("signInEmailPassword(): %w", domain.ErrAccountLocked))
+			},
+		},
+		{
+			name:   "unlock account",
+			method: http.MethodDelete,
+			path:   "/v1/users/5/lock",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().UnlockAccount(gomock.Any(), domain.ID(5)).Return(nil)
+			},
+		},
+		{
+			name:   "read user that does not exist",
+			method: http.MethodGet,
+			path:   "/v1/users/42",
+			status: http.StatusNotFound,
+			code:   "not_found",
+			mockup: func() {
+				mockService.EXPECT().Read(gomock.Any(), domain.ID(42)).
+					Return(domain.User{}, fmt.Errorf("read(): %w", domain.ErrNoUsers))
+			},
+		},
+		{
+			name:   "read all with filters",
+			method: http.MethodGet,
+			path:   "/v1/users?limit=5&offset=10&role=admin&role=moderator",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().ReadAll(gomock.Any(), domain.ReadAllInput{
+					Limit:  5,
+					Offset: 10,
+					Roles:  []domain.Role{domain.RoleAdmin, domain.RoleModerator},
+				}).Return([]domain.User{{ID: 1}}, nil)
+			},
+		},
+		{
+			name:   "read all sorted by email",
+			method: http.MethodGet,
+			path:   "/v1/users?sortBy=4",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().ReadAll(gomock.Any(), domain.ReadAllInput{Limit: 20, SortBy: domain.ReadAllSortByEmailDESC}).
+					Return([]domain.User{{ID: 1}}, nil)
+			},
+		},
+		{
+			name:   "fail with unknown sorting",
+			method: http.MethodGet,
+			path:   "/v1/users?sortBy=9",
+			status: http.StatusBadRequest,
+			code:   "invalid_query",
+			mockup: func() {},
+		},
+		{
+			name:   "delete user",
+			method: http.MethodDelete,
+			path:   "/v1/users/7",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().Delete(gomock.Any(), domain.ID(7)).Return(nil)
+			},
+		},
+		{
+			name:   "update takes id from path",
+			method: http.MethodPatch,
+			path:   "/v1/users/7",
+			body:   `{"id":1,"fullName":"Pizza Lover"}`,
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().Update(gomock.Any(), domain.UpdateInput{
+					ID:       7,
+					FullName: "Pizza Lover",
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "add unknown role",
+			method: http.MethodPut,
+			path:   "/v1/users/7/roles/chef",
+			status: http.StatusBadRequest,
+			code:   "invalid_role",
+			mockup: func() {},
+		},
+		{
+			name:   "add role without body",
+			method: http.MethodPut,
+			path:   "/v1/users/7/roles/moderator",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().AddRole(gomock.Any(), domain.AddRoleInput{UserID: 7, Role: domain.RoleModerator}).Return(nil)
+			},
+		},
+		{
+			name:   "add role in a branch",
+			method: http.MethodPut,
+			path:   "/v1/users/7/roles/moderator",
+			body:   `{"scope":"branch:12","reason":"covers a shift"}`,
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().AddRole(gomock.Any(), domain.AddRoleInput{
+					UserID: 7,
+					Role:   domain.RoleModerator,
+					Scope:  "branch:12",
+					Reason: "covers a shift",
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "remove role in a branch",
+			method: http.MethodDelete,
+			path:   "/v1/users/7/roles/moderator?scope=branch:12",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().RemoveRole(gomock.Any(), domain.ID(7), domain.RoleModerator, "branch:12").Return(nil)
+			},
+		},
+		{
+			name:   "request role by name",
+			method: http.MethodPost,
+			path:   "/v1/users/7/role-requests",
+			body:   `{"role":"deliveryman","scope":"store:3","justification":"I have a bike"}`,
+			status: http.StatusCreated,
+			mockup: func() {
+				mockService.EXPECT().RequestRole(gomock.Any(), domain.RequestRoleInput{
+					UserID:        7,
+					Role:          domain.RoleDeliveryMan,
+					Scope:         "store:3",
+					Justification: "I have a bike",
+				}).Return(domain.RoleRequest{ID: "a"}, nil)
+			},
+		},
+		{
+			name:   "pending role requests",
+			method: http.MethodGet,
+			path:   "/v1/role-requests?status=pending",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().RoleRequests(gomock.Any(), domain.ReadRoleRequestsInput{Status: domain.RoleRequestPending, Limit: 20}).
+					Return([]domain.RoleRequest{{ID: "a"}}, nil)
+			},
+		},
+		{
+			name:   "reject role request",
+			method: http.MethodPost,
+			path:   "/v1/role-requests/a/reject",
+			body:   `{"approve":true,"comment":"we have enough couriers"}`,
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().ReviewRoleRequest(gomock.Any(), domain.ReviewRoleRequestInput{ID: "a", Comment: "we have enough couriers"}).
+					Return(domain.RoleRequest{ID: "a", Status: domain.RoleRequestRejected}, nil)
+			},
+		},
+		{
+			name:   "review of reviewed request",
+			method: http.MethodPost,
+			path:   "/v1/role-requests/a/approve",
+			status: http.StatusConflict,
+			code:   "role_request_reviewed",
+			mockup: func() {
+				mockService.EXPECT().ReviewRoleRequest(gomock.Any(), domain.ReviewRoleRequestInput{ID: "a", Approve: true}).
+					Return(domain.RoleRequest{}, fmt.Errorf("reviewRoleRequest(): %w", domain.ErrRoleRequestNotPending))
+			},
+		},
+		{
+			name:   "remove role from owner",
+			method: http.MethodDelete,
+			path:   "/v1/users/1/roles/admin",
+			status: http.StatusForbidden,
+			code:   "not_allowed",
+			mockup: func() {
+				mockService.EXPECT().RemoveRole(gomock.Any(), domain.ID(1), domain.RoleAdmin, "").
+					Return(fmt.Errorf("removeRole(): %w", domain.ErrNotAllowed))
+			},
+		},
+		{
+			name:   "unknown error is hidden",
+			method: http.MethodDelete,
+			path:   "/v1/users/3",
+			status: http.StatusInternalServerError,
+			code:   "internal",
+			mockup: func() {
+				mockService.EXPECT().Delete(gomock.Any(), domain.ID(3)).
+					Return(fmt.Errorf("delete(): repo error: connection refused"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockup()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			if rec.Code != tc.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+			if tc.code == "" {
+				return
+			}
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error.Code != tc.code {
+				t.Errorf("got code %q, want %q", body.Error.Code, tc.code)
+			}
+		})
+	}
+}
+
+func TestServerPassesClientInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	handler := httpserver.NewHandler(mockService)
+
+	mockService.EXPECT().RequestSignIn(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ domain.RequestSignInInput) error {
+			info := domain.ClientInfoFromContext(ctx)
+			if info.IP != "10.0.0.1" || info.UserAgent != "pizza-app/1.0" {
+				t.Errorf("unexpected client info %+v", info)
+			}
+			return &domain.RateLimitError{Scope: domain.RateLimitScopeIP, RetryAfter: time.Millisecond * 1500}
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/signin/request", strings.NewReader(`{"email":"pizzas@gmail.com"}`))
+	req.RemoteAddr = "10.0.0.1:52000"
+	req.Header.Set("User-Agent", "pizza-app/1.0")
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("got Retry-After %q, want %q", got, "2")
+	}
+}
+
+func TestTokenEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	handler := httpserver.NewHandler(mockService)
+
+	testCases := []struct {
+		name   string
+		form   string
+		id     string
+		secret string
+		status int
+		code   string
+		mockup func()
+	}{
+		{
+			name:   "client credentials with basic auth",
+			form:   "grant_type=client_credentials&scope=menu%3Aread",
+			id:     "aggregator",
+			secret: "s%2Bcret",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().Token(gomock.Any(), domain.TokenInput{
+					GrantType:    domain.GrantClientCredentials,
+					ClientID:     "aggregator",
+					ClientSecret: "s+cret",
+					Scope:        "menu:read",
+				}).Return(domain.TokenOutput{AccessToken: "access", TokenType: "Bearer"}, nil)
+			},
+		},
+		{
+			name:   "secret both in header and form",
+			form:   "grant_type=client_credentials&client_secret=secret",
+			id:     "aggregator",
+			secret: "secret",
+			status: http.StatusBadRequest,
+			code:   "invalid_request",
+			mockup: func() {},
+		},
+		{
+			name:   "used code",
+			form:   "grant_type=authorization_code&client_id=admin&code=code&code_verifier=verifier",
+			status: http.StatusBadRequest,
+			code:   "invalid_grant",
+			mockup: func() {
+				mockService.EXPECT().Token(gomock.Any(), gomock.Any()).
+					Return(domain.TokenOutput{}, fmt.Errorf("exchangeCode(): %w", domain.ErrInvalidGrant))
+			},
+		},
+		{
+			name:   "wrong secret",
+			form:   "grant_type=client_credentials&client_id=aggregator&client_secret=wrong",
+			status: http.StatusUnauthorized,
+			code:   "invalid_client",
+			mockup: func() {
+				mockService.EXPECT().Token(gomock.Any(), gomock.Any()).
+					Return(domain.TokenOutput{}, fmt.Errorf("authenticateClient(): %w", domain.ErrInvalidClient))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockup()
+			req := httptest.NewRequest(http.MethodPost, httpserver.TokenPath, strings.NewReader(tc.form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.id != "" {
+				req.SetBasicAuth(tc.id, tc.secret)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+			if rec.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("token response may be cached")
+			}
+			if tc.code == "" {
+				return
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tc.code {
+				t.Errorf("got error %q, want %q", body.Error, tc.code)
+			}
+		})
+	}
+}
+
+func TestDiscoveryAdvertisesDelegableScopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	handler := httpserver.NewHandler(mocks.NewMockService(ctrl), httpserver.WithAuthorizationServer(httpserver.AuthorizationServer{
+		Issuer:     "https://users.pizza",
+		LoginURL:   "https://pizza/login",
+		SigningAlg: "RS256",
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, httpserver.DiscoveryPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	var doc struct {
+		ScopesSupported []string `json:"scopes_supported"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	openID := false
+	for _, v := range doc.ScopesSupported {
+		if v == domain.ScopeUsersRead || v == domain.ScopeUsersWrite {
+			t.Errorf("%q can not be delegated but is advertised", v)
+		}
+		openID = openID || v == domain.ScopeOpenID
+	}
+	if !openID {
+		t.Errorf("%q is not advertised: %v", domain.ScopeOpenID, doc.ScopesSupported)
+	}
+}
+
+func TestServerAuthenticatesCallers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := mocks.NewMockService(ctrl)
+	handler := httpserver.NewHandler(mockService)
+
+	partner := domain.AccessClaims{APIKeyID: "key", Scopes: []string{domain.ScopeUsersRead}}
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		auth   string
+		status int
+		code   string
+
+		mockup func()
+	}{
+		{
+			name:   "api key reads user",
+			method: http.MethodGet,
+			path:   "/v1/users/7",
+			auth:   "Bearer mpk_key.secret",
+			status: http.StatusOK,
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "mpk_key.secret").Return(partner, nil)
+				mockService.EXPECT().Read(gomock.Any(), domain.ID(7)).
+					DoAndReturn(func(ctx context.Context, id domain.ID) (domain.User, error) {
+						if caller, ok := domain.CallerFromContext(ctx); !ok || caller.APIKeyID != "key" {
+							t.Errorf("caller is not in context")
+						}
+						return domain.User{ID: id}, nil
+					})
+			},
+		},
+		{
+			name:   "api key can not update without write scope",
+			method: http.MethodPatch,
+			path:   "/v1/users/7",
+			auth:   "Bearer mpk_key.secret",
+			status: http.StatusForbidden,
+			code:   "not_allowed",
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "mpk_key.secret").Return(partner, nil)
+			},
+		},
+		{
+			name:   "api key can not reach routes that are not for partners",
+			method: http.MethodGet,
+			path:   "/v1/users/7/sessions",
+			auth:   "Bearer mpk_key.secret",
+			status: http.StatusForbidden,
+			code:   "not_allowed",
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "mpk_key.secret").Return(partner, nil)
+			},
+		},
+		{
+			name:   "revoked api key",
+			method: http.MethodGet,
+			path:   "/v1/users/by-email?email=pizzas@gmail.com",
+			auth:   "Bearer mpk_revoked.secret",
+			status: http.StatusUnauthorized,
+			code:   "invalid_token",
+			mockup: func() {
+				mockService.EXPECT().Authenticate(gomock.Any(), "mpk_revoked.secret").
+					Return(domain.AccessClaims{}, fmt.Errorf("authenticate(): %w", domain.ErrInvalidToken))
+			},
+		},
+		{
+			name:   "revoke api key",
+			method: http.MethodDelete,
+			path:   "/v1/api-keys/key",
+			status: http.StatusNoContent,
+			mockup: func() {
+				mockService.EXPECT().RevokeAPIKey(gomock.Any(), "key").Return(nil)
+			},
+		},
+		{
+			name:   "list api keys without owner",
+			method: http.MethodGet,
+			path:   "/v1/api-keys",
+			status: http.StatusBadRequest,
+			code:   "invalid_query",
+			mockup: func() {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockup()
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+			if tc.code == "" {
+				return
+			}
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error.Code != tc.code {
+				t.Errorf("got code %q, want %q", body.Error.Code, tc.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// COSE algorithms we accept, the same ones browsers ask for by default
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// COSE_Key parameters, https://www.rfc-editor.org/rfc/rfc8152#section-13
+const (
+	coseKty = 1
+	coseAlg = 3
+
+	coseCrv = -1
+	coseX   = -2
+	coseY   = -3
+	coseN   = -1
+	coseE   = -2
+
+	ktyOKP = 1
+	ktyEC2 = 2
+	ktyRSA = 3
+
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+var supportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+type coseKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parsePublicKey(data []byte) (coseKey, error) {
+	var params map[int]interface{}
+	if err := cbor.Unmarshal(data, &params); err != nil {
+		return coseKey{}, invalid(ErrMalformed, "public key is not a cose key")
+	}
+	kty, _ := params[coseKty].(uint64)
+	alg, ok := coseInt(params[coseAlg])
+	if !ok {
+		return coseKey{}, invalid(ErrUnsupportedKey, "no algorithm")
+	}
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := coseInt(params[coseCrv])
+		x, _ := params[coseX].([]byte)
+		y, _ := params[coseY].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return coseKey{}, invalid(ErrUnsupportedKey, "invalid P-256 key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return coseKey{}, invalid(ErrUnsupportedKey, "point is not on the curve")
+		}
+		return coseKey{alg: alg, key: pub}, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := coseInt(params[coseCrv])
+		x, _ := params[coseX].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return coseKey{}, invalid(ErrUnsupportedKey, "invalid Ed25519 key")
+		}
+		return coseKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := params[coseN].([]byte)
+		e, _ := params[coseE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return coseKey{}, invalid(ErrUnsupportedKey, "invalid RSA key")
+		}
+		return coseKey{alg: alg, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	}
+	return coseKey{}, invalid(ErrUnsupportedKey, fmt.Sprintf("kty %d alg %d", kty, alg))
+}
+
+// coseInt reads an integer cbor decoded into interface{}
+func coseInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case uint64:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+func (k coseKey) verify(message, signature []byte) error {
+	var ok bool
+	switch pub := k.key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(message)
+		ok = ecdsa.VerifyASN1(pub, hash[:], signature)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, message, signature)
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(message)
+		ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], signature) == nil
+	}
+	if !ok {
+		return invalid(ErrSignature, "")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package webauthn
+
+import (
+	"errors"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+var (
+	ErrInvalidConfig = errors.New("webauthn: rp id and at least one origin are required")
+
+	// reasons why a response was rejected
+	ErrMalformed          = errors.New("webauthn: response is malformed")
+	ErrWrongType          = errors.New("webauthn: wrong ceremony type")
+	ErrChallengeMismatch  = errors.New("webauthn: challenge does not match")
+	ErrOriginMismatch     = errors.New("webauthn: origin is not allowed")
+	ErrRPIDMismatch       = errors.New("webauthn: credential is scoped to another relying party")
+	ErrUserNotPresent     = errors.New("webauthn: user presence flag is not set")
+	ErrUserNotVerified    = errors.New("webauthn: user verification flag is not set")
+	ErrUnsupportedKey     = errors.New("webauthn: public key algorithm is not supported")
+	ErrSignature          = errors.New("webauthn: signature is invalid")
+	ErrCredentialMismatch = errors.New("webauthn: response is made with another credential")
+	ErrSignCount          = errors.New("webauthn: sign count did not grow, authenticator might be cloned")
+)
+
+// VerificationError tells why response was rejected. It is domain.ErrInvalidPasskey
+// for the service and one of the reasons above for whoever needs details.
+type VerificationError struct {
+	Reason error
+	Detail string
+}
+
+func (e *VerificationError) Error() string {
+	if e.Detail == "" {
+		return e.Reason.Error()
+	}
+	return e.Reason.Error() + ": " + e.Detail
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Reason
+}
+
+func (e *VerificationError) Is(target error) bool {
+	return target == domain.ErrInvalidPasskey
+}
+
+func invalid(reason error, detail string) error {
+	return &VerificationError{Reason: reason, Detail: detail}
+}
@@ -0,0 +1,206 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// JSON of navigator.credentials options and responses, binary fields are
+// base64url the same as PublicKeyCredential.toJSON() makes them.
+
+type (
+	RelyingPartyEntity struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	UserEntity struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	}
+
+	CredentialParameter struct {
+		Type string `json:"type"`
+		Alg  int64  `json:"alg"`
+	}
+
+	CredentialDescriptor struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	}
+
+	CreationOptions struct {
+		Challenge              string                 `json:"challenge"`
+		RP                     RelyingPartyEntity     `json:"rp"`
+		User                   UserEntity             `json:"user"`
+		PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+		Timeout                int64                  `json:"timeout"`
+		ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+		AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+		Attestation            string                 `json:"attestation"`
+	}
+
+	RequestOptions struct {
+		Challenge        string                 `json:"challenge"`
+		Timeout          int64                  `json:"timeout"`
+		RPID             string                 `json:"rpId"`
+		AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+		UserVerification string                 `json:"userVerification"`
+	}
+
+	AttestationResponse struct {
+		ID       string `json:"id"`
+		RawID    string `json:"rawId"`
+		Type     string `json:"type"`
+		Response struct {
+			ClientDataJSON    string `json:"clientDataJSON"`
+			AttestationObject string `json:"attestationObject"`
+		} `json:"response"`
+	}
+
+	AssertionResponse struct {
+		ID       string `json:"id"`
+		RawID    string `json:"rawId"`
+		Type     string `json:"type"`
+		Response struct {
+			ClientDataJSON    string `json:"clientDataJSON"`
+			AuthenticatorData string `json:"authenticatorData"`
+			Signature         string `json:"signature"`
+			UserHandle        string `json:"userHandle,omitempty"`
+		} `json:"response"`
+	}
+
+	CollectedClientData struct {
+		Type        string `json:"type"`
+		Challenge   string `json:"challenge"`
+		Origin      string `json:"origin"`
+		CrossOrigin bool   `json:"crossOrigin,omitempty"`
+	}
+
+	attestationObject struct {
+		Fmt      string          `cbor:"fmt"`
+		AttStmt  cbor.RawMessage `cbor:"attStmt"`
+		AuthData []byte          `cbor:"authData"`
+	}
+
+	authenticatorData struct {
+		rpIDHash  []byte
+		flags     byte
+		signCount uint32
+
+		// only in registration
+		aaguid       []byte
+		credentialID []byte
+		publicKey    []byte
+	}
+)
+
+const (
+	PublicKeyType = "public-key"
+
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+
+	// authenticator data flags
+	FlagUserPresent            = 0x01
+	FlagUserVerified           = 0x04
+	FlagAttestedCredentialData = 0x40
+	FlagExtensionData          = 0x80
+
+	authDataMinLength = 37
+	aaguidLength      = 16
+)
+
+var b64 = base64.RawURLEncoding
+
+// decodeB64 accepts base64url with or without padding,
+// browsers and libraries do not agree on it
+func decodeB64(s string) ([]byte, error) {
+	return b64.DecodeString(string(bytes.TrimRight([]byte(s), "=")))
+}
+
+// checkClientData verifies what the browser says about the ceremony
+func (rp *RelyingParty) checkClientData(raw []byte, typ string, challenge []byte) error {
+	var cd CollectedClientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return invalid(ErrMalformed, "client data is not json")
+	}
+	if cd.Type != typ {
+		return invalid(ErrWrongType, cd.Type)
+	}
+	got, err := decodeB64(cd.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return invalid(ErrChallengeMismatch, "")
+	}
+	for _, o := range rp.cfg.Origins {
+		if cd.Origin == o {
+			return nil
+		}
+	}
+	return invalid(ErrOriginMismatch, cd.Origin)
+}
+
+// checkAuthData verifies flags and rp id hash that are the same for both ceremonies
+func (rp *RelyingParty) checkAuthData(ad authenticatorData) error {
+	hash := sha256.Sum256([]byte(rp.cfg.RPID))
+	if subtle.ConstantTimeCompare(ad.rpIDHash, hash[:]) != 1 {
+		return invalid(ErrRPIDMismatch, "")
+	}
+	if ad.flags&FlagUserPresent == 0 {
+		return invalid(ErrUserNotPresent, "")
+	}
+	if ad.flags&FlagUserVerified == 0 {
+		return invalid(ErrUserNotVerified, "")
+	}
+	return nil
+}
+
+// parseAuthData reads authenticator data, see
+// https://www.w3.org/TR/webauthn-2/#sctn-authenticator-data
+func parseAuthData(data []byte) (authenticatorData, error) {
+	if len(data) < authDataMinLength {
+		return authenticatorData{}, invalid(ErrMalformed, "authenticator data is too short")
+	}
+	ad := authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[authDataMinLength:]
+	if ad.flags&FlagAttestedCredentialData == 0 {
+		return ad, nil
+	}
+
+	if len(rest) < aaguidLength+2 {
+		return authenticatorData{}, invalid(ErrMalformed, "attested credential data is too short")
+	}
+	ad.aaguid = rest[:aaguidLength]
+	idLength := int(binary.BigEndian.Uint16(rest[aaguidLength:]))
+	rest = rest[aaguidLength+2:]
+	if len(rest) < idLength {
+		return authenticatorData{}, invalid(ErrMalformed, "credential id is too short")
+	}
+	ad.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	// public key is followed by extensions, only decoder knows where it ends
+	var key cbor.RawMessage
+	dec := cbor.NewDecoder(bytes.NewReader(rest))
+	if err := dec.Decode(&key); err != nil {
+		return authenticatorData{}, invalid(ErrMalformed, "public key is not cbor")
+	}
+	ad.publicKey = rest[:dec.NumBytesRead()]
+	return ad, nil
+}
@@ -0,0 +1,192 @@
+// Package softauthn is a software authenticator for tests. It makes
+// ES256 passkeys in memory and answers the same JSON a browser
+// would send after navigator.credentials.create() and get().
+package softauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/webauthn"
+)
+
+var (
+	ErrExcluded     = errors.New("softauthn: authenticator already has a credential for the user")
+	ErrNoCredential = errors.New("softauthn: no credential for the relying party")
+	ErrUnsupported  = errors.New("softauthn: ES256 is not among requested algorithms")
+)
+
+var b64 = base64.RawURLEncoding
+
+type (
+	credential struct {
+		id         []byte
+		rpID       string
+		userHandle string
+		key        *ecdsa.PrivateKey
+		signCount  uint32
+	}
+
+	Authenticator struct {
+		origin string
+
+		mu          sync.Mutex
+		credentials []*credential
+	}
+)
+
+// New returns an authenticator that runs ceremonies as if on origin
+func New(origin string) *Authenticator {
+	return &Authenticator{origin: origin}
+}
+
+// Register answers creation options with an attestation response
+func (a *Authenticator) Register(options []byte) ([]byte, error) {
+	var opts webauthn.CreationOptions
+	if err := json.Unmarshal(options, &opts); err != nil {
+		return nil, err
+	}
+	supported := false
+	for _, p := range opts.PubKeyCredParams {
+		supported = supported || p.Alg == webauthn.AlgES256
+	}
+	if !supported {
+		return nil, ErrUnsupported
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, c := range a.credentials {
+		for _, ex := range opts.ExcludeCredentials {
+			if c.rpID == opts.RP.ID && b64.EncodeToString(c.id) == ex.ID {
+				return nil, ErrExcluded
+			}
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	c := &credential{id: make([]byte, 16), rpID: opts.RP.ID, userHandle: opts.User.ID, key: key}
+	if _, err := rand.Read(c.id); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := cbor.Marshal(map[int]interface{}{
+		1: 2, 3: webauthn.AlgES256, -1: 1,
+		-2: padded(key.X.Bytes()), -3: padded(key.Y.Bytes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	authData := c.authData(webauthn.FlagUserPresent | webauthn.FlagUserVerified | webauthn.FlagAttestedCredentialData)
+	authData = append(authData, make([]byte, 16)...) // aaguid
+	idLength := make([]byte, 2)
+	binary.BigEndian.PutUint16(idLength, uint16(len(c.id)))
+	authData = append(authData, idLength...)
+	authData = append(authData, c.id...)
+	authData = append(authData, publicKey...)
+
+	attestation, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clientData, err := a.clientData("webauthn.create", opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	a.credentials = append(a.credentials, c)
+
+	var resp webauthn.AttestationResponse
+	resp.ID = b64.EncodeToString(c.id)
+	resp.RawID = resp.ID
+	resp.Type = webauthn.PublicKeyType
+	resp.Response.ClientDataJSON = b64.EncodeToString(clientData)
+	resp.Response.AttestationObject = b64.EncodeToString(attestation)
+	return json.Marshal(resp)
+}
+
+// Login answers request options with an assertion made by
+// the newest allowed credential or any discoverable one of the rp
+func (a *Authenticator) Login(options []byte) ([]byte, error) {
+	var opts webauthn.RequestOptions
+	if err := json.Unmarshal(options, &opts); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var c *credential
+	for i := len(a.credentials) - 1; i >= 0 && c == nil; i-- {
+		cred := a.credentials[i]
+		if cred.rpID != opts.RPID {
+			continue
+		}
+		if len(opts.AllowCredentials) == 0 {
+			c = cred
+		}
+		for _, allowed := range opts.AllowCredentials {
+			if allowed.ID == b64.EncodeToString(cred.id) {
+				c = cred
+			}
+		}
+	}
+	if c == nil {
+		return nil, ErrNoCredential
+	}
+
+	c.signCount++
+	authData := c.authData(webauthn.FlagUserPresent | webauthn.FlagUserVerified)
+	clientData, err := a.clientData("webauthn.get", opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientData)
+	hash := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, c.key, hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var resp webauthn.AssertionResponse
+	resp.ID = b64.EncodeToString(c.id)
+	resp.RawID = resp.ID
+	resp.Type = webauthn.PublicKeyType
+	resp.Response.ClientDataJSON = b64.EncodeToString(clientData)
+	resp.Response.AuthenticatorData = b64.EncodeToString(authData)
+	resp.Response.Signature = b64.EncodeToString(signature)
+	resp.Response.UserHandle = c.userHandle
+	return json.Marshal(resp)
+}
+
+func (a *Authenticator) clientData(typ, challenge string) ([]byte, error) {
+	return json.Marshal(webauthn.CollectedClientData{Type: typ, Challenge: challenge, Origin: a.origin})
+}
+
+func (c *credential) authData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+	data := make([]byte, 37)
+	copy(data, rpIDHash[:])
+	data[32] = flags
+	binary.BigEndian.PutUint32(data[33:], c.signCount)
+	return data
+}
+
+func padded(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
@@ -0,0 +1,224 @@
+// Package webauthn is the relying party side of WebAuthn ceremonies,
+// https://www.w3.org/TR/webauthn-2/. We ask for passkeys that verify
+// the user and do not care about attestation, so only "none"-like
+// verification is done and the statement is ignored.
+package webauthn
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+type (
+	Config struct {
+		// RPID is the domain passkeys are bound to, like "micro-pizzas.com"
+		RPID   string
+		RPName string
+		// Origins are where ceremonies may happen, like "https://micro-pizzas.com"
+		Origins []string
+		Timeout time.Duration
+	}
+
+	// RelyingParty makes options for navigator.credentials
+	// and verifies what authenticators respond with.
+	RelyingParty struct {
+		cfg Config
+	}
+)
+
+var _ domain.WebAuthn = (*RelyingParty)(nil)
+
+func NewRelyingParty(cfg Config) (*RelyingParty, error) {
+	if cfg.RPID == "" || len(cfg.Origins) == 0 {
+		return nil, ErrInvalidConfig
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = cfg.RPID
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &RelyingParty{cfg: cfg}, nil
+}
+
+func (rp *RelyingParty) RegistrationOptions(challenge []byte, u domain.User, exclude []domain.Passkey) ([]byte, error) {
+	name := u.Email
+	if name == "" {
+		name = u.PhoneNumber
+	}
+	params := make([]CredentialParameter, len(supportedAlgorithms))
+	for i, alg := range supportedAlgorithms {
+		params[i] = CredentialParameter{Type: PublicKeyType, Alg: alg}
+	}
+	return json.Marshal(CreationOptions{
+		Challenge: b64.EncodeToString(challenge),
+		RP:        RelyingPartyEntity{ID: rp.cfg.RPID, Name: rp.cfg.RPName},
+		User: UserEntity{
+			// user handle comes back on sign in, it must not be personal data
+			ID:          UserHandle(u.ID),
+			Name:        name,
+			DisplayName: u.FullName,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            rp.cfg.Timeout.Milliseconds(),
+		ExcludeCredentials: descriptors(exclude),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "required",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	})
+}
+
+func (rp *RelyingParty) VerifyRegistration(challenge, response []byte) (domain.Passkey, error) {
+	var resp AttestationResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return domain.Passkey{}, invalid(ErrMalformed, "response is not json")
+	}
+	if resp.Type != PublicKeyType {
+		return domain.Passkey{}, invalid(ErrMalformed, "unknown credential type "+resp.Type)
+	}
+	clientData, err := decodeB64(resp.Response.ClientDataJSON)
+	if err != nil {
+		return domain.Passkey{}, invalid(ErrMalformed, "clientDataJSON is not base64url")
+	}
+	if err := rp.checkClientData(clientData, ceremonyCreate, challenge); err != nil {
+		return domain.Passkey{}, err
+	}
+
+	rawAttestation, err := decodeB64(resp.Response.AttestationObject)
+	if err != nil {
+		return domain.Passkey{}, invalid(ErrMalformed, "attestationObject is not base64url")
+	}
+	var att attestationObject
+	if err := cbor.Unmarshal(rawAttestation, &att); err != nil {
+		return domain.Passkey{}, invalid(ErrMalformed, "attestationObject is not cbor")
+	}
+	ad, err := parseAuthData(att.AuthData)
+	if err != nil {
+		return domain.Passkey{}, err
+	}
+	if err := rp.checkAuthData(ad); err != nil {
+		return domain.Passkey{}, err
+	}
+	if ad.credentialID == nil {
+		return domain.Passkey{}, invalid(ErrMalformed, "no attested credential data")
+	}
+	// make sure we will be able to verify assertions before storing the key
+	if _, err := parsePublicKey(ad.publicKey); err != nil {
+		return domain.Passkey{}, err
+	}
+
+	return domain.Passkey{
+		ID:        b64.EncodeToString(ad.credentialID),
+		PublicKey: ad.publicKey,
+		SignCount: ad.signCount,
+		AAGUID:    ad.aaguid,
+	}, nil
+}
+
+func (rp *RelyingParty) AssertionOptions(challenge []byte, allow []domain.Passkey) ([]byte, error) {
+	return json.Marshal(RequestOptions{
+		Challenge:        b64.EncodeToString(challenge),
+		Timeout:          rp.cfg.Timeout.Milliseconds(),
+		RPID:             rp.cfg.RPID,
+		AllowCredentials: descriptors(allow),
+		UserVerification: "required",
+	})
+}
+
+func (rp *RelyingParty) AssertedPasskey(response []byte) (string, error) {
+	var resp AssertionResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return "", invalid(ErrMalformed, "response is not json")
+	}
+	id, err := decodeB64(resp.RawID)
+	if err != nil || len(id) == 0 {
+		return "", invalid(ErrMalformed, "rawId is not base64url")
+	}
+	return b64.EncodeToString(id), nil
+}
+
+func (rp *RelyingParty) VerifyAssertion(challenge []byte, p domain.Passkey, response []byte) (uint32, error) {
+	var resp AssertionResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return 0, invalid(ErrMalformed, "response is not json")
+	}
+	if resp.Type != PublicKeyType {
+		return 0, invalid(ErrMalformed, "unknown credential type "+resp.Type)
+	}
+	if id, err := rp.AssertedPasskey(response); err != nil {
+		return 0, err
+	} else if id != p.ID {
+		return 0, invalid(ErrCredentialMismatch, "")
+	}
+	if resp.Response.UserHandle != "" {
+		handle, err := decodeB64(resp.Response.UserHandle)
+		if err != nil || string(handle) != string(userHandle(p.UserID)) {
+			return 0, invalid(ErrCredentialMismatch, "user handle does not match")
+		}
+	}
+
+	clientData, err := decodeB64(resp.Response.ClientDataJSON)
+	if err != nil {
+		return 0, invalid(ErrMalformed, "clientDataJSON is not base64url")
+	}
+	if err := rp.checkClientData(clientData, ceremonyGet, challenge); err != nil {
+		return 0, err
+	}
+	rawAuthData, err := decodeB64(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, invalid(ErrMalformed, "authenticatorData is not base64url")
+	}
+	ad, err := parseAuthData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.checkAuthData(ad); err != nil {
+		return 0, err
+	}
+
+	signature, err := decodeB64(resp.Response.Signature)
+	if err != nil {
+		return 0, invalid(ErrMalformed, "signature is not base64url")
+	}
+	key, err := parsePublicKey(p.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(clientData)
+	if err := key.verify(append(rawAuthData, clientDataHash[:]...), signature); err != nil {
+		return 0, err
+	}
+
+	// authenticators that do not count always send 0
+	if (ad.signCount != 0 || p.SignCount != 0) && ad.signCount <= p.SignCount {
+		return 0, invalid(ErrSignCount, "")
+	}
+	return ad.signCount, nil
+}
+
+// UserHandle is user.id in creation options of the user with id.
+// Authenticators return it on sign in as userHandle.
+func UserHandle(id domain.ID) string {
+	return b64.EncodeToString(userHandle(id))
+}
+
+func userHandle(id domain.ID) []byte {
+	return []byte(strconv.FormatUint(uint64(id), 10))
+}
+
+func descriptors(passkeys []domain.Passkey) []CredentialDescriptor {
+	d := make([]CredentialDescriptor, len(passkeys))
+	for i, p := range passkeys {
+		d[i] = CredentialDescriptor{Type: PublicKeyType, ID: p.ID}
+	}
+	return d
+}
@@ -0,0 +1,192 @@
+package webauthn_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/domain"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/webauthn"
+	"github.com/rasulov-emirlan/micro-pizzas/backends/users/internal/webauthn/softauthn"
+)
+
+const (
+	rpID   = "micro-pizzas.com"
+	origin = "https://micro-pizzas.com"
+)
+
+var user = domain.User{ID: 42, FullName: "John Doe", Email: "john@micro-pizzas.com"}
+
+func newRelyingParty(t *testing.T, rpID string) *webauthn.RelyingParty {
+	t.Helper()
+	rp, err := webauthn.NewRelyingParty(webauthn.Config{RPID: rpID, Origins: []string{origin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+// register runs registration ceremony and returns the passkey
+func register(t *testing.T, rp *webauthn.RelyingParty, auth *softauthn.Authenticator) domain.Passkey {
+	t.Helper()
+	challenge := []byte("registration challenge of 32 byte")
+	options, err := rp.RegistrationOptions(challenge, user, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := auth.Register(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := rp.VerifyRegistration(challenge, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.UserID = user.ID
+	return p
+}
+
+func TestRegistration(t *testing.T) {
+	challenge := []byte("registration challenge of 32 byte")
+
+	testCases := []struct {
+		name      string
+		rpID      string
+		origin    string
+		challenge []byte
+		want      error
+	}{
+		{name: "valid", rpID: rpID, origin: origin, challenge: challenge},
+		{name: "wrong challenge", rpID: rpID, origin: origin, challenge: []byte("another challenge"), want: webauthn.ErrChallengeMismatch},
+		{name: "wrong origin", rpID: rpID, origin: "https://evil.com", challenge: challenge, want: webauthn.ErrOriginMismatch},
+		{name: "wrong rp id", rpID: "evil.com", origin: origin, challenge: challenge, want: webauthn.ErrRPIDMismatch},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rp := newRelyingParty(t, rpID)
+			options, err := newRelyingParty(t, tc.rpID).RegistrationOptions(tc.challenge, user, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := softauthn.New(tc.origin).Register(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := rp.VerifyRegistration(challenge, resp)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+			if tc.want != nil {
+				if !errors.Is(err, domain.ErrInvalidPasskey) {
+					t.Errorf("error %v is not domain.ErrInvalidPasskey", err)
+				}
+				return
+			}
+			if p.ID == "" || len(p.PublicKey) == 0 || len(p.AAGUID) != 16 {
+				t.Errorf("passkey is incomplete: %+v", p)
+			}
+		})
+	}
+}
+
+func TestRegistrationOptionsExcludeExisting(t *testing.T) {
+	rp := newRelyingParty(t, rpID)
+	auth := softauthn.New(origin)
+	p := register(t, rp, auth)
+
+	options, err := rp.RegistrationOptions([]byte("challenge"), user, []domain.Passkey{p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opts webauthn.CreationOptions
+	if err := json.Unmarshal(options, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.User.ID != webauthn.UserHandle(user.ID) || opts.User.Name != user.Email {
+		t.Errorf("got user %+v", opts.User)
+	}
+	if _, err := auth.Register(options); !errors.Is(err, softauthn.ErrExcluded) {
+		t.Errorf("authenticator made a second passkey: %v", err)
+	}
+}
+
+func TestAssertion(t *testing.T) {
+	challenge := []byte("assertion challenge of 32 bytes!")
+
+	testCases := []struct {
+		name string
+		// change passkey the way db would return it
+		passkey   func(p domain.Passkey, other domain.Passkey) domain.Passkey
+		challenge []byte
+		want      error
+	}{
+		{
+			name:      "valid",
+			passkey:   func(p, _ domain.Passkey) domain.Passkey { return p },
+			challenge: challenge,
+		},
+		{
+			name:      "wrong challenge",
+			passkey:   func(p, _ domain.Passkey) domain.Passkey { return p },
+			challenge: []byte("another challenge"),
+			want:      webauthn.ErrChallengeMismatch,
+		},
+		{
+			name: "signed by another key",
+			passkey: func(p, other domain.Passkey) domain.Passkey {
+				p.PublicKey = other.PublicKey
+				return p
+			},
+			challenge: challenge,
+			want:      webauthn.ErrSignature,
+		},
+		{
+			name: "another user",
+			passkey: func(p, _ domain.Passkey) domain.Passkey {
+				p.UserID++
+				return p
+			},
+			challenge: challenge,
+			want:      webauthn.ErrCredentialMismatch,
+		},
+		{
+			name: "cloned authenticator",
+			passkey: func(p, _ domain.Passkey) domain.Passkey {
+				p.SignCount = 10
+				return p
+			},
+			challenge: challenge,
+			want:      webauthn.ErrSignCount,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rp := newRelyingParty(t, rpID)
+			auth := softauthn.New(origin)
+			other := register(t, rp, softauthn.New(origin))
+			p := register(t, rp, auth)
+
+			options, err := rp.AssertionOptions(tc.challenge, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := auth.Login(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, err := rp.AssertedPasskey(resp)
+			if err != nil || id != p.ID {
+				t.Fatalf("got passkey %q %v, want %q", id, err, p.ID)
+			}
+
+			signCount, err := rp.VerifyAssertion(challenge, tc.passkey(p, other), resp)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+			if tc.want == nil && signCount != p.SignCount+1 {
+				t.Errorf("got sign count %d, want %d", signCount, p.SignCount+1)
+			}
+		})
+	}
+}